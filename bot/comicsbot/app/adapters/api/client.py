@@ -27,8 +27,9 @@ class ApiClient:
         r.raise_for_status()
         return ComicRef.model_validate(r.json())
 
-    async def random_comic(self) -> ComicRef:
-        r = await self.client.get(f"{self.base_url}/api/comics/random")
+    async def random_comic(self, exclude: list[int] | None = None) -> ComicRef:
+        params = {"exclude": ",".join(str(i) for i in exclude)} if exclude else None
+        r = await self.client.get(f"{self.base_url}/api/comics/random", params=params)
         r.raise_for_status()
         return ComicRef.model_validate(r.json())
 
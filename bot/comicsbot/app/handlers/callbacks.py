@@ -11,6 +11,8 @@ from app.services.session import (
     get_or_login_token,
     is_saved,
     set_saved_in_cache,
+    recent_random,
+    remember_random,
 )
 from app.services.tg_edit import edit_or_replace_comic
 from app.utils.comics import center_text, comic_text_fallback
@@ -428,10 +430,11 @@ async def on_random_next(call: CallbackQuery, state: FSMContext):
     api = data["api"]
 
     try:
-        comic = await api.random_comic()
+        comic = await api.random_comic(exclude=await recent_random(state))
     except httpx.HTTPError:
         await call.answer("Не могу получить случайный комикс 😔", show_alert=False)
         return
+    await remember_random(state, comic.id)
 
     # ctx обновим после replace (вдруг поменяется message_id)
     ctx = {"mode": "random", "comic_id": int(comic.id)}
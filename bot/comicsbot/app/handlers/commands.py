@@ -16,6 +16,8 @@ from app.services.session import (
     get_or_login_token,
     ensure_fav_ids_map,
     is_saved,
+    recent_random,
+    remember_random,
 )
 
 router = Router()
@@ -69,10 +71,11 @@ async def cmd_random(message: Message, state: FSMContext):
     await ensure_fav_ids_map(state, api, message.from_user, force=False)
 
     try:
-        comic = await api.random_comic()
+        comic = await api.random_comic(exclude=await recent_random(state))
     except httpx.HTTPError:
         await message.answer("❌ Не могу получить случайный комикс. Сервер недоступен.")
         return
+    await remember_random(state, comic.id)
 
     await state.set_state(BrowseState.browsing)
 
@@ -1,7 +1,7 @@
 import time
 import httpx
 from aiogram.fsm.context import FSMContext
-from app.settings import FAV_CACHE_TTL_SEC
+from app.settings import FAV_CACHE_TTL_SEC, RANDOM_HISTORY_SIZE
 
 
 async def get_or_login_token(state: FSMContext, api, tg_user) -> str:
@@ -74,3 +74,15 @@ async def set_saved_in_cache(state: FSMContext, tg_user, comic_id: int, saved: b
     entry["ts"] = int(time.time())
     fav_cache[key] = entry
     await state.update_data(fav_cache=fav_cache)
+
+
+async def recent_random(state: FSMContext) -> list[int]:
+    """Последние показанные случайные комиксы - их не запрашиваем повторно"""
+    data = await state.get_data()
+    return list(data.get("random_history") or [])
+
+
+async def remember_random(state: FSMContext, comic_id: int) -> None:
+    history = await recent_random(state)
+    history.append(int(comic_id))
+    await state.update_data(random_history=history[-RANDOM_HISTORY_SIZE:])
@@ -2,3 +2,6 @@ SEARCH_LIMIT_DEFAULT = 50
 MAX_SEARCH_SESSIONS = 50
 
 FAV_CACHE_TTL_SEC = 60
+
+# сколько последних случайных комиксов не повторять
+RANDOM_HISTORY_SIZE = 50
@@ -8,10 +8,12 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 
+	"yadro.com/course/api/adapters/grpcerr"
 	"yadro.com/course/api/core"
+	"yadro.com/course/pkg/grpcclient"
+	"yadro.com/course/pkg/health"
 	authpb "yadro.com/course/proto/auth"
 )
 
@@ -22,10 +24,13 @@ type Client struct {
 }
 
 func NewClient(address string, log *slog.Logger) (*Client, error) {
-	conn, err := grpc.NewClient(
-		address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	// Register и BotLoginTelegram создают пользователя, их не повторяем
+	conn, err := grpcclient.New(address, grpcclient.Options{
+		Idempotent: []string{
+			authpb.Auth_Ping_FullMethodName,
+			authpb.Auth_Login_FullMethodName,
+		},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("new grpc client for %s: %w", address, err)
 	}
@@ -44,32 +49,23 @@ func (c *Client) Close() error {
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.client.Ping(ctx, &emptypb.Empty{})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.ErrUnavailable
-		default:
-			return err
-		}
+		return grpcerr.ToCore(err)
 	}
 	return nil
 }
 
+// Ready - готовность сервиса вместе с его зависимостями, по grpc.health.v1
+func (c *Client) Ready(ctx context.Context) error {
+	return grpcerr.ToCore(health.Probe(ctx, c.conn))
+}
+
 func (c *Client) Register(ctx context.Context, email, password string) (string, error) {
 	resp, err := c.client.Register(ctx, &authpb.RegisterRequest{
 		Email:    email,
 		Password: password,
 	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.InvalidArgument:
-			return "", core.ErrInvalidEmail
-		case codes.AlreadyExists:
-			return "", core.ErrAlreadyExists
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return "", core.ErrUnavailable
-		default:
-			return "", err
-		}
+		return "", credentialsError(err)
 	}
 
 	return resp.GetToken(), nil
@@ -81,16 +77,7 @@ func (c *Client) Login(ctx context.Context, email, password string) (string, err
 		Password: password,
 	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.InvalidArgument:
-			return "", core.ErrInvalidEmail
-		case codes.Unauthenticated:
-			return "", core.ErrInvalidCredentials
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return "", core.ErrUnavailable
-		default:
-			return "", err
-		}
+		return "", credentialsError(err)
 	}
 
 	return resp.GetToken(), nil
@@ -106,15 +93,16 @@ func (c *Client) BotLoginTelegram(ctx context.Context, tg core.TelegramProfile)
 		},
 	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.InvalidArgument:
-			return "", core.ErrBadArguments
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return "", core.ErrUnavailable
-		default:
-			return "", err
-		}
+		return "", grpcerr.ToCore(err)
 	}
 
 	return resp.GetToken(), nil
 }
+
+// credentialsError - InvalidArgument на регистрации и входе значит только невалидный email
+func credentialsError(err error) error {
+	if status.Code(err) == codes.InvalidArgument {
+		return core.ErrInvalidEmail
+	}
+	return grpcerr.ToCore(err)
+}
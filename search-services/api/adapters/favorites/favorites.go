@@ -6,12 +6,12 @@ import (
 	"log/slog"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"yadro.com/course/api/adapters/grpcerr"
 	"yadro.com/course/api/core"
+	"yadro.com/course/pkg/grpcclient"
+	"yadro.com/course/pkg/health"
 	favoritespb "yadro.com/course/proto/favorites"
 )
 
@@ -22,10 +22,14 @@ type Client struct {
 }
 
 func NewClient(address string, log *slog.Logger) (*Client, error) {
-	conn, err := grpc.NewClient(
-		address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	// Add и Delete не повторяются: на повтор после успешной записи сервис ответит ошибкой
+	conn, err := grpcclient.New(address, grpcclient.Options{
+		Idempotent: []string{
+			favoritespb.Favorites_Ping_FullMethodName,
+			favoritespb.Favorites_List_FullMethodName,
+			favoritespb.Favorites_Popular_FullMethodName,
+		},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("new grpc client for %s: %w", address, err)
 	}
@@ -42,32 +46,23 @@ func (c *Client) Close() error { return c.conn.Close() }
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.client.Ping(ctx, &emptypb.Empty{})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.ErrUnavailable
-		default:
-			return err
-		}
+		return grpcerr.ToCore(err)
 	}
 	return nil
 }
 
+// Ready - готовность сервиса вместе с его зависимостями, по grpc.health.v1
+func (c *Client) Ready(ctx context.Context) error {
+	return grpcerr.ToCore(health.Probe(ctx, c.conn))
+}
+
 func (c *Client) Add(ctx context.Context, userID uint32, comicID int32) error {
 	_, err := c.client.Add(ctx, &favoritespb.AddRequest{
 		UserId:  userID,
 		ComicId: comicID,
 	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.InvalidArgument:
-			return core.ErrBadArguments
-		case codes.AlreadyExists:
-			return core.ErrAlreadyExists
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.ErrUnavailable
-		default:
-			return err
-		}
+		return grpcerr.ToCore(err)
 	}
 	return nil
 }
@@ -78,16 +73,7 @@ func (c *Client) Delete(ctx context.Context, userID uint32, comicID int32) error
 		ComicId: comicID,
 	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.InvalidArgument:
-			return core.ErrBadArguments
-		case codes.NotFound:
-			return core.ErrNotFound
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.ErrUnavailable
-		default:
-			return err
-		}
+		return grpcerr.ToCore(err)
 	}
 	return nil
 }
@@ -97,14 +83,7 @@ func (c *Client) List(ctx context.Context, userID uint32) ([]core.FavoriteItem,
 		UserId: userID,
 	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.InvalidArgument:
-			return nil, core.ErrBadArguments
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return nil, core.ErrUnavailable
-		default:
-			return nil, err
-		}
+		return nil, grpcerr.ToCore(err)
 	}
 
 	out := make([]core.FavoriteItem, 0, len(resp.GetItems()))
@@ -117,4 +96,20 @@ func (c *Client) List(ctx context.Context, userID uint32) ([]core.FavoriteItem,
 	return out, nil
 }
 
+func (c *Client) Popular(ctx context.Context, limit uint32) ([]core.PopularComic, error) {
+	resp, err := c.client.Popular(ctx, &favoritespb.PopularRequest{Limit: limit})
+	if err != nil {
+		return nil, grpcerr.ToCore(err)
+	}
+
+	out := make([]core.PopularComic, 0, len(resp.GetItems()))
+	for _, it := range resp.GetItems() {
+		out = append(out, core.PopularComic{
+			ComicID: int(it.GetComicId()),
+			Count:   int(it.GetCount()),
+		})
+	}
+	return out, nil
+}
+
 var _ core.Favorites = (*Client)(nil)
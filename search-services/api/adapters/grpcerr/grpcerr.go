@@ -0,0 +1,45 @@
+package grpcerr
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"yadro.com/course/api/core"
+)
+
+// ToCore - единая для всех адаптеров таблица gRPC статус -> ошибка core.
+// Для ошибок клиента к ошибке core добавляется сообщение сервиса, оно уходит в detail ответа;
+// неизвестные коды возвращаются как есть и дают 500
+func ToCore(err error) error {
+	if err == nil {
+		return nil
+	}
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.InvalidArgument, codes.OutOfRange, codes.ResourceExhausted:
+		return wrap(core.ErrBadArguments, st)
+	case codes.NotFound:
+		return wrap(core.ErrNotFound, st)
+	case codes.AlreadyExists:
+		return wrap(core.ErrAlreadyExists, st)
+	case codes.Unauthenticated:
+		return core.ErrInvalidCredentials
+	// PermissionDenied - admin-методы search и words не приняли токен api, это ошибка конфигурации
+	case codes.PermissionDenied:
+		return core.ErrAdminTokenRejected
+	// FailedPrecondition - сервис еще не готов ответить (words без словаря), повтор позже поможет
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.FailedPrecondition:
+		return core.ErrUnavailable
+	default:
+		return err
+	}
+}
+
+func wrap(target error, st *status.Status) error {
+	if st.Message() == "" {
+		return target
+	}
+	return fmt.Errorf("%w: %s", target, st.Message())
+}
@@ -0,0 +1,48 @@
+package grpcerr
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"yadro.com/course/api/core"
+	"yadro.com/course/pkg/grpcclient"
+)
+
+func TestToCore(t *testing.T) {
+	other := errors.New("boom")
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{status.Error(codes.InvalidArgument, "bad phrase"), core.ErrBadArguments},
+		{status.Error(codes.ResourceExhausted, "too long"), core.ErrBadArguments},
+		{status.Error(codes.NotFound, "comic 7 not found"), core.ErrNotFound},
+		{status.Error(codes.AlreadyExists, ""), core.ErrAlreadyExists},
+		{status.Error(codes.Unauthenticated, ""), core.ErrInvalidCredentials},
+		{status.Error(codes.PermissionDenied, "bad admin token"), core.ErrAdminTokenRejected},
+		{status.Error(codes.Unavailable, "connection refused"), core.ErrUnavailable},
+		{status.Error(codes.DeadlineExceeded, ""), core.ErrUnavailable},
+		{status.Error(codes.FailedPrecondition, "no dictionary"), core.ErrUnavailable},
+		{grpcclient.ErrBreakerOpen, core.ErrUnavailable},
+		{other, other},
+	}
+	for _, tt := range tests {
+		if got := ToCore(tt.err); !errors.Is(got, tt.want) {
+			t.Errorf("ToCore(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	if got := ToCore(status.Error(codes.NotFound, "comic 7 not found")).Error(); got != "not found: comic 7 not found" {
+		t.Errorf("message = %q", got)
+	}
+	// Internal остается как есть и в REST дает 500
+	if got := ToCore(status.Error(codes.Internal, "db down")); status.Code(got) != codes.Internal {
+		t.Errorf("internal error mapped to %v", got)
+	}
+	if ToCore(nil) != nil {
+		t.Error("nil error mapped to non-nil")
+	}
+}
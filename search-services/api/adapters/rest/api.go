@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"yadro.com/course/api/adapters/rest/middleware"
+	"yadro.com/course/api/pkg/problem"
 	"yadro.com/course/api/pkg/res"
 
 	"yadro.com/course/api/core"
@@ -25,14 +28,14 @@ func NewPingHandler(log *slog.Logger, pingers map[string]core.Pinger, timeout ti
 		for name, p := range pingers {
 			if err := p.Ping(ctx); err != nil {
 				replies[name] = "unavailable"
-				log.Warn("ping failed", "service", name, "error", err)
+				log.WarnContext(r.Context(), "ping failed", "service", name, "error", err)
 			} else {
 				replies[name] = "ok"
 			}
 		}
 
 		res.Json(w, pingResponse{Replies: replies}, http.StatusOK)
-		log.Info("ping handled", "replies", replies, "duration", time.Since(start))
+		log.InfoContext(r.Context(), "ping handled", "replies", replies, "duration", time.Since(start))
 	}
 }
 
@@ -41,24 +44,21 @@ func NewPingHandler(log *slog.Logger, pingers map[string]core.Pinger, timeout ti
 func NewUpdateHandler(log *slog.Logger, updater core.Updater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		ctx := context.Background()
+		// Update идет дольше запроса: отмену клиента не наследуем, а request id и trace span - да
+		ctx := context.WithoutCancel(r.Context())
 
 		if err := updater.Update(ctx); err != nil {
-			switch {
-			case errors.Is(err, core.ErrAlreadyExists):
+			if errors.Is(err, core.ErrAlreadyExists) {
 				// идемпотентный повтор - задача уже запущена
 				res.Json(w, updateStatusResponse{Status: "already running"}, http.StatusAccepted)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("update failed", slog.Any("err", err))
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
+				return
 			}
+			writeError(w, r, log, "update", err)
 			return
 		}
 
 		res.Json(w, updateStatusResponse{Status: "started"}, http.StatusOK)
-		log.Info("update started", "duration", time.Since(start))
+		log.InfoContext(r.Context(), "update started", "duration", time.Since(start))
 	}
 }
 
@@ -70,12 +70,7 @@ func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater, timeout time.
 
 		st, err := updater.Stats(ctx)
 		if err != nil {
-			if errors.Is(err, core.ErrUnavailable) {
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			} else {
-				log.Error("stats failed", slog.Any("err", err))
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "stats", err)
 			return
 		}
 
@@ -86,7 +81,7 @@ func NewUpdateStatsHandler(log *slog.Logger, updater core.Updater, timeout time.
 			ComicsTotal:   st.ComicsTotal,
 		}, http.StatusOK)
 
-		log.Info(
+		log.InfoContext(r.Context(),
 			"stats ok",
 			"words_total", st.WordsTotal,
 			"words_unique", st.WordsUnique,
@@ -105,17 +100,12 @@ func NewUpdateStatusHandler(log *slog.Logger, updater core.Updater, timeout time
 
 		st, err := updater.Status(ctx)
 		if err != nil {
-			if errors.Is(err, core.ErrUnavailable) {
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			} else {
-				log.Error("status failed", slog.Any("err", err))
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "status", err)
 			return
 		}
 
 		res.Json(w, updateStatusResponse{Status: string(st)}, http.StatusOK)
-		log.Info("status ok", "status", st, "duration", time.Since(start))
+		log.InfoContext(r.Context(), "status ok", "status", st, "duration", time.Since(start))
 	}
 }
 
@@ -126,22 +116,17 @@ func NewDropHandler(log *slog.Logger, updater core.Updater, timeout time.Duratio
 		defer cancel()
 
 		if err := updater.Drop(ctx); err != nil {
-			if errors.Is(err, core.ErrUnavailable) {
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			} else {
-				log.Error("drop failed", slog.Any("err", err))
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "drop", err)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
-		log.Info("drop ok", "duration", time.Since(start))
+		log.InfoContext(r.Context(), "drop ok", "duration", time.Since(start))
 	}
 }
 
 // SEARCH HANDLERS
 
-func NewSearchHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
+func NewSearchHandler(log *slog.Logger, search core.Searcher, fav core.Favorites, corrector core.Corrector, timeout time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -157,50 +142,239 @@ func NewSearchHandler(log *slog.Logger, search core.Searcher, timeout time.Durat
 		if limitStr := q.Get("limit"); limitStr != "" {
 			n, err := strconv.ParseUint(limitStr, 10, 32)
 			if err != nil {
-				res.Json(w, errorResponse{Error: "bad limit"}, http.StatusBadRequest)
+				writeInvalid(w, r, "limit", "must be a non-negative integer")
 				return
 			}
 			limit = uint32(n)
 		}
 
-		result, err := search.Find(ctx, phrase, limit)
+		explain, err := parseBoolParam(r, "explain")
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrBadArguments):
-				res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("search failed", "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeInvalid(w, r, "explain", err.Error())
 			return
 		}
 
-		comics := make([]comicResponse, 0, len(result.Comics))
-		for _, cmt := range result.Comics {
-			comics = append(comics, comicResponse{
-				ID:  cmt.ID,
-				URL: cmt.URL,
-			})
+		filters, bad, ok := parseSearchFilters(r)
+		if !ok {
+			writeInvalid(w, r, bad.Field, bad.Message)
+			return
+		}
+		if filters.OnlyIDs && !favoriteIDs(ctx, w, r, log, fav, &filters) {
+			return
+		}
+		// 0 - анонимный запрос, токен на поиске необязателен
+		userID, _ := middleware.UserIDFromContext(r.Context())
+
+		result, err := search.Find(ctx, core.SearchQuery{
+			Phrase:  phrase,
+			Limit:   limit,
+			Explain: explain,
+			Profile: q.Get("profile"),
+			Filters: filters,
+			UserID:  userID,
+		})
+		if err != nil {
+			writeError(w, r, log, "search", err)
+			return
 		}
 
-		res.Json(w, searchResponse{
-			Comics: comics,
-			Total:  result.Total,
-		}, http.StatusOK)
+		resp := newSearchResponse(result)
+		// total у /api/search исторически - число комиксов в ответе (на это завязаны клиенты и тесты),
+		// все совпадения отдаются в matches
+		resp.Total = len(resp.Comics)
+		if result.Total == 0 {
+			didYouMean(ctx, log, corrector, phrase, &resp)
+		}
+		res.Json(w, resp, http.StatusOK)
 
-		log.Info(
+		log.InfoContext(r.Context(),
 			"search ok",
 			"phrase", phrase,
 			"limit", limit,
+			"explain", explain,
+			"profile", q.Get("profile"),
 			"total", result.Total,
 			"duration", time.Since(start),
 		)
 	}
 }
 
-func NewIndexedSearchHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
+// parseSearchFilters - from/to в формате YYYY-MM-DD, id_from/id_to, has_transcript, only_favorites;
+// ids избранного заполняются отдельно через favoriteIDs. При ошибке возвращает параметр, который не разобрался
+func parseSearchFilters(r *http.Request) (core.SearchFilters, problem.FieldError, bool) {
+	q := r.URL.Query()
+	var f core.SearchFilters
+
+	for _, d := range []struct {
+		param string
+		dst   *string
+	}{{"from", &f.PublishedFrom}, {"to", &f.PublishedTo}} {
+		v := q.Get(d.param)
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, v); err != nil {
+			return core.SearchFilters{}, problem.FieldError{Field: d.param, Message: "must be a date YYYY-MM-DD"}, false
+		}
+		*d.dst = v
+	}
+
+	for _, id := range []struct {
+		param string
+		dst   *int
+	}{{"id_from", &f.IDFrom}, {"id_to", &f.IDTo}} {
+		v := q.Get(id.param)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return core.SearchFilters{}, problem.FieldError{Field: id.param, Message: "must be a positive integer"}, false
+		}
+		*id.dst = n
+	}
+
+	if v := q.Get("has_transcript"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return core.SearchFilters{}, problem.FieldError{Field: "has_transcript", Message: "must be a boolean"}, false
+		}
+		f.HasTranscript = &b
+	}
+
+	onlyFav, err := parseBoolParam(r, "only_favorites")
+	if err != nil {
+		return core.SearchFilters{}, problem.FieldError{Field: "only_favorites", Message: err.Error()}, false
+	}
+	f.OnlyIDs = onlyFav
+
+	return f, problem.FieldError{}, true
+}
+
+// favoriteIDs - подставляет в фильтр избранное пользователя из токена;
+// при ошибке сам пишет ответ и возвращает false
+func favoriteIDs(ctx context.Context, w http.ResponseWriter, r *http.Request, log *slog.Logger, fav core.Favorites, f *core.SearchFilters) bool {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userID == 0 {
+		writeUnauthorized(w, r)
+		return false
+	}
+
+	items, err := fav.List(ctx, userID)
+	if err != nil {
+		writeError(w, r, log, "favorites list", err)
+		return false
+	}
+
+	f.IDs = make([]int, 0, len(items))
+	for _, it := range items {
+		f.IDs = append(f.IDs, int(it.ComicID))
+	}
+	return true
+}
+
+// parseBoolParam - булев параметр запроса name, пустое значение означает false
+func parseBoolParam(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean, got %q", name, v)
+	}
+	return b, nil
+}
+
+func newSearchResponse(result core.SearchResult) searchResponse {
+	comics := make([]comicResponse, 0, len(result.Comics))
+	for _, cmt := range result.Comics {
+		comics = append(comics, comicResponse{
+			ID:      cmt.ID,
+			URL:     cmt.URL,
+			Explain: newExplanationResponse(cmt.Explain),
+		})
+	}
+
+	out := searchResponse{
+		Comics:  comics,
+		Total:   result.Total,
+		Matches: result.Total,
+		Facets: facetsResponse{
+			Years:   newFacetCounts(result.Facets.Years),
+			Sources: newFacetCounts(result.Facets.Sources),
+		},
+	}
+	// токены запроса отдаем только вместе с explain, обычный ответ не меняется
+	if len(comics) > 0 && comics[0].Explain != nil {
+		out.Tokens = result.Tokens
+	}
+	return out
+}
+
+// didYouMean - исправление запроса с пустой выдачей; ошибка words не ломает поиск,
+// ответ просто остается без подсказки
+func didYouMean(ctx context.Context, log *slog.Logger, corrector core.Corrector, phrase string, resp *searchResponse) {
+	if corrector == nil || strings.TrimSpace(phrase) == "" {
+		return
+	}
+	c, err := corrector.Correct(ctx, phrase)
+	if err != nil {
+		log.DebugContext(ctx, "correct failed", "phrase", phrase, "error", err)
+		return
+	}
+	resp.DidYouMean = c.Phrase
+	for _, t := range c.Tokens {
+		cr := correctionResponse{Token: t.Token, Suggestions: make([]suggestionResponse, 0, len(t.Suggestions))}
+		for _, s := range t.Suggestions {
+			cr.Suggestions = append(cr.Suggestions, suggestionResponse{Term: s.Term, Distance: s.Distance, Count: s.Count})
+		}
+		resp.Corrections = append(resp.Corrections, cr)
+	}
+}
+
+func newFacetCounts(fc []core.FacetCount) []facetCountResponse {
+	out := make([]facetCountResponse, 0, len(fc))
+	for _, c := range fc {
+		out = append(out, facetCountResponse{Value: c.Value, Count: c.Count})
+	}
+	return out
+}
+
+func newExplanationResponse(e *core.Explanation) *explanationResponse {
+	if e == nil {
+		return nil
+	}
+
+	out := &explanationResponse{
+		Function:      e.Function,
+		Profile:       e.Profile,
+		Score:         e.Score,
+		Components:    make([]scoreComponentResponse, 0, len(e.Components)),
+		Terms:         make([]termExplainResponse, 0, len(e.Terms)),
+		MatchedFields: e.MatchedFields,
+	}
+	for _, c := range e.Components {
+		out.Components = append(out.Components, scoreComponentResponse{
+			Name:         c.Name,
+			Value:        c.Value,
+			Weight:       c.Weight,
+			Contribution: c.Contribution,
+		})
+	}
+	for _, t := range e.Terms {
+		out.Terms = append(out.Terms, termExplainResponse{
+			Token:   t.Token,
+			Fields:  t.Fields,
+			TitleTF: t.TitleTF,
+			AltTF:   t.AltTF,
+			WordsTF: t.WordsTF,
+		})
+	}
+	return out
+}
+
+func NewIndexedSearchHandler(log *slog.Logger, search core.Searcher, fav core.Favorites, corrector core.Corrector, timeout time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -214,49 +388,206 @@ func NewIndexedSearchHandler(log *slog.Logger, search core.Searcher, timeout tim
 		if limitStr := q.Get("limit"); limitStr != "" {
 			n, err := strconv.ParseUint(limitStr, 10, 32)
 			if err != nil {
-				res.Json(w, errorResponse{Error: "bad limit"}, http.StatusBadRequest)
+				writeInvalid(w, r, "limit", "must be a non-negative integer")
 				return
 			}
 			limit = uint32(n)
 		}
 
-		result, err := search.IndexedSearch(ctx, phrase, limit)
+		explain, err := parseBoolParam(r, "explain")
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrBadArguments):
-				res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("indexed search failed", "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeInvalid(w, r, "explain", err.Error())
 			return
 		}
 
-		comics := make([]comicResponse, 0, len(result.Comics))
-		for _, cmt := range result.Comics {
-			comics = append(comics, comicResponse{
-				ID:  cmt.ID,
-				URL: cmt.URL,
-			})
+		filters, bad, ok := parseSearchFilters(r)
+		if !ok {
+			writeInvalid(w, r, bad.Field, bad.Message)
+			return
+		}
+		if filters.OnlyIDs && !favoriteIDs(ctx, w, r, log, fav, &filters) {
+			return
+		}
+		// 0 - анонимный запрос, токен на поиске необязателен
+		userID, _ := middleware.UserIDFromContext(r.Context())
+
+		result, err := search.IndexedSearch(ctx, core.SearchQuery{
+			Phrase:  phrase,
+			Limit:   limit,
+			Explain: explain,
+			Profile: q.Get("profile"),
+			Filters: filters,
+			UserID:  userID,
+		})
+		if err != nil {
+			writeError(w, r, log, "indexed search", err)
+			return
 		}
 
-		res.Json(w, searchResponse{
-			Comics: comics,
-			Total:  result.Total,
-		}, http.StatusOK)
+		resp := newSearchResponse(result)
+		if result.Total == 0 {
+			didYouMean(ctx, log, corrector, phrase, &resp)
+		}
+		res.Json(w, resp, http.StatusOK)
 
-		log.Info(
+		log.InfoContext(r.Context(),
 			"indexed search ok",
 			"phrase", phrase,
 			"limit", limit,
+			"explain", explain,
+			"profile", q.Get("profile"),
 			"total", result.Total,
 			"duration", time.Since(start),
 		)
 	}
 }
 
+// STREAM HANDLERS
+// ответ в NDJSON, по комиксу на строку, каждая пачка из search сразу отправляется клиенту;
+// ошибка после начала ответа приходит последней строкой {"error": ...}
+
+func NewSearchStreamHandler(log *slog.Logger, search core.Searcher, fav core.Favorites, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		q := r.URL.Query()
+		phrase := q.Get("phrase")
+
+		// limit=0 - все результаты
+		var limit uint32
+		if limitStr := q.Get("limit"); limitStr != "" {
+			n, err := strconv.ParseUint(limitStr, 10, 32)
+			if err != nil {
+				writeInvalid(w, r, "limit", "must be a non-negative integer")
+				return
+			}
+			limit = uint32(n)
+		}
+
+		chunk, ok := parseChunk(q.Get("chunk"))
+		if !ok {
+			writeInvalid(w, r, "chunk", "must be a non-negative integer")
+			return
+		}
+
+		explain, err := parseBoolParam(r, "explain")
+		if err != nil {
+			writeInvalid(w, r, "explain", err.Error())
+			return
+		}
+
+		indexed, err := parseBoolParam(r, "indexed")
+		if err != nil {
+			writeInvalid(w, r, "indexed", err.Error())
+			return
+		}
+
+		filters, bad, ok := parseSearchFilters(r)
+		if !ok {
+			writeInvalid(w, r, bad.Field, bad.Message)
+			return
+		}
+		if filters.OnlyIDs && !favoriteIDs(ctx, w, r, log, fav, &filters) {
+			return
+		}
+		userID, _ := middleware.UserIDFromContext(r.Context())
+
+		out := res.NewNDJSON(w)
+		count := 0
+		err = search.StreamSearch(ctx, core.SearchQuery{
+			Phrase:  phrase,
+			Limit:   limit,
+			Explain: explain,
+			Profile: q.Get("profile"),
+			Filters: filters,
+			UserID:  userID,
+		}, indexed, chunk, func(comics []core.SearchComic) error {
+			for _, c := range comics {
+				if err := out.Write(comicResponse{ID: c.ID, URL: c.URL, Explain: newExplanationResponse(c.Explain)}); err != nil {
+					return err
+				}
+			}
+			count += len(comics)
+			return out.Flush()
+		})
+		if err != nil {
+			writeStreamError(w, r, out, log, "search stream", err)
+			return
+		}
+		out.Start()
+
+		log.InfoContext(r.Context(),
+			"search stream ok",
+			"phrase", phrase,
+			"indexed", indexed,
+			"limit", limit,
+			"count", count,
+			"duration", time.Since(start),
+		)
+	}
+}
+
+func NewComicsStreamHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		chunk, ok := parseChunk(r.URL.Query().Get("chunk"))
+		if !ok {
+			writeInvalid(w, r, "chunk", "must be a non-negative integer")
+			return
+		}
+
+		out := res.NewNDJSON(w)
+		count := 0
+		err := search.StreamComics(ctx, chunk, func(comics []core.SearchComic) error {
+			for _, c := range comics {
+				if err := out.Write(comicResponse{ID: c.ID, URL: c.URL}); err != nil {
+					return err
+				}
+			}
+			count += len(comics)
+			return out.Flush()
+		})
+		if err != nil {
+			writeStreamError(w, r, out, log, "comics stream", err)
+			return
+		}
+		out.Start()
+
+		log.InfoContext(r.Context(), "comics stream ok", "count", count, "duration", time.Since(start))
+	}
+}
+
+// parseChunk - размер пачки, 0 - значение по умолчанию search
+func parseChunk(s string) (uint32, bool) {
+	if s == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(n), true
+}
+
+// writeStreamError - до первой строки отвечаем обычным статусом,
+// после - статус уже ушел, ошибка дописывается строкой в поток
+func writeStreamError(w http.ResponseWriter, r *http.Request, out *res.NDJSON, log *slog.Logger, name string, err error) {
+	if out.Started() {
+		log.ErrorContext(r.Context(), name+" interrupted", "error", err)
+		_ = out.Write(streamErrorResponse{Error: problem.New(r, http.StatusInternalServerError, problem.CodeStreamInterrupted, "stream interrupted")})
+		_ = out.Flush()
+		return
+	}
+	writeError(w, r, log, name, err)
+}
+
 // SEARCH COMICS HANDLERS
 // get comics by id
 // get all(list) comics
@@ -269,35 +600,21 @@ func NewComicByIDHandler(log *slog.Logger, search core.Searcher, timeout time.Du
 		ctx, cancel := context.WithTimeout(r.Context(), timeout)
 		defer cancel()
 
-		idStr := r.PathValue("id")
-		if idStr == "" {
-			res.Json(w, errorResponse{Error: "id is required"}, http.StatusBadRequest)
-			return
-		}
-
-		id, err := strconv.Atoi(idStr)
+		id, err := strconv.Atoi(r.PathValue("id"))
 		if err != nil || id <= 0 {
-			res.Json(w, errorResponse{Error: "invalid id"}, http.StatusBadRequest)
+			writeInvalid(w, r, "id", "must be a positive integer")
 			return
 		}
 
 		comic, err := search.GetComic(ctx, id)
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrBadArguments):
-				res.Json(w, errorResponse{Error: "comic not found"}, http.StatusNotFound)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("get comic by id failed", "id", id, "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "get comic by id", err)
 			return
 		}
 
 		res.Json(w, comicResponse{ID: comic.ID, URL: comic.URL}, http.StatusOK)
 
-		log.Info("comic fetched by id",
+		log.InfoContext(r.Context(), "comic fetched by id",
 			"id", id,
 			"duration", time.Since(start),
 		)
@@ -319,7 +636,7 @@ func NewComicsListHandler(log *slog.Logger, search core.Searcher, timeout time.D
 		if pageStr := q.Get("page"); pageStr != "" {
 			n, err := strconv.ParseUint(pageStr, 10, 32)
 			if err != nil || n == 0 {
-				res.Json(w, errorResponse{Error: "bad page"}, http.StatusBadRequest)
+				writeInvalid(w, r, "page", "must be a positive integer")
 				return
 			}
 			page = uint32(n)
@@ -328,7 +645,7 @@ func NewComicsListHandler(log *slog.Logger, search core.Searcher, timeout time.D
 		if limitStr := q.Get("limit"); limitStr != "" {
 			n, err := strconv.ParseUint(limitStr, 10, 32)
 			if err != nil || n == 0 {
-				res.Json(w, errorResponse{Error: "bad limit"}, http.StatusBadRequest)
+				writeInvalid(w, r, "limit", "must be a positive integer")
 				return
 			}
 			limit = uint32(n)
@@ -336,15 +653,7 @@ func NewComicsListHandler(log *slog.Logger, search core.Searcher, timeout time.D
 
 		result, err := search.ListComics(ctx, page, limit)
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrBadArguments):
-				res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("list comics failed", "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "list comics", err)
 			return
 		}
 
@@ -357,11 +666,12 @@ func NewComicsListHandler(log *slog.Logger, search core.Searcher, timeout time.D
 		}
 
 		res.Json(w, searchResponse{
-			Comics: comics,
-			Total:  result.Total,
+			Comics:  comics,
+			Total:   result.Total,
+			Matches: result.Total,
 		}, http.StatusOK)
 
-		log.Info("comics page ok",
+		log.InfoContext(r.Context(), "comics page ok",
 			"page", page,
 			"limit", limit,
 			"total", result.Total,
@@ -370,6 +680,8 @@ func NewComicsListHandler(log *slog.Logger, search core.Searcher, timeout time.D
 	}
 }
 
+// NewRandomComicHandler - seed делает выбор воспроизводимым,
+// exclude (id через запятую) - уже показанные комиксы, чтобы бот не повторялся
 func NewRandomComicHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -377,27 +689,301 @@ func NewRandomComicHandler(log *slog.Logger, search core.Searcher, timeout time.
 		ctx, cancel := context.WithTimeout(r.Context(), timeout)
 		defer cancel()
 
-		comic, err := search.RandomComic(ctx)
+		q := r.URL.Query()
+
+		var query core.RandomQuery
+		if seedStr := q.Get("seed"); seedStr != "" {
+			seed, err := strconv.ParseUint(seedStr, 10, 64)
+			if err != nil {
+				writeInvalid(w, r, "seed", "must be a non-negative integer")
+				return
+			}
+			query.Seed = &seed
+		}
+
+		exclude, ok := parseIDList(q.Get("exclude"))
+		if !ok {
+			writeInvalid(w, r, "exclude", "must be comma-separated positive integers")
+			return
+		}
+		query.Exclude = exclude
+
+		comic, err := search.RandomComic(ctx, query)
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("get random comic failed", "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
+			writeError(w, r, log, "get random comic", err)
+			return
+		}
+
+		res.Json(w, comicResponse{ID: comic.ID, URL: comic.URL}, http.StatusOK)
+
+		log.InfoContext(r.Context(), "random comic fetched",
+			"id", comic.ID,
+			"excluded", len(exclude),
+			"duration", time.Since(start),
+		)
+	}
+}
+
+// NewComicOfTheDayHandler - date (YYYY-MM-DD, пусто - сегодня), seed,
+// popular=true - смещение к комиксам, которые чаще добавляют в избранное
+func NewComicOfTheDayHandler(log *slog.Logger, search core.Searcher, fav core.Favorites, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		q := r.URL.Query()
+
+		query := core.DailyQuery{Date: q.Get("date")}
+		if query.Date != "" {
+			if _, err := time.Parse(time.DateOnly, query.Date); err != nil {
+				writeInvalid(w, r, "date", "must be a date YYYY-MM-DD")
+				return
+			}
+		}
+		if seedStr := q.Get("seed"); seedStr != "" {
+			seed, err := strconv.ParseUint(seedStr, 10, 64)
+			if err != nil {
+				writeInvalid(w, r, "seed", "must be a non-negative integer")
+				return
 			}
+			query.Seed = seed
+		}
+
+		popular, err := parseBoolParam(r, "popular")
+		if err != nil {
+			writeInvalid(w, r, "popular", err.Error())
+			return
+		}
+		if popular {
+			// limit=0 - top по умолчанию на стороне favorites
+			items, err := fav.Popular(ctx, 0)
+			if err != nil {
+				writeError(w, r, log, "get popular favorites", err)
+				return
+			}
+			query.Popularity = items
+		}
+
+		comic, err := search.ComicOfTheDay(ctx, query)
+		if err != nil {
+			writeError(w, r, log, "get comic of the day", err)
 			return
 		}
 
 		res.Json(w, comicResponse{ID: comic.ID, URL: comic.URL}, http.StatusOK)
 
-		log.Info("random comic fetched",
+		log.InfoContext(r.Context(), "comic of the day fetched",
 			"id", comic.ID,
+			"date", query.Date,
+			"popular", popular,
+			"duration", time.Since(start),
+		)
+	}
+}
+
+// parseIDList - id через запятую, пусто - пустой список
+func parseIDList(s string) ([]int, bool) {
+	if s == "" {
+		return nil, true
+	}
+	parts := strings.Split(s, ",")
+	ids := make([]int, 0, len(parts))
+	for _, p := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || id <= 0 {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+func NewIndexStatsHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		// top=0 - значение по умолчанию на стороне search
+		var top uint32
+		if topStr := r.URL.Query().Get("top"); topStr != "" {
+			n, err := strconv.ParseUint(topStr, 10, 32)
+			if err != nil {
+				writeInvalid(w, r, "top", "must be a non-negative integer")
+				return
+			}
+			top = uint32(n)
+		}
+
+		st, err := search.IndexStats(ctx, top)
+		if err != nil {
+			writeError(w, r, log, "index stats", err)
+			return
+		}
+
+		terms := make([]termStatResponse, 0, len(st.TopTerms))
+		for _, t := range st.TopTerms {
+			terms = append(terms, termStatResponse{Term: t.Term, Docs: t.Docs})
+		}
+		versions := make([]versionStatResponse, 0, len(st.AnalyzerVersions))
+		for _, v := range st.AnalyzerVersions {
+			versions = append(versions, versionStatResponse{Version: v.Version, Docs: v.Docs})
+		}
+
+		res.Json(w, indexStatsResponse{
+			Generation:      st.Generation,
+			Terms:           st.Terms,
+			Docs:            st.Docs,
+			Postings:        st.Postings,
+			MaxPosting:      st.MaxPosting,
+			AvgPosting:      st.AvgPosting,
+			MemoryBytes:     st.MemoryBytes,
+			BuiltAtUnix:     st.BuiltAtUnix,
+			BuildDurationMs: st.BuildDurationMs,
+			Trigger:         st.Trigger,
+			TopTerms:        terms,
+			Searches:        st.Searches,
+			IndexedSearches: st.IndexedSearches,
+
+			LastRebuildError:       st.LastRebuildError,
+			LastRebuildErrorAtUnix: st.LastRebuildErrorAtUnix,
+
+			ResultCache: cacheStatsResponse(st.ResultCache),
+			NormCache:   cacheStatsResponse(st.NormCache),
+
+			AnalyzerVersions:     versions,
+			QueryAnalyzerVersion: st.QueryAnalyzerVersion,
+		}, http.StatusOK)
+
+		log.InfoContext(r.Context(), "index stats ok",
+			"generation", st.Generation,
+			"terms", st.Terms,
+			"docs", st.Docs,
 			"duration", time.Since(start),
 		)
 	}
 }
 
+func NewIndexRebuildHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		rb, err := search.RebuildIndex(ctx)
+		if err != nil {
+			writeError(w, r, log, "index rebuild", err)
+			return
+		}
+
+		res.Json(w, indexRebuildResponse{
+			Generation: rb.Generation,
+			Docs:       rb.Docs,
+			DurationMs: rb.DurationMs,
+		}, http.StatusOK)
+
+		log.InfoContext(r.Context(), "index rebuilt",
+			"generation", rb.Generation,
+			"docs", rb.Docs,
+			"rebuild_ms", rb.DurationMs,
+			"duration", time.Since(start),
+		)
+	}
+}
+
+func NewIndexVerifyHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		drift, err := search.VerifyIndex(ctx)
+		if err != nil {
+			writeError(w, r, log, "index verify", err)
+			return
+		}
+
+		res.Json(w, indexVerifyResponse{
+			Generation:      drift.Generation,
+			DBDocs:          drift.DBDocs,
+			IndexDocs:       drift.IndexDocs,
+			InSync:          drift.InSync,
+			Missing:         drift.Missing,
+			Stale:           drift.Stale,
+			Mismatched:      drift.Mismatched,
+			MissingCount:    drift.MissingCount,
+			StaleCount:      drift.StaleCount,
+			MismatchedCount: drift.MismatchedCount,
+		}, http.StatusOK)
+
+		log.InfoContext(r.Context(), "index verified",
+			"generation", drift.Generation,
+			"in_sync", drift.InSync,
+			"missing", drift.MissingCount,
+			"stale", drift.StaleCount,
+			"mismatched", drift.MismatchedCount,
+			"duration", time.Since(start),
+		)
+	}
+}
+
+// NewAnalyzeHandler - GET /api/words/analyze?phrase=...&lang=...&analyzer=...
+// что words сделал с фразой на каждой стадии, для отладки релевантности
+func NewAnalyzeHandler(log *slog.Logger, analyzer core.Analyzer, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		q := r.URL.Query()
+		phrase := q.Get("phrase")
+		if phrase == "" {
+			writeInvalid(w, r, "phrase", "must not be empty")
+			return
+		}
+
+		a, err := analyzer.Analyze(ctx, phrase, q.Get("lang"), q.Get("analyzer"))
+		if err != nil {
+			writeError(w, r, log, "analyze", err)
+			return
+		}
+
+		resp := analyzeResponse{
+			Phrase:          phrase,
+			Language:        a.Language,
+			Analyzer:        a.Analyzer,
+			AnalyzerVersion: a.AnalyzerVersion,
+			Stages:          make([]analyzeStageResponse, 0, len(a.Stages)),
+			Words:           a.Words,
+		}
+		if resp.Words == nil {
+			resp.Words = []string{}
+		}
+		for _, st := range a.Stages {
+			stage := analyzeStageResponse{Name: st.Name, Tokens: make([]analyzeTokenResponse, 0, len(st.Tokens))}
+			for _, t := range st.Tokens {
+				stage.Tokens = append(stage.Tokens, analyzeTokenResponse{
+					Text:    t.Text,
+					Start:   t.Start,
+					End:     t.End,
+					Dropped: t.Dropped,
+					Note:    t.Note,
+				})
+			}
+			resp.Stages = append(resp.Stages, stage)
+		}
+
+		res.Json(w, resp, http.StatusOK)
+		log.InfoContext(r.Context(), "analyze ok", "language", a.Language, "analyzer", a.Analyzer, "words", len(a.Words), "duration", time.Since(start))
+	}
+}
+
 // AUTH HANDLERS
 // Registers
 // Login
@@ -408,7 +994,7 @@ func NewRegisterHandler(log *slog.Logger, auth core.Auth, timeout time.Duration)
 		start := time.Now()
 
 		if r.Method != http.MethodPost {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w, r)
 			return
 		}
 
@@ -417,34 +1003,23 @@ func NewRegisterHandler(log *slog.Logger, auth core.Auth, timeout time.Duration)
 
 		var req registerRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
+			writeBadBody(w, r)
 			return
 		}
 
-		if req.Email == "" || req.Password == "" {
-			res.Json(w, errorResponse{Error: "email and password must not be empty"}, http.StatusBadRequest)
+		if !requireCredentials(w, r, req.Email, req.Password) {
 			return
 		}
 
 		token, err := auth.Register(ctx, req.Email, req.Password)
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrInvalidEmail):
-				res.Json(w, errorResponse{Error: "invalid email format"}, http.StatusBadRequest)
-			case errors.Is(err, core.ErrAlreadyExists):
-				res.Json(w, errorResponse{Error: "user already exists"}, http.StatusConflict)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("register failed", "email", req.Email, "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "register", err)
 			return
 		}
 
 		res.Json(w, tokenResponse{Token: token}, http.StatusOK)
 
-		log.Info(
+		log.InfoContext(r.Context(),
 			"user registered",
 			"email", req.Email,
 			"duration", time.Since(start),
@@ -457,7 +1032,7 @@ func NewUserLoginHandler(log *slog.Logger, auth core.Auth, timeout time.Duration
 		start := time.Now()
 
 		if r.Method != http.MethodPost {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w, r)
 			return
 		}
 
@@ -466,34 +1041,23 @@ func NewUserLoginHandler(log *slog.Logger, auth core.Auth, timeout time.Duration
 
 		var req loginRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
+			writeBadBody(w, r)
 			return
 		}
 
-		if req.Email == "" || req.Password == "" {
-			res.Json(w, errorResponse{Error: "email and password must not be empty"}, http.StatusBadRequest)
+		if !requireCredentials(w, r, req.Email, req.Password) {
 			return
 		}
 
 		token, err := auth.Login(ctx, req.Email, req.Password)
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrInvalidEmail):
-				res.Json(w, errorResponse{Error: "invalid email format"}, http.StatusBadRequest)
-			case errors.Is(err, core.ErrInvalidCredentials):
-				res.Json(w, errorResponse{Error: "invalid credentials"}, http.StatusUnauthorized)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("user login failed", "email", req.Email, "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "user login", err)
 			return
 		}
 
 		res.Json(w, tokenResponse{Token: token}, http.StatusOK)
 
-		log.Info(
+		log.InfoContext(r.Context(),
 			"user login ok",
 			"email", req.Email,
 			"duration", time.Since(start),
@@ -510,11 +1074,11 @@ func NewBotTelegramLoginHandler(log *slog.Logger, auth core.Auth, timeout time.D
 
 		var req botTelegramLoginRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
+			writeBadBody(w, r)
 			return
 		}
 		if req.TgID == 0 {
-			res.Json(w, errorResponse{Error: "tg_id is required"}, http.StatusBadRequest)
+			writeInvalid(w, r, "tg_id", "is required")
 			return
 		}
 
@@ -525,20 +1089,12 @@ func NewBotTelegramLoginHandler(log *slog.Logger, auth core.Auth, timeout time.D
 			LastName:  req.LastName,
 		})
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrBadArguments):
-				res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("bot telegram login failed", "tg_id", req.TgID, "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "bot telegram login", err)
 			return
 		}
 
 		res.Json(w, tokenResponse{Token: token}, http.StatusOK)
-		log.Info("bot telegram login ok", "tg_id", req.TgID, "duration", time.Since(start))
+		log.InfoContext(r.Context(), "bot telegram login ok", "tg_id", req.TgID, "duration", time.Since(start))
 	}
 }
 
@@ -553,7 +1109,7 @@ func NewFavoritesListHandler(log *slog.Logger, fav core.Favorites, timeout time.
 
 		userID, ok := middleware.UserIDFromContext(r.Context())
 		if !ok || userID == 0 {
-			res.Json(w, errorResponse{Error: "unauthorized"}, http.StatusUnauthorized)
+			writeUnauthorized(w, r)
 			return
 		}
 
@@ -562,15 +1118,7 @@ func NewFavoritesListHandler(log *slog.Logger, fav core.Favorites, timeout time.
 
 		items, err := fav.List(ctx, userID)
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrBadArguments):
-				res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("favorites list failed", "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "favorites list", err)
 			return
 		}
 
@@ -583,7 +1131,7 @@ func NewFavoritesListHandler(log *slog.Logger, fav core.Favorites, timeout time.
 		}
 
 		res.Json(w, resp, http.StatusOK)
-		log.Info("favorites list ok", "user_id", userID, "count", len(items), "duration", time.Since(start))
+		log.InfoContext(r.Context(), "favorites list ok", "user_id", userID, "count", len(items), "duration", time.Since(start))
 	}
 }
 
@@ -593,14 +1141,14 @@ func NewFavoritesAddHandler(log *slog.Logger, fav core.Favorites, search core.Se
 
 		userID, ok := middleware.UserIDFromContext(r.Context())
 		if !ok || userID == 0 {
-			res.Json(w, errorResponse{Error: "unauthorized"}, http.StatusUnauthorized)
+			writeUnauthorized(w, r)
 			return
 		}
 
 		idStr := r.PathValue("id")
 		comicID, err := strconv.Atoi(idStr)
 		if err != nil || comicID <= 0 {
-			res.Json(w, errorResponse{Error: "invalid id"}, http.StatusBadRequest)
+			writeInvalid(w, r, "id", "must be a positive integer")
 			return
 		}
 
@@ -610,36 +1158,18 @@ func NewFavoritesAddHandler(log *slog.Logger, fav core.Favorites, search core.Se
 		// проверяем, что комикс существует
 		_, err = search.GetComic(ctx, comicID)
 		if err != nil {
-			switch {
-			case errors.Is(err, core.ErrBadArguments):
-				res.Json(w, errorResponse{Error: "comic not found"}, http.StatusNotFound)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("get comic before favorite add failed", "comic_id", comicID, "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "get comic before favorite add", err)
 			return
 		}
 
 		// сохраняем
 		if err := fav.Add(ctx, userID, int32(comicID)); err != nil {
-			switch {
-			case errors.Is(err, core.ErrAlreadyExists):
-				res.Json(w, errorResponse{Error: "already exists"}, http.StatusConflict)
-			case errors.Is(err, core.ErrBadArguments):
-				res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("favorites add failed", "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "favorites add", err)
 			return
 		}
 
 		w.WriteHeader(http.StatusNoContent)
-		log.Info("favorites add ok", "user_id", userID, "comic_id", comicID, "duration", time.Since(start))
+		log.InfoContext(r.Context(), "favorites add ok", "user_id", userID, "comic_id", comicID, "duration", time.Since(start))
 	}
 }
 
@@ -649,14 +1179,14 @@ func NewFavoritesDeleteHandler(log *slog.Logger, fav core.Favorites, timeout tim
 
 		userID, ok := middleware.UserIDFromContext(r.Context())
 		if !ok || userID == 0 {
-			res.Json(w, errorResponse{Error: "unauthorized"}, http.StatusUnauthorized)
+			writeUnauthorized(w, r)
 			return
 		}
 
 		idStr := r.PathValue("id")
 		comicID, err := strconv.Atoi(idStr)
 		if err != nil || comicID <= 0 {
-			res.Json(w, errorResponse{Error: "invalid id"}, http.StatusBadRequest)
+			writeInvalid(w, r, "id", "must be a positive integer")
 			return
 		}
 
@@ -664,21 +1194,117 @@ func NewFavoritesDeleteHandler(log *slog.Logger, fav core.Favorites, timeout tim
 		defer cancel()
 
 		if err := fav.Delete(ctx, userID, int32(comicID)); err != nil {
-			switch {
-			case errors.Is(err, core.ErrNotFound):
-				res.Json(w, errorResponse{Error: "not found"}, http.StatusNotFound)
-			case errors.Is(err, core.ErrBadArguments):
-				res.Json(w, errorResponse{Error: "bad request"}, http.StatusBadRequest)
-			case errors.Is(err, core.ErrUnavailable):
-				res.Json(w, errorResponse{Error: "dependency unavailable"}, http.StatusServiceUnavailable)
-			default:
-				log.Error("favorites delete failed", "error", err)
-				res.Json(w, errorResponse{Error: "internal error"}, http.StatusInternalServerError)
-			}
+			writeError(w, r, log, "favorites delete", err)
 			return
 		}
 
 		w.WriteHeader(http.StatusNoContent)
-		log.Info("favorites delete ok", "user_id", userID, "comic_id", comicID, "duration", time.Since(start))
+		log.InfoContext(r.Context(), "favorites delete ok", "user_id", userID, "comic_id", comicID, "duration", time.Since(start))
+	}
+}
+
+// SEARCH ANALYTICS HANDLERS
+// window - длительность в формате Go (24h, 90m), пусто - значение по умолчанию search
+
+func NewTopQueriesHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
+	return newQueryStatsHandler(log, "top queries", search.TopQueries, timeout)
+}
+
+func NewZeroResultQueriesHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
+	return newQueryStatsHandler(log, "zero result queries", search.ZeroResultQueries, timeout)
+}
+
+type queryStatsFunc func(ctx context.Context, window time.Duration, limit uint32) ([]core.QueryStat, error)
+
+func newQueryStatsHandler(log *slog.Logger, name string, stats queryStatsFunc, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		q := r.URL.Query()
+
+		window, ok := parseWindow(q.Get("window"))
+		if !ok {
+			writeInvalid(w, r, "window", "must be a duration of at least 1s, e.g. 24h or 90m")
+			return
+		}
+
+		var limit uint32
+		if limitStr := q.Get("limit"); limitStr != "" {
+			n, err := strconv.ParseUint(limitStr, 10, 32)
+			if err != nil {
+				writeInvalid(w, r, "limit", "must be a non-negative integer")
+				return
+			}
+			limit = uint32(n)
+		}
+
+		queries, err := stats(ctx, window, limit)
+		if err != nil {
+			writeError(w, r, log, name, err)
+			return
+		}
+
+		resp := queryStatsResponse{Queries: make([]queryStatResponse, 0, len(queries))}
+		for _, qs := range queries {
+			resp.Queries = append(resp.Queries, queryStatResponse{
+				Query:        qs.Query,
+				Count:        qs.Count,
+				AvgResults:   qs.AvgResults,
+				LastSeenUnix: qs.LastSeenUnix,
+			})
+		}
+
+		res.Json(w, resp, http.StatusOK)
+		log.InfoContext(r.Context(), name+" ok", "window", window, "limit", limit, "count", len(queries), "duration", time.Since(start))
+	}
+}
+
+func NewLatencyPercentilesHandler(log *slog.Logger, search core.Searcher, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		window, ok := parseWindow(r.URL.Query().Get("window"))
+		if !ok {
+			writeInvalid(w, r, "window", "must be a duration of at least 1s, e.g. 24h or 90m")
+			return
+		}
+
+		stats, err := search.LatencyPercentiles(ctx, window)
+		if err != nil {
+			writeError(w, r, log, "latency percentiles", err)
+			return
+		}
+
+		resp := latencyResponse{Endpoints: make([]latencyStatResponse, 0, len(stats))}
+		for _, st := range stats {
+			resp.Endpoints = append(resp.Endpoints, latencyStatResponse{
+				Endpoint: st.Endpoint,
+				Count:    st.Count,
+				P50Ms:    st.P50Ms,
+				P90Ms:    st.P90Ms,
+				P99Ms:    st.P99Ms,
+			})
+		}
+
+		res.Json(w, resp, http.StatusOK)
+		log.InfoContext(r.Context(), "latency percentiles ok", "window", window, "duration", time.Since(start))
+	}
+}
+
+// parseWindow - окно не короче секунды, пусто - 0 (значение по умолчанию search)
+func parseWindow(s string) (time.Duration, bool) {
+	if s == "" {
+		return 0, true
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Second {
+		return 0, false
 	}
+	return d, true
 }
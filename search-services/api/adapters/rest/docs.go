@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Routes - ServeMux, который помнит зарегистрированные шаблоны,
+// чтобы сверить их со спецификацией
+type Routes struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func NewRoutes() *Routes {
+	return &Routes{ServeMux: http.NewServeMux()}
+}
+
+func (r *Routes) Handle(pattern string, h http.Handler) {
+	r.patterns = append(r.patterns, pattern)
+	r.ServeMux.Handle(pattern, h)
+}
+
+// Patterns - шаблоны в порядке регистрации
+func (r *Routes) Patterns() []string {
+	return slices.Clone(r.patterns)
+}
+
+// NewOpenAPIHandler - спецификация в JSON, сериализуется один раз при старте
+func NewOpenAPIHandler(log *slog.Logger, doc *openapi3.T) (http.HandlerFunc, error) {
+	body, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(body); err != nil {
+			log.Debug("write openapi spec failed", "error", err)
+		}
+	}, nil
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>xkcd search API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/api/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
+
+// NewSwaggerUIHandler - Swagger UI поверх /api/openapi.json, статика берется с CDN
+func NewSwaggerUIHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(swaggerUIPage))
+	}
+}
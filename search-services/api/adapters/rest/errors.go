@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"yadro.com/course/api/core"
+	"yadro.com/course/api/pkg/problem"
+)
+
+// errorStatuses - ошибка core -> статус и код ответа, проверяются по порядку
+var errorStatuses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{core.ErrInvalidEmail, http.StatusBadRequest, problem.CodeInvalidEmail},
+	{core.ErrBadArguments, http.StatusBadRequest, problem.CodeBadRequest},
+	{core.ErrInvalidCredentials, http.StatusUnauthorized, problem.CodeInvalidCredentials},
+	{core.ErrNotFound, http.StatusNotFound, problem.CodeNotFound},
+	{core.ErrAlreadyExists, http.StatusConflict, problem.CodeConflict},
+	{core.ErrUnavailable, http.StatusServiceUnavailable, problem.CodeUnavailable},
+	{core.ErrAdminTokenRejected, http.StatusInternalServerError, problem.CodeAdminTokenRejected},
+}
+
+// writeError - ошибка сервиса в ответ. Для ошибок клиента detail - текст ошибки
+// (с сообщением сервиса от grpcerr), для 5xx - только текст ошибки core.
+// 500 (и неизвестная ошибка) пишется в лог "<name> failed"
+func writeError(w http.ResponseWriter, r *http.Request, log *slog.Logger, name string, err error) {
+	for _, e := range errorStatuses {
+		if !errors.Is(err, e.err) {
+			continue
+		}
+		detail := err.Error()
+		if e.status >= http.StatusInternalServerError {
+			detail = e.err.Error()
+		}
+		if e.status == http.StatusInternalServerError {
+			log.ErrorContext(r.Context(), name+" failed", "error", err)
+		}
+		problem.Write(w, r, problem.New(r, e.status, e.code, detail))
+		return
+	}
+	log.ErrorContext(r.Context(), name+" failed", "error", err)
+	problem.Write(w, r, problem.New(r, http.StatusInternalServerError, problem.CodeInternal, "internal error"))
+}
+
+// writeInvalid - 400 с ошибкой по одному параметру запроса или полю тела
+func writeInvalid(w http.ResponseWriter, r *http.Request, field, message string) {
+	problem.Write(w, r, problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter, "invalid value of "+field).
+		WithField(field, message))
+}
+
+// requireCredentials - email и пароль непустые; иначе пишет 400 с ошибкой по каждому пустому полю
+func requireCredentials(w http.ResponseWriter, r *http.Request, email, password string) bool {
+	if email != "" && password != "" {
+		return true
+	}
+	p := problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter, "email and password must not be empty")
+	if email == "" {
+		p.WithField("email", "must not be empty")
+	}
+	if password == "" {
+		p.WithField("password", "must not be empty")
+	}
+	problem.Write(w, r, p)
+	return false
+}
+
+func writeBadBody(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(r, http.StatusBadRequest, problem.CodeBadRequest, "request body is not valid JSON"))
+}
+
+func writeUnauthorized(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(r, http.StatusUnauthorized, problem.CodeUnauthorized, "user token required"))
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(r, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, r.Method+" is not allowed"))
+}
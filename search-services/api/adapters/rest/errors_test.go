@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"yadro.com/course/api/core"
+	"yadro.com/course/api/pkg/problem"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{fmt.Errorf("%w: bad phrase", core.ErrBadArguments), http.StatusBadRequest, problem.CodeBadRequest, "arguments are not acceptable: bad phrase"},
+		{core.ErrUnavailable, http.StatusServiceUnavailable, problem.CodeUnavailable, "dependency unavailable"},
+		// токен api разошелся с сервисом: 500 со своим кодом, без сообщения сервиса
+		{fmt.Errorf("rebuild: %w", core.ErrAdminTokenRejected), http.StatusInternalServerError, problem.CodeAdminTokenRejected, "admin token rejected by service"},
+		{io.ErrUnexpectedEOF, http.StatusInternalServerError, problem.CodeInternal, "internal error"},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		writeError(rec, httptest.NewRequest(http.MethodGet, "/api/search", nil), log, "search", tt.err)
+
+		var p problem.Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+			t.Fatalf("%v: body %q: %v", tt.err, rec.Body, err)
+		}
+		if rec.Code != tt.status || p.Code != tt.code || p.Detail != tt.detail {
+			t.Errorf("%v: got %d %s %q, want %d %s %q", tt.err, rec.Code, p.Code, p.Detail, tt.status, tt.code, tt.detail)
+		}
+	}
+}
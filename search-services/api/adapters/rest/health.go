@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"yadro.com/course/api/core"
+	"yadro.com/course/api/pkg/res"
+)
+
+// NewLivenessHandler - /healthz: процесс api жив и отвечает, зависимости не проверяются,
+// чтобы падение одного сервиса не перезапускало api
+func NewLivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		res.Json(w, healthResponse{Status: "ok"}, http.StatusOK)
+	}
+}
+
+// NewReadinessHandler - /readyz: все сервисы готовы по grpc.health.v1. Проверки идут параллельно,
+// у каждой свой timeout, так что один зависший сервис не съедает время остальных.
+// Не готов хотя бы один - 503 со статусом каждого сервиса
+func NewReadinessHandler(log *slog.Logger, readiers map[string]core.Readier, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var (
+			mu     sync.Mutex
+			wg     sync.WaitGroup
+			checks = make(map[string]string, len(readiers))
+		)
+		for name, rd := range readiers {
+			wg.Go(func() {
+				ctx, cancel := context.WithTimeout(r.Context(), timeout)
+				defer cancel()
+
+				status := "ok"
+				if err := rd.Ready(ctx); err != nil {
+					status = "unavailable"
+					log.WarnContext(r.Context(), "readiness check failed", "service", name, "error", err)
+				}
+				mu.Lock()
+				checks[name] = status
+				mu.Unlock()
+			})
+		}
+		wg.Wait()
+
+		resp, code := healthResponse{Status: "ready", Checks: checks}, http.StatusOK
+		for _, st := range checks {
+			if st != "ok" {
+				resp.Status, code = "not ready", http.StatusServiceUnavailable
+				break
+			}
+		}
+		res.Json(w, resp, code)
+		log.DebugContext(r.Context(), "readiness handled", "status", resp.Status, "duration", time.Since(start))
+	}
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"yadro.com/course/api/core"
+)
+
+type readierFunc func(context.Context) error
+
+func (f readierFunc) Ready(ctx context.Context) error { return f(ctx) }
+
+func TestReadiness(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	slow := readierFunc(func(ctx context.Context) error {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	down := readierFunc(func(context.Context) error { return core.ErrUnavailable })
+
+	tests := []struct {
+		name     string
+		readiers map[string]core.Readier
+		code     int
+		status   string
+		checks   map[string]string
+	}{
+		{
+			name:     "all ready",
+			readiers: map[string]core.Readier{"words": slow, "search": slow, "update": slow},
+			code:     http.StatusOK,
+			status:   "ready",
+			checks:   map[string]string{"words": "ok", "search": "ok", "update": "ok"},
+		},
+		{
+			name:     "one down",
+			readiers: map[string]core.Readier{"words": slow, "search": down},
+			code:     http.StatusServiceUnavailable,
+			status:   "not ready",
+			checks:   map[string]string{"words": "ok", "search": "unavailable"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			start := time.Now()
+			NewReadinessHandler(log, tt.readiers, time.Second)(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			// проверки параллельные: три по 100ms укладываются в время одной
+			if d := time.Since(start); d > 250*time.Millisecond {
+				t.Errorf("readiness took %s, checks are not parallel", d)
+			}
+
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d", rec.Code, tt.code)
+			}
+			var got healthResponse
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Status != tt.status {
+				t.Errorf("status = %q, want %q", got.Status, tt.status)
+			}
+			for name, want := range tt.checks {
+				if got.Checks[name] != want {
+					t.Errorf("checks[%s] = %q, want %q", name, got.Checks[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewLivenessHandler()(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d, want 200", rec.Code)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"yadro.com/course/api/pkg/problem"
 )
 
 type LoginRequest struct {
@@ -13,9 +15,8 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
-func writeUnauthed(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusUnauthorized)
-	_, _ = w.Write([]byte("unauthorized"))
+func writeUnauthed(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(r, http.StatusUnauthorized, problem.CodeUnauthorized, "missing or invalid token"))
 }
 
 func NewLoginHandler(log *slog.Logger, adminUser, adminPassword string, tokenTTL time.Duration) http.Handler {
@@ -23,31 +24,31 @@ func NewLoginHandler(log *slog.Logger, adminUser, adminPassword string, tokenTTL
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			problem.Write(w, r, problem.New(r, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, r.Method+" is not allowed"))
 			return
 		}
 
 		defer func() {
 			if err := r.Body.Close(); err != nil {
-				log.Debug("failed to close response body in Get", "error", err)
+				log.DebugContext(r.Context(), "failed to close response body in Get", "error", err)
 			}
 		}()
 
 		var req LoginRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "bad request", http.StatusBadRequest)
+			problem.Write(w, r, problem.New(r, http.StatusBadRequest, problem.CodeBadRequest, "request body is not valid JSON"))
 			return
 		}
 
 		if req.Name != adminUser || req.Password != adminPassword {
-			writeUnauthed(w)
+			writeUnauthed(w, r)
 			return
 		}
 
 		token, err := j.GenerateSuperuserToken()
 		if err != nil {
-			log.Error("failed to generate token", "error", err)
-			http.Error(w, "internal error", http.StatusInternalServerError)
+			log.ErrorContext(r.Context(), "failed to generate token", "error", err)
+			problem.Write(w, r, problem.New(r, http.StatusInternalServerError, problem.CodeInternal, "internal error"))
 			return
 		}
 
@@ -62,24 +63,24 @@ func RequireSuperuser(next http.Handler, tokenTTL time.Duration) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
 		if authHeader == "" {
-			writeUnauthed(w)
+			writeUnauthed(w, r)
 			return
 		}
 
 		if !strings.HasPrefix(authHeader, "Token ") {
-			writeUnauthed(w)
+			writeUnauthed(w, r)
 			return
 		}
 
 		tokenStr := strings.TrimPrefix(authHeader, "Token ")
 		tokenStr = strings.TrimSpace(tokenStr)
 		if tokenStr == "" {
-			writeUnauthed(w)
+			writeUnauthed(w, r)
 			return
 		}
 
 		if !j.IsSuperuserToken(tokenStr) {
-			writeUnauthed(w)
+			writeUnauthed(w, r)
 			return
 		}
 
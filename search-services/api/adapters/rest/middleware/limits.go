@@ -3,6 +3,8 @@ package middleware
 import (
 	"golang.org/x/time/rate"
 	"net/http"
+
+	"yadro.com/course/api/pkg/problem"
 )
 
 // WithConcurrencyLimit ограничиваем количество одновременных запросов
@@ -19,7 +21,8 @@ func WithConcurrencyLimit(next http.Handler, max int) http.Handler {
 			defer func() { <-sem }()
 			next.ServeHTTP(w, r)
 		default:
-			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			limiterRejections.WithLabelValues("concurrency", r.Pattern).Inc()
+			problem.Write(w, r, problem.New(r, http.StatusServiceUnavailable, problem.CodeOverloaded, "too many concurrent requests, retry later"))
 		}
 	})
 }
@@ -32,7 +35,9 @@ func WithRateLimit(next http.Handler, rps int) http.Handler {
 	lim := rate.NewLimiter(rate.Limit(rps), 1)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Wait отказывает, только если клиент ушел или дедлайн наступит раньше очереди
 		if err := lim.Wait(r.Context()); err != nil {
+			limiterRejections.WithLabelValues("rate", r.Pattern).Inc()
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by server (public or internal), route pattern and status.",
+	}, []string{"server", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by server and route pattern; streams until the last line.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"server", "route"})
+	limiterRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_limiter_rejections_total",
+		Help: "Requests rejected by WithConcurrencyLimit (concurrency) or WithRateLimit (rate), by route pattern.",
+	}, []string{"limiter", "route"})
+)
+
+// Router - шаблон маршрута без вызова хендлера, как у *http.ServeMux
+type Router interface {
+	Handler(r *http.Request) (http.Handler, string)
+}
+
+// WithMetrics - счетчик и латентность запросов по шаблону маршрута из router
+// (не по пути, чтобы /api/comics/{id} не плодил серии). Маршрут ищется до вызова next,
+// поэтому учитываются и запросы, отклоненные проверкой по OpenAPI; без маршрута - "unmatched"
+func WithMetrics(next http.Handler, server string, router Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		_, route := router.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		httpRequests.WithLabelValues(server, route, strconv.Itoa(sw.status)).Inc()
+		httpDuration.WithLabelValues(server, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap - для http.ResponseController, которым NDJSON делает Flush
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWithMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/comics/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+	// хендлер не вызывается, как при отказе проверки по OpenAPI
+	reject := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("bad") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+	h := WithMetrics(reject, "test", mux)
+
+	for _, target := range []string{"/api/comics/1", "/api/comics/2", "/api/comics/0", "/api/comics/1?bad", "/nope"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	tests := []struct {
+		route, status string
+		want          float64
+	}{
+		{"GET /api/comics/{id}", "200", 2},
+		{"GET /api/comics/{id}", "404", 1},
+		{"GET /api/comics/{id}", "400", 1},
+		{"unmatched", "404", 1},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(httpRequests.WithLabelValues("test", tt.route, tt.status)); got != tt.want {
+			t.Errorf("requests{%s,%s} = %v, want %v", tt.route, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestWithMetricsFlush(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stream", func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("flush through metrics writer: %v", err)
+		}
+	})
+	rec := httptest.NewRecorder()
+	WithMetrics(mux, "test", mux).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if !rec.Flushed {
+		t.Fatal("response was not flushed")
+	}
+}
+
+func TestConcurrencyLimitRejections(t *testing.T) {
+	block := make(chan struct{})
+	started := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.Handle("GET /limited", WithConcurrencyLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-block
+	}), 1))
+
+	done := make(chan struct{})
+	go func() {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/limited", nil))
+		close(done)
+	}()
+	<-started
+
+	before := testutil.ToFloat64(limiterRejections.WithLabelValues("concurrency", "GET /limited"))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/limited", nil))
+	close(block)
+	<-done
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if got := testutil.ToFloat64(limiterRejections.WithLabelValues("concurrency", "GET /limited")); got != before+1 {
+		t.Fatalf("rejections = %v, want %v", got, before+1)
+	}
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"yadro.com/course/api/pkg/problem"
+)
+
+// unbuffered - ответы, которые пишутся потоком или не разбираются валидатором;
+// их не буферизуем и не проверяем
+var unbuffered = []string{"application/x-ndjson", "text/html", "text/plain"}
+
+// WithOpenAPI - проверка запросов и ответов по спецификации.
+// Маршруты, которых нет в спецификации, проходят без проверки.
+// Невалидный запрос - 400 до вызова хендлера; невалидный ответ (validateResponses,
+// включается в тестовом окружении) - 500 вместо ответа хендлера и ошибка в логе
+func WithOpenAPI(next http.Handler, doc *openapi3.T, validateRequests, validateResponses bool, log *slog.Logger) (http.Handler, error) {
+	if !validateRequests && !validateResponses {
+		return next, nil
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &openapi3filter.Options{
+		// токены проверяют RequireSuperuser/RequireUser, здесь только форма запроса
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults:   true,
+		IncludeResponseStatus: true,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, params, err := router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// клиенты шлют JSON и без Content-Type, хендлеры его не смотрят
+		if r.ContentLength != 0 && r.Header.Get("Content-Type") == "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+
+		in := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: params,
+			Route:      route,
+			Options:    opts,
+		}
+		if validateRequests {
+			if err := openapi3filter.ValidateRequest(r.Context(), in); err != nil {
+				log.DebugContext(r.Context(), "request does not match openapi spec", "method", r.Method, "path", r.URL.Path, "error", err)
+				problem.Write(w, r, requestProblem(r, err))
+				return
+			}
+		}
+
+		if !validateResponses || streaming(route) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		// как net/http: без явного Content-Type тип определяется по телу
+		if rec.header.Get("Content-Type") == "" && rec.body.Len() > 0 {
+			rec.header.Set("Content-Type", http.DetectContentType(rec.body.Bytes()))
+		}
+
+		out := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: in,
+			Status:                 rec.status,
+			Header:                 rec.header,
+			Options:                opts,
+		}
+		out.SetBodyBytes(rec.body.Bytes())
+		if err := openapi3filter.ValidateResponse(r.Context(), out); err != nil {
+			log.ErrorContext(r.Context(), "response does not match openapi spec",
+				"method", r.Method, "path", r.URL.Path, "status", rec.status, "error", err)
+			problem.Write(w, r, problem.New(r, http.StatusInternalServerError, problem.CodeInternal,
+				"response does not match openapi spec"))
+			return
+		}
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(rec.body.Bytes())
+	}), nil
+}
+
+// requestProblem - 400 с ошибкой по параметру или телу, которые не прошли проверку
+func requestProblem(r *http.Request, err error) *problem.Problem {
+	var re *openapi3filter.RequestError
+	if !errors.As(err, &re) {
+		return problem.New(r, http.StatusBadRequest, problem.CodeBadRequest, err.Error())
+	}
+	field := "body"
+	if re.Parameter != nil {
+		field = re.Parameter.Name
+	}
+	msg := re.Reason
+	var se *openapi3.SchemaError
+	if errors.As(re.Err, &se) {
+		msg = se.Reason
+	} else if re.Err != nil && msg == "" {
+		msg = re.Err.Error()
+	}
+	return problem.New(r, http.StatusBadRequest, problem.CodeInvalidParameter, "invalid value of "+field).
+		WithField(field, msg)
+}
+
+// streaming - у операции есть успешный ответ из unbuffered
+func streaming(route *routers.Route) bool {
+	for code, resp := range route.Operation.Responses.Map() {
+		if !strings.HasPrefix(code, "2") || resp.Value == nil {
+			continue
+		}
+		for _, ct := range unbuffered {
+			if resp.Value.Content.Get(ct) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// responseRecorder - ответ хендлера целиком, до проверки по спецификации
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(b)
+}
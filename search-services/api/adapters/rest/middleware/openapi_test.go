@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"yadro.com/course/api/adapters/rest/openapi"
+	"yadro.com/course/api/pkg/problem"
+	"yadro.com/course/api/pkg/res"
+	"yadro.com/course/pkg/requestid"
+)
+
+func TestWithOpenAPI(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+
+	var called string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, r *http.Request) {
+		called = "search"
+		res.Json(w, map[string]any{"comics": []any{}, "total": 0, "matches": 0, "facets": map[string]any{}}, http.StatusOK)
+	})
+	mux.HandleFunc("GET /api/comics/{id}", func(w http.ResponseWriter, r *http.Request) {
+		called = "comic"
+		res.Json(w, map[string]any{"id": "not a number"}, http.StatusOK)
+	})
+	mux.HandleFunc("GET /api/comics/random", func(w http.ResponseWriter, r *http.Request) {
+		called = "random"
+		res.Json(w, map[string]any{"id": 1, "url": "https://xkcd.com/1"}, http.StatusOK)
+	})
+	mux.HandleFunc("POST /api/login", func(w http.ResponseWriter, r *http.Request) {
+		called = "login"
+		writeUnauthed(w, r)
+	})
+	mux.HandleFunc("GET /api/undocumented", func(w http.ResponseWriter, r *http.Request) {
+		called = "undocumented"
+	})
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h, err := WithOpenAPI(mux, doc, true, true, log)
+	if err != nil {
+		t.Fatalf("with openapi: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCalled string
+	}{
+		{name: "valid search", method: http.MethodGet, target: "/api/search?phrase=linux&limit=5",
+			wantStatus: http.StatusOK, wantCalled: "search"},
+		{name: "no phrase", method: http.MethodGet, target: "/api/search",
+			wantStatus: http.StatusBadRequest},
+		{name: "bad limit", method: http.MethodGet, target: "/api/search?phrase=linux&limit=asdf",
+			wantStatus: http.StatusBadRequest},
+		{name: "negative limit", method: http.MethodGet, target: "/api/search?phrase=linux&limit=-1",
+			wantStatus: http.StatusBadRequest},
+		// литеральный путь не должен уходить в /api/comics/{id}
+		{name: "random", method: http.MethodGet, target: "/api/comics/random",
+			wantStatus: http.StatusOK, wantCalled: "random"},
+		{name: "bad id", method: http.MethodGet, target: "/api/comics/abc",
+			wantStatus: http.StatusBadRequest},
+		{name: "response not in spec", method: http.MethodGet, target: "/api/comics/1",
+			wantStatus: http.StatusInternalServerError, wantCalled: "comic"},
+		// тело без Content-Type считается JSON
+		{name: "login without content type", method: http.MethodPost, target: "/api/login",
+			body: `{"name":"user","password":""}`, wantStatus: http.StatusUnauthorized, wantCalled: "login"},
+		{name: "undocumented route", method: http.MethodGet, target: "/api/undocumented",
+			wantStatus: http.StatusOK, wantCalled: "undocumented"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = ""
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, body))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("called %q, want %q", called, tt.wantCalled)
+			}
+		})
+	}
+}
+
+func TestWithOpenAPIProblem(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	h, err := WithOpenAPI(http.NotFoundHandler(), doc, true, false, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("with openapi: %v", err)
+	}
+	h = WithRequestID(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/search?phrase=linux&limit=asdf", nil)
+	req.Header.Set(requestid.Header, "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Fatalf("content type = %q", ct)
+	}
+	var p problem.Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if p.Status != http.StatusBadRequest || p.Code != problem.CodeInvalidParameter ||
+		p.RequestID != "req-1" || p.Instance != "/api/search" {
+		t.Fatalf("problem = %+v", p)
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Field != "limit" {
+		t.Fatalf("field errors = %+v", p.Errors)
+	}
+	if got := rec.Header().Get(requestid.Header); got != "req-1" {
+		t.Fatalf("request id header = %q", got)
+	}
+}
+
+func TestUndocumented(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	got := openapi.Undocumented(doc, []string{
+		"GET /api/comics/{id}",
+		"DELETE /api/mycomics/{id}",
+		"POST /api/comics/{id}",
+		"GET /api/nope",
+	})
+	want := []string{"POST /api/comics/{id}", "GET /api/nope"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("undocumented = %v, want %v", got, want)
+	}
+}
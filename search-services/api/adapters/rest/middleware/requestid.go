@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"yadro.com/course/pkg/requestid"
+)
+
+// WithRequestID - id запроса из X-Request-ID клиента или новый; кладется в контекст
+// (оттуда его берут ответы с ошибкой и логи) и возвращается в том же заголовке
+func WithRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.WithID(r.Context(), id)))
+	})
+}
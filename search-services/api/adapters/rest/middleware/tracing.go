@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"yadro.com/course/pkg/requestid"
+)
+
+// WithTracing - серверный спан на запрос, родитель из traceparent клиента, если он есть.
+// Имя спана - шаблон маршрута из router, как у метрик; request_id в атрибутах,
+// чтобы по id из ответа или логов найти трейс
+func WithTracing(next http.Handler, server string, router Router) http.Handler {
+	withID := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		trace.SpanFromContext(r.Context()).SetAttributes(
+			attribute.String("http.server", server),
+			attribute.String(requestid.LogKey, requestid.FromContext(r.Context())),
+		)
+		next.ServeHTTP(w, r)
+	})
+	return otelhttp.NewHandler(withID, server,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			_, route := router.Handler(r)
+			if route == "" {
+				return r.Method + " unmatched"
+			}
+			return route
+		}),
+	)
+}
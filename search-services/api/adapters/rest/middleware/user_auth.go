@@ -26,36 +26,65 @@ func RequireUser(next http.Handler, jwtSecret string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tokenStr, ok := readToken(r)
 		if !ok {
-			w.WriteHeader(http.StatusUnauthorized)
-			_, _ = w.Write([]byte("unauthorized"))
+			writeUnauthed(w, r)
 			return
 		}
 
-		claims := &UserJWTClaims{}
-		t, err := jwt.ParseWithClaims(
-			tokenStr,
-			claims,
-			func(token *jwt.Token) (interface{}, error) {
-				// алгоритм HS256 (sha-256)
-				if token.Method != jwt.SigningMethodHS256 {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-				}
-				return secret, nil
-			},
-			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
-		)
-
-		if err != nil || !t.Valid || claims.UserID == 0 {
-			w.WriteHeader(http.StatusUnauthorized)
-			_, _ = w.Write([]byte("unauthorized"))
+		userID, ok := parseUserToken(tokenStr, secret)
+		if !ok {
+			writeUnauthed(w, r)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), userIDKey, claims.UserID)
+		ctx := context.WithValue(r.Context(), userIDKey, userID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// OptionalUser - как RequireUser, но запрос без токена пропускается анонимно;
+// невалидный токен все равно дает 401
+func OptionalUser(next http.Handler, jwtSecret string) http.Handler {
+	secret := []byte(jwtSecret)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenStr, ok := readToken(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, ok := parseUserToken(tokenStr, secret)
+		if !ok {
+			writeUnauthed(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDKey, userID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func parseUserToken(tokenStr string, secret []byte) (uint32, bool) {
+	claims := &UserJWTClaims{}
+	t, err := jwt.ParseWithClaims(
+		tokenStr,
+		claims,
+		func(token *jwt.Token) (interface{}, error) {
+			// алгоритм HS256 (sha-256)
+			if token.Method != jwt.SigningMethodHS256 {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return secret, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+	)
+
+	if err != nil || !t.Valid || claims.UserID == 0 {
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
 func UserIDFromContext(ctx context.Context) (uint32, bool) {
 	v := ctx.Value(userIDKey)
 	id, ok := v.(uint32)
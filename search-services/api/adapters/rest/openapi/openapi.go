@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+// Load - разбирает и проверяет встроенную спецификацию gateway
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate openapi spec: %w", err)
+	}
+	return doc, nil
+}
+
+// Undocumented - шаблоны ServeMux ("GET /api/comics/{id}"), которых нет в спецификации.
+// Шаблон без метода считается описанным, если путь есть хотя бы с одним методом
+func Undocumented(doc *openapi3.T, patterns []string) []string {
+	var out []string
+	for _, p := range patterns {
+		method, path, ok := strings.Cut(p, " ")
+		if !ok {
+			method, path = "", p
+		}
+		item := doc.Paths.Find(path)
+		if item == nil || (method != "" && item.GetOperation(method) == nil) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
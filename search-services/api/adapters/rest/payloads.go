@@ -1,11 +1,15 @@
 package rest
 
+import "yadro.com/course/api/pkg/problem"
+
 type pingResponse struct {
 	Replies map[string]string `json:"replies"`
 }
 
-type errorResponse struct {
-	Error string `json:"error"`
+// healthResponse - /healthz и /readyz; checks только у /readyz
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
 }
 
 // update payloads
@@ -22,13 +26,176 @@ type updateStatsResponse struct {
 
 // search payloads
 type comicResponse struct {
-	ID  int    `json:"id"`
-	URL string `json:"url"`
+	ID      int                  `json:"id"`
+	URL     string               `json:"url"`
+	Explain *explanationResponse `json:"explain,omitempty"`
 }
 
 type searchResponse struct {
 	Comics []comicResponse `json:"comics"`
 	Total  int             `json:"total"`
+	// Matches - все найденные комиксы до limit, по ним же посчитаны facets
+	Matches int            `json:"matches"`
+	Tokens  []string       `json:"tokens,omitempty"`
+	Facets  facetsResponse `json:"facets"`
+
+	// только при пустой выдаче и если words нашел исправление
+	DidYouMean  string               `json:"did_you_mean,omitempty"`
+	Corrections []correctionResponse `json:"corrections,omitempty"`
+}
+
+type correctionResponse struct {
+	Token       string               `json:"token"`
+	Suggestions []suggestionResponse `json:"suggestions"`
+}
+
+type suggestionResponse struct {
+	Term     string `json:"term"`
+	Distance int    `json:"distance"`
+	Count    int    `json:"count"`
+}
+
+type facetsResponse struct {
+	Years   []facetCountResponse `json:"years"`
+	Sources []facetCountResponse `json:"sources"`
+}
+
+type facetCountResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type explanationResponse struct {
+	Function      string                   `json:"function"`
+	Profile       string                   `json:"profile"`
+	Score         float64                  `json:"score"`
+	Components    []scoreComponentResponse `json:"components"`
+	Terms         []termExplainResponse    `json:"terms"`
+	MatchedFields []string                 `json:"matched_fields"`
+}
+
+type scoreComponentResponse struct {
+	Name         string  `json:"name"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+type termExplainResponse struct {
+	Token   string   `json:"token"`
+	Fields  []string `json:"fields"`
+	TitleTF int      `json:"title_tf"`
+	AltTF   int      `json:"alt_tf"`
+	WordsTF int      `json:"words_tf"`
+}
+
+type termStatResponse struct {
+	Term string `json:"term"`
+	Docs int    `json:"docs"`
+}
+
+type indexStatsResponse struct {
+	Generation      uint64             `json:"generation"`
+	Terms           int                `json:"terms"`
+	Docs            int                `json:"docs"`
+	Postings        int                `json:"postings"`
+	MaxPosting      int                `json:"max_posting"`
+	AvgPosting      float64            `json:"avg_posting"`
+	MemoryBytes     uint64             `json:"memory_bytes"`
+	BuiltAtUnix     int64              `json:"built_at_unix"`
+	BuildDurationMs int64              `json:"build_duration_ms"`
+	Trigger         string             `json:"trigger"`
+	TopTerms        []termStatResponse `json:"top_terms"`
+	Searches        uint64             `json:"searches"`
+	IndexedSearches uint64             `json:"indexed_searches"`
+
+	LastRebuildError       string `json:"last_rebuild_error,omitempty"`
+	LastRebuildErrorAtUnix int64  `json:"last_rebuild_error_at_unix,omitempty"`
+
+	ResultCache cacheStatsResponse `json:"result_cache"`
+	NormCache   cacheStatsResponse `json:"norm_cache"`
+
+	// пустая версия - комиксы, сохраненные до появления версий
+	AnalyzerVersions     []versionStatResponse `json:"analyzer_versions"`
+	QueryAnalyzerVersion string                `json:"query_analyzer_version,omitempty"`
+}
+
+type versionStatResponse struct {
+	Version string `json:"version"`
+	Docs    int    `json:"docs"`
+}
+
+type cacheStatsResponse struct {
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+	Entries  int    `json:"entries"`
+	Capacity int    `json:"capacity"`
+}
+
+type indexRebuildResponse struct {
+	Generation uint64 `json:"generation"`
+	Docs       int    `json:"docs"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type indexVerifyResponse struct {
+	Generation      uint64 `json:"generation"`
+	DBDocs          int    `json:"db_docs"`
+	IndexDocs       int    `json:"index_docs"`
+	InSync          bool   `json:"in_sync"`
+	Missing         []int  `json:"missing"`
+	Stale           []int  `json:"stale"`
+	Mismatched      []int  `json:"mismatched"`
+	MissingCount    int    `json:"missing_count"`
+	StaleCount      int    `json:"stale_count"`
+	MismatchedCount int    `json:"mismatched_count"`
+}
+
+// analytics payloads
+type queryStatResponse struct {
+	Query        string  `json:"query"`
+	Count        int     `json:"count"`
+	AvgResults   float64 `json:"avg_results"`
+	LastSeenUnix int64   `json:"last_seen_unix"`
+}
+
+type queryStatsResponse struct {
+	Queries []queryStatResponse `json:"queries"`
+}
+
+type latencyStatResponse struct {
+	Endpoint string  `json:"endpoint"`
+	Count    int     `json:"count"`
+	P50Ms    float64 `json:"p50_ms"`
+	P90Ms    float64 `json:"p90_ms"`
+	P99Ms    float64 `json:"p99_ms"`
+}
+
+type latencyResponse struct {
+	Endpoints []latencyStatResponse `json:"endpoints"`
+}
+
+// words payloads
+type analyzeTokenResponse struct {
+	Text    string `json:"text"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Dropped string `json:"dropped,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+type analyzeStageResponse struct {
+	Name   string                 `json:"name"`
+	Tokens []analyzeTokenResponse `json:"tokens"`
+}
+
+type analyzeResponse struct {
+	Phrase          string                 `json:"phrase"`
+	Language        string                 `json:"language"`
+	Analyzer        string                 `json:"analyzer"`
+	AnalyzerVersion string                 `json:"analyzer_version"`
+	Stages          []analyzeStageResponse `json:"stages"`
+	Words           []string               `json:"words"`
 }
 
 // auth payloads
@@ -62,3 +229,9 @@ type favoriteItemResponse struct {
 type favoritesListResponse struct {
 	Items []favoriteItemResponse `json:"items"`
 }
+
+// streamErrorResponse - последняя строка прерванного NDJSON-потока:
+// ключ error отличает ее от строк с комиксами
+type streamErrorResponse struct {
+	Error *problem.Problem `json:"error"`
+}
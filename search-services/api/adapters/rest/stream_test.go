@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"yadro.com/course/api/core"
+	"yadro.com/course/api/pkg/problem"
+)
+
+// brokenStream - search, у которого выгрузка обрывается после первой пачки
+type brokenStream struct {
+	core.Searcher
+}
+
+func (brokenStream) StreamComics(_ context.Context, _ uint32, send func([]core.SearchComic) error) error {
+	if err := send([]core.SearchComic{{ID: 1, URL: "https://xkcd.com/1"}}); err != nil {
+		return err
+	}
+	return core.ErrUnavailable
+}
+
+func TestComicsStream_ErrorLine(t *testing.T) {
+	h := NewComicsStreamHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), brokenStream{}, time.Second)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/comics/stream", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 sent with the first chunk", rec.Code)
+	}
+	var lines []map[string]json.RawMessage
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		var line map[string]json.RawMessage
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want comic and error", len(lines))
+	}
+	if _, ok := lines[0]["error"]; ok {
+		t.Fatalf("comic line has error key: %v", lines[0])
+	}
+	var p problem.Problem
+	if err := json.Unmarshal(lines[1]["error"], &p); err != nil {
+		t.Fatalf("error line %v: %v", lines[1], err)
+	}
+	if p.Code != problem.CodeStreamInterrupted || len(lines[1]) != 1 {
+		t.Fatalf("error line = %v, want only {\"error\": stream_interrupted}", lines[1])
+	}
+}
@@ -2,17 +2,21 @@ package search
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/status"
 
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"yadro.com/course/api/adapters/grpcerr"
 	"yadro.com/course/api/core"
+	"yadro.com/course/pkg/admintoken"
+	"yadro.com/course/pkg/grpcclient"
+	"yadro.com/course/pkg/health"
 	searchpb "yadro.com/course/proto/search"
 )
 
@@ -20,20 +24,37 @@ type Client struct {
 	log    *slog.Logger
 	client searchpb.SearchClient
 	conn   *grpc.ClientConn
+
+	// adminToken - для RebuildIndex и VerifyIndex, search без него их отклоняет
+	adminToken string
 }
 
-func NewClient(address string, log *slog.Logger) (*Client, error) {
-	conn, err := grpc.NewClient(
-		address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+func NewClient(address, adminToken string, log *slog.Logger) (*Client, error) {
+	// RebuildIndex и стримы не повторяются: пересборка дорогая, стрим мог отдать часть данных
+	conn, err := grpcclient.New(address, grpcclient.Options{
+		Idempotent: []string{
+			searchpb.Search_Ping_FullMethodName,
+			searchpb.Search_Find_FullMethodName,
+			searchpb.Search_IndexedSearch_FullMethodName,
+			searchpb.Search_GetIDComic_FullMethodName,
+			searchpb.Search_GetAllComics_FullMethodName,
+			searchpb.Search_GetRandomComic_FullMethodName,
+			searchpb.Search_ComicOfTheDay_FullMethodName,
+			searchpb.Search_IndexStats_FullMethodName,
+			searchpb.Search_VerifyIndex_FullMethodName,
+			searchpb.Search_TopQueries_FullMethodName,
+			searchpb.Search_ZeroResultQueries_FullMethodName,
+			searchpb.Search_LatencyPercentiles_FullMethodName,
+		},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("new grpc client for %s: %w", address, err)
 	}
 	return &Client{
-		client: searchpb.NewSearchClient(conn),
-		conn:   conn,
-		log:    log,
+		client:     searchpb.NewSearchClient(conn),
+		conn:       conn,
+		log:        log,
+		adminToken: adminToken,
 	}, nil
 }
 
@@ -42,76 +63,126 @@ func (c *Client) Close() error { return c.conn.Close() }
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.client.Ping(ctx, &emptypb.Empty{})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.ErrUnavailable
-		default:
-			return err
-		}
+		return grpcerr.ToCore(err)
 	}
 	return nil
 }
 
-func (c *Client) Find(ctx context.Context, phrase string, limit uint32) (core.SearchResult, error) {
+// Ready - готовность сервиса вместе с его зависимостями, по grpc.health.v1
+func (c *Client) Ready(ctx context.Context) error {
+	return grpcerr.ToCore(health.Probe(ctx, c.conn))
+}
+
+func (c *Client) Find(ctx context.Context, q core.SearchQuery) (core.SearchResult, error) {
 	res, err := c.client.Find(ctx, &searchpb.SearchRequest{
-		Phrase: phrase,
-		Limit:  limit,
+		Phrase:  q.Phrase,
+		Limit:   q.Limit,
+		Explain: q.Explain,
+		Profile: q.Profile,
+		Filters: searchFilters(q.Filters),
+		UserId:  q.UserID,
 	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.InvalidArgument:
-			return core.SearchResult{}, core.ErrBadArguments
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.SearchResult{}, core.ErrUnavailable
-		default:
-			return core.SearchResult{}, err
-		}
+		return core.SearchResult{}, grpcerr.ToCore(err)
 	}
 
+	return searchResult(res), nil
+}
+
+func (c *Client) IndexedSearch(ctx context.Context, q core.SearchQuery) (core.SearchResult, error) {
+	res, err := c.client.IndexedSearch(ctx, &searchpb.SearchRequest{
+		Phrase:  q.Phrase,
+		Limit:   q.Limit,
+		Explain: q.Explain,
+		Profile: q.Profile,
+		Filters: searchFilters(q.Filters),
+		UserId:  q.UserID,
+	})
+	if err != nil {
+		return core.SearchResult{}, grpcerr.ToCore(err)
+	}
+
+	return searchResult(res), nil
+}
+
+func searchResult(res *searchpb.SearchReply) core.SearchResult {
 	out := core.SearchResult{
 		Comics: make([]core.SearchComic, 0, len(res.GetComics())),
 		Total:  int(res.GetTotal()),
+		Tokens: res.GetTokens(),
+		Facets: core.Facets{
+			Years:   facetCounts(res.GetYears()),
+			Sources: facetCounts(res.GetSources()),
+		},
 	}
 
 	for _, cr := range res.GetComics() {
 		out.Comics = append(out.Comics, core.SearchComic{
-			ID:  int(cr.GetId()),
-			URL: cr.GetUrl(),
+			ID:      int(cr.GetId()),
+			URL:     cr.GetUrl(),
+			Explain: explanation(cr.GetExplain()),
 		})
 	}
 
-	return out, nil
+	return out
 }
 
-func (c *Client) IndexedSearch(ctx context.Context, phrase string, limit uint32) (core.SearchResult, error) {
-	res, err := c.client.IndexedSearch(ctx, &searchpb.SearchRequest{
-		Phrase: phrase,
-		Limit:  limit,
-	})
-	if err != nil {
-		switch status.Code(err) {
-		case codes.InvalidArgument:
-			return core.SearchResult{}, core.ErrBadArguments
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.SearchResult{}, core.ErrUnavailable
-		default:
-			return core.SearchResult{}, err
-		}
+func searchFilters(f core.SearchFilters) *searchpb.SearchFilters {
+	ids := make([]uint32, 0, len(f.IDs))
+	for _, id := range f.IDs {
+		ids = append(ids, uint32(id))
 	}
 
-	out := core.SearchResult{
-		Comics: make([]core.SearchComic, 0, len(res.GetComics())),
-		Total:  int(res.GetTotal()),
+	return &searchpb.SearchFilters{
+		PublishedFrom: f.PublishedFrom,
+		PublishedTo:   f.PublishedTo,
+		IdFrom:        uint32(f.IDFrom),
+		IdTo:          uint32(f.IDTo),
+		HasTranscript: f.HasTranscript,
+		OnlyIds:       f.OnlyIDs,
+		Ids:           ids,
 	}
+}
 
-	for _, cr := range res.GetComics() {
-		out.Comics = append(out.Comics, core.SearchComic{
-			ID:  int(cr.GetId()),
-			URL: cr.GetUrl(),
-		})
+func facetCounts(fc []*searchpb.FacetCount) []core.FacetCount {
+	out := make([]core.FacetCount, 0, len(fc))
+	for _, c := range fc {
+		out = append(out, core.FacetCount{Value: c.GetValue(), Count: int(c.GetCount())})
 	}
+	return out
+}
 
-	return out, nil
+func explanation(e *searchpb.Explanation) *core.Explanation {
+	if e == nil {
+		return nil
+	}
+
+	out := &core.Explanation{
+		Function:      e.GetFunction(),
+		Profile:       e.GetProfile(),
+		Score:         e.GetScore(),
+		Components:    make([]core.ScoreComponent, 0, len(e.GetComponents())),
+		Terms:         make([]core.TermExplain, 0, len(e.GetTerms())),
+		MatchedFields: e.GetMatchedFields(),
+	}
+	for _, c := range e.GetComponents() {
+		out.Components = append(out.Components, core.ScoreComponent{
+			Name:         c.GetName(),
+			Value:        c.GetValue(),
+			Weight:       c.GetWeight(),
+			Contribution: c.GetContribution(),
+		})
+	}
+	for _, t := range e.GetTerms() {
+		out.Terms = append(out.Terms, core.TermExplain{
+			Token:   t.GetToken(),
+			Fields:  t.GetFields(),
+			TitleTF: int(t.GetTitleTf()),
+			AltTF:   int(t.GetAltTf()),
+			WordsTF: int(t.GetWordsTf()),
+		})
+	}
+	return out
 }
 
 func (c *Client) GetComic(ctx context.Context, id int) (core.SearchComic, error) {
@@ -119,14 +190,7 @@ func (c *Client) GetComic(ctx context.Context, id int) (core.SearchComic, error)
 		Id: uint32(id),
 	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.NotFound:
-			return core.SearchComic{}, core.ErrBadArguments
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.SearchComic{}, core.ErrUnavailable
-		default:
-			return core.SearchComic{}, err
-		}
+		return core.SearchComic{}, grpcerr.ToCore(err)
 	}
 
 	return core.SearchComic{
@@ -135,15 +199,42 @@ func (c *Client) GetComic(ctx context.Context, id int) (core.SearchComic, error)
 	}, nil
 }
 
-func (c *Client) RandomComic(ctx context.Context) (core.SearchComic, error) {
-	res, err := c.client.GetRandomComic(ctx, &emptypb.Empty{})
+func (c *Client) RandomComic(ctx context.Context, q core.RandomQuery) (core.SearchComic, error) {
+	exclude := make([]uint32, 0, len(q.Exclude))
+	for _, id := range q.Exclude {
+		exclude = append(exclude, uint32(id))
+	}
+
+	res, err := c.client.GetRandomComic(ctx, &searchpb.RandomComicRequest{
+		Seed:    q.Seed,
+		Exclude: exclude,
+	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.SearchComic{}, core.ErrUnavailable
-		default:
-			return core.SearchComic{}, err
-		}
+		return core.SearchComic{}, grpcerr.ToCore(err)
+	}
+
+	return core.SearchComic{
+		ID:  int(res.GetId()),
+		URL: res.GetUrl(),
+	}, nil
+}
+
+func (c *Client) ComicOfTheDay(ctx context.Context, q core.DailyQuery) (core.SearchComic, error) {
+	popularity := make([]*searchpb.ComicPopularity, 0, len(q.Popularity))
+	for _, p := range q.Popularity {
+		popularity = append(popularity, &searchpb.ComicPopularity{
+			ComicId: uint32(p.ComicID),
+			Count:   uint32(p.Count),
+		})
+	}
+
+	res, err := c.client.ComicOfTheDay(ctx, &searchpb.ComicOfTheDayRequest{
+		Date:       q.Date,
+		Seed:       q.Seed,
+		Popularity: popularity,
+	})
+	if err != nil {
+		return core.SearchComic{}, grpcerr.ToCore(err)
 	}
 
 	return core.SearchComic{
@@ -158,14 +249,7 @@ func (c *Client) ListComics(ctx context.Context, page, limit uint32) (core.Searc
 		PerPage: limit,
 	})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.InvalidArgument:
-			return core.SearchResult{}, core.ErrBadArguments
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.SearchResult{}, core.ErrUnavailable
-		default:
-			return core.SearchResult{}, err
-		}
+		return core.SearchResult{}, grpcerr.ToCore(err)
 	}
 
 	out := core.SearchResult{
@@ -182,3 +266,207 @@ func (c *Client) ListComics(ctx context.Context, page, limit uint32) (core.Searc
 
 	return out, nil
 }
+
+func (c *Client) StreamSearch(ctx context.Context, q core.SearchQuery, indexed bool, chunk uint32, send func([]core.SearchComic) error) error {
+	stream, err := c.client.StreamSearch(ctx, &searchpb.StreamSearchRequest{
+		Query: &searchpb.SearchRequest{
+			Phrase:  q.Phrase,
+			Limit:   q.Limit,
+			Explain: q.Explain,
+			Profile: q.Profile,
+			Filters: searchFilters(q.Filters),
+			UserId:  q.UserID,
+		},
+		Indexed:   indexed,
+		ChunkSize: chunk,
+	})
+	if err != nil {
+		return grpcerr.ToCore(err)
+	}
+	return recvChunks(stream, send)
+}
+
+func (c *Client) StreamComics(ctx context.Context, chunk uint32, send func([]core.SearchComic) error) error {
+	stream, err := c.client.StreamComics(admintoken.Outgoing(ctx, c.adminToken), &searchpb.StreamComicsRequest{ChunkSize: chunk})
+	if err != nil {
+		return grpcerr.ToCore(err)
+	}
+	return recvChunks(stream, send)
+}
+
+// recvChunks - читает поток до EOF; следующая пачка не читается, пока send не вернется
+func recvChunks(stream grpc.ServerStreamingClient[searchpb.ComicsChunk], send func([]core.SearchComic) error) error {
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return grpcerr.ToCore(err)
+		}
+
+		comics := make([]core.SearchComic, 0, len(chunk.GetComics()))
+		for _, cr := range chunk.GetComics() {
+			comics = append(comics, core.SearchComic{
+				ID:      int(cr.GetId()),
+				URL:     cr.GetUrl(),
+				Explain: explanation(cr.GetExplain()),
+			})
+		}
+		if err := send(comics); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) IndexStats(ctx context.Context, top uint32) (core.IndexStats, error) {
+	res, err := c.client.IndexStats(ctx, &searchpb.IndexStatsRequest{Top: top})
+	if err != nil {
+		return core.IndexStats{}, grpcerr.ToCore(err)
+	}
+
+	out := core.IndexStats{
+		Generation:      res.GetGeneration(),
+		Terms:           int(res.GetTerms()),
+		Docs:            int(res.GetDocs()),
+		Postings:        int(res.GetPostings()),
+		MaxPosting:      int(res.GetMaxPosting()),
+		AvgPosting:      res.GetAvgPosting(),
+		MemoryBytes:     res.GetMemoryBytes(),
+		BuiltAtUnix:     res.GetBuiltAtUnix(),
+		BuildDurationMs: res.GetBuildDurationMs(),
+		Trigger:         res.GetTrigger(),
+		TopTerms:        make([]core.TermStat, 0, len(res.GetTopTerms())),
+		Searches:        res.GetSearches(),
+		IndexedSearches: res.GetIndexedSearches(),
+
+		LastRebuildError:       res.GetLastRebuildError(),
+		LastRebuildErrorAtUnix: res.GetLastRebuildErrorAtUnix(),
+
+		ResultCache: cacheStats(res.GetResultCache()),
+		NormCache:   cacheStats(res.GetNormCache()),
+
+		AnalyzerVersions:     make([]core.VersionStat, 0, len(res.GetAnalyzerVersions())),
+		QueryAnalyzerVersion: res.GetQueryAnalyzerVersion(),
+	}
+
+	for _, t := range res.GetTopTerms() {
+		out.TopTerms = append(out.TopTerms, core.TermStat{
+			Term: t.GetTerm(),
+			Docs: int(t.GetDocs()),
+		})
+	}
+	for _, v := range res.GetAnalyzerVersions() {
+		out.AnalyzerVersions = append(out.AnalyzerVersions, core.VersionStat{
+			Version: v.GetVersion(),
+			Docs:    int(v.GetDocs()),
+		})
+	}
+
+	return out, nil
+}
+
+func cacheStats(c *searchpb.CacheStats) core.CacheStats {
+	return core.CacheStats{
+		Hits:     c.GetHits(),
+		Misses:   c.GetMisses(),
+		Entries:  int(c.GetEntries()),
+		Capacity: int(c.GetCapacity()),
+	}
+}
+
+func (c *Client) RebuildIndex(ctx context.Context) (core.IndexRebuild, error) {
+	res, err := c.client.RebuildIndex(admintoken.Outgoing(ctx, c.adminToken), &emptypb.Empty{})
+	if err != nil {
+		return core.IndexRebuild{}, grpcerr.ToCore(err)
+	}
+
+	return core.IndexRebuild{
+		Generation: res.GetGeneration(),
+		Docs:       int(res.GetDocs()),
+		DurationMs: res.GetDurationMs(),
+	}, nil
+}
+
+func (c *Client) VerifyIndex(ctx context.Context) (core.IndexDrift, error) {
+	res, err := c.client.VerifyIndex(admintoken.Outgoing(ctx, c.adminToken), &emptypb.Empty{})
+	if err != nil {
+		return core.IndexDrift{}, grpcerr.ToCore(err)
+	}
+
+	return core.IndexDrift{
+		Generation:      res.GetGeneration(),
+		DBDocs:          int(res.GetDbDocs()),
+		IndexDocs:       int(res.GetIndexDocs()),
+		InSync:          res.GetInSync(),
+		Missing:         toInts(res.GetMissing()),
+		Stale:           toInts(res.GetStale()),
+		Mismatched:      toInts(res.GetMismatched()),
+		MissingCount:    int(res.GetMissingCount()),
+		StaleCount:      int(res.GetStaleCount()),
+		MismatchedCount: int(res.GetMismatchedCount()),
+	}, nil
+}
+
+func (c *Client) TopQueries(ctx context.Context, window time.Duration, limit uint32) ([]core.QueryStat, error) {
+	res, err := c.client.TopQueries(admintoken.Outgoing(ctx, c.adminToken), analyticsRequest(window, limit))
+	if err != nil {
+		return nil, grpcerr.ToCore(err)
+	}
+	return queryStats(res), nil
+}
+
+func (c *Client) ZeroResultQueries(ctx context.Context, window time.Duration, limit uint32) ([]core.QueryStat, error) {
+	res, err := c.client.ZeroResultQueries(admintoken.Outgoing(ctx, c.adminToken), analyticsRequest(window, limit))
+	if err != nil {
+		return nil, grpcerr.ToCore(err)
+	}
+	return queryStats(res), nil
+}
+
+func (c *Client) LatencyPercentiles(ctx context.Context, window time.Duration) ([]core.LatencyStat, error) {
+	res, err := c.client.LatencyPercentiles(admintoken.Outgoing(ctx, c.adminToken), analyticsRequest(window, 0))
+	if err != nil {
+		return nil, grpcerr.ToCore(err)
+	}
+
+	out := make([]core.LatencyStat, 0, len(res.GetEndpoints()))
+	for _, e := range res.GetEndpoints() {
+		out = append(out, core.LatencyStat{
+			Endpoint: e.GetEndpoint(),
+			Count:    int(e.GetCount()),
+			P50Ms:    e.GetP50Ms(),
+			P90Ms:    e.GetP90Ms(),
+			P99Ms:    e.GetP99Ms(),
+		})
+	}
+	return out, nil
+}
+
+func analyticsRequest(window time.Duration, limit uint32) *searchpb.AnalyticsRequest {
+	return &searchpb.AnalyticsRequest{
+		WindowSeconds: uint32(window / time.Second),
+		Limit:         limit,
+	}
+}
+
+func queryStats(res *searchpb.QueryStatsReply) []core.QueryStat {
+	out := make([]core.QueryStat, 0, len(res.GetQueries()))
+	for _, q := range res.GetQueries() {
+		out = append(out, core.QueryStat{
+			Query:        q.GetQuery(),
+			Count:        int(q.GetCount()),
+			AvgResults:   q.GetAvgResults(),
+			LastSeenUnix: q.GetLastSeenUnix(),
+		})
+	}
+	return out
+}
+
+func toInts(ids []uint32) []int {
+	out := make([]int, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, int(id))
+	}
+	return out
+}
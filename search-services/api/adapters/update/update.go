@@ -2,14 +2,14 @@ package update
 
 import (
 	"context"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"log/slog"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"yadro.com/course/api/adapters/grpcerr"
 	"yadro.com/course/api/core"
+	"yadro.com/course/pkg/grpcclient"
+	"yadro.com/course/pkg/health"
 	updatepb "yadro.com/course/proto/update"
 )
 
@@ -20,7 +20,14 @@ type Client struct {
 }
 
 func NewClient(address string, log *slog.Logger) (*Client, error) {
-	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// Update и Drop не повторяются: повтор запустит обновление или удаление второй раз
+	conn, err := grpcclient.New(address, grpcclient.Options{
+		Idempotent: []string{
+			updatepb.Update_Ping_FullMethodName,
+			updatepb.Update_Status_FullMethodName,
+			updatepb.Update_Stats_FullMethodName,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -36,25 +43,20 @@ func (c *Client) Close() error { return c.conn.Close() }
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.client.Ping(ctx, &emptypb.Empty{})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.ErrUnavailable
-		default:
-			return err
-		}
+		return grpcerr.ToCore(err)
 	}
 	return nil
 }
 
+// Ready - готовность сервиса вместе с его зависимостями, по grpc.health.v1
+func (c *Client) Ready(ctx context.Context) error {
+	return grpcerr.ToCore(health.Probe(ctx, c.conn))
+}
+
 func (c *Client) Status(ctx context.Context) (core.UpdateStatus, error) {
 	resp, err := c.client.Status(ctx, &emptypb.Empty{})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.StatusUpdateUnknown, core.ErrUnavailable
-		default:
-			return core.StatusUpdateUnknown, err
-		}
+		return core.StatusUpdateUnknown, grpcerr.ToCore(err)
 	}
 	switch resp.GetStatus() {
 	case updatepb.Status_STATUS_IDLE:
@@ -69,12 +71,7 @@ func (c *Client) Status(ctx context.Context) (core.UpdateStatus, error) {
 func (c *Client) Stats(ctx context.Context) (core.UpdateStats, error) {
 	resp, err := c.client.Stats(ctx, &emptypb.Empty{})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.UpdateStats{}, core.ErrUnavailable
-		default:
-			return core.UpdateStats{}, err
-		}
+		return core.UpdateStats{}, grpcerr.ToCore(err)
 	}
 	return core.UpdateStats{
 		WordsTotal:    int(resp.GetWordsTotal()),
@@ -87,14 +84,7 @@ func (c *Client) Stats(ctx context.Context) (core.UpdateStats, error) {
 func (c *Client) Update(ctx context.Context) error {
 	_, err := c.client.Update(ctx, &emptypb.Empty{})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.AlreadyExists:
-			return core.ErrAlreadyExists
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.ErrUnavailable
-		default:
-			return err
-		}
+		return grpcerr.ToCore(err)
 	}
 	return nil
 }
@@ -102,12 +92,7 @@ func (c *Client) Update(ctx context.Context) error {
 func (c *Client) Drop(ctx context.Context) error {
 	_, err := c.client.Drop(ctx, &emptypb.Empty{})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.ErrUnavailable
-		default:
-			return err
-		}
+		return grpcerr.ToCore(err)
 	}
 	return nil
 }
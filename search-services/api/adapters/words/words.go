@@ -3,35 +3,46 @@ package words
 import (
 	"context"
 	"fmt"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"log/slog"
+	"yadro.com/course/api/adapters/grpcerr"
 	"yadro.com/course/api/core"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"yadro.com/course/pkg/admintoken"
+	"yadro.com/course/pkg/grpcclient"
+	"yadro.com/course/pkg/health"
 	wordspb "yadro.com/course/proto/words"
 )
 
 type Client struct {
-	log    *slog.Logger
-	client wordspb.WordsClient
-	conn   *grpc.ClientConn
+	log        *slog.Logger
+	client     wordspb.WordsClient
+	conn       *grpc.ClientConn
+	adminToken string
 }
 
-func NewClient(address string, log *slog.Logger) (*Client, error) {
-	conn, err := grpc.NewClient(
-		address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+// NewClient - adminToken нужен для Analyze, words пускает к нему только api
+func NewClient(address, adminToken string, log *slog.Logger) (*Client, error) {
+	conn, err := grpcclient.New(address, grpcclient.Options{
+		Idempotent: []string{
+			wordspb.Words_Ping_FullMethodName,
+			wordspb.Words_Norm_FullMethodName,
+			wordspb.Words_NormBatch_FullMethodName,
+			wordspb.Words_Expand_FullMethodName,
+			wordspb.Words_Analyze_FullMethodName,
+			wordspb.Words_Vocabulary_FullMethodName,
+			wordspb.Words_Correct_FullMethodName,
+		},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("new grpc client for  %s: %w", address, err)
 	}
 	return &Client{
-		client: wordspb.NewWordsClient(conn),
-		conn:   conn,
-		log:    log,
+		client:     wordspb.NewWordsClient(conn),
+		conn:       conn,
+		log:        log,
+		adminToken: adminToken,
 	}, nil
 }
 
@@ -40,27 +51,81 @@ func (c *Client) Close() error { return c.conn.Close() }
 func (c *Client) Norm(ctx context.Context, phrase string) ([]string, error) {
 	resp, err := c.client.Norm(ctx, &wordspb.WordsRequest{Phrase: phrase})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.ResourceExhausted:
-			return nil, core.ErrBadArguments
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return nil, core.ErrUnavailable
-		default:
-			return nil, err
-		}
+		return nil, grpcerr.ToCore(err)
 	}
 	return resp.GetWords(), nil
 }
 
+// Correct - пока words не получил словарь, ошибка ErrUnavailable
+func (c *Client) Correct(ctx context.Context, phrase string) (core.Correction, error) {
+	resp, err := c.client.Correct(ctx, &wordspb.WordsRequest{Phrase: phrase})
+	if err != nil {
+		return core.Correction{}, grpcerr.ToCore(err)
+	}
+
+	out := core.Correction{Phrase: resp.GetPhrase()}
+	for _, t := range resp.GetTokens() {
+		if t.GetKnown() || len(t.GetSuggestions()) == 0 {
+			continue
+		}
+		tc := core.TokenCorrection{Token: t.GetToken()}
+		for _, s := range t.GetSuggestions() {
+			tc.Suggestions = append(tc.Suggestions, core.Suggestion{
+				Term:     s.GetTerm(),
+				Distance: int(s.GetDistance()),
+				Count:    int(s.GetCount()),
+			})
+		}
+		out.Tokens = append(out.Tokens, tc)
+	}
+	return out, nil
+}
+
+func (c *Client) Analyze(ctx context.Context, phrase, lang, analyzer string) (core.Analysis, error) {
+	req := &wordspb.WordsRequest{Phrase: phrase}
+	if lang != "" {
+		req.Language = &lang
+	}
+	if analyzer != "" {
+		req.Analyzer = &analyzer
+	}
+	resp, err := c.client.Analyze(admintoken.Outgoing(ctx, c.adminToken), req)
+	if err != nil {
+		return core.Analysis{}, grpcerr.ToCore(err)
+	}
+
+	a := core.Analysis{
+		Language:        resp.GetLanguage(),
+		Analyzer:        resp.GetAnalyzer(),
+		AnalyzerVersion: resp.GetAnalyzerVersion(),
+		Stages:          make([]core.AnalyzeStage, 0, len(resp.GetStages())),
+		Words:           resp.GetWords(),
+	}
+	for _, st := range resp.GetStages() {
+		stage := core.AnalyzeStage{Name: st.GetName(), Tokens: make([]core.AnalyzedToken, 0, len(st.GetTokens()))}
+		for _, t := range st.GetTokens() {
+			stage.Tokens = append(stage.Tokens, core.AnalyzedToken{
+				Text:    t.GetText(),
+				Start:   int(t.GetStart()),
+				End:     int(t.GetEnd()),
+				Dropped: t.GetDropped(),
+				Note:    t.GetNote(),
+			})
+		}
+		a.Stages = append(a.Stages, stage)
+	}
+	return a, nil
+}
+
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.client.Ping(ctx, &emptypb.Empty{})
 	if err != nil {
-		switch status.Code(err) {
-		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
-			return core.ErrUnavailable
-		default:
-			return err
-		}
+		return grpcerr.ToCore(err)
 	}
 	return nil
 }
+
+// Ready - готовность сервиса вместе с его зависимостями, по grpc.health.v1
+func (c *Client) Ready(ctx context.Context) error {
+	return grpcerr.ToCore(health.Probe(ctx, c.conn))
+}
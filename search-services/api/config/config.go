@@ -5,12 +5,23 @@ import (
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+
+	"yadro.com/course/pkg/tracing"
 )
 
 type HTTPConfig struct {
 	Address         string        `yaml:"address" env:"API_ADDRESS" env-default:"localhost:80"`
 	InternalAddress string        `yaml:"internal_address" env:"API_INTERNAL_ADDRESS" env-default:"localhost:81"`
 	Timeout         time.Duration `yaml:"timeout" env:"API_TIMEOUT" env-default:"5s"`
+	// StreamTimeout - для NDJSON-выгрузок, обычного timeout на них не хватает
+	StreamTimeout time.Duration `yaml:"stream_timeout" env:"API_STREAM_TIMEOUT" env-default:"5m"`
+}
+
+// OpenAPIConfig - проверка запросов и ответов по спецификации;
+// ответы проверяются только в тестовом окружении, это буферизация и лишняя работа на каждый запрос
+type OpenAPIConfig struct {
+	ValidateRequests  bool `yaml:"validate_requests"  env:"OPENAPI_VALIDATE_REQUESTS"  env-default:"true"`
+	ValidateResponses bool `yaml:"validate_responses" env:"OPENAPI_VALIDATE_RESPONSES" env-default:"false"`
 }
 
 type Config struct {
@@ -27,11 +38,21 @@ type Config struct {
 	AdminPassword string        `yaml:"admin_password" env:"ADMIN_PASSWORD" env-required:"true"`
 	TokenTTL      time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" env-default:"2m"`
 
+	// SearchAdminToken - токен для методов суперпользователя в search, совпадает с его admin_token
+	SearchAdminToken string `yaml:"search_admin_token" env:"SEARCH_ADMIN_TOKEN"`
+	// WordsAdminToken - токен для Analyze в words, совпадает с его admin_token
+	WordsAdminToken string `yaml:"words_admin_token" env:"WORDS_ADMIN_TOKEN"`
+
 	// user jwt verify
 	AuthJWTSecret string `yaml:"auth_jwt_secret" env:"AUTH_JWT_SECRET" env-required:"true"`
 
 	SearchConcurrency int `yaml:"search_concurrency" env:"SEARCH_CONCURRENCY" env-default:"10"`
 	SearchRate        int `yaml:"search_rate"        env:"SEARCH_RATE"        env-default:"100"`
+
+	OpenAPI OpenAPIConfig `yaml:"openapi"`
+
+	// Tracing - экспорт OTLP трейсов, по умолчанию выключен
+	Tracing tracing.Config `yaml:"tracing"`
 }
 
 func MustLoad(configPath string) Config {
@@ -8,3 +8,6 @@ var ErrUnavailable = errors.New("dependency unavailable")
 var ErrInvalidCredentials = errors.New("invalid credentials")
 var ErrInvalidEmail = errors.New("invalid email format")
 var ErrNotFound = errors.New("not found")
+
+// ErrAdminTokenRejected - сервис не принял ADMIN_TOKEN api: токены в конфигурации разошлись
+var ErrAdminTokenRejected = errors.New("admin token rejected by service")
@@ -15,14 +15,134 @@ type UpdateStats struct {
 	ComicsTotal   int
 }
 
+type SearchQuery struct {
+	Phrase  string
+	Limit   uint32
+	Explain bool
+	Profile string
+	Filters SearchFilters
+	UserID  uint32 // из токена, если он есть; search пишет его в журнал запросов
+}
+
+// SearchFilters - даты в формате YYYY-MM-DD, пустые значения не фильтруют
+type SearchFilters struct {
+	PublishedFrom string
+	PublishedTo   string
+	IDFrom        int
+	IDTo          int
+	HasTranscript *bool
+	OnlyIDs       bool // искать только среди IDs (избранное)
+	IDs           []int
+}
+
 type SearchComic struct {
-	ID  int
-	URL string
+	ID      int
+	URL     string
+	Explain *Explanation // только для поиска с explain
 }
 
 type SearchResult struct {
 	Comics []SearchComic
 	Total  int
+	Tokens []string
+	Facets Facets
+}
+
+// Facets - количество найденных комиксов по годам и источникам совпадения
+type Facets struct {
+	Years   []FacetCount
+	Sources []FacetCount
+}
+
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// Explanation - разбор score комикса, приходит из search как есть
+type Explanation struct {
+	Function      string
+	Profile       string
+	Score         float64
+	Components    []ScoreComponent
+	Terms         []TermExplain
+	MatchedFields []string
+}
+
+type ScoreComponent struct {
+	Name         string
+	Value        float64
+	Weight       float64
+	Contribution float64
+}
+
+type TermExplain struct {
+	Token   string
+	Fields  []string
+	TitleTF int
+	AltTF   int
+	WordsTF int
+}
+
+type TermStat struct {
+	Term string
+	Docs int
+}
+
+type VersionStat struct {
+	Version string
+	Docs    int
+}
+
+type IndexStats struct {
+	Generation      uint64
+	Terms           int
+	Docs            int
+	Postings        int
+	MaxPosting      int
+	AvgPosting      float64
+	MemoryBytes     uint64
+	BuiltAtUnix     int64
+	BuildDurationMs int64
+	Trigger         string
+	TopTerms        []TermStat
+	Searches        uint64
+	IndexedSearches uint64
+
+	LastRebuildError       string
+	LastRebuildErrorAtUnix int64
+
+	ResultCache CacheStats
+	NormCache   CacheStats
+
+	AnalyzerVersions     []VersionStat
+	QueryAnalyzerVersion string
+}
+
+type CacheStats struct {
+	Hits     uint64
+	Misses   uint64
+	Entries  int
+	Capacity int
+}
+
+type IndexRebuild struct {
+	Generation uint64
+	Docs       int
+	DurationMs int64
+}
+
+type IndexDrift struct {
+	Generation      uint64
+	DBDocs          int
+	IndexDocs       int
+	InSync          bool
+	Missing         []int
+	Stale           []int
+	Mismatched      []int
+	MissingCount    int
+	StaleCount      int
+	MismatchedCount int
 }
 
 type TelegramProfile struct {
@@ -36,3 +156,80 @@ type FavoriteItem struct {
 	ComicID       int32
 	CreatedAtUnix int64
 }
+
+// PopularComic - сколько пользователей добавили комикс в избранное
+type PopularComic struct {
+	ComicID int
+	Count   int
+}
+
+// QueryStat - агрегат журнала поисковых запросов
+type QueryStat struct {
+	Query        string
+	Count        int
+	AvgResults   float64
+	LastSeenUnix int64
+}
+
+type LatencyStat struct {
+	Endpoint string
+	Count    int
+	P50Ms    float64
+	P90Ms    float64
+	P99Ms    float64
+}
+
+// RandomQuery - Seed nil - случайный выбор; Exclude - уже показанные комиксы
+type RandomQuery struct {
+	Seed    *uint64
+	Exclude []int
+}
+
+// DailyQuery - Date в формате YYYY-MM-DD, пусто - сегодня;
+// Popularity - комиксы из избранного пользователей, смещают выбор к популярным
+type DailyQuery struct {
+	Date       string
+	Seed       uint64
+	Popularity []PopularComic
+}
+
+// Analysis - разбор Norm по стадиям от words
+// Correction - Phrase - исправленный запрос, пустой, если исправлять нечего.
+// Tokens - только слова, которых нет в словаре корпуса
+type Correction struct {
+	Phrase string
+	Tokens []TokenCorrection
+}
+
+type TokenCorrection struct {
+	Token       string
+	Suggestions []Suggestion
+}
+
+type Suggestion struct {
+	Term     string
+	Distance int
+	Count    int
+}
+
+type Analysis struct {
+	Language        string
+	Analyzer        string
+	AnalyzerVersion string
+	Stages          []AnalyzeStage
+	Words           []string
+}
+
+type AnalyzeStage struct {
+	Name   string
+	Tokens []AnalyzedToken
+}
+
+// AnalyzedToken - Start и End в символах исходной фразы, Dropped - причина отсева на стадии
+type AnalyzedToken struct {
+	Text    string
+	Start   int
+	End     int
+	Dropped string
+	Note    string
+}
@@ -1,15 +1,34 @@
 package core
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Normalizer interface {
 	Norm(context.Context, string) ([]string, error)
 }
 
+// Analyzer - разбор нормализации по стадиям для отладки поиска, пустой lang - автоопределение,
+// пустой analyzer - цепочка default
+type Analyzer interface {
+	Analyze(ctx context.Context, phrase, lang, analyzer string) (Analysis, error)
+}
+
+// Corrector - исправление опечаток по словарю корпуса для "did you mean"
+type Corrector interface {
+	Correct(ctx context.Context, phrase string) (Correction, error)
+}
+
 type Pinger interface {
 	Ping(context.Context) error
 }
 
+// Readier - готовность сервиса с его зависимостями (Postgres, NATS, индекс), а не только процесса
+type Readier interface {
+	Ready(context.Context) error
+}
+
 type Updater interface {
 	Update(context.Context) error
 	Stats(context.Context) (UpdateStats, error)
@@ -19,13 +38,26 @@ type Updater interface {
 }
 
 type Searcher interface {
-	Find(ctx context.Context, phrase string, limit uint32) (SearchResult, error)
-	IndexedSearch(ctx context.Context, phrase string, limit uint32) (SearchResult, error)
+	Find(ctx context.Context, q SearchQuery) (SearchResult, error)
+	IndexedSearch(ctx context.Context, q SearchQuery) (SearchResult, error)
 	Ping(ctx context.Context) error
 
 	GetComic(ctx context.Context, id int) (SearchComic, error)
-	RandomComic(ctx context.Context) (SearchComic, error)
+	RandomComic(ctx context.Context, q RandomQuery) (SearchComic, error)
+	ComicOfTheDay(ctx context.Context, q DailyQuery) (SearchComic, error)
 	ListComics(ctx context.Context, page, limit uint32) (SearchResult, error)
+
+	// стриминг: send вызывается на каждую пачку, ошибка send прерывает поток
+	StreamSearch(ctx context.Context, q SearchQuery, indexed bool, chunk uint32, send func([]SearchComic) error) error
+	StreamComics(ctx context.Context, chunk uint32, send func([]SearchComic) error) error
+
+	IndexStats(ctx context.Context, top uint32) (IndexStats, error)
+	RebuildIndex(ctx context.Context) (IndexRebuild, error)
+	VerifyIndex(ctx context.Context) (IndexDrift, error)
+
+	TopQueries(ctx context.Context, window time.Duration, limit uint32) ([]QueryStat, error)
+	ZeroResultQueries(ctx context.Context, window time.Duration, limit uint32) ([]QueryStat, error)
+	LatencyPercentiles(ctx context.Context, window time.Duration) ([]LatencyStat, error)
 }
 
 type Auth interface {
@@ -39,5 +71,6 @@ type Favorites interface {
 	Add(ctx context.Context, userID uint32, comicID int32) error
 	Delete(ctx context.Context, userID uint32, comicID int32) error
 	List(ctx context.Context, userID uint32) ([]FavoriteItem, error)
+	Popular(ctx context.Context, limit uint32) ([]PopularComic, error)
 	Ping(ctx context.Context) error
 }
@@ -12,11 +12,12 @@ import (
 	"yadro.com/course/api/adapters/auth"
 	"yadro.com/course/api/adapters/favorites"
 	"yadro.com/course/api/adapters/rest/middleware"
+	"yadro.com/course/api/adapters/rest/openapi"
 	"yadro.com/course/api/adapters/search"
 	"yadro.com/course/api/adapters/words"
-	"yadro.com/course/api/core"
+	"yadro.com/course/pkg/requestid"
+	"yadro.com/course/pkg/tracing"
 
-	"yadro.com/course/api/adapters/rest"
 	"yadro.com/course/api/adapters/update"
 	"yadro.com/course/api/config"
 )
@@ -29,22 +30,31 @@ func main() {
 	cfg := config.MustLoad(configPath)
 
 	log := mustMakeLogger(cfg.LogLevel)
+	// slog.Default - для пакетов без своего логгера (problem)
+	slog.SetDefault(log)
 
 	log.Info("starting server")
 	log.Debug("debug messages are enabled")
 
+	// tracing: до создания gRPC клиентов, они берут провайдер при создании
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "api")
+	if err != nil {
+		log.Error("cannot init tracing", "error", err)
+		os.Exit(1)
+	}
+
 	// Clients
 	updateClient, err := update.NewClient(cfg.UpdateAddress, log)
 	if err != nil {
 		log.Error("cannot init update adapter", "error", err)
 		os.Exit(1)
 	}
-	wordsClient, err := words.NewClient(cfg.WordsAddress, log)
+	wordsClient, err := words.NewClient(cfg.WordsAddress, cfg.WordsAdminToken, log)
 	if err != nil {
 		log.Error("cannot init words adapter", "error", err)
 		os.Exit(1)
 	}
-	searchClient, err := search.NewClient(cfg.SearchAddress, log)
+	searchClient, err := search.NewClient(cfg.SearchAddress, cfg.SearchAdminToken, log)
 	if err != nil {
 		log.Error("cannot init search adapter", "error", err)
 		os.Exit(1)
@@ -60,92 +70,55 @@ func main() {
 		os.Exit(1)
 	}
 
-	// приведение типов для компилятора
-	pingmap := map[string]core.Pinger{
-		"words":     wordsClient,
-		"update":    updateClient,
-		"search":    searchClient,
-		"auth":      authClient,
-		"favorites": favoritesClient,
+	// openapi: спецификация, swagger ui и проверка запросов/ответов
+	doc, err := openapi.Load()
+	if err != nil {
+		log.Error("cannot load openapi spec", "error", err)
+		os.Exit(1)
+	}
+	mux, internalmux, err := newRoutes(log, cfg, doc, clients{
+		update:    updateClient,
+		words:     wordsClient,
+		search:    searchClient,
+		auth:      authClient,
+		favorites: favoritesClient,
+	})
+	if err != nil {
+		log.Error("cannot marshal openapi spec", "error", err)
+		os.Exit(1)
 	}
 
-	mux := http.NewServeMux()
-	internalmux := http.NewServeMux()
-
-	mux.Handle("GET /api/ping", rest.NewPingHandler(log, pingmap, cfg.HTTPConfig.Timeout))
-
-	// login
-	mux.Handle("POST /api/login", middleware.NewLoginHandler(log, cfg.AdminUser, cfg.AdminPassword, cfg.TokenTTL))
-
-	// search api
-	searchHandler := rest.NewSearchHandler(log, searchClient, cfg.HTTPConfig.Timeout)
-	mux.Handle("GET /api/search",
-		middleware.WithConcurrencyLimit(searchHandler, cfg.SearchConcurrency),
-	)
-
-	isearchHandler := rest.NewIndexedSearchHandler(log, searchClient, cfg.HTTPConfig.Timeout)
-	mux.Handle("GET /api/isearch",
-		middleware.WithRateLimit(isearchHandler, cfg.SearchRate),
-	)
-
-	// search(comics api)
-	mux.Handle("GET /api/comics",
-		rest.NewComicsListHandler(log, searchClient, cfg.HTTPConfig.Timeout),
-	)
-	mux.Handle("GET /api/comics/{id}",
-		rest.NewComicByIDHandler(log, searchClient, cfg.HTTPConfig.Timeout),
-	)
-	mux.Handle("GET /api/comics/random",
-		rest.NewRandomComicHandler(log, searchClient, cfg.HTTPConfig.Timeout),
-	)
-
-	// update api
-	mux.Handle("POST /api/db/update",
-		middleware.RequireSuperuser(rest.NewUpdateHandler(log, updateClient), cfg.TokenTTL),
-	)
-	mux.Handle("GET /api/db/stats",
-		rest.NewUpdateStatsHandler(log, updateClient, cfg.HTTPConfig.Timeout),
-	)
-	mux.Handle("GET /api/db/status",
-		rest.NewUpdateStatusHandler(log, updateClient, cfg.HTTPConfig.Timeout),
-	)
-	mux.Handle("DELETE /api/db",
-		middleware.RequireSuperuser(rest.NewDropHandler(log, updateClient, cfg.HTTPConfig.Timeout), cfg.TokenTTL),
-	)
-
-	// auth api
-	mux.Handle("POST /api/auth/register",
-		rest.NewRegisterHandler(log, authClient, cfg.HTTPConfig.Timeout),
-	)
-	mux.Handle("POST /api/auth/login",
-		rest.NewUserLoginHandler(log, authClient, cfg.HTTPConfig.Timeout),
-	)
-	// internal pen
-	internalmux.Handle("POST /api/auth/bot/telegram/login",
-		rest.NewBotTelegramLoginHandler(log, authClient, cfg.HTTPConfig.Timeout),
-	)
+	for _, p := range openapi.Undocumented(doc, append(mux.Patterns(), internalmux.Patterns()...)) {
+		log.Warn("route is not documented in openapi spec", "route", p)
+	}
 
-	// favorites api
-	mux.Handle("GET /api/mycomics",
-		middleware.RequireUser(rest.NewFavoritesListHandler(log, favoritesClient, cfg.HTTPConfig.Timeout), cfg.AuthJWTSecret),
-	)
-	mux.Handle("POST /api/mycomics/{id}",
-		middleware.RequireUser(rest.NewFavoritesAddHandler(log, favoritesClient, searchClient, cfg.HTTPConfig.Timeout), cfg.AuthJWTSecret),
-	)
-	mux.Handle("DELETE /api/mycomics/{id}",
-		middleware.RequireUser(rest.NewFavoritesDeleteHandler(log, favoritesClient, cfg.HTTPConfig.Timeout), cfg.AuthJWTSecret),
+	handler, err := middleware.WithOpenAPI(mux, doc, cfg.OpenAPI.ValidateRequests, cfg.OpenAPI.ValidateResponses, log)
+	if err != nil {
+		log.Error("cannot init openapi validation", "error", err)
+		os.Exit(1)
+	}
+	internalHandler, err := middleware.WithOpenAPI(internalmux, doc, cfg.OpenAPI.ValidateRequests, cfg.OpenAPI.ValidateResponses, log)
+	if err != nil {
+		log.Error("cannot init openapi validation", "error", err)
+		os.Exit(1)
+	}
+	log.Info("openapi validation",
+		"requests", cfg.OpenAPI.ValidateRequests,
+		"responses", cfg.OpenAPI.ValidateResponses,
 	)
 
+	// id запроса снаружи всего остального, чтобы попасть и в ошибки проверки по спецификации;
+	// метрики и трейсы тоже снаружи проверки, отклоненные запросы считаются по своему маршруту
 	server := http.Server{
 		Addr:        cfg.HTTPConfig.Address,
 		ReadTimeout: cfg.HTTPConfig.Timeout,
-		Handler:     mux,
+		Handler:     middleware.WithRequestID(middleware.WithTracing(middleware.WithMetrics(handler, "public", mux), "public", mux)),
 	}
 
 	internalServer := http.Server{
 		Addr:        cfg.HTTPConfig.InternalAddress,
 		ReadTimeout: cfg.HTTPConfig.Timeout,
-		Handler:     internalmux,
+		Handler:     middleware.WithRequestID(middleware.WithTracing(middleware.WithMetrics(internalHandler, "internal", internalmux), "internal", internalmux)),
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -183,6 +156,10 @@ func main() {
 	_ = searchClient.Close()
 	_ = authClient.Close()
 	_ = favoritesClient.Close()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("cannot flush traces", "error", err)
+	}
 }
 
 func mustMakeLogger(logLevel string) *slog.Logger {
@@ -198,5 +175,6 @@ func mustMakeLogger(logLevel string) *slog.Logger {
 		panic("unknown log level: " + logLevel)
 	}
 	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
-	return slog.New(handler)
+	// request_id из контекста вызова добавляется к записям *Context
+	return slog.New(requestid.NewLogHandler(handler))
 }
@@ -0,0 +1,74 @@
+package problem
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"yadro.com/course/pkg/requestid"
+)
+
+const ContentType = "application/problem+json"
+
+// Коды ошибок - стабильная часть ответа, клиенты ветвятся по ним, а не по тексту
+const (
+	CodeBadRequest         = "bad_request"
+	CodeInvalidParameter   = "invalid_parameter"
+	CodeInvalidEmail       = "invalid_email"
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeNotFound           = "not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeConflict           = "conflict"
+	CodeInternal           = "internal"
+	CodeUnavailable        = "unavailable"
+	CodeOverloaded         = "overloaded"
+	CodeStreamInterrupted  = "stream_interrupted"
+	CodeAdminTokenRejected = "admin_token_rejected"
+)
+
+// Problem - тело ошибки по RFC 9457. Type всегда about:blank, поэтому Title - текст статуса;
+// Code, RequestID и Errors - расширения
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError - ошибка в конкретном параметре или поле тела запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// New - ошибка для запроса r: Instance - путь запроса, RequestID - из контекста
+func New(r *http.Request, status int, code, detail string) *Problem {
+	return &Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: requestid.FromContext(r.Context()),
+	}
+}
+
+func (p *Problem) WithField(field, message string) *Problem {
+	p.Errors = append(p.Errors, FieldError{Field: field, Message: message})
+	return p
+}
+
+// Write - ответ с ошибкой p на запрос r; сбой записи логируется с request_id запроса
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.Default().ErrorContext(r.Context(), "write problem failed", "error", err)
+	}
+}
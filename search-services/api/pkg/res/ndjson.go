@@ -0,0 +1,48 @@
+package res
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NDJSON - потоковый ответ application/x-ndjson, по объекту на строку.
+// Заголовки уходят с первой записью, до нее еще можно ответить обычным Json
+type NDJSON struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	enc     *json.Encoder
+	started bool
+}
+
+func NewNDJSON(w http.ResponseWriter) *NDJSON {
+	return &NDJSON{
+		w:   w,
+		rc:  http.NewResponseController(w),
+		enc: json.NewEncoder(w),
+	}
+}
+
+// Started - ответ уже начат, статус поменять нельзя
+func (n *NDJSON) Started() bool {
+	return n.started
+}
+
+// Start - отправить заголовки, если еще не отправлены (нужно для пустого потока)
+func (n *NDJSON) Start() {
+	if n.started {
+		return
+	}
+	n.w.Header().Set("Content-Type", "application/x-ndjson")
+	n.w.WriteHeader(http.StatusOK)
+	n.started = true
+}
+
+func (n *NDJSON) Write(v any) error {
+	n.Start()
+	return n.enc.Encode(v)
+}
+
+// Flush - отправить накопленное клиенту, вызывается после каждой пачки
+func (n *NDJSON) Flush() error {
+	return n.rc.Flush()
+}
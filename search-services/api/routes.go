@@ -0,0 +1,173 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"yadro.com/course/api/adapters/auth"
+	"yadro.com/course/api/adapters/favorites"
+	"yadro.com/course/api/adapters/rest"
+	"yadro.com/course/api/adapters/rest/middleware"
+	"yadro.com/course/api/adapters/search"
+	"yadro.com/course/api/adapters/update"
+	"yadro.com/course/api/adapters/words"
+	"yadro.com/course/api/config"
+	"yadro.com/course/api/core"
+	"yadro.com/course/pkg/metrics"
+)
+
+// clients - адаптеры сервисов, на которых стоят обработчики
+type clients struct {
+	update    *update.Client
+	words     *words.Client
+	search    *search.Client
+	auth      *auth.Client
+	favorites *favorites.Client
+}
+
+// newRoutes - маршруты публичного и внутреннего портов.
+// Обработчики только запоминают клиентов, поэтому тест строит маршруты без сервисов
+func newRoutes(log *slog.Logger, cfg config.Config, doc *openapi3.T, c clients) (mux, internalmux *rest.Routes, err error) {
+	// приведение типов для компилятора
+	pingmap := map[string]core.Pinger{
+		"words":     c.words,
+		"update":    c.update,
+		"search":    c.search,
+		"auth":      c.auth,
+		"favorites": c.favorites,
+	}
+
+	mux = rest.NewRoutes()
+	internalmux = rest.NewRoutes()
+
+	mux.Handle("GET /api/ping", rest.NewPingHandler(log, pingmap, cfg.HTTPConfig.Timeout))
+
+	// liveness и readiness для оркестратора: /readyz опрашивает grpc.health.v1 всех сервисов
+	readymap := map[string]core.Readier{
+		"words":     c.words,
+		"update":    c.update,
+		"search":    c.search,
+		"auth":      c.auth,
+		"favorites": c.favorites,
+	}
+	mux.Handle("GET /healthz", rest.NewLivenessHandler())
+	mux.Handle("GET /readyz", rest.NewReadinessHandler(log, readymap, cfg.HTTPConfig.Timeout))
+
+	// login
+	mux.Handle("POST /api/login", middleware.NewLoginHandler(log, cfg.AdminUser, cfg.AdminPassword, cfg.TokenTTL))
+
+	// search api
+	// токен необязателен, нужен только для only_favorites
+	searchHandler := rest.NewSearchHandler(log, c.search, c.favorites, c.words, cfg.HTTPConfig.Timeout)
+	mux.Handle("GET /api/search",
+		middleware.WithConcurrencyLimit(middleware.OptionalUser(searchHandler, cfg.AuthJWTSecret), cfg.SearchConcurrency),
+	)
+
+	isearchHandler := rest.NewIndexedSearchHandler(log, c.search, c.favorites, c.words, cfg.HTTPConfig.Timeout)
+	mux.Handle("GET /api/isearch",
+		middleware.WithRateLimit(middleware.OptionalUser(isearchHandler, cfg.AuthJWTSecret), cfg.SearchRate),
+	)
+
+	// стриминг в NDJSON, у каждой выгрузки свой лимит конкурентности, чтобы долгие потоки не занимали слоты /api/search
+	searchStreamHandler := rest.NewSearchStreamHandler(log, c.search, c.favorites, cfg.HTTPConfig.StreamTimeout)
+	mux.Handle("GET /api/search/stream",
+		middleware.WithConcurrencyLimit(middleware.OptionalUser(searchStreamHandler, cfg.AuthJWTSecret), cfg.SearchConcurrency),
+	)
+	// выгрузка всей базы - любому пользователю с токеном (листание каталога в боте)
+	mux.Handle("GET /api/comics/stream",
+		middleware.WithConcurrencyLimit(
+			middleware.RequireUser(rest.NewComicsStreamHandler(log, c.search, cfg.HTTPConfig.StreamTimeout), cfg.AuthJWTSecret),
+			cfg.SearchConcurrency,
+		),
+	)
+
+	// search(comics api)
+	mux.Handle("GET /api/comics",
+		rest.NewComicsListHandler(log, c.search, cfg.HTTPConfig.Timeout),
+	)
+	mux.Handle("GET /api/comics/{id}",
+		rest.NewComicByIDHandler(log, c.search, cfg.HTTPConfig.Timeout),
+	)
+	mux.Handle("GET /api/comics/random",
+		rest.NewRandomComicHandler(log, c.search, cfg.HTTPConfig.Timeout),
+	)
+	mux.Handle("GET /api/comics/daily",
+		rest.NewComicOfTheDayHandler(log, c.search, c.favorites, cfg.HTTPConfig.Timeout),
+	)
+
+	mux.Handle("GET /api/index/stats",
+		rest.NewIndexStatsHandler(log, c.search, cfg.HTTPConfig.Timeout),
+	)
+	mux.Handle("POST /api/index/rebuild",
+		middleware.RequireSuperuser(rest.NewIndexRebuildHandler(log, c.search, cfg.HTTPConfig.Timeout), cfg.TokenTTL),
+	)
+	mux.Handle("GET /api/index/verify",
+		middleware.RequireSuperuser(rest.NewIndexVerifyHandler(log, c.search, cfg.HTTPConfig.Timeout), cfg.TokenTTL),
+	)
+
+	// отладка нормализации
+	mux.Handle("GET /api/words/analyze",
+		middleware.RequireSuperuser(rest.NewAnalyzeHandler(log, c.words, cfg.HTTPConfig.Timeout), cfg.TokenTTL),
+	)
+
+	// search analytics
+	mux.Handle("GET /api/analytics/queries/top",
+		middleware.RequireSuperuser(rest.NewTopQueriesHandler(log, c.search, cfg.HTTPConfig.Timeout), cfg.TokenTTL),
+	)
+	mux.Handle("GET /api/analytics/queries/zero",
+		middleware.RequireSuperuser(rest.NewZeroResultQueriesHandler(log, c.search, cfg.HTTPConfig.Timeout), cfg.TokenTTL),
+	)
+	mux.Handle("GET /api/analytics/latency",
+		middleware.RequireSuperuser(rest.NewLatencyPercentilesHandler(log, c.search, cfg.HTTPConfig.Timeout), cfg.TokenTTL),
+	)
+
+	// update api
+	mux.Handle("POST /api/db/update",
+		middleware.RequireSuperuser(rest.NewUpdateHandler(log, c.update), cfg.TokenTTL),
+	)
+	mux.Handle("GET /api/db/stats",
+		rest.NewUpdateStatsHandler(log, c.update, cfg.HTTPConfig.Timeout),
+	)
+	mux.Handle("GET /api/db/status",
+		rest.NewUpdateStatusHandler(log, c.update, cfg.HTTPConfig.Timeout),
+	)
+	mux.Handle("DELETE /api/db",
+		middleware.RequireSuperuser(rest.NewDropHandler(log, c.update, cfg.HTTPConfig.Timeout), cfg.TokenTTL),
+	)
+
+	// auth api
+	mux.Handle("POST /api/auth/register",
+		rest.NewRegisterHandler(log, c.auth, cfg.HTTPConfig.Timeout),
+	)
+	mux.Handle("POST /api/auth/login",
+		rest.NewUserLoginHandler(log, c.auth, cfg.HTTPConfig.Timeout),
+	)
+	// internal pen
+	internalmux.Handle("POST /api/auth/bot/telegram/login",
+		rest.NewBotTelegramLoginHandler(log, c.auth, cfg.HTTPConfig.Timeout),
+	)
+
+	// prometheus, только на внутреннем порту
+	internalmux.Handle("GET /metrics", metrics.Handler())
+
+	// favorites api
+	mux.Handle("GET /api/mycomics",
+		middleware.RequireUser(rest.NewFavoritesListHandler(log, c.favorites, cfg.HTTPConfig.Timeout), cfg.AuthJWTSecret),
+	)
+	mux.Handle("POST /api/mycomics/{id}",
+		middleware.RequireUser(rest.NewFavoritesAddHandler(log, c.favorites, c.search, cfg.HTTPConfig.Timeout), cfg.AuthJWTSecret),
+	)
+	mux.Handle("DELETE /api/mycomics/{id}",
+		middleware.RequireUser(rest.NewFavoritesDeleteHandler(log, c.favorites, cfg.HTTPConfig.Timeout), cfg.AuthJWTSecret),
+	)
+
+	// openapi: спецификация и swagger ui
+	openapiHandler, err := rest.NewOpenAPIHandler(log, doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	mux.Handle("GET /api/openapi.json", openapiHandler)
+	mux.Handle("GET /api/docs", rest.NewSwaggerUIHandler())
+
+	return mux, internalmux, nil
+}
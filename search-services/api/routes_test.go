@@ -0,0 +1,31 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"yadro.com/course/api/adapters/rest/openapi"
+	"yadro.com/course/api/config"
+)
+
+// маршруты строятся с нулевыми конфигом и клиентами: обработчики сервисы не вызывают
+func TestRoutesDocumented(t *testing.T) {
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mux, internalmux, err := newRoutes(log, config.Config{}, doc, clients{})
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+
+	patterns := append(mux.Patterns(), internalmux.Patterns()...)
+	if len(patterns) == 0 {
+		t.Fatal("no routes registered")
+	}
+	if missing := openapi.Undocumented(doc, patterns); len(missing) != 0 {
+		t.Fatalf("routes not documented in openapi spec: %v", missing)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"log/slog"
 
 	"yadro.com/course/auth/core"
+	"yadro.com/course/pkg/tracing"
 )
 
 type DB struct {
@@ -20,7 +21,7 @@ type DB struct {
 }
 
 func New(log *slog.Logger, address string) (*DB, error) {
-	db, err := sqlx.Connect("pgx", address)
+	db, err := tracing.ConnectDB(address)
 	if err != nil {
 		log.Error("connection problem", "address", address, "error", err)
 		return nil, err
@@ -28,6 +29,11 @@ func New(log *slog.Logger, address string) (*DB, error) {
 	return &DB{log: log, conn: db}, nil
 }
 
+// Pool - пул соединений, его статистика уходит в метрики
+func (db *DB) Pool() *sql.DB {
+	return db.conn.DB
+}
+
 func (db *DB) Ping(ctx context.Context) error {
 	return db.conn.PingContext(ctx)
 }
@@ -39,7 +39,7 @@ func (s *Server) Register(ctx context.Context, req *authpb.RegisterRequest) (*au
 		case errors.Is(err, core.ErrInvalidEmail):
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		default:
-			s.log.Error("register failed", "email", req.GetEmail(), "error", err)
+			s.log.ErrorContext(ctx, "register failed", "email", req.GetEmail(), "error", err)
 			return nil, status.Error(codes.Internal, "internal error")
 		}
 	}
@@ -59,7 +59,7 @@ func (s *Server) Login(ctx context.Context, req *authpb.LoginRequest) (*authpb.T
 		case errors.Is(err, core.ErrInvalidEmail):
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		default:
-			s.log.Error("login failed", "email", req.GetEmail(), "error", err)
+			s.log.ErrorContext(ctx, "login failed", "email", req.GetEmail(), "error", err)
 			return nil, status.Error(codes.Internal, "internal error")
 		}
 	}
@@ -79,7 +79,7 @@ func (s *Server) BotLoginTelegram(ctx context.Context, req *authpb.BotLoginTeleg
 		LastName:  u.GetLastName(),
 	})
 	if err != nil {
-		s.log.Error("bot login telegram failed", "tg_id", u.GetTgId(), "error", err)
+		s.log.ErrorContext(ctx, "bot login telegram failed", "tg_id", u.GetTgId(), "error", err)
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 	return &authpb.TokenResponse{Token: token}, nil
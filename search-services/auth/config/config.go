@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+
+	"yadro.com/course/pkg/tracing"
 )
 
 type Config struct {
@@ -13,6 +15,12 @@ type Config struct {
 	DBAddress string        `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
 	JWTSecret string        `yaml:"jwt_secret" env:"AUTH_JWT_SECRET" env-required:"true"`
 	TokenTTL  time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" env-default:"24h"`
+
+	// MetricsAddress - /metrics для Prometheus на отдельном порту, пусто - выключено
+	MetricsAddress string `yaml:"metrics_address" env:"METRICS_ADDRESS" env-default:":9090"`
+
+	// Tracing - экспорт OTLP трейсов, по умолчанию выключен
+	Tracing tracing.Config `yaml:"tracing"`
 }
 
 func MustLoad(configPath string) Config {
@@ -66,7 +66,7 @@ func (s *Service) Register(ctx context.Context, email, password string) (string,
 	// Хэшируем пароль
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		s.log.Error("failed to hash password", "error", err)
+		s.log.ErrorContext(ctx, "failed to hash password", "error", err)
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 
@@ -78,14 +78,14 @@ func (s *Service) Register(ctx context.Context, email, password string) (string,
 		if errors.Is(err, ErrUserAlreadyExists) {
 			return "", ErrUserAlreadyExists
 		}
-		s.log.Error("failed to create comicshub user", "email", email, "error", err)
+		s.log.ErrorContext(ctx, "failed to create comicshub user", "email", email, "error", err)
 		return "", fmt.Errorf("failed to create user: %w", err)
 	}
 
 	// Генерируем JWT для только что созданного пользователя
 	token, err := s.generateToken(u.ID)
 	if err != nil {
-		s.log.Error("failed to generate jwt on register", "email", email, "error", err)
+		s.log.ErrorContext(ctx, "failed to generate jwt on register", "email", email, "error", err)
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
 
@@ -106,7 +106,7 @@ func (s *Service) Login(ctx context.Context, email, password string) (string, er
 
 	u, hash, err := s.db.GetComicshubByEmail(ctx, email)
 	if err != nil {
-		s.log.Warn("user not found or db error on login", "email", email, "error", err)
+		s.log.WarnContext(ctx, "user not found or db error on login", "email", email, "error", err)
 		return "", ErrInvalidCredentials
 	}
 
@@ -118,7 +118,7 @@ func (s *Service) Login(ctx context.Context, email, password string) (string, er
 	// Генерируем JWT
 	token, err := s.generateToken(u.ID)
 	if err != nil {
-		s.log.Error("failed to generate jwt on login", "email", email, "error", err)
+		s.log.ErrorContext(ctx, "failed to generate jwt on login", "email", email, "error", err)
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
 
@@ -9,11 +9,16 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"yadro.com/course/auth/adapters/db"
 	authgrpc "yadro.com/course/auth/adapters/grpc"
 	"yadro.com/course/auth/config"
 	"yadro.com/course/auth/core"
+	"yadro.com/course/pkg/health"
+	"yadro.com/course/pkg/metrics"
+	"yadro.com/course/pkg/requestid"
+	"yadro.com/course/pkg/tracing"
 	authpb "yadro.com/course/proto/auth"
 
 	"google.golang.org/grpc"
@@ -42,6 +47,19 @@ func run(cfg config.Config, log *slog.Logger) error {
 	log.Info("starting auth server")
 	log.Debug("debug messages are enabled")
 
+	// tracing: до создания gRPC клиентов и серверов, они берут провайдер при создании
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "auth")
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("failed to flush traces", "error", err)
+		}
+	}()
+
 	// database adapter
 	storage, err := db.New(log, cfg.DBAddress)
 	if err != nil {
@@ -64,14 +82,27 @@ func run(cfg config.Config, log *slog.Logger) error {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestid.UnaryServerInterceptor(), metrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(requestid.StreamServerInterceptor(), metrics.StreamServerInterceptor()),
+		tracing.ServerOption(),
+	)
 	authpb.RegisterAuthServer(s, authgrpc.NewServer(log, authorization))
+	// grpc.health.v1: готовность по Postgres
+	checker := health.Register(s, log, authpb.Auth_ServiceDesc.ServiceName, map[string]health.Check{
+		"postgres": storage.Ping,
+	})
 	reflection.Register(s)
 
 	// graceful shutdown
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// metrics: пул БД и /metrics на отдельном порту
+	metrics.RegisterDB(storage.Pool(), "auth")
+	metrics.Serve(ctx, log, cfg.MetricsAddress)
+	checker.Start(ctx, health.Interval)
+
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down auth server")
@@ -102,5 +133,6 @@ func mustMakeLogger(levelStr string) *slog.Logger {
 	}
 
 	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
-	return slog.New(handler)
+	// request_id из контекста вызова добавляется к записям *Context
+	return slog.New(requestid.NewLogHandler(handler))
 }
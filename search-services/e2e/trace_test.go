@@ -0,0 +1,161 @@
+// сквозной трейс api -> search -> words в одном процессе: пакет отдельно от сервисов,
+// чтобы их unit-тесты не зависели друг от друга
+package e2e_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	"yadro.com/course/api/adapters/rest"
+	"yadro.com/course/api/adapters/rest/middleware"
+	apisearch "yadro.com/course/api/adapters/search"
+	"yadro.com/course/pkg/tracing"
+	searchpb "yadro.com/course/proto/search"
+	wordspb "yadro.com/course/proto/words"
+	searchgrpc "yadro.com/course/search/adapters/grpc"
+	"yadro.com/course/search/adapters/ranking"
+	searchwords "yadro.com/course/search/adapters/words"
+	searchcore "yadro.com/course/search/core"
+)
+
+// traceDB - БД search с одним комиксом, остальные методы порта не вызываются
+type traceDB struct {
+	searchcore.DB
+}
+
+func (traceDB) Find(context.Context, []string, searchcore.SearchFilters) ([]searchcore.Comics, error) {
+	return []searchcore.Comics{{ID: 1, URL: "https://xkcd.com/1", Title: []string{"linux"}, Words: []string{"linux"}}}, nil
+}
+
+// traceWords - words, который раскрывает фразу в нее саму: трейсу нужен только вызов Expand
+type traceWords struct {
+	wordspb.UnimplementedWordsServer
+}
+
+func (traceWords) Expand(_ context.Context, in *wordspb.WordsRequest) (*wordspb.ExpandReply, error) {
+	return &wordspb.ExpandReply{Words: []*wordspb.WeightedWord{{Word: in.GetPhrase(), Weight: 1}}}, nil
+}
+
+// serveGRPC - настоящий gRPC сервер на локальном порту, с теми же опциями трейсинга, что в main
+func serveGRPC(t *testing.T, register func(*grpc.Server)) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer(tracing.ServerOption())
+	register(srv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+// TestSearchTrace - один /api/search дает один связный трейс api -> search -> words
+func TestSearchTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// words
+	wordsAddr := serveGRPC(t, func(s *grpc.Server) {
+		wordspb.RegisterWordsServer(s, traceWords{})
+	})
+
+	// search
+	wordsClient, err := searchwords.NewClient(wordsAddr, "", "", "", log, 0, 0)
+	if err != nil {
+		t.Fatalf("search words client: %v", err)
+	}
+	t.Cleanup(func() { _ = wordsClient.Close() })
+	profiles, err := ranking.New(log, "")
+	if err != nil {
+		t.Fatalf("ranking: %v", err)
+	}
+	search := searchcore.NewService(log, traceDB{}, wordsClient, searchcore.BackendArray, profiles, nil, nil)
+	searchAddr := serveGRPC(t, func(s *grpc.Server) {
+		searchpb.RegisterSearchServer(s, searchgrpc.NewServer(search))
+	})
+
+	// api
+	searchClient, err := apisearch.NewClient(searchAddr, "", log)
+	if err != nil {
+		t.Fatalf("api search client: %v", err)
+	}
+	t.Cleanup(func() { _ = searchClient.Close() })
+	mux := rest.NewRoutes()
+	mux.Handle("GET /api/search", rest.NewSearchHandler(log, searchClient, nil, nil, 5*time.Second))
+	api := httptest.NewServer(middleware.WithTracing(mux, "public", mux))
+	t.Cleanup(api.Close)
+
+	resp, err := http.Get(api.URL + "/api/search?phrase=linux")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) == 0 {
+		t.Fatal("no spans exported")
+	}
+	ids := make(map[trace.SpanID]bool, len(spans))
+	for _, s := range spans {
+		ids[s.SpanContext.SpanID()] = true
+	}
+
+	traceID := spans[0].SpanContext.TraceID()
+	roots := 0
+	byName := make(map[string]bool, len(spans))
+	for _, s := range spans {
+		if s.SpanContext.TraceID() != traceID {
+			t.Errorf("span %q in trace %s, want %s", s.Name, s.SpanContext.TraceID(), traceID)
+		}
+		if !s.Parent.IsValid() {
+			roots++
+		} else if !ids[s.Parent.SpanID()] {
+			t.Errorf("span %q has parent %s outside the trace", s.Name, s.Parent.SpanID())
+		}
+		byName[s.Name+" "+s.SpanKind.String()] = true
+	}
+	if roots != 1 {
+		t.Errorf("roots = %d, want 1", roots)
+	}
+
+	for _, want := range []string{
+		"GET /api/search server",
+		"search.Search/Find client",
+		"search.Search/Find server",
+		"words.Words/Expand client",
+		"words.Words/Expand server",
+	} {
+		if !byName[want] {
+			t.Errorf("missing span %q, got %v", want, byName)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"log/slog"
 
 	"yadro.com/course/favorites/core"
+	"yadro.com/course/pkg/tracing"
 )
 
 type DB struct {
@@ -20,7 +22,7 @@ type DB struct {
 }
 
 func New(log *slog.Logger, address string) (*DB, error) {
-	db, err := sqlx.Connect("pgx", address)
+	db, err := tracing.ConnectDB(address)
 	if err != nil {
 		log.Error("connection problem", "address", address, "error", err)
 		return nil, err
@@ -28,6 +30,11 @@ func New(log *slog.Logger, address string) (*DB, error) {
 	return &DB{log: log, conn: db}, nil
 }
 
+// Pool - пул соединений, его статистика уходит в метрики
+func (db *DB) Pool() *sql.DB {
+	return db.conn.DB
+}
+
 func (db *DB) Ping(ctx context.Context) error {
 	return db.conn.PingContext(ctx)
 }
@@ -84,6 +91,25 @@ func (db *DB) List(ctx context.Context, userID uint32) ([]core.Favorite, error)
 	return out, nil
 }
 
+func (db *DB) Popular(ctx context.Context, limit int) ([]core.Popularity, error) {
+	const q = `SELECT comic_id, count(*) AS cnt FROM favorites GROUP BY comic_id ORDER BY cnt DESC, comic_id LIMIT $1`
+
+	var rows []struct {
+		ComicID int32 `db:"comic_id"`
+		Count   int   `db:"cnt"`
+	}
+
+	if err := db.conn.SelectContext(ctx, &rows, q, limit); err != nil {
+		return nil, fmt.Errorf("select popular favorites: %w", err)
+	}
+
+	out := make([]core.Popularity, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, core.Popularity{ComicID: r.ComicID, Count: r.Count})
+	}
+	return out, nil
+}
+
 func isUniqueViolation(err error) bool {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
@@ -32,7 +32,7 @@ func (s *Server) Add(ctx context.Context, req *favoritespb.AddRequest) (*emptypb
 		case errors.Is(err, core.ErrAlreadyExists):
 			return nil, status.Error(codes.AlreadyExists, err.Error())
 		default:
-			s.log.Error("add favorite failed", "error", err)
+			s.log.ErrorContext(ctx, "add favorite failed", "error", err)
 			return nil, status.Error(codes.Internal, "internal error")
 		}
 	}
@@ -48,7 +48,7 @@ func (s *Server) Delete(ctx context.Context, req *favoritespb.DeleteRequest) (*e
 		case errors.Is(err, core.ErrNotFound):
 			return nil, status.Error(codes.NotFound, err.Error())
 		default:
-			s.log.Error("delete favorite failed", "error", err)
+			s.log.ErrorContext(ctx, "delete favorite failed", "error", err)
 			return nil, status.Error(codes.Internal, "internal error")
 		}
 	}
@@ -62,7 +62,7 @@ func (s *Server) List(ctx context.Context, req *favoritespb.ListRequest) (*favor
 		case errors.Is(err, core.ErrInvalidArgs):
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		default:
-			s.log.Error("list favorites failed", "error", err)
+			s.log.ErrorContext(ctx, "list favorites failed", "error", err)
 			return nil, status.Error(codes.Internal, "internal error")
 		}
 	}
@@ -77,6 +77,28 @@ func (s *Server) List(ctx context.Context, req *favoritespb.ListRequest) (*favor
 	return resp, nil
 }
 
+func (s *Server) Popular(ctx context.Context, req *favoritespb.PopularRequest) (*favoritespb.PopularResponse, error) {
+	items, err := s.service.Popular(ctx, req.GetLimit())
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrInvalidArgs):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			s.log.ErrorContext(ctx, "popular favorites failed", "error", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	resp := &favoritespb.PopularResponse{Items: make([]*favoritespb.PopularItem, 0, len(items))}
+	for _, it := range items {
+		resp.Items = append(resp.Items, &favoritespb.PopularItem{
+			ComicId: it.ComicID,
+			Count:   uint32(it.Count),
+		})
+	}
+	return resp, nil
+}
+
 func (s *Server) Ping(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
 	if err := s.service.Ping(ctx); err != nil {
 		return nil, status.Error(codes.Unavailable, err.Error())
@@ -2,13 +2,21 @@ package config
 
 import (
 	"github.com/ilyakaznacheev/cleanenv"
+
 	"log"
+	"yadro.com/course/pkg/tracing"
 )
 
 type Config struct {
 	LogLevel  string `yaml:"log_level" env:"LOG_LEVEL" env-default:"DEBUG"`
 	Address   string `yaml:"favorites_address" env:"FAVORITES_ADDRESS" env-default:"localhost:80"`
 	DBAddress string `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
+
+	// MetricsAddress - /metrics для Prometheus на отдельном порту, пусто - выключено
+	MetricsAddress string `yaml:"metrics_address" env:"METRICS_ADDRESS" env-default:":9090"`
+
+	// Tracing - экспорт OTLP трейсов, по умолчанию выключен
+	Tracing tracing.Config `yaml:"tracing"`
 }
 
 func MustLoad(configPath string) Config {
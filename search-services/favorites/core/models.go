@@ -2,6 +2,12 @@ package core
 
 import "time"
 
+// Popularity - сколько пользователей добавили комикс в избранное
+type Popularity struct {
+	ComicID int32
+	Count   int
+}
+
 type Favorite struct {
 	ComicID   int32
 	CreatedAt time.Time
@@ -6,5 +6,6 @@ type DB interface {
 	Add(ctx context.Context, userID uint32, comicID int32) error
 	Delete(ctx context.Context, userID uint32, comicID int32) error
 	List(ctx context.Context, userID uint32) ([]Favorite, error)
+	Popular(ctx context.Context, limit int) ([]Popularity, error)
 	Ping(ctx context.Context) error
 }
@@ -35,6 +35,22 @@ func (s *Service) List(ctx context.Context, userID uint32) ([]Favorite, error) {
 	return s.db.List(ctx, userID)
 }
 
+const (
+	defaultPopularLimit = 100
+	maxPopularLimit     = 1000
+)
+
+// Popular - самые популярные комиксы по числу добавлений в избранное
+func (s *Service) Popular(ctx context.Context, limit uint32) ([]Popularity, error) {
+	if limit == 0 {
+		limit = defaultPopularLimit
+	}
+	if limit > maxPopularLimit {
+		return nil, ErrInvalidArgs
+	}
+	return s.db.Popular(ctx, int(limit))
+}
+
 func (s *Service) Ping(ctx context.Context) error {
 	return s.db.Ping(ctx)
 }
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -17,6 +18,10 @@ import (
 	favgrpc "yadro.com/course/favorites/adapters/grpc"
 	"yadro.com/course/favorites/config"
 	"yadro.com/course/favorites/core"
+	"yadro.com/course/pkg/health"
+	"yadro.com/course/pkg/metrics"
+	"yadro.com/course/pkg/requestid"
+	"yadro.com/course/pkg/tracing"
 	favoritespb "yadro.com/course/proto/favorites"
 )
 
@@ -40,6 +45,19 @@ func main() {
 func run(cfg config.Config, log *slog.Logger) error {
 	log.Info("starting favorites server")
 
+	// tracing: до создания gRPC клиентов и серверов, они берут провайдер при создании
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "favorites")
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("failed to flush traces", "error", err)
+		}
+	}()
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
@@ -62,10 +80,23 @@ func run(cfg config.Config, log *slog.Logger) error {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestid.UnaryServerInterceptor(), metrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(requestid.StreamServerInterceptor(), metrics.StreamServerInterceptor()),
+		tracing.ServerOption(),
+	)
 	favoritespb.RegisterFavoritesServer(s, favgrpc.NewServer(log, favorites))
+	// grpc.health.v1: готовность по Postgres
+	checker := health.Register(s, log, favoritespb.Favorites_ServiceDesc.ServiceName, map[string]health.Check{
+		"postgres": storage.Ping,
+	})
 	reflection.Register(s)
 
+	// metrics: пул БД и /metrics на отдельном порту
+	metrics.RegisterDB(storage.Pool(), "favorites")
+	metrics.Serve(ctx, log, cfg.MetricsAddress)
+	checker.Start(ctx, health.Interval)
+
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down server")
@@ -91,5 +122,6 @@ func mustMakeLogger(levelStr string) *slog.Logger {
 		panic("unknown log level: " + levelStr)
 	}
 	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
-	return slog.New(handler)
+	// request_id из контекста вызова добавляется к записям *Context
+	return slog.New(requestid.NewLogHandler(handler))
 }
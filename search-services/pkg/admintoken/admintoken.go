@@ -0,0 +1,71 @@
+package admintoken
+
+import (
+	"context"
+	"crypto/subtle"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataKey - служебный токен администраторских вызовов в gRPC метаданных.
+// Суперпользователя проверяет api, сервис за ним доверяет только тому, кто знает токен
+const MetadataKey = "x-admin-token"
+
+// Outgoing - токен в метаданные исходящего вызова
+func Outgoing(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, token)
+}
+
+// UnaryServerInterceptor - methods (полные имена) пропускаются только с токеном token.
+// Пустой token на сервере закрывает methods совсем, а не открывает их всем
+func UnaryServerInterceptor(token string, methods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := check(ctx, token, methods, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor - то же для стримов: токен проверяется до первого сообщения
+func StreamServerInterceptor(token string, methods ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := check(ss.Context(), token, methods, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func check(ctx context.Context, token string, methods []string, method string) error {
+	if !slices.Contains(methods, method) {
+		return nil
+	}
+	if token == "" {
+		return status.Error(codes.PermissionDenied, "admin token is not configured")
+	}
+	if !valid(ctx, token) {
+		return status.Error(codes.PermissionDenied, "admin token required")
+	}
+	return nil
+}
+
+func valid(ctx context.Context, token string) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	for _, v := range md.Get(MetadataKey) {
+		if subtle.ConstantTimeCompare([]byte(v), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package admintoken
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const admin = "/test.Fake/Rebuild"
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+
+	// метаданные исходящего вызова становятся входящими на сервере
+	incoming := func(token string) context.Context {
+		ctx := Outgoing(context.Background(), token)
+		md, _ := metadata.FromOutgoingContext(ctx)
+		return metadata.NewIncomingContext(context.Background(), md)
+	}
+
+	tests := []struct {
+		name   string
+		server string
+		ctx    context.Context
+		method string
+		code   codes.Code
+	}{
+		{name: "valid token", server: "secret", ctx: incoming("secret"), method: admin, code: codes.OK},
+		{name: "wrong token", server: "secret", ctx: incoming("guess"), method: admin, code: codes.PermissionDenied},
+		{name: "no token", server: "secret", ctx: context.Background(), method: admin, code: codes.PermissionDenied},
+		{name: "not configured", server: "", ctx: incoming(""), method: admin, code: codes.PermissionDenied},
+		{name: "other method", server: "secret", ctx: context.Background(), method: "/test.Fake/Find", code: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := UnaryServerInterceptor(tt.server, admin)
+			_, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("code = %v, want %v", got, tt.code)
+			}
+		})
+	}
+}
+
+// stream - ServerStream, у которого есть только контекст
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s stream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	const admin = "/test.Fake/Dump"
+	handler := func(any, grpc.ServerStream) error { return nil }
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "secret"))
+
+	interceptor := StreamServerInterceptor("secret", admin)
+	if err := interceptor(nil, stream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: admin}, handler); err != nil {
+		t.Fatalf("valid token: %v", err)
+	}
+	err := interceptor(nil, stream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: admin}, handler)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("no token: code = %v, want PermissionDenied", status.Code(err))
+	}
+}
@@ -0,0 +1,177 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultBreakerFailures - сколько вызовов подряд должно упасть, чтобы breaker открылся
+	DefaultBreakerFailures = 5
+	// DefaultBreakerCooldown - сколько breaker открыт до пробного вызова
+	DefaultBreakerCooldown = 5 * time.Second
+	// DefaultBreakerProbeTimeout - дедлайн пробного вызова, если у ctx нет своего или он дальше
+	DefaultBreakerProbeTimeout = 5 * time.Second
+)
+
+// ErrBreakerOpen - сервис недавно не отвечал, вызов отклонен без сети.
+// Код Unavailable, адаптеры переводят его в свой core.ErrUnavailable
+var ErrBreakerOpen = status.Error(codes.Unavailable, "circuit breaker is open")
+
+// BreakerConfig - нули заменяются значениями по умолчанию; Failures < 0 выключает breaker
+type BreakerConfig struct {
+	Failures     int
+	Cooldown     time.Duration
+	ProbeTimeout time.Duration
+}
+
+type breakerState int
+
+const (
+	closed breakerState = iota
+	open
+	halfOpen
+)
+
+// breaker - один на ClientConn: closed пропускает все; после Failures отказов подряд
+// open отвечает ErrBreakerOpen; через Cooldown half-open пропускает один пробный вызов,
+// его успех закрывает breaker, отказ открывает снова.
+// Пробным может быть только unary вызов с дедлайном - своим в ctx или из service config
+// для идемпотентных методов: Update без дедлайна идет минутами и держал бы breaker
+// в half-open. Дедлайн пробы дополнительно ограничен ProbeTimeout
+type breaker struct {
+	failures   int
+	cooldown   time.Duration
+	probe      time.Duration
+	idempotent map[string]bool
+	now        func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failed   int
+	openedAt time.Time
+}
+
+// newBreaker - idempotent: методы, которым service config ставит дедлайн по умолчанию
+func newBreaker(cfg BreakerConfig, idempotent []string) *breaker {
+	b := &breaker{
+		failures:   cfg.Failures,
+		cooldown:   cfg.Cooldown,
+		probe:      cfg.ProbeTimeout,
+		idempotent: make(map[string]bool, len(idempotent)),
+		now:        time.Now,
+	}
+	if b.failures == 0 {
+		b.failures = DefaultBreakerFailures
+	}
+	if b.cooldown <= 0 {
+		b.cooldown = DefaultBreakerCooldown
+	}
+	if b.probe <= 0 {
+		b.probe = DefaultBreakerProbeTimeout
+	}
+	for _, m := range idempotent {
+		b.idempotent[m] = true
+	}
+	return b
+}
+
+// bounded - вызов закончится не позже своего дедлайна и годится в пробные
+func (b *breaker) bounded(ctx context.Context, method string) bool {
+	_, ok := ctx.Deadline()
+	return ok || b.idempotent[method]
+}
+
+// allow - probe: вызов пробный, его исход решает, закрыть ли breaker
+func (b *breaker) allow(bounded bool) (probe bool, err error) {
+	if b.failures < 0 {
+		return false, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case open:
+		if !bounded || b.now().Sub(b.openedAt) < b.cooldown {
+			return false, ErrBreakerOpen
+		}
+		b.state = halfOpen
+		return true, nil
+	case halfOpen:
+		// пробный вызов уже идет
+		return false, ErrBreakerOpen
+	default:
+		return false, nil
+	}
+}
+
+// done - ctx вызывающего: если он отменен, отказ говорит о клиенте, а не о сервисе.
+// Истекший дедлайн вызывающего считается отказом: API ждет меньше DefaultTimeout,
+// и зависший сервис иначе никогда не открыл бы breaker
+func (b *breaker) done(ctx context.Context, probe bool, err error) {
+	if b.failures < 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		if probe {
+			// проба ничего не показала: openedAt прежний, следующий вызов с дедлайном пробует снова
+			b.state = open
+		}
+		return
+	}
+	if !isFailure(err) {
+		b.state, b.failed = closed, 0
+		return
+	}
+	b.failed++
+	if b.state == halfOpen || b.failed >= b.failures {
+		b.state, b.openedAt = open, b.now()
+	}
+}
+
+// isFailure - сервис недоступен или не успел ответить; ошибки в данных запроса
+// (InvalidArgument, NotFound, ...) говорят, что сервис жив
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *breaker) unary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	probe, err := b.allow(b.bounded(ctx, method))
+	if err != nil {
+		return err
+	}
+	callCtx := ctx
+	if probe {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, b.probe)
+		defer cancel()
+	}
+	err = invoker(callCtx, method, req, reply, cc, opts...)
+	b.done(ctx, probe, err)
+	return err
+}
+
+// stream - учитывается только открытие стрима, обрыв посередине выгрузки breaker не трогает.
+// Стрим не бывает пробным: дедлайн пробы оборвал бы выгрузку
+func (b *breaker) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if _, err := b.allow(false); err != nil {
+		return nil, err
+	}
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	b.done(ctx, false, err)
+	return s, err
+}
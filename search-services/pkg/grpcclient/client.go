@@ -0,0 +1,131 @@
+package grpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"yadro.com/course/pkg/metrics"
+	"yadro.com/course/pkg/requestid"
+	"yadro.com/course/pkg/tracing"
+)
+
+const (
+	// DefaultTimeout - дедлайн идемпотентных вызовов, если у ctx нет своего или он дальше
+	DefaultTimeout = 10 * time.Second
+	// DefaultMaxAttempts - первая попытка и два повтора
+	DefaultMaxAttempts = 3
+)
+
+// Options - политика клиента одного gRPC сервиса
+type Options struct {
+	// Idempotent - полные имена методов (wordspb.Words_Norm_FullMethodName), которые безопасно
+	// повторять и ограничивать дедлайном. Остальные (Update, Drop, Add, Register, стримы)
+	// идут одной попыткой и без дедлайна по умолчанию: повтор может выполнить их дважды
+	Idempotent []string
+
+	Timeout     time.Duration // 0 - DefaultTimeout
+	MaxAttempts int           // 0 - DefaultMaxAttempts
+	Breaker     BreakerConfig
+}
+
+// New - клиент с общими для всех сервисов настройками: request id, метрики, трейсинг,
+// service config с повторами и дедлайнами, circuit breaker и round_robin.
+// Адрес без схемы резолвится через dns, поэтому несколько реплик за одним именем
+// получают запросы по очереди
+func New(address string, opts Options) (*grpc.ClientConn, error) {
+	return dial(address, opts)
+}
+
+// dial - extra для тестов: bufconn и ручной резолвер вместо dns
+func dial(address string, opts Options, extra ...grpc.DialOption) (*grpc.ClientConn, error) {
+	config, err := serviceConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	br := newBreaker(opts.Breaker, opts.Idempotent)
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(config),
+		// breaker последним: метрики видят и быстрые отказы, а повторы внутри
+		// ClientConn он считает одним вызовом
+		grpc.WithChainUnaryInterceptor(requestid.UnaryClientInterceptor(), metrics.UnaryClientInterceptor(), br.unary),
+		grpc.WithChainStreamInterceptor(requestid.StreamClientInterceptor(), metrics.StreamClientInterceptor(), br.stream),
+		tracing.DialOption(),
+	}
+	return grpc.NewClient(address, append(dialOpts, extra...)...)
+}
+
+// serviceConfig - https://github.com/grpc/grpc/blob/master/doc/service_config.md
+type (
+	methodName struct {
+		Service string `json:"service"`
+		Method  string `json:"method"`
+	}
+	retryPolicy struct {
+		MaxAttempts          int      `json:"maxAttempts"`
+		InitialBackoff       string   `json:"initialBackoff"`
+		MaxBackoff           string   `json:"maxBackoff"`
+		BackoffMultiplier    float64  `json:"backoffMultiplier"`
+		RetryableStatusCodes []string `json:"retryableStatusCodes"`
+	}
+	methodConfig struct {
+		Name        []methodName `json:"name"`
+		Timeout     string       `json:"timeout"`
+		RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+	}
+	serviceConfigJSON struct {
+		LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+		MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+		RetryThrottling     map[string]float64    `json:"retryThrottling,omitempty"`
+	}
+)
+
+func serviceConfig(opts Options) (string, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	attempts := opts.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxAttempts
+	}
+
+	cfg := serviceConfigJSON{
+		LoadBalancingConfig: []map[string]struct{}{{"round_robin": {}}},
+		// повторов не больше ~10% от успешных вызовов, чтобы упавший сервис не добивали ретраями
+		RetryThrottling: map[string]float64{"maxTokens": 10, "tokenRatio": 0.1},
+	}
+	if len(opts.Idempotent) > 0 {
+		mc := methodConfig{Timeout: fmt.Sprintf("%gs", timeout.Seconds())}
+		for _, full := range opts.Idempotent {
+			service, method, ok := strings.Cut(strings.TrimPrefix(full, "/"), "/")
+			if !ok || service == "" || method == "" {
+				return "", fmt.Errorf("bad method name %q, want /package.Service/Method", full)
+			}
+			mc.Name = append(mc.Name, methodName{Service: service, Method: method})
+		}
+		if attempts > 1 {
+			// UNAVAILABLE - запрос не дошел до сервиса (рестарт, переключение реплики)
+			mc.RetryPolicy = &retryPolicy{
+				MaxAttempts:          attempts,
+				InitialBackoff:       "0.05s",
+				MaxBackoff:           "1s",
+				BackoffMultiplier:    2,
+				RetryableStatusCodes: []string{"UNAVAILABLE"},
+			}
+		}
+		cfg.MethodConfig = []methodConfig{mc}
+	}
+
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("marshal service config: %w", err)
+	}
+	return string(b), nil
+}
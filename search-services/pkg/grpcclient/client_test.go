@@ -0,0 +1,315 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	getMethod  = "/test.Fake/Get"  // идемпотентный
+	postMethod = "/test.Fake/Post" // не повторяется
+)
+
+// fake - сервис test.Fake без proto: Get и Post на emptypb, поведение задает тест
+type fake struct {
+	name    string
+	calls   atomic.Int32
+	failing atomic.Int32 // столько ближайших вызовов ответят Unavailable
+	delay   time.Duration
+}
+
+func (f *fake) handle(ctx context.Context) (*emptypb.Empty, error) {
+	f.calls.Add(1)
+	if f.failing.Add(-1) >= 0 {
+		return nil, status.Error(codes.Unavailable, "restarting")
+	}
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	// имя реплики в trailer, чтобы проверить round_robin
+	_ = grpc.SetTrailer(ctx, map[string][]string{"replica": {f.name}})
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fake) desc() *grpc.ServiceDesc {
+	handler := func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+		if err := dec(new(emptypb.Empty)); err != nil {
+			return nil, err
+		}
+		return srv.(*fake).handle(ctx)
+	}
+	return &grpc.ServiceDesc{
+		ServiceName: "test.Fake",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "Get", Handler: handler},
+			{MethodName: "Post", Handler: handler},
+		},
+	}
+}
+
+// newTestConn - реплики на bufconn за ручным резолвером, как несколько A-записей в dns
+func newTestConn(t *testing.T, opts Options, replicas ...*fake) *grpc.ClientConn {
+	t.Helper()
+	listeners := make(map[string]*bufconn.Listener, len(replicas))
+	addrs := make([]resolver.Address, 0, len(replicas))
+	for _, f := range replicas {
+		lis := bufconn.Listen(1 << 20)
+		srv := grpc.NewServer()
+		srv.RegisterService(f.desc(), f)
+		go func() { _ = srv.Serve(lis) }()
+		t.Cleanup(srv.Stop)
+		listeners[f.name] = lis
+		addrs = append(addrs, resolver.Address{Addr: f.name})
+	}
+
+	r := manual.NewBuilderWithScheme("fake")
+	r.InitialState(resolver.State{Addresses: addrs})
+	conn, err := dial("fake:///test", opts,
+		grpc.WithResolvers(r),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return listeners[addr].DialContext(ctx)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func call(ctx context.Context, conn *grpc.ClientConn, method string, opts ...grpc.CallOption) error {
+	return conn.Invoke(ctx, method, &emptypb.Empty{}, &emptypb.Empty{}, opts...)
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	f := &fake{name: "a"}
+	conn := newTestConn(t, Options{Idempotent: []string{getMethod}}, f)
+	ctx := context.Background()
+
+	f.failing.Store(2)
+	if err := call(ctx, conn, getMethod); err != nil {
+		t.Fatalf("get after two failures: %v", err)
+	}
+	if got := f.calls.Load(); got != 3 {
+		t.Errorf("get calls = %d, want 3", got)
+	}
+
+	f.calls.Store(0)
+	f.failing.Store(1)
+	if err := call(ctx, conn, postMethod); status.Code(err) != codes.Unavailable {
+		t.Fatalf("post: err = %v, want Unavailable without retry", err)
+	}
+	if got := f.calls.Load(); got != 1 {
+		t.Errorf("post calls = %d, want 1", got)
+	}
+}
+
+func TestDefaultDeadline(t *testing.T) {
+	f := &fake{name: "a", delay: time.Second}
+	conn := newTestConn(t, Options{Idempotent: []string{getMethod}, Timeout: 50 * time.Millisecond}, f)
+
+	start := time.Now()
+	err := call(context.Background(), conn, getMethod)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("call took %s, default deadline is not applied", d)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	f := &fake{name: "a"}
+	conn := newTestConn(t, Options{
+		MaxAttempts: 1,
+		Breaker:     BreakerConfig{Failures: 3, Cooldown: 100 * time.Millisecond},
+	}, f)
+	ctx := context.Background()
+
+	f.failing.Store(100)
+	for range 3 {
+		if err := call(ctx, conn, getMethod); status.Code(err) != codes.Unavailable {
+			t.Fatalf("err = %v, want Unavailable", err)
+		}
+	}
+	// открыт: ошибка сразу, до сервиса вызов не доходит
+	calls := f.calls.Load()
+	if err := call(ctx, conn, getMethod); err != ErrBreakerOpen {
+		t.Fatalf("open breaker: err = %v, want ErrBreakerOpen", err)
+	}
+	if f.calls.Load() != calls {
+		t.Error("open breaker let the call through")
+	}
+
+	// после cooldown вызов без дедлайна пробой не становится, а с дедлайном проходит и закрывает breaker
+	f.failing.Store(0)
+	time.Sleep(150 * time.Millisecond)
+	if err := call(ctx, conn, postMethod); err != ErrBreakerOpen {
+		t.Fatalf("unbounded probe: err = %v, want ErrBreakerOpen", err)
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := call(probeCtx, conn, getMethod); err != nil {
+		t.Fatalf("half-open probe: %v", err)
+	}
+	if err := call(ctx, conn, postMethod); err != nil {
+		t.Fatalf("closed again: %v", err)
+	}
+}
+
+func TestBreakerIgnoresClientErrors(t *testing.T) {
+	b := newBreaker(BreakerConfig{Failures: 1}, nil)
+	ctx := context.Background()
+	b.done(ctx, false, status.Error(codes.InvalidArgument, "bad phrase"))
+	b.done(ctx, false, status.Error(codes.NotFound, "no comic"))
+	if _, err := b.allow(false); err != nil {
+		t.Fatalf("breaker opened on client errors: %v", err)
+	}
+}
+
+func TestBreakerIgnoresCallerCancel(t *testing.T) {
+	f := &fake{name: "a", delay: time.Second}
+	conn := newTestConn(t, Options{
+		MaxAttempts: 1,
+		Breaker:     BreakerConfig{Failures: 1, Cooldown: time.Hour},
+	}, f)
+
+	// вызывающий ушел: сервис жив, breaker закрыт
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if err := call(ctx, conn, postMethod); status.Code(err) != codes.Canceled {
+		t.Fatalf("err = %v, want Canceled", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := call(ctx, conn, postMethod); err == ErrBreakerOpen {
+		t.Fatal("breaker opened on caller cancel")
+	}
+}
+
+func TestBreakerCallerDeadline(t *testing.T) {
+	// сервис завис, а вызывающий (как API с API_TIMEOUT) ждет меньше DefaultTimeout
+	f := &fake{name: "a", delay: time.Hour}
+	conn := newTestConn(t, Options{
+		Idempotent:  []string{getMethod},
+		MaxAttempts: 1,
+		Breaker:     BreakerConfig{Failures: 2, Cooldown: time.Hour},
+	}, f)
+
+	for range 2 {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		err := call(ctx, conn, getMethod)
+		cancel()
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Fatalf("err = %v, want DeadlineExceeded", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := call(ctx, conn, getMethod); err != ErrBreakerOpen {
+		t.Fatalf("err = %v, want ErrBreakerOpen", err)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("open breaker answered in %s, want fast", d)
+	}
+}
+
+func TestBreakerProbeCallerDeadline(t *testing.T) {
+	b := newBreaker(BreakerConfig{Failures: 1, Cooldown: 50 * time.Millisecond}, nil)
+	b.done(context.Background(), false, status.Error(codes.Unavailable, "down"))
+	time.Sleep(60 * time.Millisecond)
+
+	// проба истекла по дедлайну вызывающего - это отказ, breaker снова открыт
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if err := b.unary(ctx, getMethod, nil, nil, nil, invoker); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("probe err = %v, want DeadlineExceeded", err)
+	}
+	if _, err := b.allow(true); err != ErrBreakerOpen {
+		t.Fatalf("after expired probe: err = %v, want ErrBreakerOpen", err)
+	}
+}
+
+func TestBreakerProbeTimeout(t *testing.T) {
+	b := newBreaker(BreakerConfig{Failures: 1, Cooldown: 10 * time.Millisecond, ProbeTimeout: 20 * time.Millisecond}, []string{getMethod})
+	b.done(context.Background(), false, status.Error(codes.Unavailable, "down"))
+	time.Sleep(20 * time.Millisecond)
+
+	// идемпотентный метод без дедлайна в ctx - проба с дедлайном ProbeTimeout;
+	// сервис висит дольше, проба истекает и снова открывает breaker
+	var deadline time.Duration
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		d, _ := ctx.Deadline()
+		deadline = time.Until(d)
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if err := b.unary(context.Background(), getMethod, nil, nil, nil, invoker); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("probe err = %v, want DeadlineExceeded", err)
+	}
+	if deadline <= 0 || deadline > 20*time.Millisecond {
+		t.Fatalf("probe deadline = %s, want ProbeTimeout", deadline)
+	}
+	if _, err := b.allow(true); err != ErrBreakerOpen {
+		t.Fatalf("after failed probe: err = %v, want ErrBreakerOpen", err)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	a, b := &fake{name: "a"}, &fake{name: "b"}
+	conn := newTestConn(t, Options{}, a, b)
+	ctx := context.Background()
+
+	// round_robin подключается к репликам не сразу, первые вызовы могут уйти в одну
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && (a.calls.Load() == 0 || b.calls.Load() == 0) {
+		if err := call(ctx, conn, getMethod); err != nil {
+			t.Fatalf("call: %v", err)
+		}
+	}
+	if a.calls.Load() == 0 || b.calls.Load() == 0 {
+		t.Fatalf("calls a=%d b=%d, want both replicas used", a.calls.Load(), b.calls.Load())
+	}
+
+	// дальше строго по очереди
+	var prev string
+	for range 6 {
+		var trailer metadata.MD
+		if err := call(ctx, conn, getMethod, grpc.Trailer(&trailer)); err != nil {
+			t.Fatalf("call: %v", err)
+		}
+		got := trailer.Get("replica")[0]
+		if got == prev {
+			t.Fatalf("replica %s twice in a row", got)
+		}
+		prev = got
+	}
+}
+
+func TestServiceConfigBadMethod(t *testing.T) {
+	if _, err := New("localhost:0", Options{Idempotent: []string{"Norm"}}); err == nil {
+		t.Fatal("want error for method without service")
+	}
+}
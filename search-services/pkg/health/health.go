@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Interval - как часто пересчитывается статус сервиса
+	Interval = 5 * time.Second
+	// checkTimeout - на одну проверку зависимости
+	checkTimeout = 2 * time.Second
+)
+
+// Check - проверка зависимости сервиса (Postgres, NATS, индекс), nil - доступна
+type Check func(ctx context.Context) error
+
+// Checker - grpc.health.v1 на сервере. Статус общий ("") и по имени сервиса из proto
+// (например search.Search) одинаковый: SERVING, только если прошли все проверки
+type Checker struct {
+	log     *slog.Logger
+	server  *health.Server
+	service string
+	checks  map[string]Check
+
+	mu   sync.Mutex
+	last string // статус и упавшие проверки прошлого прохода, для лога
+}
+
+// Register - регистрирует health сервер в s; до первого Start статус NOT_SERVING,
+// без проверок (words) - сразу SERVING
+func Register(s *grpc.Server, log *slog.Logger, service string, checks map[string]Check) *Checker {
+	c := &Checker{
+		log:     log,
+		server:  health.NewServer(),
+		service: service,
+		checks:  checks,
+	}
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if len(checks) == 0 {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	c.set(status)
+	healthpb.RegisterHealthServer(s, c.server)
+	return c
+}
+
+// Start - проверки сразу и далее каждые interval; по отмене ctx статус NOT_SERVING,
+// чтобы клиенты ушли с сервиса до GracefulStop
+func (c *Checker) Start(ctx context.Context, interval time.Duration) {
+	c.Update(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				c.server.Shutdown()
+				return
+			case <-ticker.C:
+				c.Update(ctx)
+			}
+		}
+	}()
+}
+
+// Update - один проход по проверкам, параллельно; смена статуса пишется в лог
+func (c *Checker) Update(ctx context.Context) {
+	if len(c.checks) == 0 {
+		return
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed []string
+	)
+	for name, check := range c.checks {
+		wg.Go(func() {
+			cctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+			if err := check(cctx); err != nil {
+				mu.Lock()
+				failed = append(failed, name)
+				mu.Unlock()
+				c.log.DebugContext(ctx, "health check failed", "check", name, "error", err)
+			}
+		})
+	}
+	wg.Wait()
+	sort.Strings(failed)
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if len(failed) > 0 {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	c.mu.Lock()
+	last := fmt.Sprint(status, failed)
+	changed := c.last != last
+	c.last = last
+	c.mu.Unlock()
+
+	if changed {
+		if len(failed) > 0 {
+			c.log.WarnContext(ctx, "service is not ready", "service", c.service, "failed", failed)
+		} else {
+			c.log.InfoContext(ctx, "service is ready", "service", c.service)
+		}
+	}
+	c.set(status)
+}
+
+func (c *Checker) set(status healthpb.HealthCheckResponse_ServingStatus) {
+	c.server.SetServingStatus("", status)
+	c.server.SetServingStatus(c.service, status)
+}
+
+// Probe - клиентская сторона: nil, если сервис за conn отвечает SERVING.
+// Ответ NOT_SERVING тоже ошибка с кодом Unavailable, как и недоступный сервис
+func Probe(ctx context.Context, conn grpc.ClientConnInterface) error {
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return status.Errorf(codes.Unavailable, "service is %s", resp.GetStatus())
+	}
+	return nil
+}
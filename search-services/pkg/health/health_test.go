@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestChecker(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var dbDown atomic.Bool
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	checker := Register(srv, log, "test.Service", map[string]Check{
+		"postgres": func(context.Context) error {
+			if dbDown.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+		"nats": func(context.Context) error { return nil },
+	})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	ctx := context.Background()
+
+	// до первой проверки сервис не готов
+	if err := Probe(ctx, conn); status.Code(err) != codes.Unavailable {
+		t.Fatalf("before checks: err = %v, want Unavailable", err)
+	}
+
+	checker.Update(ctx)
+	if err := Probe(ctx, conn); err != nil {
+		t.Fatalf("all checks ok: %v", err)
+	}
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "test.Service"})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("named service: %v %v", resp.GetStatus(), err)
+	}
+
+	dbDown.Store(true)
+	checker.Update(ctx)
+	if err := Probe(ctx, conn); status.Code(err) != codes.Unavailable {
+		t.Fatalf("postgres down: err = %v, want Unavailable", err)
+	}
+}
+
+func TestRegisterWithoutChecks(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	Register(srv, slog.New(slog.NewTextHandler(io.Discard, nil)), "test.Service", nil)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	if err := Probe(context.Background(), conn); err != nil {
+		t.Fatalf("no checks: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Svc/Unary"}
+
+	_, _ = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+	_, _ = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "no comic")
+	})
+
+	if got := testutil.ToFloat64(serverHandled.WithLabelValues("/test.Svc/Unary", "OK")); got != 1 {
+		t.Errorf("OK = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(serverHandled.WithLabelValues("/test.Svc/Unary", "NotFound")); got != 1 {
+		t.Errorf("NotFound = %v, want 1", got)
+	}
+}
+
+// fakeStream - отдает n сообщений, затем err
+type fakeStream struct {
+	grpc.ClientStream
+	n   int
+	err error
+}
+
+func (s *fakeStream) RecvMsg(any) error {
+	if s.n == 0 {
+		return s.err
+	}
+	s.n--
+	return nil
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	tests := []struct {
+		method string
+		err    error
+		code   string
+	}{
+		{"/test.Svc/StreamOK", io.EOF, "OK"},
+		{"/test.Svc/StreamFail", status.Error(codes.Unavailable, "gone"), "Unavailable"},
+	}
+	for _, tt := range tests {
+		streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeStream{n: 2, err: tt.err}, nil
+		}
+		cs, err := StreamClientInterceptor()(context.Background(), &grpc.StreamDesc{}, nil, tt.method, streamer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for cs.RecvMsg(nil) == nil {
+		}
+		// повторное чтение после конца не считается вторым вызовом
+		_ = cs.RecvMsg(nil)
+
+		if got := testutil.ToFloat64(clientHandled.WithLabelValues(tt.method, tt.code)); got != 1 {
+			t.Errorf("%s %s = %v, want 1", tt.method, tt.code, got)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package requestid
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogKey - атрибут с id запроса в логах всех сервисов
+const LogKey = "request_id"
+
+// logHandler - добавляет request_id к записям, залогированным с контекстом запроса
+// (log.InfoContext и т.п.); записи без контекста или без id не меняются
+type logHandler struct {
+	slog.Handler
+}
+
+func NewLogHandler(h slog.Handler) slog.Handler {
+	return logHandler{Handler: h}
+}
+
+func (h logHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		rec.AddAttrs(slog.String(LogKey, id))
+	}
+	return h.Handler.Handle(ctx, rec)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,100 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"req-1", true},
+		{New(), true},
+		{"", false},
+		{"with space", false},
+		{"line\nbreak", false},
+		{strings.Repeat("a", maxLen+1), false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	// клиент: id из контекста уходит в метаданные
+	var sent metadata.MD
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	ctx := WithID(context.Background(), "req-1")
+	if err := UnaryClientInterceptor()(ctx, "/svc/M", nil, nil, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if got := sent.Get(MetadataKey); len(got) != 1 || got[0] != "req-1" {
+		t.Fatalf("outgoing metadata = %v", sent)
+	}
+
+	// сервер: id из метаданных попадает в контекст хендлера
+	var got string
+	handler := func(ctx context.Context, _ any) (any, error) {
+		got = FromContext(ctx)
+		return nil, nil
+	}
+	server := UnaryServerInterceptor()
+	if _, err := server(metadata.NewIncomingContext(context.Background(), sent), nil, nil, handler); err != nil {
+		t.Fatal(err)
+	}
+	if got != "req-1" {
+		t.Fatalf("server id = %q, want req-1", got)
+	}
+
+	// без метаданных - сгенерированный id
+	if _, err := server(context.Background(), nil, nil, handler); err != nil {
+		t.Fatal(err)
+	}
+	if !Valid(got) || got == "req-1" {
+		t.Fatalf("generated id = %q", got)
+	}
+}
+
+func TestUnaryClientInterceptorWithoutID(t *testing.T) {
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(MetadataKey)) > 0 {
+			t.Fatalf("unexpected metadata %v", md)
+		}
+		return nil
+	}
+	if err := UnaryClientInterceptor()(context.Background(), "/svc/M", nil, nil, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewLogHandler(slog.NewTextHandler(&buf, nil))).With("service", "test")
+
+	log.InfoContext(WithID(context.Background(), "req-1"), "with id")
+	log.Info("without id")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+	if !strings.Contains(lines[0], "request_id=req-1") || !strings.Contains(lines[0], "service=test") {
+		t.Fatalf("line with id = %q", lines[0])
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Fatalf("line without id = %q", lines[1])
+	}
+}
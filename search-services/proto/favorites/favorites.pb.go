@@ -266,6 +266,146 @@ func (x *ListRequest) GetUserId() uint32 {
 	return 0
 }
 
+type PopularRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         uint32                 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"` // 0 - 100
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PopularRequest) Reset() {
+	*x = PopularRequest{}
+	mi := &file_favorites_favorites_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PopularRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PopularRequest) ProtoMessage() {}
+
+func (x *PopularRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_favorites_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PopularRequest.ProtoReflect.Descriptor instead.
+func (*PopularRequest) Descriptor() ([]byte, []int) {
+	return file_favorites_favorites_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PopularRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type PopularItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ComicId       int32                  `protobuf:"varint,1,opt,name=comic_id,json=comicId,proto3" json:"comic_id,omitempty"`
+	Count         uint32                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"` // сколько пользователей добавили в избранное
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PopularItem) Reset() {
+	*x = PopularItem{}
+	mi := &file_favorites_favorites_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PopularItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PopularItem) ProtoMessage() {}
+
+func (x *PopularItem) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_favorites_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PopularItem.ProtoReflect.Descriptor instead.
+func (*PopularItem) Descriptor() ([]byte, []int) {
+	return file_favorites_favorites_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PopularItem) GetComicId() int32 {
+	if x != nil {
+		return x.ComicId
+	}
+	return 0
+}
+
+func (x *PopularItem) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type PopularResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*PopularItem         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PopularResponse) Reset() {
+	*x = PopularResponse{}
+	mi := &file_favorites_favorites_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PopularResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PopularResponse) ProtoMessage() {}
+
+func (x *PopularResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_favorites_favorites_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PopularResponse.ProtoReflect.Descriptor instead.
+func (*PopularResponse) Descriptor() ([]byte, []int) {
+	return file_favorites_favorites_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PopularResponse) GetItems() []*PopularItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
 var File_favorites_favorites_proto protoreflect.FileDescriptor
 
 const file_favorites_favorites_proto_rawDesc = "" +
@@ -284,12 +424,20 @@ const file_favorites_favorites_proto_rawDesc = "" +
 	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x19\n" +
 	"\bcomic_id\x18\x02 \x01(\x05R\acomicId\"&\n" +
 	"\vListRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\rR\x06userId2\xee\x01\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\"&\n" +
+	"\x0ePopularRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\rR\x05limit\">\n" +
+	"\vPopularItem\x12\x19\n" +
+	"\bcomic_id\x18\x01 \x01(\x05R\acomicId\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\rR\x05count\"?\n" +
+	"\x0fPopularResponse\x12,\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.favorites.PopularItemR\x05items2\xb0\x02\n" +
 	"\tFavorites\x126\n" +
 	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x124\n" +
 	"\x03Add\x12\x15.favorites.AddRequest\x1a\x16.google.protobuf.Empty\x12:\n" +
 	"\x06Delete\x12\x18.favorites.DeleteRequest\x1a\x16.google.protobuf.Empty\x127\n" +
-	"\x04List\x12\x16.favorites.ListRequest\x1a\x17.favorites.ListResponseB\"Z yadro.com/course/proto/favoritesb\x06proto3"
+	"\x04List\x12\x16.favorites.ListRequest\x1a\x17.favorites.ListResponse\x12@\n" +
+	"\aPopular\x12\x19.favorites.PopularRequest\x1a\x1a.favorites.PopularResponseB\"Z yadro.com/course/proto/favoritesb\x06proto3"
 
 var (
 	file_favorites_favorites_proto_rawDescOnce sync.Once
@@ -303,30 +451,36 @@ func file_favorites_favorites_proto_rawDescGZIP() []byte {
 	return file_favorites_favorites_proto_rawDescData
 }
 
-var file_favorites_favorites_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_favorites_favorites_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_favorites_favorites_proto_goTypes = []any{
-	(*FavoriteItem)(nil),  // 0: favorites.FavoriteItem
-	(*ListResponse)(nil),  // 1: favorites.ListResponse
-	(*AddRequest)(nil),    // 2: favorites.AddRequest
-	(*DeleteRequest)(nil), // 3: favorites.DeleteRequest
-	(*ListRequest)(nil),   // 4: favorites.ListRequest
-	(*emptypb.Empty)(nil), // 5: google.protobuf.Empty
+	(*FavoriteItem)(nil),    // 0: favorites.FavoriteItem
+	(*ListResponse)(nil),    // 1: favorites.ListResponse
+	(*AddRequest)(nil),      // 2: favorites.AddRequest
+	(*DeleteRequest)(nil),   // 3: favorites.DeleteRequest
+	(*ListRequest)(nil),     // 4: favorites.ListRequest
+	(*PopularRequest)(nil),  // 5: favorites.PopularRequest
+	(*PopularItem)(nil),     // 6: favorites.PopularItem
+	(*PopularResponse)(nil), // 7: favorites.PopularResponse
+	(*emptypb.Empty)(nil),   // 8: google.protobuf.Empty
 }
 var file_favorites_favorites_proto_depIdxs = []int32{
 	0, // 0: favorites.ListResponse.items:type_name -> favorites.FavoriteItem
-	5, // 1: favorites.Favorites.Ping:input_type -> google.protobuf.Empty
-	2, // 2: favorites.Favorites.Add:input_type -> favorites.AddRequest
-	3, // 3: favorites.Favorites.Delete:input_type -> favorites.DeleteRequest
-	4, // 4: favorites.Favorites.List:input_type -> favorites.ListRequest
-	5, // 5: favorites.Favorites.Ping:output_type -> google.protobuf.Empty
-	5, // 6: favorites.Favorites.Add:output_type -> google.protobuf.Empty
-	5, // 7: favorites.Favorites.Delete:output_type -> google.protobuf.Empty
-	1, // 8: favorites.Favorites.List:output_type -> favorites.ListResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	6, // 1: favorites.PopularResponse.items:type_name -> favorites.PopularItem
+	8, // 2: favorites.Favorites.Ping:input_type -> google.protobuf.Empty
+	2, // 3: favorites.Favorites.Add:input_type -> favorites.AddRequest
+	3, // 4: favorites.Favorites.Delete:input_type -> favorites.DeleteRequest
+	4, // 5: favorites.Favorites.List:input_type -> favorites.ListRequest
+	5, // 6: favorites.Favorites.Popular:input_type -> favorites.PopularRequest
+	8, // 7: favorites.Favorites.Ping:output_type -> google.protobuf.Empty
+	8, // 8: favorites.Favorites.Add:output_type -> google.protobuf.Empty
+	8, // 9: favorites.Favorites.Delete:output_type -> google.protobuf.Empty
+	1, // 10: favorites.Favorites.List:output_type -> favorites.ListResponse
+	7, // 11: favorites.Favorites.Popular:output_type -> favorites.PopularResponse
+	7, // [7:12] is the sub-list for method output_type
+	2, // [2:7] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_favorites_favorites_proto_init() }
@@ -340,7 +494,7 @@ func file_favorites_favorites_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_favorites_favorites_proto_rawDesc), len(file_favorites_favorites_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,10 +20,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Favorites_Ping_FullMethodName   = "/favorites.Favorites/Ping"
-	Favorites_Add_FullMethodName    = "/favorites.Favorites/Add"
-	Favorites_Delete_FullMethodName = "/favorites.Favorites/Delete"
-	Favorites_List_FullMethodName   = "/favorites.Favorites/List"
+	Favorites_Ping_FullMethodName    = "/favorites.Favorites/Ping"
+	Favorites_Add_FullMethodName     = "/favorites.Favorites/Add"
+	Favorites_Delete_FullMethodName  = "/favorites.Favorites/Delete"
+	Favorites_List_FullMethodName    = "/favorites.Favorites/List"
+	Favorites_Popular_FullMethodName = "/favorites.Favorites/Popular"
 )
 
 // FavoritesClient is the client API for Favorites service.
@@ -34,6 +35,7 @@ type FavoritesClient interface {
 	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	Popular(ctx context.Context, in *PopularRequest, opts ...grpc.CallOption) (*PopularResponse, error)
 }
 
 type favoritesClient struct {
@@ -84,6 +86,16 @@ func (c *favoritesClient) List(ctx context.Context, in *ListRequest, opts ...grp
 	return out, nil
 }
 
+func (c *favoritesClient) Popular(ctx context.Context, in *PopularRequest, opts ...grpc.CallOption) (*PopularResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PopularResponse)
+	err := c.cc.Invoke(ctx, Favorites_Popular_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FavoritesServer is the server API for Favorites service.
 // All implementations must embed UnimplementedFavoritesServer
 // for forward compatibility.
@@ -92,6 +104,7 @@ type FavoritesServer interface {
 	Add(context.Context, *AddRequest) (*emptypb.Empty, error)
 	Delete(context.Context, *DeleteRequest) (*emptypb.Empty, error)
 	List(context.Context, *ListRequest) (*ListResponse, error)
+	Popular(context.Context, *PopularRequest) (*PopularResponse, error)
 	mustEmbedUnimplementedFavoritesServer()
 }
 
@@ -114,6 +127,9 @@ func (UnimplementedFavoritesServer) Delete(context.Context, *DeleteRequest) (*em
 func (UnimplementedFavoritesServer) List(context.Context, *ListRequest) (*ListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
 }
+func (UnimplementedFavoritesServer) Popular(context.Context, *PopularRequest) (*PopularResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Popular not implemented")
+}
 func (UnimplementedFavoritesServer) mustEmbedUnimplementedFavoritesServer() {}
 func (UnimplementedFavoritesServer) testEmbeddedByValue()                   {}
 
@@ -207,6 +223,24 @@ func _Favorites_List_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Favorites_Popular_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PopularRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FavoritesServer).Popular(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Favorites_Popular_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FavoritesServer).Popular(ctx, req.(*PopularRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Favorites_ServiceDesc is the grpc.ServiceDesc for Favorites service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -230,6 +264,10 @@ var Favorites_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "List",
 			Handler:    _Favorites_List_Handler,
 		},
+		{
+			MethodName: "Popular",
+			Handler:    _Favorites_Popular_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "favorites/favorites.proto",
@@ -22,29 +22,1419 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// SearchFilters - пустые поля означают отсутствие ограничения
+type SearchFilters struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PublishedFrom string                 `protobuf:"bytes,1,opt,name=published_from,json=publishedFrom,proto3" json:"published_from,omitempty"` // YYYY-MM-DD, включительно
+	PublishedTo   string                 `protobuf:"bytes,2,opt,name=published_to,json=publishedTo,proto3" json:"published_to,omitempty"`       // YYYY-MM-DD, включительно
+	IdFrom        uint32                 `protobuf:"varint,3,opt,name=id_from,json=idFrom,proto3" json:"id_from,omitempty"`
+	IdTo          uint32                 `protobuf:"varint,4,opt,name=id_to,json=idTo,proto3" json:"id_to,omitempty"`
+	HasTranscript *bool                  `protobuf:"varint,5,opt,name=has_transcript,json=hasTranscript,proto3,oneof" json:"has_transcript,omitempty"`
+	// only_ids - искать только среди ids (избранное пользователя)
+	OnlyIds       bool     `protobuf:"varint,6,opt,name=only_ids,json=onlyIds,proto3" json:"only_ids,omitempty"`
+	Ids           []uint32 `protobuf:"varint,7,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchFilters) Reset() {
+	*x = SearchFilters{}
+	mi := &file_search_search_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFilters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFilters) ProtoMessage() {}
+
+func (x *SearchFilters) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFilters.ProtoReflect.Descriptor instead.
+func (*SearchFilters) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchFilters) GetPublishedFrom() string {
+	if x != nil {
+		return x.PublishedFrom
+	}
+	return ""
+}
+
+func (x *SearchFilters) GetPublishedTo() string {
+	if x != nil {
+		return x.PublishedTo
+	}
+	return ""
+}
+
+func (x *SearchFilters) GetIdFrom() uint32 {
+	if x != nil {
+		return x.IdFrom
+	}
+	return 0
+}
+
+func (x *SearchFilters) GetIdTo() uint32 {
+	if x != nil {
+		return x.IdTo
+	}
+	return 0
+}
+
+func (x *SearchFilters) GetHasTranscript() bool {
+	if x != nil && x.HasTranscript != nil {
+		return *x.HasTranscript
+	}
+	return false
+}
+
+func (x *SearchFilters) GetOnlyIds() bool {
+	if x != nil {
+		return x.OnlyIds
+	}
+	return false
+}
+
+func (x *SearchFilters) GetIds() []uint32 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
 type SearchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
-	Limit         uint32                 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	Limit         uint32                 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Explain       bool                   `protobuf:"varint,3,opt,name=explain,proto3" json:"explain,omitempty"`
+	Profile       string                 `protobuf:"bytes,4,opt,name=profile,proto3" json:"profile,omitempty"`
+	Filters       *SearchFilters         `protobuf:"bytes,5,opt,name=filters,proto3" json:"filters,omitempty"`
+	UserId        uint32                 `protobuf:"varint,6,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // для журнала запросов, 0 - анонимный
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_search_search_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchRequest) GetPhrase() string {
+	if x != nil {
+		return x.Phrase
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetExplain() bool {
+	if x != nil {
+		return x.Explain
+	}
+	return false
+}
+
+func (x *SearchRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetFilters() *SearchFilters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetUserId() uint32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type FacetCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Count         uint32                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FacetCount) Reset() {
+	*x = FacetCount{}
+	mi := &file_search_search_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FacetCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FacetCount) ProtoMessage() {}
+
+func (x *FacetCount) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FacetCount.ProtoReflect.Descriptor instead.
+func (*FacetCount) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FacetCount) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *FacetCount) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type ScoreComponent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value         float64                `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	Weight        float64                `protobuf:"fixed64,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	Contribution  float64                `protobuf:"fixed64,4,opt,name=contribution,proto3" json:"contribution,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoreComponent) Reset() {
+	*x = ScoreComponent{}
+	mi := &file_search_search_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoreComponent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreComponent) ProtoMessage() {}
+
+func (x *ScoreComponent) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreComponent.ProtoReflect.Descriptor instead.
+func (*ScoreComponent) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScoreComponent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScoreComponent) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *ScoreComponent) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *ScoreComponent) GetContribution() float64 {
+	if x != nil {
+		return x.Contribution
+	}
+	return 0
+}
+
+type TermExplain struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Fields        []string               `protobuf:"bytes,2,rep,name=fields,proto3" json:"fields,omitempty"`
+	TitleTf       uint32                 `protobuf:"varint,3,opt,name=title_tf,json=titleTf,proto3" json:"title_tf,omitempty"`
+	AltTf         uint32                 `protobuf:"varint,4,opt,name=alt_tf,json=altTf,proto3" json:"alt_tf,omitempty"`
+	WordsTf       uint32                 `protobuf:"varint,5,opt,name=words_tf,json=wordsTf,proto3" json:"words_tf,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TermExplain) Reset() {
+	*x = TermExplain{}
+	mi := &file_search_search_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TermExplain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TermExplain) ProtoMessage() {}
+
+func (x *TermExplain) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TermExplain.ProtoReflect.Descriptor instead.
+func (*TermExplain) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TermExplain) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *TermExplain) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *TermExplain) GetTitleTf() uint32 {
+	if x != nil {
+		return x.TitleTf
+	}
+	return 0
+}
+
+func (x *TermExplain) GetAltTf() uint32 {
+	if x != nil {
+		return x.AltTf
+	}
+	return 0
+}
+
+func (x *TermExplain) GetWordsTf() uint32 {
+	if x != nil {
+		return x.WordsTf
+	}
+	return 0
+}
+
+type Explanation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Function      string                 `protobuf:"bytes,1,opt,name=function,proto3" json:"function,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Components    []*ScoreComponent      `protobuf:"bytes,3,rep,name=components,proto3" json:"components,omitempty"`
+	Terms         []*TermExplain         `protobuf:"bytes,4,rep,name=terms,proto3" json:"terms,omitempty"`
+	MatchedFields []string               `protobuf:"bytes,5,rep,name=matched_fields,json=matchedFields,proto3" json:"matched_fields,omitempty"`
+	Profile       string                 `protobuf:"bytes,6,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Explanation) Reset() {
+	*x = Explanation{}
+	mi := &file_search_search_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Explanation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Explanation) ProtoMessage() {}
+
+func (x *Explanation) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Explanation.ProtoReflect.Descriptor instead.
+func (*Explanation) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Explanation) GetFunction() string {
+	if x != nil {
+		return x.Function
+	}
+	return ""
+}
+
+func (x *Explanation) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Explanation) GetComponents() []*ScoreComponent {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+func (x *Explanation) GetTerms() []*TermExplain {
+	if x != nil {
+		return x.Terms
+	}
+	return nil
+}
+
+func (x *Explanation) GetMatchedFields() []string {
+	if x != nil {
+		return x.MatchedFields
+	}
+	return nil
+}
+
+func (x *Explanation) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+type ComicReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Explain       *Explanation           `protobuf:"bytes,3,opt,name=explain,proto3" json:"explain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComicReply) Reset() {
+	*x = ComicReply{}
+	mi := &file_search_search_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComicReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComicReply) ProtoMessage() {}
+
+func (x *ComicReply) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComicReply.ProtoReflect.Descriptor instead.
+func (*ComicReply) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ComicReply) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ComicReply) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ComicReply) GetExplain() *Explanation {
+	if x != nil {
+		return x.Explain
+	}
+	return nil
+}
+
+type SearchReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Comics        []*ComicReply          `protobuf:"bytes,1,rep,name=comics,proto3" json:"comics,omitempty"`
+	Total         uint32                 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Tokens        []string               `protobuf:"bytes,3,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	Years         []*FacetCount          `protobuf:"bytes,4,rep,name=years,proto3" json:"years,omitempty"`
+	Sources       []*FacetCount          `protobuf:"bytes,5,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchReply) Reset() {
+	*x = SearchReply{}
+	mi := &file_search_search_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchReply) ProtoMessage() {}
+
+func (x *SearchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchReply.ProtoReflect.Descriptor instead.
+func (*SearchReply) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SearchReply) GetComics() []*ComicReply {
+	if x != nil {
+		return x.Comics
+	}
+	return nil
+}
+
+func (x *SearchReply) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchReply) GetTokens() []string {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *SearchReply) GetYears() []*FacetCount {
+	if x != nil {
+		return x.Years
+	}
+	return nil
+}
+
+func (x *SearchReply) GetSources() []*FacetCount {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type ComicByIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComicByIDRequest) Reset() {
+	*x = ComicByIDRequest{}
+	mi := &file_search_search_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComicByIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComicByIDRequest) ProtoMessage() {}
+
+func (x *ComicByIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComicByIDRequest.ProtoReflect.Descriptor instead.
+func (*ComicByIDRequest) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ComicByIDRequest) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ComicsPageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          uint32                 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage       uint32                 `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComicsPageRequest) Reset() {
+	*x = ComicsPageRequest{}
+	mi := &file_search_search_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComicsPageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComicsPageRequest) ProtoMessage() {}
+
+func (x *ComicsPageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComicsPageRequest.ProtoReflect.Descriptor instead.
+func (*ComicsPageRequest) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ComicsPageRequest) GetPage() uint32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ComicsPageRequest) GetPerPage() uint32 {
+	if x != nil {
+		return x.PerPage
+	}
+	return 0
+}
+
+type IndexStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Top           uint32                 `protobuf:"varint,1,opt,name=top,proto3" json:"top,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexStatsRequest) Reset() {
+	*x = IndexStatsRequest{}
+	mi := &file_search_search_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexStatsRequest) ProtoMessage() {}
+
+func (x *IndexStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexStatsRequest.ProtoReflect.Descriptor instead.
+func (*IndexStatsRequest) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *IndexStatsRequest) GetTop() uint32 {
+	if x != nil {
+		return x.Top
+	}
+	return 0
+}
+
+type TermStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          string                 `protobuf:"bytes,1,opt,name=term,proto3" json:"term,omitempty"`
+	Docs          uint32                 `protobuf:"varint,2,opt,name=docs,proto3" json:"docs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TermStat) Reset() {
+	*x = TermStat{}
+	mi := &file_search_search_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TermStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TermStat) ProtoMessage() {}
+
+func (x *TermStat) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TermStat.ProtoReflect.Descriptor instead.
+func (*TermStat) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TermStat) GetTerm() string {
+	if x != nil {
+		return x.Term
+	}
+	return ""
+}
+
+func (x *TermStat) GetDocs() uint32 {
+	if x != nil {
+		return x.Docs
+	}
+	return 0
+}
+
+type IndexStatsReply struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Generation             uint64                 `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	Terms                  uint32                 `protobuf:"varint,2,opt,name=terms,proto3" json:"terms,omitempty"`
+	Docs                   uint32                 `protobuf:"varint,3,opt,name=docs,proto3" json:"docs,omitempty"`
+	Postings               uint64                 `protobuf:"varint,4,opt,name=postings,proto3" json:"postings,omitempty"`
+	MaxPosting             uint32                 `protobuf:"varint,5,opt,name=max_posting,json=maxPosting,proto3" json:"max_posting,omitempty"`
+	AvgPosting             float64                `protobuf:"fixed64,6,opt,name=avg_posting,json=avgPosting,proto3" json:"avg_posting,omitempty"`
+	MemoryBytes            uint64                 `protobuf:"varint,7,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	BuiltAtUnix            int64                  `protobuf:"varint,8,opt,name=built_at_unix,json=builtAtUnix,proto3" json:"built_at_unix,omitempty"`
+	BuildDurationMs        int64                  `protobuf:"varint,9,opt,name=build_duration_ms,json=buildDurationMs,proto3" json:"build_duration_ms,omitempty"`
+	Trigger                string                 `protobuf:"bytes,10,opt,name=trigger,proto3" json:"trigger,omitempty"`
+	TopTerms               []*TermStat            `protobuf:"bytes,11,rep,name=top_terms,json=topTerms,proto3" json:"top_terms,omitempty"`
+	Searches               uint64                 `protobuf:"varint,12,opt,name=searches,proto3" json:"searches,omitempty"`
+	IndexedSearches        uint64                 `protobuf:"varint,13,opt,name=indexed_searches,json=indexedSearches,proto3" json:"indexed_searches,omitempty"`
+	LastRebuildError       string                 `protobuf:"bytes,14,opt,name=last_rebuild_error,json=lastRebuildError,proto3" json:"last_rebuild_error,omitempty"`
+	LastRebuildErrorAtUnix int64                  `protobuf:"varint,15,opt,name=last_rebuild_error_at_unix,json=lastRebuildErrorAtUnix,proto3" json:"last_rebuild_error_at_unix,omitempty"`
+	ResultCache            *CacheStats            `protobuf:"bytes,16,opt,name=result_cache,json=resultCache,proto3" json:"result_cache,omitempty"`
+	NormCache              *CacheStats            `protobuf:"bytes,17,opt,name=norm_cache,json=normCache,proto3" json:"norm_cache,omitempty"`
+	AnalyzerVersions       []*AnalyzerVersionStat `protobuf:"bytes,18,rep,name=analyzer_versions,json=analyzerVersions,proto3" json:"analyzer_versions,omitempty"`
+	QueryAnalyzerVersion   string                 `protobuf:"bytes,19,opt,name=query_analyzer_version,json=queryAnalyzerVersion,proto3" json:"query_analyzer_version,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *IndexStatsReply) Reset() {
+	*x = IndexStatsReply{}
+	mi := &file_search_search_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexStatsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexStatsReply) ProtoMessage() {}
+
+func (x *IndexStatsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexStatsReply.ProtoReflect.Descriptor instead.
+func (*IndexStatsReply) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *IndexStatsReply) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetTerms() uint32 {
+	if x != nil {
+		return x.Terms
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetDocs() uint32 {
+	if x != nil {
+		return x.Docs
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetPostings() uint64 {
+	if x != nil {
+		return x.Postings
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetMaxPosting() uint32 {
+	if x != nil {
+		return x.MaxPosting
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetAvgPosting() float64 {
+	if x != nil {
+		return x.AvgPosting
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetMemoryBytes() uint64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetBuiltAtUnix() int64 {
+	if x != nil {
+		return x.BuiltAtUnix
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetBuildDurationMs() int64 {
+	if x != nil {
+		return x.BuildDurationMs
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetTrigger() string {
+	if x != nil {
+		return x.Trigger
+	}
+	return ""
+}
+
+func (x *IndexStatsReply) GetTopTerms() []*TermStat {
+	if x != nil {
+		return x.TopTerms
+	}
+	return nil
+}
+
+func (x *IndexStatsReply) GetSearches() uint64 {
+	if x != nil {
+		return x.Searches
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetIndexedSearches() uint64 {
+	if x != nil {
+		return x.IndexedSearches
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetLastRebuildError() string {
+	if x != nil {
+		return x.LastRebuildError
+	}
+	return ""
+}
+
+func (x *IndexStatsReply) GetLastRebuildErrorAtUnix() int64 {
+	if x != nil {
+		return x.LastRebuildErrorAtUnix
+	}
+	return 0
+}
+
+func (x *IndexStatsReply) GetResultCache() *CacheStats {
+	if x != nil {
+		return x.ResultCache
+	}
+	return nil
+}
+
+func (x *IndexStatsReply) GetNormCache() *CacheStats {
+	if x != nil {
+		return x.NormCache
+	}
+	return nil
+}
+
+func (x *IndexStatsReply) GetAnalyzerVersions() []*AnalyzerVersionStat {
+	if x != nil {
+		return x.AnalyzerVersions
+	}
+	return nil
+}
+
+func (x *IndexStatsReply) GetQueryAnalyzerVersion() string {
+	if x != nil {
+		return x.QueryAnalyzerVersion
+	}
+	return ""
+}
+
+type AnalyzerVersionStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Docs          uint32                 `protobuf:"varint,2,opt,name=docs,proto3" json:"docs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzerVersionStat) Reset() {
+	*x = AnalyzerVersionStat{}
+	mi := &file_search_search_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzerVersionStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzerVersionStat) ProtoMessage() {}
+
+func (x *AnalyzerVersionStat) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzerVersionStat.ProtoReflect.Descriptor instead.
+func (*AnalyzerVersionStat) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AnalyzerVersionStat) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *AnalyzerVersionStat) GetDocs() uint32 {
+	if x != nil {
+		return x.Docs
+	}
+	return 0
+}
+
+type CacheStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          uint64                 `protobuf:"varint,1,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses        uint64                 `protobuf:"varint,2,opt,name=misses,proto3" json:"misses,omitempty"`
+	Entries       uint32                 `protobuf:"varint,3,opt,name=entries,proto3" json:"entries,omitempty"`
+	Capacity      uint32                 `protobuf:"varint,4,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheStats) Reset() {
+	*x = CacheStats{}
+	mi := &file_search_search_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStats) ProtoMessage() {}
+
+func (x *CacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStats.ProtoReflect.Descriptor instead.
+func (*CacheStats) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CacheStats) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheStats) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *CacheStats) GetEntries() uint32 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *CacheStats) GetCapacity() uint32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+type RebuildIndexReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generation    uint64                 `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	Docs          uint32                 `protobuf:"varint,2,opt,name=docs,proto3" json:"docs,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RebuildIndexReply) Reset() {
+	*x = RebuildIndexReply{}
+	mi := &file_search_search_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RebuildIndexReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RebuildIndexReply) ProtoMessage() {}
+
+func (x *RebuildIndexReply) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RebuildIndexReply.ProtoReflect.Descriptor instead.
+func (*RebuildIndexReply) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RebuildIndexReply) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *RebuildIndexReply) GetDocs() uint32 {
+	if x != nil {
+		return x.Docs
+	}
+	return 0
+}
+
+func (x *RebuildIndexReply) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type VerifyIndexReply struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Generation      uint64                 `protobuf:"varint,1,opt,name=generation,proto3" json:"generation,omitempty"`
+	DbDocs          uint32                 `protobuf:"varint,2,opt,name=db_docs,json=dbDocs,proto3" json:"db_docs,omitempty"`
+	IndexDocs       uint32                 `protobuf:"varint,3,opt,name=index_docs,json=indexDocs,proto3" json:"index_docs,omitempty"`
+	InSync          bool                   `protobuf:"varint,4,opt,name=in_sync,json=inSync,proto3" json:"in_sync,omitempty"`
+	Missing         []uint32               `protobuf:"varint,5,rep,packed,name=missing,proto3" json:"missing,omitempty"`
+	Stale           []uint32               `protobuf:"varint,6,rep,packed,name=stale,proto3" json:"stale,omitempty"`
+	Mismatched      []uint32               `protobuf:"varint,7,rep,packed,name=mismatched,proto3" json:"mismatched,omitempty"`
+	MissingCount    uint32                 `protobuf:"varint,8,opt,name=missing_count,json=missingCount,proto3" json:"missing_count,omitempty"`
+	StaleCount      uint32                 `protobuf:"varint,9,opt,name=stale_count,json=staleCount,proto3" json:"stale_count,omitempty"`
+	MismatchedCount uint32                 `protobuf:"varint,10,opt,name=mismatched_count,json=mismatchedCount,proto3" json:"mismatched_count,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *VerifyIndexReply) Reset() {
+	*x = VerifyIndexReply{}
+	mi := &file_search_search_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyIndexReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyIndexReply) ProtoMessage() {}
+
+func (x *VerifyIndexReply) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyIndexReply.ProtoReflect.Descriptor instead.
+func (*VerifyIndexReply) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *VerifyIndexReply) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *VerifyIndexReply) GetDbDocs() uint32 {
+	if x != nil {
+		return x.DbDocs
+	}
+	return 0
+}
+
+func (x *VerifyIndexReply) GetIndexDocs() uint32 {
+	if x != nil {
+		return x.IndexDocs
+	}
+	return 0
+}
+
+func (x *VerifyIndexReply) GetInSync() bool {
+	if x != nil {
+		return x.InSync
+	}
+	return false
+}
+
+func (x *VerifyIndexReply) GetMissing() []uint32 {
+	if x != nil {
+		return x.Missing
+	}
+	return nil
+}
+
+func (x *VerifyIndexReply) GetStale() []uint32 {
+	if x != nil {
+		return x.Stale
+	}
+	return nil
+}
+
+func (x *VerifyIndexReply) GetMismatched() []uint32 {
+	if x != nil {
+		return x.Mismatched
+	}
+	return nil
+}
+
+func (x *VerifyIndexReply) GetMissingCount() uint32 {
+	if x != nil {
+		return x.MissingCount
+	}
+	return 0
+}
+
+func (x *VerifyIndexReply) GetStaleCount() uint32 {
+	if x != nil {
+		return x.StaleCount
+	}
+	return 0
+}
+
+func (x *VerifyIndexReply) GetMismatchedCount() uint32 {
+	if x != nil {
+		return x.MismatchedCount
+	}
+	return 0
+}
+
+// окно аналитики, 0 - последние сутки
+type AnalyticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WindowSeconds uint32                 `protobuf:"varint,1,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`
+	Limit         uint32                 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyticsRequest) Reset() {
+	*x = AnalyticsRequest{}
+	mi := &file_search_search_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyticsRequest) ProtoMessage() {}
+
+func (x *AnalyticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyticsRequest.ProtoReflect.Descriptor instead.
+func (*AnalyticsRequest) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AnalyticsRequest) GetWindowSeconds() uint32 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+func (x *AnalyticsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type QueryStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Count         uint32                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	AvgResults    float64                `protobuf:"fixed64,3,opt,name=avg_results,json=avgResults,proto3" json:"avg_results,omitempty"`
+	LastSeenUnix  int64                  `protobuf:"varint,4,opt,name=last_seen_unix,json=lastSeenUnix,proto3" json:"last_seen_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryStat) Reset() {
+	*x = QueryStat{}
+	mi := &file_search_search_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryStat) ProtoMessage() {}
+
+func (x *QueryStat) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryStat.ProtoReflect.Descriptor instead.
+func (*QueryStat) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *QueryStat) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *QueryStat) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *QueryStat) GetAvgResults() float64 {
+	if x != nil {
+		return x.AvgResults
+	}
+	return 0
+}
+
+func (x *QueryStat) GetLastSeenUnix() int64 {
+	if x != nil {
+		return x.LastSeenUnix
+	}
+	return 0
+}
+
+type QueryStatsReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queries       []*QueryStat           `protobuf:"bytes,1,rep,name=queries,proto3" json:"queries,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchRequest) Reset() {
-	*x = SearchRequest{}
-	mi := &file_search_search_proto_msgTypes[0]
+func (x *QueryStatsReply) Reset() {
+	*x = QueryStatsReply{}
+	mi := &file_search_search_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchRequest) String() string {
+func (x *QueryStatsReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchRequest) ProtoMessage() {}
+func (*QueryStatsReply) ProtoMessage() {}
 
-func (x *SearchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_search_search_proto_msgTypes[0]
+func (x *QueryStatsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,48 +1445,116 @@ func (x *SearchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
-func (*SearchRequest) Descriptor() ([]byte, []int) {
-	return file_search_search_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use QueryStatsReply.ProtoReflect.Descriptor instead.
+func (*QueryStatsReply) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *SearchRequest) GetPhrase() string {
+func (x *QueryStatsReply) GetQueries() []*QueryStat {
 	if x != nil {
-		return x.Phrase
+		return x.Queries
+	}
+	return nil
+}
+
+type LatencyStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Endpoint      string                 `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	Count         uint32                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	P50Ms         float64                `protobuf:"fixed64,3,opt,name=p50_ms,json=p50Ms,proto3" json:"p50_ms,omitempty"`
+	P90Ms         float64                `protobuf:"fixed64,4,opt,name=p90_ms,json=p90Ms,proto3" json:"p90_ms,omitempty"`
+	P99Ms         float64                `protobuf:"fixed64,5,opt,name=p99_ms,json=p99Ms,proto3" json:"p99_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LatencyStat) Reset() {
+	*x = LatencyStat{}
+	mi := &file_search_search_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LatencyStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatencyStat) ProtoMessage() {}
+
+func (x *LatencyStat) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatencyStat.ProtoReflect.Descriptor instead.
+func (*LatencyStat) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LatencyStat) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
 	}
 	return ""
 }
 
-func (x *SearchRequest) GetLimit() uint32 {
+func (x *LatencyStat) GetCount() uint32 {
 	if x != nil {
-		return x.Limit
+		return x.Count
 	}
 	return 0
 }
 
-type ComicReply struct {
+func (x *LatencyStat) GetP50Ms() float64 {
+	if x != nil {
+		return x.P50Ms
+	}
+	return 0
+}
+
+func (x *LatencyStat) GetP90Ms() float64 {
+	if x != nil {
+		return x.P90Ms
+	}
+	return 0
+}
+
+func (x *LatencyStat) GetP99Ms() float64 {
+	if x != nil {
+		return x.P99Ms
+	}
+	return 0
+}
+
+type LatencyReply struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Endpoints     []*LatencyStat         `protobuf:"bytes,1,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ComicReply) Reset() {
-	*x = ComicReply{}
-	mi := &file_search_search_proto_msgTypes[1]
+func (x *LatencyReply) Reset() {
+	*x = LatencyReply{}
+	mi := &file_search_search_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ComicReply) String() string {
+func (x *LatencyReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ComicReply) ProtoMessage() {}
+func (*LatencyReply) ProtoMessage() {}
 
-func (x *ComicReply) ProtoReflect() protoreflect.Message {
-	mi := &file_search_search_proto_msgTypes[1]
+func (x *LatencyReply) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -107,48 +1565,94 @@ func (x *ComicReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ComicReply.ProtoReflect.Descriptor instead.
-func (*ComicReply) Descriptor() ([]byte, []int) {
-	return file_search_search_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use LatencyReply.ProtoReflect.Descriptor instead.
+func (*LatencyReply) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *ComicReply) GetId() uint32 {
+func (x *LatencyReply) GetEndpoints() []*LatencyStat {
 	if x != nil {
-		return x.Id
+		return x.Endpoints
+	}
+	return nil
+}
+
+// без seed выбор случайный; exclude - уже показанные комиксы, не больше 1000
+type RandomComicRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seed          *uint64                `protobuf:"varint,1,opt,name=seed,proto3,oneof" json:"seed,omitempty"`
+	Exclude       []uint32               `protobuf:"varint,2,rep,packed,name=exclude,proto3" json:"exclude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RandomComicRequest) Reset() {
+	*x = RandomComicRequest{}
+	mi := &file_search_search_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RandomComicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RandomComicRequest) ProtoMessage() {}
+
+func (x *RandomComicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RandomComicRequest.ProtoReflect.Descriptor instead.
+func (*RandomComicRequest) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RandomComicRequest) GetSeed() uint64 {
+	if x != nil && x.Seed != nil {
+		return *x.Seed
 	}
 	return 0
 }
 
-func (x *ComicReply) GetUrl() string {
+func (x *RandomComicRequest) GetExclude() []uint32 {
 	if x != nil {
-		return x.Url
+		return x.Exclude
 	}
-	return ""
+	return nil
 }
 
-type SearchReply struct {
+type ComicPopularity struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Comics        []*ComicReply          `protobuf:"bytes,1,rep,name=comics,proto3" json:"comics,omitempty"`
-	Total         uint32                 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	ComicId       uint32                 `protobuf:"varint,1,opt,name=comic_id,json=comicId,proto3" json:"comic_id,omitempty"`
+	Count         uint32                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchReply) Reset() {
-	*x = SearchReply{}
-	mi := &file_search_search_proto_msgTypes[2]
+func (x *ComicPopularity) Reset() {
+	*x = ComicPopularity{}
+	mi := &file_search_search_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchReply) String() string {
+func (x *ComicPopularity) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchReply) ProtoMessage() {}
+func (*ComicPopularity) ProtoMessage() {}
 
-func (x *SearchReply) ProtoReflect() protoreflect.Message {
-	mi := &file_search_search_proto_msgTypes[2]
+func (x *ComicPopularity) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -159,47 +1663,50 @@ func (x *SearchReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchReply.ProtoReflect.Descriptor instead.
-func (*SearchReply) Descriptor() ([]byte, []int) {
-	return file_search_search_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ComicPopularity.ProtoReflect.Descriptor instead.
+func (*ComicPopularity) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *SearchReply) GetComics() []*ComicReply {
+func (x *ComicPopularity) GetComicId() uint32 {
 	if x != nil {
-		return x.Comics
+		return x.ComicId
 	}
-	return nil
+	return 0
 }
 
-func (x *SearchReply) GetTotal() uint32 {
+func (x *ComicPopularity) GetCount() uint32 {
 	if x != nil {
-		return x.Total
+		return x.Count
 	}
 	return 0
 }
 
-type ComicByIDRequest struct {
+// date - YYYY-MM-DD (UTC), пусто - сегодня; popularity смещает выбор к популярным комиксам
+type ComicOfTheDayRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Date          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Seed          uint64                 `protobuf:"varint,2,opt,name=seed,proto3" json:"seed,omitempty"`
+	Popularity    []*ComicPopularity     `protobuf:"bytes,3,rep,name=popularity,proto3" json:"popularity,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ComicByIDRequest) Reset() {
-	*x = ComicByIDRequest{}
-	mi := &file_search_search_proto_msgTypes[3]
+func (x *ComicOfTheDayRequest) Reset() {
+	*x = ComicOfTheDayRequest{}
+	mi := &file_search_search_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ComicByIDRequest) String() string {
+func (x *ComicOfTheDayRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ComicByIDRequest) ProtoMessage() {}
+func (*ComicOfTheDayRequest) ProtoMessage() {}
 
-func (x *ComicByIDRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_search_search_proto_msgTypes[3]
+func (x *ComicOfTheDayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -210,41 +1717,57 @@ func (x *ComicByIDRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ComicByIDRequest.ProtoReflect.Descriptor instead.
-func (*ComicByIDRequest) Descriptor() ([]byte, []int) {
-	return file_search_search_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ComicOfTheDayRequest.ProtoReflect.Descriptor instead.
+func (*ComicOfTheDayRequest) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *ComicByIDRequest) GetId() uint32 {
+func (x *ComicOfTheDayRequest) GetDate() string {
 	if x != nil {
-		return x.Id
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ComicOfTheDayRequest) GetSeed() uint64 {
+	if x != nil {
+		return x.Seed
 	}
 	return 0
 }
 
-type ComicsPageRequest struct {
+func (x *ComicOfTheDayRequest) GetPopularity() []*ComicPopularity {
+	if x != nil {
+		return x.Popularity
+	}
+	return nil
+}
+
+// стриминг: chunk_size 0 - значение по умолчанию, не больше 1000
+type StreamSearchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Page          uint32                 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
-	PerPage       uint32                 `protobuf:"varint,2,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	Query         *SearchRequest         `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`      // limit 0 - все результаты
+	Indexed       bool                   `protobuf:"varint,2,opt,name=indexed,proto3" json:"indexed,omitempty"` // true - по индексу, как IndexedSearch
+	ChunkSize     uint32                 `protobuf:"varint,3,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ComicsPageRequest) Reset() {
-	*x = ComicsPageRequest{}
-	mi := &file_search_search_proto_msgTypes[4]
+func (x *StreamSearchRequest) Reset() {
+	*x = StreamSearchRequest{}
+	mi := &file_search_search_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ComicsPageRequest) String() string {
+func (x *StreamSearchRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ComicsPageRequest) ProtoMessage() {}
+func (*StreamSearchRequest) ProtoMessage() {}
 
-func (x *ComicsPageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_search_search_proto_msgTypes[4]
+func (x *StreamSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -255,53 +1778,307 @@ func (x *ComicsPageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ComicsPageRequest.ProtoReflect.Descriptor instead.
-func (*ComicsPageRequest) Descriptor() ([]byte, []int) {
-	return file_search_search_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use StreamSearchRequest.ProtoReflect.Descriptor instead.
+func (*StreamSearchRequest) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *ComicsPageRequest) GetPage() uint32 {
+func (x *StreamSearchRequest) GetQuery() *SearchRequest {
 	if x != nil {
-		return x.Page
+		return x.Query
+	}
+	return nil
+}
+
+func (x *StreamSearchRequest) GetIndexed() bool {
+	if x != nil {
+		return x.Indexed
+	}
+	return false
+}
+
+func (x *StreamSearchRequest) GetChunkSize() uint32 {
+	if x != nil {
+		return x.ChunkSize
 	}
 	return 0
 }
 
-func (x *ComicsPageRequest) GetPerPage() uint32 {
+type StreamComicsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChunkSize     uint32                 `protobuf:"varint,1,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamComicsRequest) Reset() {
+	*x = StreamComicsRequest{}
+	mi := &file_search_search_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamComicsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamComicsRequest) ProtoMessage() {}
+
+func (x *StreamComicsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[26]
 	if x != nil {
-		return x.PerPage
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamComicsRequest.ProtoReflect.Descriptor instead.
+func (*StreamComicsRequest) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StreamComicsRequest) GetChunkSize() uint32 {
+	if x != nil {
+		return x.ChunkSize
 	}
 	return 0
 }
 
+type ComicsChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Comics        []*ComicReply          `protobuf:"bytes,1,rep,name=comics,proto3" json:"comics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComicsChunk) Reset() {
+	*x = ComicsChunk{}
+	mi := &file_search_search_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComicsChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComicsChunk) ProtoMessage() {}
+
+func (x *ComicsChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_search_search_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComicsChunk.ProtoReflect.Descriptor instead.
+func (*ComicsChunk) Descriptor() ([]byte, []int) {
+	return file_search_search_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ComicsChunk) GetComics() []*ComicReply {
+	if x != nil {
+		return x.Comics
+	}
+	return nil
+}
+
 var File_search_search_proto protoreflect.FileDescriptor
 
 const file_search_search_proto_rawDesc = "" +
 	"\n" +
-	"\x13search/search.proto\x12\x06search\x1a\x1bgoogle/protobuf/empty.proto\"=\n" +
+	"\x13search/search.proto\x12\x06search\x1a\x1bgoogle/protobuf/empty.proto\"\xf3\x01\n" +
+	"\rSearchFilters\x12%\n" +
+	"\x0epublished_from\x18\x01 \x01(\tR\rpublishedFrom\x12!\n" +
+	"\fpublished_to\x18\x02 \x01(\tR\vpublishedTo\x12\x17\n" +
+	"\aid_from\x18\x03 \x01(\rR\x06idFrom\x12\x13\n" +
+	"\x05id_to\x18\x04 \x01(\rR\x04idTo\x12*\n" +
+	"\x0ehas_transcript\x18\x05 \x01(\bH\x00R\rhasTranscript\x88\x01\x01\x12\x19\n" +
+	"\bonly_ids\x18\x06 \x01(\bR\aonlyIds\x12\x10\n" +
+	"\x03ids\x18\a \x03(\rR\x03idsB\x11\n" +
+	"\x0f_has_transcript\"\xbb\x01\n" +
 	"\rSearchRequest\x12\x16\n" +
 	"\x06phrase\x18\x01 \x01(\tR\x06phrase\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\rR\x05limit\".\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\x12\x18\n" +
+	"\aexplain\x18\x03 \x01(\bR\aexplain\x12\x18\n" +
+	"\aprofile\x18\x04 \x01(\tR\aprofile\x12/\n" +
+	"\afilters\x18\x05 \x01(\v2\x15.search.SearchFiltersR\afilters\x12\x17\n" +
+	"\auser_id\x18\x06 \x01(\rR\x06userId\"8\n" +
+	"\n" +
+	"FacetCount\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\rR\x05count\"v\n" +
+	"\x0eScoreComponent\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x01R\x06weight\x12\"\n" +
+	"\fcontribution\x18\x04 \x01(\x01R\fcontribution\"\x88\x01\n" +
+	"\vTermExplain\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x16\n" +
+	"\x06fields\x18\x02 \x03(\tR\x06fields\x12\x19\n" +
+	"\btitle_tf\x18\x03 \x01(\rR\atitleTf\x12\x15\n" +
+	"\x06alt_tf\x18\x04 \x01(\rR\x05altTf\x12\x19\n" +
+	"\bwords_tf\x18\x05 \x01(\rR\awordsTf\"\xe3\x01\n" +
+	"\vExplanation\x12\x1a\n" +
+	"\bfunction\x18\x01 \x01(\tR\bfunction\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x126\n" +
+	"\n" +
+	"components\x18\x03 \x03(\v2\x16.search.ScoreComponentR\n" +
+	"components\x12)\n" +
+	"\x05terms\x18\x04 \x03(\v2\x13.search.TermExplainR\x05terms\x12%\n" +
+	"\x0ematched_fields\x18\x05 \x03(\tR\rmatchedFields\x12\x18\n" +
+	"\aprofile\x18\x06 \x01(\tR\aprofile\"]\n" +
 	"\n" +
 	"ComicReply\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\rR\x02id\x12\x10\n" +
-	"\x03url\x18\x02 \x01(\tR\x03url\"O\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12-\n" +
+	"\aexplain\x18\x03 \x01(\v2\x13.search.ExplanationR\aexplain\"\xbf\x01\n" +
 	"\vSearchReply\x12*\n" +
 	"\x06comics\x18\x01 \x03(\v2\x12.search.ComicReplyR\x06comics\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\rR\x05total\"\"\n" +
+	"\x05total\x18\x02 \x01(\rR\x05total\x12\x16\n" +
+	"\x06tokens\x18\x03 \x03(\tR\x06tokens\x12(\n" +
+	"\x05years\x18\x04 \x03(\v2\x12.search.FacetCountR\x05years\x12,\n" +
+	"\asources\x18\x05 \x03(\v2\x12.search.FacetCountR\asources\"\"\n" +
 	"\x10ComicByIDRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\rR\x02id\"B\n" +
 	"\x11ComicsPageRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\rR\x04page\x12\x19\n" +
-	"\bper_page\x18\x02 \x01(\rR\aperPage2\xeb\x02\n" +
+	"\bper_page\x18\x02 \x01(\rR\aperPage\"%\n" +
+	"\x11IndexStatsRequest\x12\x10\n" +
+	"\x03top\x18\x01 \x01(\rR\x03top\"2\n" +
+	"\bTermStat\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\tR\x04term\x12\x12\n" +
+	"\x04docs\x18\x02 \x01(\rR\x04docs\"\x90\x06\n" +
+	"\x0fIndexStatsReply\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x04R\n" +
+	"generation\x12\x14\n" +
+	"\x05terms\x18\x02 \x01(\rR\x05terms\x12\x12\n" +
+	"\x04docs\x18\x03 \x01(\rR\x04docs\x12\x1a\n" +
+	"\bpostings\x18\x04 \x01(\x04R\bpostings\x12\x1f\n" +
+	"\vmax_posting\x18\x05 \x01(\rR\n" +
+	"maxPosting\x12\x1f\n" +
+	"\vavg_posting\x18\x06 \x01(\x01R\n" +
+	"avgPosting\x12!\n" +
+	"\fmemory_bytes\x18\a \x01(\x04R\vmemoryBytes\x12\"\n" +
+	"\rbuilt_at_unix\x18\b \x01(\x03R\vbuiltAtUnix\x12*\n" +
+	"\x11build_duration_ms\x18\t \x01(\x03R\x0fbuildDurationMs\x12\x18\n" +
+	"\atrigger\x18\n" +
+	" \x01(\tR\atrigger\x12-\n" +
+	"\ttop_terms\x18\v \x03(\v2\x10.search.TermStatR\btopTerms\x12\x1a\n" +
+	"\bsearches\x18\f \x01(\x04R\bsearches\x12)\n" +
+	"\x10indexed_searches\x18\r \x01(\x04R\x0findexedSearches\x12,\n" +
+	"\x12last_rebuild_error\x18\x0e \x01(\tR\x10lastRebuildError\x12:\n" +
+	"\x1alast_rebuild_error_at_unix\x18\x0f \x01(\x03R\x16lastRebuildErrorAtUnix\x125\n" +
+	"\fresult_cache\x18\x10 \x01(\v2\x12.search.CacheStatsR\vresultCache\x121\n" +
+	"\n" +
+	"norm_cache\x18\x11 \x01(\v2\x12.search.CacheStatsR\tnormCache\x12H\n" +
+	"\x11analyzer_versions\x18\x12 \x03(\v2\x1b.search.AnalyzerVersionStatR\x10analyzerVersions\x124\n" +
+	"\x16query_analyzer_version\x18\x13 \x01(\tR\x14queryAnalyzerVersion\"C\n" +
+	"\x13AnalyzerVersionStat\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x12\n" +
+	"\x04docs\x18\x02 \x01(\rR\x04docs\"n\n" +
+	"\n" +
+	"CacheStats\x12\x12\n" +
+	"\x04hits\x18\x01 \x01(\x04R\x04hits\x12\x16\n" +
+	"\x06misses\x18\x02 \x01(\x04R\x06misses\x12\x18\n" +
+	"\aentries\x18\x03 \x01(\rR\aentries\x12\x1a\n" +
+	"\bcapacity\x18\x04 \x01(\rR\bcapacity\"h\n" +
+	"\x11RebuildIndexReply\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x04R\n" +
+	"generation\x12\x12\n" +
+	"\x04docs\x18\x02 \x01(\rR\x04docs\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x03R\n" +
+	"durationMs\"\xc4\x02\n" +
+	"\x10VerifyIndexReply\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x01 \x01(\x04R\n" +
+	"generation\x12\x17\n" +
+	"\adb_docs\x18\x02 \x01(\rR\x06dbDocs\x12\x1d\n" +
+	"\n" +
+	"index_docs\x18\x03 \x01(\rR\tindexDocs\x12\x17\n" +
+	"\ain_sync\x18\x04 \x01(\bR\x06inSync\x12\x18\n" +
+	"\amissing\x18\x05 \x03(\rR\amissing\x12\x14\n" +
+	"\x05stale\x18\x06 \x03(\rR\x05stale\x12\x1e\n" +
+	"\n" +
+	"mismatched\x18\a \x03(\rR\n" +
+	"mismatched\x12#\n" +
+	"\rmissing_count\x18\b \x01(\rR\fmissingCount\x12\x1f\n" +
+	"\vstale_count\x18\t \x01(\rR\n" +
+	"staleCount\x12)\n" +
+	"\x10mismatched_count\x18\n" +
+	" \x01(\rR\x0fmismatchedCount\"O\n" +
+	"\x10AnalyticsRequest\x12%\n" +
+	"\x0ewindow_seconds\x18\x01 \x01(\rR\rwindowSeconds\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\"~\n" +
+	"\tQueryStat\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\rR\x05count\x12\x1f\n" +
+	"\vavg_results\x18\x03 \x01(\x01R\n" +
+	"avgResults\x12$\n" +
+	"\x0elast_seen_unix\x18\x04 \x01(\x03R\flastSeenUnix\">\n" +
+	"\x0fQueryStatsReply\x12+\n" +
+	"\aqueries\x18\x01 \x03(\v2\x11.search.QueryStatR\aqueries\"\x84\x01\n" +
+	"\vLatencyStat\x12\x1a\n" +
+	"\bendpoint\x18\x01 \x01(\tR\bendpoint\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\rR\x05count\x12\x15\n" +
+	"\x06p50_ms\x18\x03 \x01(\x01R\x05p50Ms\x12\x15\n" +
+	"\x06p90_ms\x18\x04 \x01(\x01R\x05p90Ms\x12\x15\n" +
+	"\x06p99_ms\x18\x05 \x01(\x01R\x05p99Ms\"A\n" +
+	"\fLatencyReply\x121\n" +
+	"\tendpoints\x18\x01 \x03(\v2\x13.search.LatencyStatR\tendpoints\"P\n" +
+	"\x12RandomComicRequest\x12\x17\n" +
+	"\x04seed\x18\x01 \x01(\x04H\x00R\x04seed\x88\x01\x01\x12\x18\n" +
+	"\aexclude\x18\x02 \x03(\rR\aexcludeB\a\n" +
+	"\x05_seed\"B\n" +
+	"\x0fComicPopularity\x12\x19\n" +
+	"\bcomic_id\x18\x01 \x01(\rR\acomicId\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\rR\x05count\"w\n" +
+	"\x14ComicOfTheDayRequest\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x12\n" +
+	"\x04seed\x18\x02 \x01(\x04R\x04seed\x127\n" +
+	"\n" +
+	"popularity\x18\x03 \x03(\v2\x17.search.ComicPopularityR\n" +
+	"popularity\"{\n" +
+	"\x13StreamSearchRequest\x12+\n" +
+	"\x05query\x18\x01 \x01(\v2\x15.search.SearchRequestR\x05query\x12\x18\n" +
+	"\aindexed\x18\x02 \x01(\bR\aindexed\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x03 \x01(\rR\tchunkSize\"4\n" +
+	"\x13StreamComicsRequest\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x01 \x01(\rR\tchunkSize\"9\n" +
+	"\vComicsChunk\x12*\n" +
+	"\x06comics\x18\x01 \x03(\v2\x12.search.ComicReplyR\x06comics2\xcf\a\n" +
 	"\x06Search\x126\n" +
 	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\x122\n" +
 	"\x04Find\x12\x15.search.SearchRequest\x1a\x13.search.SearchReply\x12;\n" +
 	"\rIndexedSearch\x12\x15.search.SearchRequest\x1a\x13.search.SearchReply\x12:\n" +
 	"\n" +
 	"GetIDComic\x12\x18.search.ComicByIDRequest\x1a\x12.search.ComicReply\x12>\n" +
-	"\fGetAllComics\x12\x19.search.ComicsPageRequest\x1a\x13.search.SearchReply\x12<\n" +
-	"\x0eGetRandomComic\x12\x16.google.protobuf.Empty\x1a\x12.search.ComicReplyB\x1fZ\x1dyadro.com/course/proto/searchb\x06proto3"
+	"\fGetAllComics\x12\x19.search.ComicsPageRequest\x1a\x13.search.SearchReply\x12@\n" +
+	"\x0eGetRandomComic\x12\x1a.search.RandomComicRequest\x1a\x12.search.ComicReply\x12A\n" +
+	"\rComicOfTheDay\x12\x1c.search.ComicOfTheDayRequest\x1a\x12.search.ComicReply\x12B\n" +
+	"\fStreamSearch\x12\x1b.search.StreamSearchRequest\x1a\x13.search.ComicsChunk0\x01\x12B\n" +
+	"\fStreamComics\x12\x1b.search.StreamComicsRequest\x1a\x13.search.ComicsChunk0\x01\x12@\n" +
+	"\n" +
+	"IndexStats\x12\x19.search.IndexStatsRequest\x1a\x17.search.IndexStatsReply\x12A\n" +
+	"\fRebuildIndex\x12\x16.google.protobuf.Empty\x1a\x19.search.RebuildIndexReply\x12?\n" +
+	"\vVerifyIndex\x12\x16.google.protobuf.Empty\x1a\x18.search.VerifyIndexReply\x12?\n" +
+	"\n" +
+	"TopQueries\x12\x18.search.AnalyticsRequest\x1a\x17.search.QueryStatsReply\x12F\n" +
+	"\x11ZeroResultQueries\x12\x18.search.AnalyticsRequest\x1a\x17.search.QueryStatsReply\x12D\n" +
+	"\x12LatencyPercentiles\x12\x18.search.AnalyticsRequest\x1a\x14.search.LatencyReplyB\x1fZ\x1dyadro.com/course/proto/searchb\x06proto3"
 
 var (
 	file_search_search_proto_rawDescOnce sync.Once
@@ -315,34 +2092,90 @@ func file_search_search_proto_rawDescGZIP() []byte {
 	return file_search_search_proto_rawDescData
 }
 
-var file_search_search_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_search_search_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_search_search_proto_goTypes = []any{
-	(*SearchRequest)(nil),     // 0: search.SearchRequest
-	(*ComicReply)(nil),        // 1: search.ComicReply
-	(*SearchReply)(nil),       // 2: search.SearchReply
-	(*ComicByIDRequest)(nil),  // 3: search.ComicByIDRequest
-	(*ComicsPageRequest)(nil), // 4: search.ComicsPageRequest
-	(*emptypb.Empty)(nil),     // 5: google.protobuf.Empty
+	(*SearchFilters)(nil),        // 0: search.SearchFilters
+	(*SearchRequest)(nil),        // 1: search.SearchRequest
+	(*FacetCount)(nil),           // 2: search.FacetCount
+	(*ScoreComponent)(nil),       // 3: search.ScoreComponent
+	(*TermExplain)(nil),          // 4: search.TermExplain
+	(*Explanation)(nil),          // 5: search.Explanation
+	(*ComicReply)(nil),           // 6: search.ComicReply
+	(*SearchReply)(nil),          // 7: search.SearchReply
+	(*ComicByIDRequest)(nil),     // 8: search.ComicByIDRequest
+	(*ComicsPageRequest)(nil),    // 9: search.ComicsPageRequest
+	(*IndexStatsRequest)(nil),    // 10: search.IndexStatsRequest
+	(*TermStat)(nil),             // 11: search.TermStat
+	(*IndexStatsReply)(nil),      // 12: search.IndexStatsReply
+	(*AnalyzerVersionStat)(nil),  // 13: search.AnalyzerVersionStat
+	(*CacheStats)(nil),           // 14: search.CacheStats
+	(*RebuildIndexReply)(nil),    // 15: search.RebuildIndexReply
+	(*VerifyIndexReply)(nil),     // 16: search.VerifyIndexReply
+	(*AnalyticsRequest)(nil),     // 17: search.AnalyticsRequest
+	(*QueryStat)(nil),            // 18: search.QueryStat
+	(*QueryStatsReply)(nil),      // 19: search.QueryStatsReply
+	(*LatencyStat)(nil),          // 20: search.LatencyStat
+	(*LatencyReply)(nil),         // 21: search.LatencyReply
+	(*RandomComicRequest)(nil),   // 22: search.RandomComicRequest
+	(*ComicPopularity)(nil),      // 23: search.ComicPopularity
+	(*ComicOfTheDayRequest)(nil), // 24: search.ComicOfTheDayRequest
+	(*StreamSearchRequest)(nil),  // 25: search.StreamSearchRequest
+	(*StreamComicsRequest)(nil),  // 26: search.StreamComicsRequest
+	(*ComicsChunk)(nil),          // 27: search.ComicsChunk
+	(*emptypb.Empty)(nil),        // 28: google.protobuf.Empty
 }
 var file_search_search_proto_depIdxs = []int32{
-	1, // 0: search.SearchReply.comics:type_name -> search.ComicReply
-	5, // 1: search.Search.Ping:input_type -> google.protobuf.Empty
-	0, // 2: search.Search.Find:input_type -> search.SearchRequest
-	0, // 3: search.Search.IndexedSearch:input_type -> search.SearchRequest
-	3, // 4: search.Search.GetIDComic:input_type -> search.ComicByIDRequest
-	4, // 5: search.Search.GetAllComics:input_type -> search.ComicsPageRequest
-	5, // 6: search.Search.GetRandomComic:input_type -> google.protobuf.Empty
-	5, // 7: search.Search.Ping:output_type -> google.protobuf.Empty
-	2, // 8: search.Search.Find:output_type -> search.SearchReply
-	2, // 9: search.Search.IndexedSearch:output_type -> search.SearchReply
-	1, // 10: search.Search.GetIDComic:output_type -> search.ComicReply
-	2, // 11: search.Search.GetAllComics:output_type -> search.SearchReply
-	1, // 12: search.Search.GetRandomComic:output_type -> search.ComicReply
-	7, // [7:13] is the sub-list for method output_type
-	1, // [1:7] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: search.SearchRequest.filters:type_name -> search.SearchFilters
+	3,  // 1: search.Explanation.components:type_name -> search.ScoreComponent
+	4,  // 2: search.Explanation.terms:type_name -> search.TermExplain
+	5,  // 3: search.ComicReply.explain:type_name -> search.Explanation
+	6,  // 4: search.SearchReply.comics:type_name -> search.ComicReply
+	2,  // 5: search.SearchReply.years:type_name -> search.FacetCount
+	2,  // 6: search.SearchReply.sources:type_name -> search.FacetCount
+	11, // 7: search.IndexStatsReply.top_terms:type_name -> search.TermStat
+	14, // 8: search.IndexStatsReply.result_cache:type_name -> search.CacheStats
+	14, // 9: search.IndexStatsReply.norm_cache:type_name -> search.CacheStats
+	13, // 10: search.IndexStatsReply.analyzer_versions:type_name -> search.AnalyzerVersionStat
+	18, // 11: search.QueryStatsReply.queries:type_name -> search.QueryStat
+	20, // 12: search.LatencyReply.endpoints:type_name -> search.LatencyStat
+	23, // 13: search.ComicOfTheDayRequest.popularity:type_name -> search.ComicPopularity
+	1,  // 14: search.StreamSearchRequest.query:type_name -> search.SearchRequest
+	6,  // 15: search.ComicsChunk.comics:type_name -> search.ComicReply
+	28, // 16: search.Search.Ping:input_type -> google.protobuf.Empty
+	1,  // 17: search.Search.Find:input_type -> search.SearchRequest
+	1,  // 18: search.Search.IndexedSearch:input_type -> search.SearchRequest
+	8,  // 19: search.Search.GetIDComic:input_type -> search.ComicByIDRequest
+	9,  // 20: search.Search.GetAllComics:input_type -> search.ComicsPageRequest
+	22, // 21: search.Search.GetRandomComic:input_type -> search.RandomComicRequest
+	24, // 22: search.Search.ComicOfTheDay:input_type -> search.ComicOfTheDayRequest
+	25, // 23: search.Search.StreamSearch:input_type -> search.StreamSearchRequest
+	26, // 24: search.Search.StreamComics:input_type -> search.StreamComicsRequest
+	10, // 25: search.Search.IndexStats:input_type -> search.IndexStatsRequest
+	28, // 26: search.Search.RebuildIndex:input_type -> google.protobuf.Empty
+	28, // 27: search.Search.VerifyIndex:input_type -> google.protobuf.Empty
+	17, // 28: search.Search.TopQueries:input_type -> search.AnalyticsRequest
+	17, // 29: search.Search.ZeroResultQueries:input_type -> search.AnalyticsRequest
+	17, // 30: search.Search.LatencyPercentiles:input_type -> search.AnalyticsRequest
+	28, // 31: search.Search.Ping:output_type -> google.protobuf.Empty
+	7,  // 32: search.Search.Find:output_type -> search.SearchReply
+	7,  // 33: search.Search.IndexedSearch:output_type -> search.SearchReply
+	6,  // 34: search.Search.GetIDComic:output_type -> search.ComicReply
+	7,  // 35: search.Search.GetAllComics:output_type -> search.SearchReply
+	6,  // 36: search.Search.GetRandomComic:output_type -> search.ComicReply
+	6,  // 37: search.Search.ComicOfTheDay:output_type -> search.ComicReply
+	27, // 38: search.Search.StreamSearch:output_type -> search.ComicsChunk
+	27, // 39: search.Search.StreamComics:output_type -> search.ComicsChunk
+	12, // 40: search.Search.IndexStats:output_type -> search.IndexStatsReply
+	15, // 41: search.Search.RebuildIndex:output_type -> search.RebuildIndexReply
+	16, // 42: search.Search.VerifyIndex:output_type -> search.VerifyIndexReply
+	19, // 43: search.Search.TopQueries:output_type -> search.QueryStatsReply
+	19, // 44: search.Search.ZeroResultQueries:output_type -> search.QueryStatsReply
+	21, // 45: search.Search.LatencyPercentiles:output_type -> search.LatencyReply
+	31, // [31:46] is the sub-list for method output_type
+	16, // [16:31] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_search_search_proto_init() }
@@ -350,13 +2183,15 @@ func file_search_search_proto_init() {
 	if File_search_search_proto != nil {
 		return
 	}
+	file_search_search_proto_msgTypes[0].OneofWrappers = []any{}
+	file_search_search_proto_msgTypes[22].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_search_search_proto_rawDesc), len(file_search_search_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
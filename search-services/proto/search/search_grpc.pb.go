@@ -20,12 +20,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Search_Ping_FullMethodName           = "/search.Search/Ping"
-	Search_Find_FullMethodName           = "/search.Search/Find"
-	Search_IndexedSearch_FullMethodName  = "/search.Search/IndexedSearch"
-	Search_GetIDComic_FullMethodName     = "/search.Search/GetIDComic"
-	Search_GetAllComics_FullMethodName   = "/search.Search/GetAllComics"
-	Search_GetRandomComic_FullMethodName = "/search.Search/GetRandomComic"
+	Search_Ping_FullMethodName               = "/search.Search/Ping"
+	Search_Find_FullMethodName               = "/search.Search/Find"
+	Search_IndexedSearch_FullMethodName      = "/search.Search/IndexedSearch"
+	Search_GetIDComic_FullMethodName         = "/search.Search/GetIDComic"
+	Search_GetAllComics_FullMethodName       = "/search.Search/GetAllComics"
+	Search_GetRandomComic_FullMethodName     = "/search.Search/GetRandomComic"
+	Search_ComicOfTheDay_FullMethodName      = "/search.Search/ComicOfTheDay"
+	Search_StreamSearch_FullMethodName       = "/search.Search/StreamSearch"
+	Search_StreamComics_FullMethodName       = "/search.Search/StreamComics"
+	Search_IndexStats_FullMethodName         = "/search.Search/IndexStats"
+	Search_RebuildIndex_FullMethodName       = "/search.Search/RebuildIndex"
+	Search_VerifyIndex_FullMethodName        = "/search.Search/VerifyIndex"
+	Search_TopQueries_FullMethodName         = "/search.Search/TopQueries"
+	Search_ZeroResultQueries_FullMethodName  = "/search.Search/ZeroResultQueries"
+	Search_LatencyPercentiles_FullMethodName = "/search.Search/LatencyPercentiles"
 )
 
 // SearchClient is the client API for Search service.
@@ -37,7 +46,16 @@ type SearchClient interface {
 	IndexedSearch(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchReply, error)
 	GetIDComic(ctx context.Context, in *ComicByIDRequest, opts ...grpc.CallOption) (*ComicReply, error)
 	GetAllComics(ctx context.Context, in *ComicsPageRequest, opts ...grpc.CallOption) (*SearchReply, error)
-	GetRandomComic(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ComicReply, error)
+	GetRandomComic(ctx context.Context, in *RandomComicRequest, opts ...grpc.CallOption) (*ComicReply, error)
+	ComicOfTheDay(ctx context.Context, in *ComicOfTheDayRequest, opts ...grpc.CallOption) (*ComicReply, error)
+	StreamSearch(ctx context.Context, in *StreamSearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ComicsChunk], error)
+	StreamComics(ctx context.Context, in *StreamComicsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ComicsChunk], error)
+	IndexStats(ctx context.Context, in *IndexStatsRequest, opts ...grpc.CallOption) (*IndexStatsReply, error)
+	RebuildIndex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RebuildIndexReply, error)
+	VerifyIndex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VerifyIndexReply, error)
+	TopQueries(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*QueryStatsReply, error)
+	ZeroResultQueries(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*QueryStatsReply, error)
+	LatencyPercentiles(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*LatencyReply, error)
 }
 
 type searchClient struct {
@@ -98,7 +116,7 @@ func (c *searchClient) GetAllComics(ctx context.Context, in *ComicsPageRequest,
 	return out, nil
 }
 
-func (c *searchClient) GetRandomComic(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ComicReply, error) {
+func (c *searchClient) GetRandomComic(ctx context.Context, in *RandomComicRequest, opts ...grpc.CallOption) (*ComicReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ComicReply)
 	err := c.cc.Invoke(ctx, Search_GetRandomComic_FullMethodName, in, out, cOpts...)
@@ -108,6 +126,114 @@ func (c *searchClient) GetRandomComic(ctx context.Context, in *emptypb.Empty, op
 	return out, nil
 }
 
+func (c *searchClient) ComicOfTheDay(ctx context.Context, in *ComicOfTheDayRequest, opts ...grpc.CallOption) (*ComicReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ComicReply)
+	err := c.cc.Invoke(ctx, Search_ComicOfTheDay_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) StreamSearch(ctx context.Context, in *StreamSearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ComicsChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Search_ServiceDesc.Streams[0], Search_StreamSearch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamSearchRequest, ComicsChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Search_StreamSearchClient = grpc.ServerStreamingClient[ComicsChunk]
+
+func (c *searchClient) StreamComics(ctx context.Context, in *StreamComicsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ComicsChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Search_ServiceDesc.Streams[1], Search_StreamComics_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamComicsRequest, ComicsChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Search_StreamComicsClient = grpc.ServerStreamingClient[ComicsChunk]
+
+func (c *searchClient) IndexStats(ctx context.Context, in *IndexStatsRequest, opts ...grpc.CallOption) (*IndexStatsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IndexStatsReply)
+	err := c.cc.Invoke(ctx, Search_IndexStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) RebuildIndex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RebuildIndexReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RebuildIndexReply)
+	err := c.cc.Invoke(ctx, Search_RebuildIndex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) VerifyIndex(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VerifyIndexReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyIndexReply)
+	err := c.cc.Invoke(ctx, Search_VerifyIndex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) TopQueries(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*QueryStatsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryStatsReply)
+	err := c.cc.Invoke(ctx, Search_TopQueries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) ZeroResultQueries(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*QueryStatsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryStatsReply)
+	err := c.cc.Invoke(ctx, Search_ZeroResultQueries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchClient) LatencyPercentiles(ctx context.Context, in *AnalyticsRequest, opts ...grpc.CallOption) (*LatencyReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LatencyReply)
+	err := c.cc.Invoke(ctx, Search_LatencyPercentiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SearchServer is the server API for Search service.
 // All implementations must embed UnimplementedSearchServer
 // for forward compatibility.
@@ -117,7 +243,16 @@ type SearchServer interface {
 	IndexedSearch(context.Context, *SearchRequest) (*SearchReply, error)
 	GetIDComic(context.Context, *ComicByIDRequest) (*ComicReply, error)
 	GetAllComics(context.Context, *ComicsPageRequest) (*SearchReply, error)
-	GetRandomComic(context.Context, *emptypb.Empty) (*ComicReply, error)
+	GetRandomComic(context.Context, *RandomComicRequest) (*ComicReply, error)
+	ComicOfTheDay(context.Context, *ComicOfTheDayRequest) (*ComicReply, error)
+	StreamSearch(*StreamSearchRequest, grpc.ServerStreamingServer[ComicsChunk]) error
+	StreamComics(*StreamComicsRequest, grpc.ServerStreamingServer[ComicsChunk]) error
+	IndexStats(context.Context, *IndexStatsRequest) (*IndexStatsReply, error)
+	RebuildIndex(context.Context, *emptypb.Empty) (*RebuildIndexReply, error)
+	VerifyIndex(context.Context, *emptypb.Empty) (*VerifyIndexReply, error)
+	TopQueries(context.Context, *AnalyticsRequest) (*QueryStatsReply, error)
+	ZeroResultQueries(context.Context, *AnalyticsRequest) (*QueryStatsReply, error)
+	LatencyPercentiles(context.Context, *AnalyticsRequest) (*LatencyReply, error)
 	mustEmbedUnimplementedSearchServer()
 }
 
@@ -143,9 +278,36 @@ func (UnimplementedSearchServer) GetIDComic(context.Context, *ComicByIDRequest)
 func (UnimplementedSearchServer) GetAllComics(context.Context, *ComicsPageRequest) (*SearchReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllComics not implemented")
 }
-func (UnimplementedSearchServer) GetRandomComic(context.Context, *emptypb.Empty) (*ComicReply, error) {
+func (UnimplementedSearchServer) GetRandomComic(context.Context, *RandomComicRequest) (*ComicReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRandomComic not implemented")
 }
+func (UnimplementedSearchServer) ComicOfTheDay(context.Context, *ComicOfTheDayRequest) (*ComicReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ComicOfTheDay not implemented")
+}
+func (UnimplementedSearchServer) StreamSearch(*StreamSearchRequest, grpc.ServerStreamingServer[ComicsChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSearch not implemented")
+}
+func (UnimplementedSearchServer) StreamComics(*StreamComicsRequest, grpc.ServerStreamingServer[ComicsChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamComics not implemented")
+}
+func (UnimplementedSearchServer) IndexStats(context.Context, *IndexStatsRequest) (*IndexStatsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IndexStats not implemented")
+}
+func (UnimplementedSearchServer) RebuildIndex(context.Context, *emptypb.Empty) (*RebuildIndexReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RebuildIndex not implemented")
+}
+func (UnimplementedSearchServer) VerifyIndex(context.Context, *emptypb.Empty) (*VerifyIndexReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyIndex not implemented")
+}
+func (UnimplementedSearchServer) TopQueries(context.Context, *AnalyticsRequest) (*QueryStatsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TopQueries not implemented")
+}
+func (UnimplementedSearchServer) ZeroResultQueries(context.Context, *AnalyticsRequest) (*QueryStatsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ZeroResultQueries not implemented")
+}
+func (UnimplementedSearchServer) LatencyPercentiles(context.Context, *AnalyticsRequest) (*LatencyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LatencyPercentiles not implemented")
+}
 func (UnimplementedSearchServer) mustEmbedUnimplementedSearchServer() {}
 func (UnimplementedSearchServer) testEmbeddedByValue()                {}
 
@@ -258,7 +420,7 @@ func _Search_GetAllComics_Handler(srv interface{}, ctx context.Context, dec func
 }
 
 func _Search_GetRandomComic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+	in := new(RandomComicRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -270,7 +432,155 @@ func _Search_GetRandomComic_Handler(srv interface{}, ctx context.Context, dec fu
 		FullMethod: Search_GetRandomComic_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(SearchServer).GetRandomComic(ctx, req.(*emptypb.Empty))
+		return srv.(SearchServer).GetRandomComic(ctx, req.(*RandomComicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_ComicOfTheDay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComicOfTheDayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).ComicOfTheDay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_ComicOfTheDay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).ComicOfTheDay(ctx, req.(*ComicOfTheDayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_StreamSearch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamSearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SearchServer).StreamSearch(m, &grpc.GenericServerStream[StreamSearchRequest, ComicsChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Search_StreamSearchServer = grpc.ServerStreamingServer[ComicsChunk]
+
+func _Search_StreamComics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamComicsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SearchServer).StreamComics(m, &grpc.GenericServerStream[StreamComicsRequest, ComicsChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Search_StreamComicsServer = grpc.ServerStreamingServer[ComicsChunk]
+
+func _Search_IndexStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IndexStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).IndexStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_IndexStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).IndexStats(ctx, req.(*IndexStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_RebuildIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).RebuildIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_RebuildIndex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).RebuildIndex(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_VerifyIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).VerifyIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_VerifyIndex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).VerifyIndex(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_TopQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).TopQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_TopQueries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).TopQueries(ctx, req.(*AnalyticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_ZeroResultQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).ZeroResultQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_ZeroResultQueries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).ZeroResultQueries(ctx, req.(*AnalyticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Search_LatencyPercentiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).LatencyPercentiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_LatencyPercentiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).LatencyPercentiles(ctx, req.(*AnalyticsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -306,7 +616,46 @@ var Search_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetRandomComic",
 			Handler:    _Search_GetRandomComic_Handler,
 		},
+		{
+			MethodName: "ComicOfTheDay",
+			Handler:    _Search_ComicOfTheDay_Handler,
+		},
+		{
+			MethodName: "IndexStats",
+			Handler:    _Search_IndexStats_Handler,
+		},
+		{
+			MethodName: "RebuildIndex",
+			Handler:    _Search_RebuildIndex_Handler,
+		},
+		{
+			MethodName: "VerifyIndex",
+			Handler:    _Search_VerifyIndex_Handler,
+		},
+		{
+			MethodName: "TopQueries",
+			Handler:    _Search_TopQueries_Handler,
+		},
+		{
+			MethodName: "ZeroResultQueries",
+			Handler:    _Search_ZeroResultQueries_Handler,
+		},
+		{
+			MethodName: "LatencyPercentiles",
+			Handler:    _Search_LatencyPercentiles_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSearch",
+			Handler:       _Search_StreamSearch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamComics",
+			Handler:       _Search_StreamComics_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "search/search.proto",
 }
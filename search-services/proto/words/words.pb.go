@@ -23,8 +23,12 @@ const (
 )
 
 type WordsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Phrase string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	// имя (russian) или код ISO 639-1 (ru); не задан - язык определяется по фразе
+	Language *string `protobuf:"bytes,2,opt,name=language,proto3,oneof" json:"language,omitempty"`
+	// цепочка фильтров из конфига words, не задана - default
+	Analyzer      *string `protobuf:"bytes,3,opt,name=analyzer,proto3,oneof" json:"analyzer,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -66,11 +70,29 @@ func (x *WordsRequest) GetPhrase() string {
 	return ""
 }
 
+func (x *WordsRequest) GetLanguage() string {
+	if x != nil && x.Language != nil {
+		return *x.Language
+	}
+	return ""
+}
+
+func (x *WordsRequest) GetAnalyzer() string {
+	if x != nil && x.Analyzer != nil {
+		return *x.Analyzer
+	}
+	return ""
+}
+
+// analyzer_version - хэш цепочки и ее словарей: токены разных версий несравнимы
 type WordsReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Words         []string               `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Words           []string               `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
+	Language        string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Analyzer        string                 `protobuf:"bytes,3,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	AnalyzerVersion string                 `protobuf:"bytes,4,opt,name=analyzer_version,json=analyzerVersion,proto3" json:"analyzer_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *WordsReply) Reset() {
@@ -110,19 +132,1317 @@ func (x *WordsReply) GetWords() []string {
 	return nil
 }
 
-var File_proto_words_words_proto protoreflect.FileDescriptor
+func (x *WordsReply) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
 
-const file_proto_words_words_proto_rawDesc = "" +
-	"\n" +
-	"\x17proto/words/words.proto\x12\x05words\x1a\x1bgoogle/protobuf/empty.proto\"&\n" +
-	"\fWordsRequest\x12\x16\n" +
-	"\x06phrase\x18\x01 \x01(\tR\x06phrase\"\"\n" +
-	"\n" +
-	"WordsReply\x12\x14\n" +
-	"\x05words\x18\x01 \x03(\tR\x05words2s\n" +
-	"\x05Words\x128\n" +
-	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x120\n" +
-	"\x04Norm\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00B\x1eZ\x1cyadro.com/course/proto/wordsb\x06proto3"
+func (x *WordsReply) GetAnalyzer() string {
+	if x != nil {
+		return x.Analyzer
+	}
+	return ""
+}
+
+func (x *WordsReply) GetAnalyzerVersion() string {
+	if x != nil {
+		return x.AnalyzerVersion
+	}
+	return ""
+}
+
+// WeightedWord - вес 1 у слов запроса, меньше - у синонимов из словаря
+type WeightedWord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Word          string                 `protobuf:"bytes,1,opt,name=word,proto3" json:"word,omitempty"`
+	Weight        float64                `protobuf:"fixed64,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeightedWord) Reset() {
+	*x = WeightedWord{}
+	mi := &file_proto_words_words_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeightedWord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeightedWord) ProtoMessage() {}
+
+func (x *WeightedWord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeightedWord.ProtoReflect.Descriptor instead.
+func (*WeightedWord) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WeightedWord) GetWord() string {
+	if x != nil {
+		return x.Word
+	}
+	return ""
+}
+
+func (x *WeightedWord) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// synonyms_version - хэш словаря синонимов, меняется при его перезагрузке
+// без смены analyzer_version; пустой - словаря нет
+type ExpandReply struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Words           []*WeightedWord        `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
+	Language        string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Analyzer        string                 `protobuf:"bytes,3,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	AnalyzerVersion string                 `protobuf:"bytes,4,opt,name=analyzer_version,json=analyzerVersion,proto3" json:"analyzer_version,omitempty"`
+	SynonymsVersion string                 `protobuf:"bytes,5,opt,name=synonyms_version,json=synonymsVersion,proto3" json:"synonyms_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExpandReply) Reset() {
+	*x = ExpandReply{}
+	mi := &file_proto_words_words_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandReply) ProtoMessage() {}
+
+func (x *ExpandReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandReply.ProtoReflect.Descriptor instead.
+func (*ExpandReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExpandReply) GetWords() []*WeightedWord {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+func (x *ExpandReply) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ExpandReply) GetAnalyzer() string {
+	if x != nil {
+		return x.Analyzer
+	}
+	return ""
+}
+
+func (x *ExpandReply) GetAnalyzerVersion() string {
+	if x != nil {
+		return x.AnalyzerVersion
+	}
+	return ""
+}
+
+func (x *ExpandReply) GetSynonymsVersion() string {
+	if x != nil {
+		return x.SynonymsVersion
+	}
+	return ""
+}
+
+// NormItem - фраза пакета; language переопределяет язык пакета
+type NormItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Phrase        string                 `protobuf:"bytes,2,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	Language      *string                `protobuf:"bytes,3,opt,name=language,proto3,oneof" json:"language,omitempty"`
+	Analyzer      *string                `protobuf:"bytes,4,opt,name=analyzer,proto3,oneof" json:"analyzer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormItem) Reset() {
+	*x = NormItem{}
+	mi := &file_proto_words_words_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormItem) ProtoMessage() {}
+
+func (x *NormItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormItem.ProtoReflect.Descriptor instead.
+func (*NormItem) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NormItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NormItem) GetPhrase() string {
+	if x != nil {
+		return x.Phrase
+	}
+	return ""
+}
+
+func (x *NormItem) GetLanguage() string {
+	if x != nil && x.Language != nil {
+		return *x.Language
+	}
+	return ""
+}
+
+func (x *NormItem) GetAnalyzer() string {
+	if x != nil && x.Analyzer != nil {
+		return *x.Analyzer
+	}
+	return ""
+}
+
+type NormBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Items []*NormItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// язык для всех фраз без своего, не задан - определяется по каждой фразе
+	Language *string `protobuf:"bytes,2,opt,name=language,proto3,oneof" json:"language,omitempty"`
+	Analyzer *string `protobuf:"bytes,3,opt,name=analyzer,proto3,oneof" json:"analyzer,omitempty"`
+	// expand - слова и синонимы с весами, как в Expand
+	Expand        bool `protobuf:"varint,4,opt,name=expand,proto3" json:"expand,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormBatchRequest) Reset() {
+	*x = NormBatchRequest{}
+	mi := &file_proto_words_words_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormBatchRequest) ProtoMessage() {}
+
+func (x *NormBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormBatchRequest.ProtoReflect.Descriptor instead.
+func (*NormBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NormBatchRequest) GetItems() []*NormItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *NormBatchRequest) GetLanguage() string {
+	if x != nil && x.Language != nil {
+		return *x.Language
+	}
+	return ""
+}
+
+func (x *NormBatchRequest) GetAnalyzer() string {
+	if x != nil && x.Analyzer != nil {
+		return *x.Analyzer
+	}
+	return ""
+}
+
+func (x *NormBatchRequest) GetExpand() bool {
+	if x != nil {
+		return x.Expand
+	}
+	return false
+}
+
+// NormResult - ошибка одной фразы не роняет пакет: code - код gRPC, 0 - успех
+type NormResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Words           []string               `protobuf:"bytes,2,rep,name=words,proto3" json:"words,omitempty"`
+	Language        string                 `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	Code            uint32                 `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`
+	Error           string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Analyzer        string                 `protobuf:"bytes,6,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	AnalyzerVersion string                 `protobuf:"bytes,7,opt,name=analyzer_version,json=analyzerVersion,proto3" json:"analyzer_version,omitempty"`
+	// weights - веса words для пакета с expand: 1 у слов фразы, меньше у синонимов
+	Weights         []float64 `protobuf:"fixed64,8,rep,packed,name=weights,proto3" json:"weights,omitempty"`
+	SynonymsVersion string    `protobuf:"bytes,9,opt,name=synonyms_version,json=synonymsVersion,proto3" json:"synonyms_version,omitempty"`
+	// surfaces - исходное написание каждого из words в нижнем регистре, до стемминга
+	Surfaces      []string `protobuf:"bytes,10,rep,name=surfaces,proto3" json:"surfaces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormResult) Reset() {
+	*x = NormResult{}
+	mi := &file_proto_words_words_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormResult) ProtoMessage() {}
+
+func (x *NormResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormResult.ProtoReflect.Descriptor instead.
+func (*NormResult) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NormResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NormResult) GetWords() []string {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+func (x *NormResult) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *NormResult) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *NormResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *NormResult) GetAnalyzer() string {
+	if x != nil {
+		return x.Analyzer
+	}
+	return ""
+}
+
+func (x *NormResult) GetAnalyzerVersion() string {
+	if x != nil {
+		return x.AnalyzerVersion
+	}
+	return ""
+}
+
+func (x *NormResult) GetWeights() []float64 {
+	if x != nil {
+		return x.Weights
+	}
+	return nil
+}
+
+func (x *NormResult) GetSynonymsVersion() string {
+	if x != nil {
+		return x.SynonymsVersion
+	}
+	return ""
+}
+
+func (x *NormResult) GetSurfaces() []string {
+	if x != nil {
+		return x.Surfaces
+	}
+	return nil
+}
+
+// NormBatchReply - results в порядке items запроса
+type NormBatchReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*NormResult          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NormBatchReply) Reset() {
+	*x = NormBatchReply{}
+	mi := &file_proto_words_words_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NormBatchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormBatchReply) ProtoMessage() {}
+
+func (x *NormBatchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormBatchReply.ProtoReflect.Descriptor instead.
+func (*NormBatchReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NormBatchReply) GetResults() []*NormResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// AnalyzeToken - позиция в исходной фразе в символах, [start, end).
+// dropped - причина отсева на этой стадии, note - почему токен прошел стадию без изменений
+type AnalyzeToken struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Start         uint32                 `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           uint32                 `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+	Dropped       string                 `protobuf:"bytes,4,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	Note          string                 `protobuf:"bytes,5,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeToken) Reset() {
+	*x = AnalyzeToken{}
+	mi := &file_proto_words_words_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeToken) ProtoMessage() {}
+
+func (x *AnalyzeToken) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeToken.ProtoReflect.Descriptor instead.
+func (*AnalyzeToken) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AnalyzeToken) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *AnalyzeToken) GetStart() uint32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *AnalyzeToken) GetEnd() uint32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *AnalyzeToken) GetDropped() string {
+	if x != nil {
+		return x.Dropped
+	}
+	return ""
+}
+
+func (x *AnalyzeToken) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type AnalyzeStage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Tokens        []*AnalyzeToken        `protobuf:"bytes,2,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeStage) Reset() {
+	*x = AnalyzeStage{}
+	mi := &file_proto_words_words_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeStage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeStage) ProtoMessage() {}
+
+func (x *AnalyzeStage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeStage.ProtoReflect.Descriptor instead.
+func (*AnalyzeStage) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AnalyzeStage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AnalyzeStage) GetTokens() []*AnalyzeToken {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+// AnalyzeReply - стадии в порядке применения, words совпадает с ответом Norm
+type AnalyzeReply struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Language        string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	Stages          []*AnalyzeStage        `protobuf:"bytes,2,rep,name=stages,proto3" json:"stages,omitempty"`
+	Words           []string               `protobuf:"bytes,3,rep,name=words,proto3" json:"words,omitempty"`
+	Analyzer        string                 `protobuf:"bytes,4,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	AnalyzerVersion string                 `protobuf:"bytes,5,opt,name=analyzer_version,json=analyzerVersion,proto3" json:"analyzer_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AnalyzeReply) Reset() {
+	*x = AnalyzeReply{}
+	mi := &file_proto_words_words_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeReply) ProtoMessage() {}
+
+func (x *AnalyzeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeReply.ProtoReflect.Descriptor instead.
+func (*AnalyzeReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AnalyzeReply) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *AnalyzeReply) GetStages() []*AnalyzeStage {
+	if x != nil {
+		return x.Stages
+	}
+	return nil
+}
+
+func (x *AnalyzeReply) GetWords() []string {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+func (x *AnalyzeReply) GetAnalyzer() string {
+	if x != nil {
+		return x.Analyzer
+	}
+	return ""
+}
+
+func (x *AnalyzeReply) GetAnalyzerVersion() string {
+	if x != nil {
+		return x.AnalyzerVersion
+	}
+	return ""
+}
+
+type AnalyzerInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Filters       []string               `protobuf:"bytes,2,rep,name=filters,proto3" json:"filters,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzerInfo) Reset() {
+	*x = AnalyzerInfo{}
+	mi := &file_proto_words_words_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzerInfo) ProtoMessage() {}
+
+func (x *AnalyzerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzerInfo.ProtoReflect.Descriptor instead.
+func (*AnalyzerInfo) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AnalyzerInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AnalyzerInfo) GetFilters() []string {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *AnalyzerInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// VocabularyReply - настройки развертывания поверх стоп-слов snowball
+type VocabularyReply struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	StopWordsAdded   []string               `protobuf:"bytes,1,rep,name=stop_words_added,json=stopWordsAdded,proto3" json:"stop_words_added,omitempty"`
+	StopWordsRemoved []string               `protobuf:"bytes,2,rep,name=stop_words_removed,json=stopWordsRemoved,proto3" json:"stop_words_removed,omitempty"`
+	ProtectedTerms   []string               `protobuf:"bytes,3,rep,name=protected_terms,json=protectedTerms,proto3" json:"protected_terms,omitempty"`
+	Languages        []string               `protobuf:"bytes,4,rep,name=languages,proto3" json:"languages,omitempty"`
+	Analyzers        []*AnalyzerInfo        `protobuf:"bytes,5,rep,name=analyzers,proto3" json:"analyzers,omitempty"`
+	// dictionary_version - версия словаря Correct из SetDictionary, пустая - словарь не загружен
+	DictionaryVersion string `protobuf:"bytes,6,opt,name=dictionary_version,json=dictionaryVersion,proto3" json:"dictionary_version,omitempty"`
+	// synonyms_version - как в ExpandReply: по ней и версиям цепочек клиенты сбрасывают кэши,
+	// не дожидаясь промаха
+	SynonymsVersion string `protobuf:"bytes,7,opt,name=synonyms_version,json=synonymsVersion,proto3" json:"synonyms_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *VocabularyReply) Reset() {
+	*x = VocabularyReply{}
+	mi := &file_proto_words_words_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VocabularyReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VocabularyReply) ProtoMessage() {}
+
+func (x *VocabularyReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VocabularyReply.ProtoReflect.Descriptor instead.
+func (*VocabularyReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *VocabularyReply) GetStopWordsAdded() []string {
+	if x != nil {
+		return x.StopWordsAdded
+	}
+	return nil
+}
+
+func (x *VocabularyReply) GetStopWordsRemoved() []string {
+	if x != nil {
+		return x.StopWordsRemoved
+	}
+	return nil
+}
+
+func (x *VocabularyReply) GetProtectedTerms() []string {
+	if x != nil {
+		return x.ProtectedTerms
+	}
+	return nil
+}
+
+func (x *VocabularyReply) GetLanguages() []string {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *VocabularyReply) GetAnalyzers() []*AnalyzerInfo {
+	if x != nil {
+		return x.Analyzers
+	}
+	return nil
+}
+
+func (x *VocabularyReply) GetDictionaryVersion() string {
+	if x != nil {
+		return x.DictionaryVersion
+	}
+	return ""
+}
+
+func (x *VocabularyReply) GetSynonymsVersion() string {
+	if x != nil {
+		return x.SynonymsVersion
+	}
+	return ""
+}
+
+// DictionaryTerm - термин корпуса и число комиксов, в которых он встречается
+type DictionaryTerm struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Term  string                 `protobuf:"bytes,1,opt,name=term,proto3" json:"term,omitempty"`
+	Count uint32                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// surface - самое частое исходное написание термина в корпусе, им Correct пишет исправленную фразу
+	Surface       string `protobuf:"bytes,3,opt,name=surface,proto3" json:"surface,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DictionaryTerm) Reset() {
+	*x = DictionaryTerm{}
+	mi := &file_proto_words_words_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DictionaryTerm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DictionaryTerm) ProtoMessage() {}
+
+func (x *DictionaryTerm) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DictionaryTerm.ProtoReflect.Descriptor instead.
+func (*DictionaryTerm) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DictionaryTerm) GetTerm() string {
+	if x != nil {
+		return x.Term
+	}
+	return ""
+}
+
+func (x *DictionaryTerm) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *DictionaryTerm) GetSurface() string {
+	if x != nil {
+		return x.Surface
+	}
+	return ""
+}
+
+// DictionaryRequest - словарь целиком; analyzer - цепочка, которой получены термины
+type DictionaryRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Terms    []*DictionaryTerm      `protobuf:"bytes,1,rep,name=terms,proto3" json:"terms,omitempty"`
+	Analyzer string                 `protobuf:"bytes,2,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	// version - по ней search находит реплики со старым словарем или без него после рестарта
+	Version       string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DictionaryRequest) Reset() {
+	*x = DictionaryRequest{}
+	mi := &file_proto_words_words_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DictionaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DictionaryRequest) ProtoMessage() {}
+
+func (x *DictionaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DictionaryRequest.ProtoReflect.Descriptor instead.
+func (*DictionaryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DictionaryRequest) GetTerms() []*DictionaryTerm {
+	if x != nil {
+		return x.Terms
+	}
+	return nil
+}
+
+func (x *DictionaryRequest) GetAnalyzer() string {
+	if x != nil {
+		return x.Analyzer
+	}
+	return ""
+}
+
+func (x *DictionaryRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type DictionaryReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Terms         uint32                 `protobuf:"varint,1,opt,name=terms,proto3" json:"terms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DictionaryReply) Reset() {
+	*x = DictionaryReply{}
+	mi := &file_proto_words_words_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DictionaryReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DictionaryReply) ProtoMessage() {}
+
+func (x *DictionaryReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DictionaryReply.ProtoReflect.Descriptor instead.
+func (*DictionaryReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DictionaryReply) GetTerms() uint32 {
+	if x != nil {
+		return x.Terms
+	}
+	return 0
+}
+
+type Suggestion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Term          string                 `protobuf:"bytes,1,opt,name=term,proto3" json:"term,omitempty"`
+	Distance      uint32                 `protobuf:"varint,2,opt,name=distance,proto3" json:"distance,omitempty"`
+	Count         uint32                 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Suggestion) Reset() {
+	*x = Suggestion{}
+	mi := &file_proto_words_words_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Suggestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suggestion) ProtoMessage() {}
+
+func (x *Suggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suggestion.ProtoReflect.Descriptor instead.
+func (*Suggestion) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Suggestion) GetTerm() string {
+	if x != nil {
+		return x.Term
+	}
+	return ""
+}
+
+func (x *Suggestion) GetDistance() uint32 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *Suggestion) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type TokenCorrection struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Term          string                 `protobuf:"bytes,2,opt,name=term,proto3" json:"term,omitempty"`
+	Known         bool                   `protobuf:"varint,3,opt,name=known,proto3" json:"known,omitempty"`
+	Suggestions   []*Suggestion          `protobuf:"bytes,4,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenCorrection) Reset() {
+	*x = TokenCorrection{}
+	mi := &file_proto_words_words_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenCorrection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenCorrection) ProtoMessage() {}
+
+func (x *TokenCorrection) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenCorrection.ProtoReflect.Descriptor instead.
+func (*TokenCorrection) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TokenCorrection) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *TokenCorrection) GetTerm() string {
+	if x != nil {
+		return x.Term
+	}
+	return ""
+}
+
+func (x *TokenCorrection) GetKnown() bool {
+	if x != nil {
+		return x.Known
+	}
+	return false
+}
+
+func (x *TokenCorrection) GetSuggestions() []*Suggestion {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+// CorrectReply - phrase пустой, если исправлять нечего
+type CorrectReply struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Tokens          []*TokenCorrection     `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	Phrase          string                 `protobuf:"bytes,2,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	Language        string                 `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	Analyzer        string                 `protobuf:"bytes,4,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	AnalyzerVersion string                 `protobuf:"bytes,5,opt,name=analyzer_version,json=analyzerVersion,proto3" json:"analyzer_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CorrectReply) Reset() {
+	*x = CorrectReply{}
+	mi := &file_proto_words_words_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CorrectReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CorrectReply) ProtoMessage() {}
+
+func (x *CorrectReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_words_words_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CorrectReply.ProtoReflect.Descriptor instead.
+func (*CorrectReply) Descriptor() ([]byte, []int) {
+	return file_proto_words_words_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CorrectReply) GetTokens() []*TokenCorrection {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *CorrectReply) GetPhrase() string {
+	if x != nil {
+		return x.Phrase
+	}
+	return ""
+}
+
+func (x *CorrectReply) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *CorrectReply) GetAnalyzer() string {
+	if x != nil {
+		return x.Analyzer
+	}
+	return ""
+}
+
+func (x *CorrectReply) GetAnalyzerVersion() string {
+	if x != nil {
+		return x.AnalyzerVersion
+	}
+	return ""
+}
+
+var File_proto_words_words_proto protoreflect.FileDescriptor
+
+const file_proto_words_words_proto_rawDesc = "" +
+	"\n" +
+	"\x17proto/words/words.proto\x12\x05words\x1a\x1bgoogle/protobuf/empty.proto\"\x82\x01\n" +
+	"\fWordsRequest\x12\x16\n" +
+	"\x06phrase\x18\x01 \x01(\tR\x06phrase\x12\x1f\n" +
+	"\blanguage\x18\x02 \x01(\tH\x00R\blanguage\x88\x01\x01\x12\x1f\n" +
+	"\banalyzer\x18\x03 \x01(\tH\x01R\banalyzer\x88\x01\x01B\v\n" +
+	"\t_languageB\v\n" +
+	"\t_analyzer\"\x85\x01\n" +
+	"\n" +
+	"WordsReply\x12\x14\n" +
+	"\x05words\x18\x01 \x03(\tR\x05words\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x1a\n" +
+	"\banalyzer\x18\x03 \x01(\tR\banalyzer\x12)\n" +
+	"\x10analyzer_version\x18\x04 \x01(\tR\x0fanalyzerVersion\":\n" +
+	"\fWeightedWord\x12\x12\n" +
+	"\x04word\x18\x01 \x01(\tR\x04word\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x01R\x06weight\"\xc6\x01\n" +
+	"\vExpandReply\x12)\n" +
+	"\x05words\x18\x01 \x03(\v2\x13.words.WeightedWordR\x05words\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x1a\n" +
+	"\banalyzer\x18\x03 \x01(\tR\banalyzer\x12)\n" +
+	"\x10analyzer_version\x18\x04 \x01(\tR\x0fanalyzerVersion\x12)\n" +
+	"\x10synonyms_version\x18\x05 \x01(\tR\x0fsynonymsVersion\"\x8e\x01\n" +
+	"\bNormItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06phrase\x18\x02 \x01(\tR\x06phrase\x12\x1f\n" +
+	"\blanguage\x18\x03 \x01(\tH\x00R\blanguage\x88\x01\x01\x12\x1f\n" +
+	"\banalyzer\x18\x04 \x01(\tH\x01R\banalyzer\x88\x01\x01B\v\n" +
+	"\t_languageB\v\n" +
+	"\t_analyzer\"\xad\x01\n" +
+	"\x10NormBatchRequest\x12%\n" +
+	"\x05items\x18\x01 \x03(\v2\x0f.words.NormItemR\x05items\x12\x1f\n" +
+	"\blanguage\x18\x02 \x01(\tH\x00R\blanguage\x88\x01\x01\x12\x1f\n" +
+	"\banalyzer\x18\x03 \x01(\tH\x01R\banalyzer\x88\x01\x01\x12\x16\n" +
+	"\x06expand\x18\x04 \x01(\bR\x06expandB\v\n" +
+	"\t_languageB\v\n" +
+	"\t_analyzer\"\xa0\x02\n" +
+	"\n" +
+	"NormResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05words\x18\x02 \x03(\tR\x05words\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\rR\x04code\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x1a\n" +
+	"\banalyzer\x18\x06 \x01(\tR\banalyzer\x12)\n" +
+	"\x10analyzer_version\x18\a \x01(\tR\x0fanalyzerVersion\x12\x18\n" +
+	"\aweights\x18\b \x03(\x01R\aweights\x12)\n" +
+	"\x10synonyms_version\x18\t \x01(\tR\x0fsynonymsVersion\x12\x1a\n" +
+	"\bsurfaces\x18\n" +
+	" \x03(\tR\bsurfaces\"=\n" +
+	"\x0eNormBatchReply\x12+\n" +
+	"\aresults\x18\x01 \x03(\v2\x11.words.NormResultR\aresults\"x\n" +
+	"\fAnalyzeToken\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\rR\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\rR\x03end\x12\x18\n" +
+	"\adropped\x18\x04 \x01(\tR\adropped\x12\x12\n" +
+	"\x04note\x18\x05 \x01(\tR\x04note\"O\n" +
+	"\fAnalyzeStage\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12+\n" +
+	"\x06tokens\x18\x02 \x03(\v2\x13.words.AnalyzeTokenR\x06tokens\"\xb4\x01\n" +
+	"\fAnalyzeReply\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12+\n" +
+	"\x06stages\x18\x02 \x03(\v2\x13.words.AnalyzeStageR\x06stages\x12\x14\n" +
+	"\x05words\x18\x03 \x03(\tR\x05words\x12\x1a\n" +
+	"\banalyzer\x18\x04 \x01(\tR\banalyzer\x12)\n" +
+	"\x10analyzer_version\x18\x05 \x01(\tR\x0fanalyzerVersion\"V\n" +
+	"\fAnalyzerInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\afilters\x18\x02 \x03(\tR\afilters\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"\xbd\x02\n" +
+	"\x0fVocabularyReply\x12(\n" +
+	"\x10stop_words_added\x18\x01 \x03(\tR\x0estopWordsAdded\x12,\n" +
+	"\x12stop_words_removed\x18\x02 \x03(\tR\x10stopWordsRemoved\x12'\n" +
+	"\x0fprotected_terms\x18\x03 \x03(\tR\x0eprotectedTerms\x12\x1c\n" +
+	"\tlanguages\x18\x04 \x03(\tR\tlanguages\x121\n" +
+	"\tanalyzers\x18\x05 \x03(\v2\x13.words.AnalyzerInfoR\tanalyzers\x12-\n" +
+	"\x12dictionary_version\x18\x06 \x01(\tR\x11dictionaryVersion\x12)\n" +
+	"\x10synonyms_version\x18\a \x01(\tR\x0fsynonymsVersion\"T\n" +
+	"\x0eDictionaryTerm\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\tR\x04term\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\rR\x05count\x12\x18\n" +
+	"\asurface\x18\x03 \x01(\tR\asurface\"v\n" +
+	"\x11DictionaryRequest\x12+\n" +
+	"\x05terms\x18\x01 \x03(\v2\x15.words.DictionaryTermR\x05terms\x12\x1a\n" +
+	"\banalyzer\x18\x02 \x01(\tR\banalyzer\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"'\n" +
+	"\x0fDictionaryReply\x12\x14\n" +
+	"\x05terms\x18\x01 \x01(\rR\x05terms\"R\n" +
+	"\n" +
+	"Suggestion\x12\x12\n" +
+	"\x04term\x18\x01 \x01(\tR\x04term\x12\x1a\n" +
+	"\bdistance\x18\x02 \x01(\rR\bdistance\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\rR\x05count\"\x86\x01\n" +
+	"\x0fTokenCorrection\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x12\n" +
+	"\x04term\x18\x02 \x01(\tR\x04term\x12\x14\n" +
+	"\x05known\x18\x03 \x01(\bR\x05known\x123\n" +
+	"\vsuggestions\x18\x04 \x03(\v2\x11.words.SuggestionR\vsuggestions\"\xb9\x01\n" +
+	"\fCorrectReply\x12.\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x16.words.TokenCorrectionR\x06tokens\x12\x16\n" +
+	"\x06phrase\x18\x02 \x01(\tR\x06phrase\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\x12\x1a\n" +
+	"\banalyzer\x18\x04 \x01(\tR\banalyzer\x12)\n" +
+	"\x10analyzer_version\x18\x05 \x01(\tR\x0fanalyzerVersion2\x92\x04\n" +
+	"\x05Words\x128\n" +
+	"\x04Ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x00\x120\n" +
+	"\x04Norm\x12\x13.words.WordsRequest\x1a\x11.words.WordsReply\"\x00\x12=\n" +
+	"\tNormBatch\x12\x17.words.NormBatchRequest\x1a\x15.words.NormBatchReply\"\x00\x126\n" +
+	"\n" +
+	"NormStream\x12\x0f.words.NormItem\x1a\x11.words.NormResult\"\x00(\x010\x01\x123\n" +
+	"\x06Expand\x12\x13.words.WordsRequest\x1a\x12.words.ExpandReply\"\x00\x125\n" +
+	"\aAnalyze\x12\x13.words.WordsRequest\x1a\x13.words.AnalyzeReply\"\x00\x12>\n" +
+	"\n" +
+	"Vocabulary\x12\x16.google.protobuf.Empty\x1a\x16.words.VocabularyReply\"\x00\x12C\n" +
+	"\rSetDictionary\x12\x18.words.DictionaryRequest\x1a\x16.words.DictionaryReply\"\x00\x125\n" +
+	"\aCorrect\x12\x13.words.WordsRequest\x1a\x13.words.CorrectReply\"\x00B\x1eZ\x1cyadro.com/course/proto/wordsb\x06proto3"
 
 var (
 	file_proto_words_words_proto_rawDescOnce sync.Once
@@ -136,22 +1456,62 @@ func file_proto_words_words_proto_rawDescGZIP() []byte {
 	return file_proto_words_words_proto_rawDescData
 }
 
-var file_proto_words_words_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_words_words_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_proto_words_words_proto_goTypes = []any{
-	(*WordsRequest)(nil),  // 0: words.WordsRequest
-	(*WordsReply)(nil),    // 1: words.WordsReply
-	(*emptypb.Empty)(nil), // 2: google.protobuf.Empty
+	(*WordsRequest)(nil),      // 0: words.WordsRequest
+	(*WordsReply)(nil),        // 1: words.WordsReply
+	(*WeightedWord)(nil),      // 2: words.WeightedWord
+	(*ExpandReply)(nil),       // 3: words.ExpandReply
+	(*NormItem)(nil),          // 4: words.NormItem
+	(*NormBatchRequest)(nil),  // 5: words.NormBatchRequest
+	(*NormResult)(nil),        // 6: words.NormResult
+	(*NormBatchReply)(nil),    // 7: words.NormBatchReply
+	(*AnalyzeToken)(nil),      // 8: words.AnalyzeToken
+	(*AnalyzeStage)(nil),      // 9: words.AnalyzeStage
+	(*AnalyzeReply)(nil),      // 10: words.AnalyzeReply
+	(*AnalyzerInfo)(nil),      // 11: words.AnalyzerInfo
+	(*VocabularyReply)(nil),   // 12: words.VocabularyReply
+	(*DictionaryTerm)(nil),    // 13: words.DictionaryTerm
+	(*DictionaryRequest)(nil), // 14: words.DictionaryRequest
+	(*DictionaryReply)(nil),   // 15: words.DictionaryReply
+	(*Suggestion)(nil),        // 16: words.Suggestion
+	(*TokenCorrection)(nil),   // 17: words.TokenCorrection
+	(*CorrectReply)(nil),      // 18: words.CorrectReply
+	(*emptypb.Empty)(nil),     // 19: google.protobuf.Empty
 }
 var file_proto_words_words_proto_depIdxs = []int32{
-	2, // 0: words.Words.Ping:input_type -> google.protobuf.Empty
-	0, // 1: words.Words.Norm:input_type -> words.WordsRequest
-	2, // 2: words.Words.Ping:output_type -> google.protobuf.Empty
-	1, // 3: words.Words.Norm:output_type -> words.WordsReply
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2,  // 0: words.ExpandReply.words:type_name -> words.WeightedWord
+	4,  // 1: words.NormBatchRequest.items:type_name -> words.NormItem
+	6,  // 2: words.NormBatchReply.results:type_name -> words.NormResult
+	8,  // 3: words.AnalyzeStage.tokens:type_name -> words.AnalyzeToken
+	9,  // 4: words.AnalyzeReply.stages:type_name -> words.AnalyzeStage
+	11, // 5: words.VocabularyReply.analyzers:type_name -> words.AnalyzerInfo
+	13, // 6: words.DictionaryRequest.terms:type_name -> words.DictionaryTerm
+	16, // 7: words.TokenCorrection.suggestions:type_name -> words.Suggestion
+	17, // 8: words.CorrectReply.tokens:type_name -> words.TokenCorrection
+	19, // 9: words.Words.Ping:input_type -> google.protobuf.Empty
+	0,  // 10: words.Words.Norm:input_type -> words.WordsRequest
+	5,  // 11: words.Words.NormBatch:input_type -> words.NormBatchRequest
+	4,  // 12: words.Words.NormStream:input_type -> words.NormItem
+	0,  // 13: words.Words.Expand:input_type -> words.WordsRequest
+	0,  // 14: words.Words.Analyze:input_type -> words.WordsRequest
+	19, // 15: words.Words.Vocabulary:input_type -> google.protobuf.Empty
+	14, // 16: words.Words.SetDictionary:input_type -> words.DictionaryRequest
+	0,  // 17: words.Words.Correct:input_type -> words.WordsRequest
+	19, // 18: words.Words.Ping:output_type -> google.protobuf.Empty
+	1,  // 19: words.Words.Norm:output_type -> words.WordsReply
+	7,  // 20: words.Words.NormBatch:output_type -> words.NormBatchReply
+	6,  // 21: words.Words.NormStream:output_type -> words.NormResult
+	3,  // 22: words.Words.Expand:output_type -> words.ExpandReply
+	10, // 23: words.Words.Analyze:output_type -> words.AnalyzeReply
+	12, // 24: words.Words.Vocabulary:output_type -> words.VocabularyReply
+	15, // 25: words.Words.SetDictionary:output_type -> words.DictionaryReply
+	18, // 26: words.Words.Correct:output_type -> words.CorrectReply
+	18, // [18:27] is the sub-list for method output_type
+	9,  // [9:18] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_words_words_proto_init() }
@@ -159,13 +1519,16 @@ func file_proto_words_words_proto_init() {
 	if File_proto_words_words_proto != nil {
 		return
 	}
+	file_proto_words_words_proto_msgTypes[0].OneofWrappers = []any{}
+	file_proto_words_words_proto_msgTypes[4].OneofWrappers = []any{}
+	file_proto_words_words_proto_msgTypes[5].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_words_words_proto_rawDesc), len(file_proto_words_words_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,8 +20,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Words_Ping_FullMethodName = "/words.Words/Ping"
-	Words_Norm_FullMethodName = "/words.Words/Norm"
+	Words_Ping_FullMethodName          = "/words.Words/Ping"
+	Words_Norm_FullMethodName          = "/words.Words/Norm"
+	Words_NormBatch_FullMethodName     = "/words.Words/NormBatch"
+	Words_NormStream_FullMethodName    = "/words.Words/NormStream"
+	Words_Expand_FullMethodName        = "/words.Words/Expand"
+	Words_Analyze_FullMethodName       = "/words.Words/Analyze"
+	Words_Vocabulary_FullMethodName    = "/words.Words/Vocabulary"
+	Words_SetDictionary_FullMethodName = "/words.Words/SetDictionary"
+	Words_Correct_FullMethodName       = "/words.Words/Correct"
 )
 
 // WordsClient is the client API for Words service.
@@ -30,6 +37,13 @@ const (
 type WordsClient interface {
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Norm(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*WordsReply, error)
+	NormBatch(ctx context.Context, in *NormBatchRequest, opts ...grpc.CallOption) (*NormBatchReply, error)
+	NormStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[NormItem, NormResult], error)
+	Expand(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*ExpandReply, error)
+	Analyze(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*AnalyzeReply, error)
+	Vocabulary(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VocabularyReply, error)
+	SetDictionary(ctx context.Context, in *DictionaryRequest, opts ...grpc.CallOption) (*DictionaryReply, error)
+	Correct(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*CorrectReply, error)
 }
 
 type wordsClient struct {
@@ -60,12 +74,92 @@ func (c *wordsClient) Norm(ctx context.Context, in *WordsRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *wordsClient) NormBatch(ctx context.Context, in *NormBatchRequest, opts ...grpc.CallOption) (*NormBatchReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NormBatchReply)
+	err := c.cc.Invoke(ctx, Words_NormBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) NormStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[NormItem, NormResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Words_ServiceDesc.Streams[0], Words_NormStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[NormItem, NormResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Words_NormStreamClient = grpc.BidiStreamingClient[NormItem, NormResult]
+
+func (c *wordsClient) Expand(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*ExpandReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExpandReply)
+	err := c.cc.Invoke(ctx, Words_Expand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) Analyze(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*AnalyzeReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalyzeReply)
+	err := c.cc.Invoke(ctx, Words_Analyze_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) Vocabulary(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VocabularyReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VocabularyReply)
+	err := c.cc.Invoke(ctx, Words_Vocabulary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) SetDictionary(ctx context.Context, in *DictionaryRequest, opts ...grpc.CallOption) (*DictionaryReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DictionaryReply)
+	err := c.cc.Invoke(ctx, Words_SetDictionary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *wordsClient) Correct(ctx context.Context, in *WordsRequest, opts ...grpc.CallOption) (*CorrectReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CorrectReply)
+	err := c.cc.Invoke(ctx, Words_Correct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WordsServer is the server API for Words service.
 // All implementations must embed UnimplementedWordsServer
 // for forward compatibility.
 type WordsServer interface {
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	Norm(context.Context, *WordsRequest) (*WordsReply, error)
+	NormBatch(context.Context, *NormBatchRequest) (*NormBatchReply, error)
+	NormStream(grpc.BidiStreamingServer[NormItem, NormResult]) error
+	Expand(context.Context, *WordsRequest) (*ExpandReply, error)
+	Analyze(context.Context, *WordsRequest) (*AnalyzeReply, error)
+	Vocabulary(context.Context, *emptypb.Empty) (*VocabularyReply, error)
+	SetDictionary(context.Context, *DictionaryRequest) (*DictionaryReply, error)
+	Correct(context.Context, *WordsRequest) (*CorrectReply, error)
 	mustEmbedUnimplementedWordsServer()
 }
 
@@ -82,6 +176,27 @@ func (UnimplementedWordsServer) Ping(context.Context, *emptypb.Empty) (*emptypb.
 func (UnimplementedWordsServer) Norm(context.Context, *WordsRequest) (*WordsReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Norm not implemented")
 }
+func (UnimplementedWordsServer) NormBatch(context.Context, *NormBatchRequest) (*NormBatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NormBatch not implemented")
+}
+func (UnimplementedWordsServer) NormStream(grpc.BidiStreamingServer[NormItem, NormResult]) error {
+	return status.Errorf(codes.Unimplemented, "method NormStream not implemented")
+}
+func (UnimplementedWordsServer) Expand(context.Context, *WordsRequest) (*ExpandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Expand not implemented")
+}
+func (UnimplementedWordsServer) Analyze(context.Context, *WordsRequest) (*AnalyzeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Analyze not implemented")
+}
+func (UnimplementedWordsServer) Vocabulary(context.Context, *emptypb.Empty) (*VocabularyReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Vocabulary not implemented")
+}
+func (UnimplementedWordsServer) SetDictionary(context.Context, *DictionaryRequest) (*DictionaryReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDictionary not implemented")
+}
+func (UnimplementedWordsServer) Correct(context.Context, *WordsRequest) (*CorrectReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Correct not implemented")
+}
 func (UnimplementedWordsServer) mustEmbedUnimplementedWordsServer() {}
 func (UnimplementedWordsServer) testEmbeddedByValue()               {}
 
@@ -139,6 +254,121 @@ func _Words_Norm_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Words_NormBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NormBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).NormBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_NormBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).NormBatch(ctx, req.(*NormBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_NormStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WordsServer).NormStream(&grpc.GenericServerStream[NormItem, NormResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Words_NormStreamServer = grpc.BidiStreamingServer[NormItem, NormResult]
+
+func _Words_Expand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).Expand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_Expand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).Expand(ctx, req.(*WordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_Analyze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).Analyze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_Analyze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).Analyze(ctx, req.(*WordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_Vocabulary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).Vocabulary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_Vocabulary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).Vocabulary(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_SetDictionary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DictionaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).SetDictionary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_SetDictionary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).SetDictionary(ctx, req.(*DictionaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Words_Correct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WordsServer).Correct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Words_Correct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WordsServer).Correct(ctx, req.(*WordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Words_ServiceDesc is the grpc.ServiceDesc for Words service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -154,7 +384,38 @@ var Words_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Norm",
 			Handler:    _Words_Norm_Handler,
 		},
+		{
+			MethodName: "NormBatch",
+			Handler:    _Words_NormBatch_Handler,
+		},
+		{
+			MethodName: "Expand",
+			Handler:    _Words_Expand_Handler,
+		},
+		{
+			MethodName: "Analyze",
+			Handler:    _Words_Analyze_Handler,
+		},
+		{
+			MethodName: "Vocabulary",
+			Handler:    _Words_Vocabulary_Handler,
+		},
+		{
+			MethodName: "SetDictionary",
+			Handler:    _Words_SetDictionary_Handler,
+		},
+		{
+			MethodName: "Correct",
+			Handler:    _Words_Correct_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "NormStream",
+			Handler:       _Words_NormStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/words/words.proto",
 }
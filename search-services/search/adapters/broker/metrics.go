@@ -0,0 +1,12 @@
+package broker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// consumed - result: ok или error, если обработка сообщения (пересборка индекса) упала
+var consumed = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "nats_consumed_total",
+	Help: "NATS messages consumed, by subject and processing result.",
+}, []string{"subject", "result"})
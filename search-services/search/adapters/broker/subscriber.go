@@ -4,10 +4,17 @@ import (
 	"context"
 	"github.com/nats-io/nats.go"
 	"log/slog"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"yadro.com/course/pkg/requestid"
+	"yadro.com/course/pkg/tracing"
+	"yadro.com/course/search/core"
 )
 
 type IndexUpdater interface {
-	RebuildIndex(ctx context.Context) error
+	RebuildIndex(ctx context.Context, trigger core.IndexTrigger) error
 }
 
 type Subscriber struct {
@@ -36,6 +43,11 @@ func (s *Subscriber) Close() {
 	s.nc.Close()
 }
 
+// Ping - соединение с NATS живо: сервер отвечает на PING
+func (s *Subscriber) Ping(ctx context.Context) error {
+	return s.nc.FlushWithContext(ctx)
+}
+
 func (s *Subscriber) Start(ctx context.Context) error {
 	ch := make(chan *nats.Msg, 10)
 
@@ -62,17 +74,42 @@ func (s *Subscriber) Start(ctx context.Context) error {
 					return
 				}
 
-				s.log.Info("got db updated event, rebuilding index",
-					"subject", s.subject,
-					"data", msg.Data,
-				)
-
-				if err := s.service.RebuildIndex(ctx); err != nil {
-					s.log.Error("rebuild index failed", "error", err)
-				}
+				s.handle(messageContext(ctx, msg), msg)
 			}
 		}
 	}()
 
 	return nil
 }
+
+// handle - пересборка индекса по событию; спан consumer продолжает трейс из заголовков
+func (s *Subscriber) handle(ctx context.Context, msg *nats.Msg) {
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, msg.Header), "process "+s.subject,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(semconv.MessagingSystemKey.String("nats"), semconv.MessagingDestinationName(s.subject)),
+	)
+	defer span.End()
+
+	s.log.InfoContext(ctx, "got db updated event, rebuilding index",
+		"subject", s.subject,
+		"data", msg.Data,
+	)
+
+	if err := s.service.RebuildIndex(ctx, core.TriggerBroker); err != nil {
+		consumed.WithLabelValues(s.subject, "error").Inc()
+		span.SetStatus(codes.Error, err.Error())
+		s.log.ErrorContext(ctx, "rebuild index failed", "error", err)
+		return
+	}
+	consumed.WithLabelValues(s.subject, "ok").Inc()
+}
+
+// messageContext - id запроса из заголовка сообщения (его ставит update); у старых
+// публикаций без заголовка генерируется свой
+func messageContext(ctx context.Context, msg *nats.Msg) context.Context {
+	id := msg.Header.Get(requestid.Header)
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+	return requestid.WithID(ctx, id)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DailyPopularity - обычно один SELECT; снимка на day нет и popularity не пустая - INSERT.
+// Первый записанный снимок выигрывает: INSERT другой реплики с той же датой ничего не меняет,
+// а повторный SELECT видит победителя
+func (db *DB) DailyPopularity(ctx context.Context, day string, popularity map[int]int) (map[int]int, error) {
+	const insert = `
+		INSERT INTO daily_popularity (day, popularity)
+		VALUES ($1::date, $2)
+		ON CONFLICT (day) DO NOTHING;
+	`
+
+	snapshot, ok, err := db.loadDailyPopularity(ctx, day)
+	if err != nil || ok || len(popularity) == 0 {
+		return snapshot, err
+	}
+
+	body, err := json.Marshal(popularity)
+	if err != nil {
+		return nil, fmt.Errorf("marshal daily popularity: %w", err)
+	}
+	if _, err := db.conn.ExecContext(ctx, insert, day, body); err != nil {
+		db.log.Error("save daily popularity failed", "day", day, "error", err)
+		return nil, fmt.Errorf("save daily popularity: %w", err)
+	}
+
+	snapshot, _, err = db.loadDailyPopularity(ctx, day)
+	return snapshot, err
+}
+
+func (db *DB) loadDailyPopularity(ctx context.Context, day string) (map[int]int, bool, error) {
+	const selectQ = `SELECT popularity FROM daily_popularity WHERE day = $1::date;`
+
+	var stored []byte
+	if err := db.conn.GetContext(ctx, &stored, selectQ, day); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		db.log.Error("load daily popularity failed", "day", day, "error", err)
+		return nil, false, fmt.Errorf("load daily popularity: %w", err)
+	}
+	var snapshot map[int]int
+	if err := json.Unmarshal(stored, &snapshot); err != nil {
+		return nil, false, fmt.Errorf("unmarshal daily popularity: %w", err)
+	}
+	return snapshot, true, nil
+}
@@ -0,0 +1,48 @@
+package db_test
+
+import (
+	"context"
+	"maps"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestDailyPopularity_FirstWins(t *testing.T) {
+	storage := prepareDB(t)
+	ctx := context.Background()
+
+	if err := storage.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// снимки не удаляются, поэтому у каждого запуска своя дата в прошлом
+	day := time.Now().AddDate(-1-rand.IntN(1000), 0, -rand.IntN(365)).Format(time.DateOnly)
+	first := map[int]int{5: 2, 7: 1}
+
+	// без популярности только чтение: снимка нет - nil, и ничего не записано
+	got, err := storage.DailyPopularity(ctx, day, nil)
+	if err != nil {
+		t.Fatalf("daily popularity: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("snapshot = %v, want nil before the first write", got)
+	}
+
+	got, err = storage.DailyPopularity(ctx, day, first)
+	if err != nil {
+		t.Fatalf("daily popularity: %v", err)
+	}
+	if !maps.Equal(got, first) {
+		t.Fatalf("snapshot = %v, want %v", got, first)
+	}
+
+	// другая реплика позже за ту же дату получает снимок первой
+	got, err = storage.DailyPopularity(ctx, day, map[int]int{5: 2, 7: 1, 9: 4})
+	if err != nil {
+		t.Fatalf("daily popularity: %v", err)
+	}
+	if !maps.Equal(got, first) {
+		t.Fatalf("second snapshot = %v, want the first %v", got, first)
+	}
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/pgx"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationsTable - версия схемы search. Таблица comics принадлежит update,
+// его schema_migrations в общей БД уже занята, поэтому у search своя таблица версий
+const migrationsTable = "search_schema_migrations"
+
+// Migrate применяет миграции search-сервиса
+func (db *DB) Migrate() error {
+	db.log.Debug("running search migrations")
+	files, err := iofs.New(migrationFiles, "migrations")
+	if err != nil {
+		return err
+	}
+	driver, err := pgx.WithInstance(db.conn.DB, &pgx.Config{MigrationsTable: migrationsTable})
+	if err != nil {
+		return err
+	}
+	m, err := migrate.NewWithInstance("iofs", files, "pgx", driver)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Up(); err != nil {
+		if !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("apply search migrations: %w", err)
+		}
+		db.log.Debug("search migrations did not change anything")
+	}
+
+	db.log.Debug("search migrations finished")
+	return nil
+}
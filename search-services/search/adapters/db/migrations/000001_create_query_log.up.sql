@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS query_log (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL,
+    endpoint TEXT NOT NULL,
+    phrase TEXT NOT NULL,
+    query TEXT NOT NULL,
+    tokens TEXT[] NOT NULL,
+    results INTEGER NOT NULL,
+    latency_us BIGINT NOT NULL,
+    user_id BIGINT,
+    cached BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX IF NOT EXISTS idx_query_log_created_at ON query_log(created_at);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS daily_popularity (
+    day DATE PRIMARY KEY,
+    popularity JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
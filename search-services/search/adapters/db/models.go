@@ -1,6 +1,12 @@
 package db
 
-import "github.com/lib/pq"
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"yadro.com/course/search/core"
+)
 
 // ComicsRow - промежуточная модель для скана, не стал выносить в core/models,
 // так как зависит от постгреса и pq драйвера
@@ -10,4 +16,85 @@ type ComicsRow struct {
 	Title pq.StringArray `db:"title"`
 	Alt   pq.StringArray `db:"alt"`
 	Words pq.StringArray `db:"words"`
+
+	Published     sql.NullTime `db:"published"`
+	HasTranscript bool         `db:"has_transcript"`
+
+	AnalyzerVersion sql.NullString `db:"analyzer_version"`
+}
+
+func (r ComicsRow) toCore() core.Comics {
+	return core.Comics{
+		ID:    r.ID,
+		URL:   r.URL,
+		Title: []string(r.Title),
+		Alt:   []string(r.Alt),
+		Words: []string(r.Words),
+
+		Published:     r.Published.Time, // NULL -> нулевое время
+		HasTranscript: r.HasTranscript,
+
+		AnalyzerVersion: r.AnalyzerVersion.String, // NULL -> ""
+	}
+}
+
+// filterArgs - фильтры поиска в виде NULL-able параметров запроса:
+// NULL означает "без ограничения", см. filterSQL
+type filterArgs struct {
+	publishedFrom sql.NullTime
+	publishedTo   sql.NullTime
+	idFrom        sql.NullInt64
+	idTo          sql.NullInt64
+	hasTranscript sql.NullBool
+	ids           pq.Int64Array // nil -> NULL
+}
+
+func newFilterArgs(f core.SearchFilters) filterArgs {
+	a := filterArgs{
+		publishedFrom: sql.NullTime{Time: f.PublishedFrom, Valid: !f.PublishedFrom.IsZero()},
+		publishedTo:   sql.NullTime{Time: f.PublishedTo, Valid: !f.PublishedTo.IsZero()},
+		idFrom:        sql.NullInt64{Int64: int64(f.IDFrom), Valid: f.IDFrom > 0},
+		idTo:          sql.NullInt64{Int64: int64(f.IDTo), Valid: f.IDTo > 0},
+	}
+	if f.HasTranscript != nil {
+		a.hasTranscript = sql.NullBool{Bool: *f.HasTranscript, Valid: true}
+	}
+	if f.OnlyIDs {
+		a.ids = make(pq.Int64Array, 0, len(f.IDs))
+		for _, id := range f.IDs {
+			a.ids = append(a.ids, int64(id))
+		}
+	}
+	return a
+}
+
+// values - параметры в порядке плейсхолдеров filterSQL, начиная с $first
+func (a filterArgs) values() []any {
+	return []any{a.publishedFrom, a.publishedTo, a.idFrom, a.idTo, a.hasTranscript, a.ids}
+}
+
+// RankedRow - строка полнотекстового поиска с рангами ts_rank_cd
+type RankedRow struct {
+	ComicsRow
+	Rank      float64 `db:"rank"`
+	TitleRank float64 `db:"title_rank"`
+	AltRank   float64 `db:"alt_rank"`
+	WordsRank float64 `db:"words_rank"`
+}
+
+// QueryStatRow - агрегат журнала запросов по query
+type QueryStatRow struct {
+	Query      string    `db:"query"`
+	Count      int       `db:"count"`
+	AvgResults float64   `db:"avg_results"`
+	LastSeen   time.Time `db:"last_seen"`
+}
+
+// LatencyRow - перцентили latency_us по эндпоинту
+type LatencyRow struct {
+	Endpoint string  `db:"endpoint"`
+	Count    int     `db:"count"`
+	P50      float64 `db:"p50"`
+	P90      float64 `db:"p90"`
+	P99      float64 `db:"p99"`
 }
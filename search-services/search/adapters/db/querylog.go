@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"yadro.com/course/search/core"
+)
+
+// InsertQueries - пачка журнала одной транзакцией
+func (db *DB) InsertQueries(ctx context.Context, entries []core.QueryLogEntry) error {
+	const q = `
+		INSERT INTO query_log (created_at, endpoint, phrase, query, tokens, results, latency_us, user_id, cached)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+	`
+
+	tx, err := db.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin query log tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PreparexContext(ctx, q)
+	if err != nil {
+		return fmt.Errorf("prepare query log insert: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, e := range entries {
+		userID := sql.NullInt64{Int64: int64(e.UserID), Valid: e.UserID != 0}
+		if _, err := stmt.ExecContext(ctx,
+			e.At, e.Endpoint, e.Phrase, e.Query, pq.StringArray(e.Tokens),
+			e.Results, e.Latency.Microseconds(), userID, e.Cached,
+		); err != nil {
+			return fmt.Errorf("insert query log: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit query log: %w", err)
+	}
+	return nil
+}
+
+// DeleteQueriesBefore - по индексу created_at, реплики search могут чистить одновременно
+func (db *DB) DeleteQueriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	const q = `DELETE FROM query_log WHERE created_at < $1;`
+
+	res, err := db.conn.ExecContext(ctx, q, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete old query log: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delete old query log: %w", err)
+	}
+	return n, nil
+}
+
+const queryStatsQuery = `
+		SELECT query, count(*) AS count, avg(results)::float8 AS avg_results, max(created_at) AS last_seen
+		FROM query_log
+		WHERE created_at >= $1 %s
+		GROUP BY query
+		ORDER BY count DESC, query ASC
+		LIMIT $2;
+	`
+
+// TopQueries - самые частые нормализованные запросы с created_at >= since
+func (db *DB) TopQueries(ctx context.Context, since time.Time, limit int) ([]core.QueryStat, error) {
+	return db.queryStats(ctx, fmt.Sprintf(queryStatsQuery, ""), since, limit)
+}
+
+// ZeroResultQueries - то же, но только запросы без результатов
+func (db *DB) ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]core.QueryStat, error) {
+	return db.queryStats(ctx, fmt.Sprintf(queryStatsQuery, "AND results = 0"), since, limit)
+}
+
+func (db *DB) queryStats(ctx context.Context, q string, since time.Time, limit int) ([]core.QueryStat, error) {
+	var rows []QueryStatRow
+	if err := db.conn.SelectContext(ctx, &rows, q, since, limit); err != nil {
+		db.log.Error("query stats failed", "since", since, "error", err)
+		return nil, fmt.Errorf("query stats: %w", err)
+	}
+
+	stats := make([]core.QueryStat, 0, len(rows))
+	for _, r := range rows {
+		stats = append(stats, core.QueryStat{
+			Query:      r.Query,
+			Count:      r.Count,
+			AvgResults: r.AvgResults,
+			LastSeen:   r.LastSeen,
+		})
+	}
+	return stats, nil
+}
+
+// LatencyPercentiles - перцентили latency по эндпоинтам с created_at >= since
+func (db *DB) LatencyPercentiles(ctx context.Context, since time.Time) ([]core.LatencyStats, error) {
+	const q = `
+		SELECT endpoint, count(*) AS count,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_us) AS p50,
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY latency_us) AS p90,
+			percentile_cont(0.99) WITHIN GROUP (ORDER BY latency_us) AS p99
+		FROM query_log
+		WHERE created_at >= $1
+		GROUP BY endpoint
+		ORDER BY endpoint;
+	`
+
+	var rows []LatencyRow
+	if err := db.conn.SelectContext(ctx, &rows, q, since); err != nil {
+		db.log.Error("latency percentiles failed", "since", since, "error", err)
+		return nil, fmt.Errorf("latency percentiles: %w", err)
+	}
+
+	stats := make([]core.LatencyStats, 0, len(rows))
+	for _, r := range rows {
+		stats = append(stats, core.LatencyStats{
+			Endpoint: r.Endpoint,
+			Count:    r.Count,
+			P50:      microseconds(r.P50),
+			P90:      microseconds(r.P90),
+			P99:      microseconds(r.P99),
+		})
+	}
+	return stats, nil
+}
+
+func microseconds(us float64) time.Duration {
+	return time.Duration(us * float64(time.Microsecond))
+}
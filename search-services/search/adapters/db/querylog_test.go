@@ -0,0 +1,110 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"yadro.com/course/search/core"
+)
+
+// Окно начинается со старта теста, поэтому записи прошлых запусков в агрегаты не попадают
+func TestQueryLog_Aggregates(t *testing.T) {
+	storage := prepareDB(t)
+	ctx := context.Background()
+
+	if err := storage.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	since := time.Now()
+	entry := func(endpoint, query string, results int, latency time.Duration) core.QueryLogEntry {
+		return core.QueryLogEntry{
+			At:       time.Now(),
+			Endpoint: endpoint,
+			Phrase:   query,
+			Query:    query,
+			Tokens:   []string{query},
+			Results:  results,
+			Latency:  latency,
+		}
+	}
+
+	entries := []core.QueryLogEntry{
+		entry(core.EndpointSearch, "linux", 3, 10*time.Millisecond),
+		entry(core.EndpointSearch, "linux", 5, 20*time.Millisecond),
+		entry(core.EndpointISearch, "linux", 4, time.Millisecond),
+		entry(core.EndpointISearch, "absent", 0, 2*time.Millisecond),
+		entry(core.EndpointSearch, "tree", 1, 30*time.Millisecond),
+	}
+	entries[1].UserID = 7
+	if err := storage.InsertQueries(ctx, entries); err != nil {
+		t.Fatalf("insert queries: %v", err)
+	}
+
+	top, err := storage.TopQueries(ctx, since, 2)
+	if err != nil {
+		t.Fatalf("top queries: %v", err)
+	}
+	if len(top) != 2 || top[0].Query != "linux" || top[0].Count != 3 || top[0].AvgResults != 4 {
+		t.Fatalf("top queries = %+v, want linux x3 with avg 4 first", top)
+	}
+
+	zero, err := storage.ZeroResultQueries(ctx, since, 10)
+	if err != nil {
+		t.Fatalf("zero result queries: %v", err)
+	}
+	if len(zero) != 1 || zero[0].Query != "absent" {
+		t.Fatalf("zero result queries = %+v, want only absent", zero)
+	}
+
+	latency, err := storage.LatencyPercentiles(ctx, since)
+	if err != nil {
+		t.Fatalf("latency percentiles: %v", err)
+	}
+	if len(latency) != 2 || latency[0].Endpoint != core.EndpointISearch || latency[1].Endpoint != core.EndpointSearch {
+		t.Fatalf("latency endpoints = %+v, want isearch and search", latency)
+	}
+	if s := latency[1]; s.Count != 3 || s.P50 != 20*time.Millisecond || s.P99 < s.P90 || s.P90 < s.P50 {
+		t.Fatalf("search latency = %+v, want 3 queries with p50 20ms", s)
+	}
+}
+
+func TestQueryLog_DeleteBefore(t *testing.T) {
+	storage := prepareDB(t)
+	ctx := context.Background()
+
+	if err := storage.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// запрос уникален для запуска, чтобы не зависеть от записей прошлых тестов
+	query := fmt.Sprintf("retention-%d", time.Now().UnixNano())
+	now := time.Now()
+	entries := []core.QueryLogEntry{
+		{At: now.Add(-core.QueryLogRetention - time.Hour), Endpoint: core.EndpointSearch, Phrase: query, Query: query, Tokens: []string{query}},
+		{At: now, Endpoint: core.EndpointSearch, Phrase: query, Query: query, Tokens: []string{query}},
+	}
+	if err := storage.InsertQueries(ctx, entries); err != nil {
+		t.Fatalf("insert queries: %v", err)
+	}
+
+	n, err := storage.DeleteQueriesBefore(ctx, now.Add(-core.QueryLogRetention))
+	if err != nil {
+		t.Fatalf("delete queries: %v", err)
+	}
+	if n < 1 {
+		t.Fatalf("deleted = %d, want the old entry", n)
+	}
+
+	top, err := storage.TopQueries(ctx, now.Add(-2*core.QueryLogRetention), 1000)
+	if err != nil {
+		t.Fatalf("top queries: %v", err)
+	}
+	for _, q := range top {
+		if q.Query == query && q.Count != 1 {
+			t.Fatalf("%s count = %d, want only the recent entry", query, q.Count)
+		}
+	}
+}
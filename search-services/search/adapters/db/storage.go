@@ -6,9 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"yadro.com/course/pkg/tracing"
 	"yadro.com/course/search/core"
 )
 
@@ -18,7 +21,7 @@ type DB struct {
 }
 
 func New(log *slog.Logger, address string) (*DB, error) {
-	db, err := sqlx.Connect("pgx", address)
+	db, err := tracing.ConnectDB(address)
 	if err != nil {
 		log.Error("connection problem", "address", address, "error", err)
 		return nil, err
@@ -30,22 +33,43 @@ func New(log *slog.Logger, address string) (*DB, error) {
 	}, nil
 }
 
+// Pool - пул соединений, его статистика уходит в метрики
+func (db *DB) Pool() *sql.DB {
+	return db.conn.DB
+}
+
 func (db *DB) Ping(ctx context.Context) error {
 	return db.conn.PingContext(ctx)
 }
 
-func (db *DB) Find(ctx context.Context, tokens []string) ([]core.Comics, error) {
-	// && - overlaps(есть ли пересечение двух множеств) $1 - наш tokens
-	// выбрать все комиксы, у которых хотя бы один токен из запроса встречается
-	// в title или в alt, или в words
-	const q = `
-		SELECT id, img_url, title, alt, words
+// filterSQL - условия фильтров поиска; параметры $first..$first+5 заполняются filterArgs.values.
+// NULL-параметр отключает свое условие, поэтому текст запроса не зависит от набора фильтров
+func filterSQL(first int) string {
+	return fmt.Sprintf(`
+			AND ($%[1]d::date IS NULL OR published >= $%[1]d)
+			AND ($%[2]d::date IS NULL OR published <= $%[2]d)
+			AND ($%[3]d::int IS NULL OR id >= $%[3]d)
+			AND ($%[4]d::int IS NULL OR id <= $%[4]d)
+			AND ($%[5]d::boolean IS NULL OR has_transcript = $%[5]d)
+			AND ($%[6]d::int[] IS NULL OR id = ANY($%[6]d))`,
+		first, first+1, first+2, first+3, first+4, first+5,
+	)
+}
+
+// && - overlaps(есть ли пересечение двух множеств) $1 - наш tokens
+// выбрать все комиксы, у которых хотя бы один токен из запроса встречается
+// в title или в alt, или в words, и которые проходят фильтры
+var findQuery = `
+		SELECT id, img_url, title, alt, words, published, has_transcript, analyzer_version
 		FROM comics
-		WHERE title && $1 OR alt && $1 OR words && $1;
+		WHERE (title && $1 OR alt && $1 OR words && $1)` + filterSQL(2) + `;
 	`
 
+func (db *DB) Find(ctx context.Context, tokens []string, filters core.SearchFilters) ([]core.Comics, error) {
+	args := append([]any{pq.StringArray(tokens)}, newFilterArgs(filters).values()...)
+
 	var rows []ComicsRow // используем промежуточную модель
-	if err := db.conn.SelectContext(ctx, &rows, q, tokens); err != nil {
+	if err := db.conn.SelectContext(ctx, &rows, findQuery, args...); err != nil {
 		db.log.Error("find comics failed", "tokens", tokens, "error", err)
 		return nil, fmt.Errorf("find comics by tokens: %w", err)
 	}
@@ -53,21 +77,155 @@ func (db *DB) Find(ctx context.Context, tokens []string) ([]core.Comics, error)
 	// конвертируем обратно
 	comics := make([]core.Comics, 0, len(rows))
 	for _, r := range rows {
-		comics = append(comics, core.Comics{
-			ID:    r.ID,
-			URL:   r.URL,
-			Title: []string(r.Title),
-			Alt:   []string(r.Alt),
-			Words: []string(r.Words),
-		})
+		comics = append(comics, r.toCore())
 	}
 
 	return comics, nil
 }
 
+// termsQuery - токены через | как готовый tsquery, без парсера websearch_to_tsquery:
+// парсер режет защищенные термины вроде c++ и .net на части и понимает "or" и "-" как операторы.
+// каждый токен - одна лексема в кавычках, см. tsLexeme
+func termsQuery(param int) string {
+	return fmt.Sprintf(`(SELECT string_agg(%s, ' | ')::tsquery AS query FROM unnest($%d::text[]) AS t)`, tsLexeme("t"), param)
+}
+
+// tsLexeme - значение col как лексема tsquery в кавычках: кавычки и обратные слеши внутри удваиваются.
+// quote_literal не подходит: для строки с \ он возвращает E'...', и tsquery читает E как отдельную лексему
+func tsLexeme(col string) string {
+	return fmt.Sprintf(`'''' || replace(replace(%s, '\', '\\'), '''', '''''') || ''''`, col)
+}
+
+// Ранги по отдельным полям считаются только для строк после limit - для explain.
+// rank - сумма ts_rank_cd по группам токенов с одним весом, умноженных на вес группы:
+// совпадение с синонимом дает меньший вклад, чем со словом запроса. Без синонимов группа одна с весом 1
+var findRankedQuery = `
+		WITH term_groups AS (
+			SELECT w AS weight, string_agg(` + tsLexeme("t") + `, ' | ')::tsquery AS query
+			FROM unnest($1::text[], $10::float8[]) AS u(t, w)
+			GROUP BY w
+		)
+		SELECT id, img_url, title, alt, words, published, has_transcript, analyzer_version, rank,
+			ts_rank_cd('{0, 0, 0, 1}', tsv, query) AS title_rank,
+			ts_rank_cd('{0, 0, 1, 0}', tsv, query) AS alt_rank,
+			ts_rank_cd('{0, 1, 0, 0}', tsv, query) AS words_rank
+		FROM (
+			SELECT id, img_url, title, alt, words, published, has_transcript, analyzer_version, tsv, query,
+				(SELECT sum(g.weight * ts_rank_cd($3::real[], tsv, g.query)) FROM term_groups AS g) AS rank
+			FROM comics, ` + termsQuery(1) + ` AS q
+			WHERE tsv @@ query` + filterSQL(4) + `
+			ORDER BY rank DESC, id ASC
+			LIMIT $2
+		) AS top
+		ORDER BY rank DESC, id ASC;
+	`
+
+// FindRanked - полнотекстовый поиск по tsv (миграция update 000002)
+// токены уже нормализованы и идут в tsquery как есть, без повторного стемминга,
+// токены объединяем через |, как и в Find достаточно одного совпадения.
+// Веса ts_rank_cd (из профиля ранжирования) идут в порядке {D, C, B, A}: words=C, alt=B, title=A.
+func (db *DB) FindRanked(ctx context.Context, terms []core.WeightedToken, limit uint32, weights [4]float64, filters core.SearchFilters) ([]core.RankedComics, error) {
+	comics := []core.RankedComics{}
+	err := db.FindRankedEach(ctx, terms, limit, weights, filters, func(rc core.RankedComics) error {
+		comics = append(comics, rc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comics, nil
+}
+
+// FindRankedEach - то же, что FindRanked, но строки отдаются в fn по мере чтения из БД,
+// без среза на весь limit. Ошибка fn прерывает чтение и возвращается как есть
+func (db *DB) FindRankedEach(ctx context.Context, terms []core.WeightedToken, limit uint32, weights [4]float64, filters core.SearchFilters, fn func(core.RankedComics) error) error {
+	tokens := core.Tokens(terms)
+	termWeights := make([]float64, 0, len(terms))
+	for _, t := range terms {
+		termWeights = append(termWeights, t.Weight)
+	}
+	args := append([]any{pq.StringArray(tokens), limit, pq.Float64Array(weights[:])}, newFilterArgs(filters).values()...)
+	args = append(args, pq.Float64Array(termWeights))
+
+	rows, err := db.conn.QueryxContext(ctx, findRankedQuery, args...)
+	if err != nil {
+		db.log.Error("full text find comics failed", "tokens", tokens, "error", err)
+		return fmt.Errorf("full text find comics: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var r RankedRow
+		if err := rows.StructScan(&r); err != nil {
+			return fmt.Errorf("scan ranked comic: %w", err)
+		}
+		if err := fn(core.RankedComics{
+			Comics:    r.toCore(),
+			Rank:      r.Rank,
+			TitleRank: r.TitleRank,
+			AltRank:   r.AltRank,
+			WordsRank: r.WordsRank,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		db.log.Error("full text find comics failed", "tokens", tokens, "error", err)
+		return fmt.Errorf("full text find comics: %w", err)
+	}
+	return nil
+}
+
+var facetsQuery = `
+		SELECT EXTRACT(YEAR FROM published)::int AS year,
+			count(*) AS total,
+			count(*) FILTER (WHERE title && $1) AS in_title,
+			count(*) FILTER (WHERE alt && $1) AS in_alt,
+			count(*) FILTER (WHERE words && $1) AS in_words
+		FROM comics, ` + termsQuery(1) + ` AS q
+		WHERE tsv @@ query` + filterSQL(2) + `
+		GROUP BY year;
+	`
+
+// Facets - фасеты по всем кандидатам полнотекстового поиска (без limit)
+func (db *DB) Facets(ctx context.Context, tokens []string, filters core.SearchFilters) (core.Facets, error) {
+	args := append([]any{pq.StringArray(tokens)}, newFilterArgs(filters).values()...)
+
+	var rows []struct {
+		Year    sql.NullInt64 `db:"year"`
+		Total   int           `db:"total"`
+		InTitle int           `db:"in_title"`
+		InAlt   int           `db:"in_alt"`
+		InWords int           `db:"in_words"`
+	}
+	if err := db.conn.SelectContext(ctx, &rows, facetsQuery, args...); err != nil {
+		db.log.Error("facets failed", "tokens", tokens, "error", err)
+		return core.Facets{}, fmt.Errorf("search facets: %w", err)
+	}
+
+	facets := core.Facets{Years: map[string]int{}, Sources: map[string]int{}}
+	for _, r := range rows {
+		year := core.FacetUnknownYear
+		if r.Year.Valid {
+			year = strconv.FormatInt(r.Year.Int64, 10)
+		}
+		facets.Years[year] += r.Total
+		facets.Sources[core.SourceTitle] += r.InTitle
+		facets.Sources[core.SourceAlt] += r.InAlt
+		facets.Sources[core.SourceTranscript] += r.InWords
+	}
+	// источники без совпадений не отдаем, как и в фасетах по индексу
+	for k, v := range facets.Sources {
+		if v == 0 {
+			delete(facets.Sources, k)
+		}
+	}
+	return facets, nil
+}
+
 func (db *DB) All(ctx context.Context) ([]core.Comics, error) {
 	const q = `
-		SELECT id, img_url, title, alt, words
+		SELECT id, img_url, title, alt, words, published, has_transcript, analyzer_version
 		FROM comics;
 	`
 
@@ -79,21 +237,44 @@ func (db *DB) All(ctx context.Context) ([]core.Comics, error) {
 
 	comics := make([]core.Comics, 0, len(rows))
 	for _, r := range rows {
-		comics = append(comics, core.Comics{
-			ID:    r.ID,
-			URL:   r.URL,
-			Title: []string(r.Title),
-			Alt:   []string(r.Alt),
-			Words: []string(r.Words),
-		})
+		comics = append(comics, r.toCore())
 	}
 
 	return comics, nil
 }
 
+// termSurfacesQuery - написания считаются по комиксам, у каждого терма берется самое частое
+const termSurfacesQuery = `
+		SELECT DISTINCT ON (term) term, surface
+		FROM (
+			SELECT s.key AS term, s.value AS surface, count(*) AS n
+			FROM comics, jsonb_each_text(surfaces) AS s
+			GROUP BY s.key, s.value
+		) AS forms
+		ORDER BY term, n DESC, surface;
+	`
+
+// TermSurfaces - написания термов до стемминга (миграция update 000005) для словаря Correct
+func (db *DB) TermSurfaces(ctx context.Context) (map[string]string, error) {
+	var rows []struct {
+		Term    string `db:"term"`
+		Surface string `db:"surface"`
+	}
+	if err := db.conn.SelectContext(ctx, &rows, termSurfacesQuery); err != nil {
+		db.log.Error("term surfaces failed", "error", err)
+		return nil, fmt.Errorf("term surfaces: %w", err)
+	}
+
+	surfaces := make(map[string]string, len(rows))
+	for _, r := range rows {
+		surfaces[r.Term] = r.Surface
+	}
+	return surfaces, nil
+}
+
 func (db *DB) GetByID(ctx context.Context, id int) (core.Comics, error) {
 	const q = `
-        SELECT id, img_url, title, alt, words
+        SELECT id, img_url, title, alt, words, published, has_transcript, analyzer_version
         FROM comics
         WHERE id = $1;
     `
@@ -106,18 +287,12 @@ func (db *DB) GetByID(ctx context.Context, id int) (core.Comics, error) {
 		return core.Comics{}, fmt.Errorf("get comic by id: %w", err)
 	}
 
-	return core.Comics{
-		ID:    r.ID,
-		URL:   r.URL,
-		Title: []string(r.Title),
-		Alt:   []string(r.Alt),
-		Words: []string(r.Words),
-	}, nil
+	return r.toCore(), nil
 }
 
 func (db *DB) GetAll(ctx context.Context, offset, limit int) ([]core.Comics, error) {
 	const q = `
-        SELECT id, img_url, title, alt, words
+        SELECT id, img_url, title, alt, words, published, has_transcript, analyzer_version
         FROM comics
         ORDER BY id
         OFFSET $1
@@ -131,13 +306,30 @@ func (db *DB) GetAll(ctx context.Context, offset, limit int) ([]core.Comics, err
 
 	comics := make([]core.Comics, 0, len(rows))
 	for _, r := range rows {
-		comics = append(comics, core.Comics{
-			ID:    r.ID,
-			URL:   r.URL,
-			Title: []string(r.Title),
-			Alt:   []string(r.Alt),
-			Words: []string(r.Words),
-		})
+		comics = append(comics, r.toCore())
+	}
+
+	return comics, nil
+}
+
+// GetAfter - keyset-пагинация по id для стриминга, без OFFSET на больших выгрузках
+func (db *DB) GetAfter(ctx context.Context, afterID, limit int) ([]core.Comics, error) {
+	const q = `
+        SELECT id, img_url, title, alt, words, published, has_transcript, analyzer_version
+        FROM comics
+        WHERE id > $1
+        ORDER BY id
+        LIMIT $2;
+    `
+	var rows []ComicsRow
+	if err := db.conn.SelectContext(ctx, &rows, q, afterID, limit); err != nil {
+		db.log.Error("list comics after id failed", "after_id", afterID, "limit", limit, "error", err)
+		return nil, fmt.Errorf("list comics after id: %w", err)
+	}
+
+	comics := make([]core.Comics, 0, len(rows))
+	for _, r := range rows {
+		comics = append(comics, r.toCore())
 	}
 
 	return comics, nil
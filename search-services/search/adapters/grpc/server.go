@@ -3,7 +3,11 @@ package grpc
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -30,11 +34,17 @@ func (s *Server) Ping(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, er
 }
 
 func (s *Server) Find(ctx context.Context, in *searchpb.SearchRequest) (*searchpb.SearchReply, error) {
-	comics, err := s.service.Find(ctx, in.GetPhrase(), in.GetLimit())
+	q, err := searchQuery(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	result, err := s.service.Find(ctx, q)
 	if err != nil {
 		switch {
 		case errors.Is(err, core.ErrEmptyPhrase),
 			errors.Is(err, core.ErrBadArguments),
+			errors.Is(err, core.ErrUnknownProfile),
 			errors.Is(err, core.ErrToLargeLimit):
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		case errors.Is(err, core.ErrUnavailable):
@@ -44,27 +54,21 @@ func (s *Server) Find(ctx context.Context, in *searchpb.SearchRequest) (*searchp
 		}
 	}
 
-	res := &searchpb.SearchReply{
-		Comics: make([]*searchpb.ComicReply, 0, len(comics)),
-		Total:  uint32(len(comics)),
-	}
-
-	for _, c := range comics {
-		res.Comics = append(res.Comics, &searchpb.ComicReply{
-			Id:  uint32(c.ID),
-			Url: c.URL,
-		})
-	}
-
-	return res, nil
+	return searchReply(result), nil
 }
 
 func (s *Server) IndexedSearch(ctx context.Context, in *searchpb.SearchRequest) (*searchpb.SearchReply, error) {
-	comics, total, err := s.service.IndexedSearch(ctx, in.GetPhrase(), in.GetLimit())
+	q, err := searchQuery(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	result, err := s.service.IndexedSearch(ctx, q)
 	if err != nil {
 		switch {
 		case errors.Is(err, core.ErrEmptyPhrase),
 			errors.Is(err, core.ErrBadArguments),
+			errors.Is(err, core.ErrUnknownProfile),
 			errors.Is(err, core.ErrToLargeLimit):
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		case errors.Is(err, core.ErrUnavailable):
@@ -74,19 +78,127 @@ func (s *Server) IndexedSearch(ctx context.Context, in *searchpb.SearchRequest)
 		}
 	}
 
+	return searchReply(result), nil
+}
+
+func searchQuery(in *searchpb.SearchRequest) (core.SearchQuery, error) {
+	filters, err := searchFilters(in.GetFilters())
+	if err != nil {
+		return core.SearchQuery{}, err
+	}
+
+	return core.SearchQuery{
+		Phrase:  in.GetPhrase(),
+		Limit:   in.GetLimit(),
+		Explain: in.GetExplain(),
+		Profile: in.GetProfile(),
+		Filters: filters,
+		UserID:  in.GetUserId(),
+	}, nil
+}
+
+func searchFilters(in *searchpb.SearchFilters) (core.SearchFilters, error) {
+	out := core.SearchFilters{
+		IDFrom:        int(in.GetIdFrom()),
+		IDTo:          int(in.GetIdTo()),
+		HasTranscript: in.HasTranscript,
+		OnlyIDs:       in.GetOnlyIds(),
+	}
+
+	var err error
+	if v := in.GetPublishedFrom(); v != "" {
+		if out.PublishedFrom, err = time.Parse(time.DateOnly, v); err != nil {
+			return core.SearchFilters{}, fmt.Errorf("bad published_from %q", v)
+		}
+	}
+	if v := in.GetPublishedTo(); v != "" {
+		if out.PublishedTo, err = time.Parse(time.DateOnly, v); err != nil {
+			return core.SearchFilters{}, fmt.Errorf("bad published_to %q", v)
+		}
+	}
+
+	for _, id := range in.GetIds() {
+		out.IDs = append(out.IDs, int(id))
+	}
+	return out, nil
+}
+
+// facetCounts - счетчики фасета в порядке keys, отсутствующие ключи пропускаются
+func facetCounts(m map[string]int, keys []string) []*searchpb.FacetCount {
+	out := make([]*searchpb.FacetCount, 0, len(m))
+	for _, k := range keys {
+		if n, ok := m[k]; ok {
+			out = append(out, &searchpb.FacetCount{Value: k, Count: uint32(n)})
+		}
+	}
+	return out
+}
+
+// yearKeys - года по возрастанию, неизвестный год последним
+func yearKeys(years map[string]int) []string {
+	keys := make([]string, 0, len(years))
+	for k := range years {
+		if k != core.FacetUnknownYear {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return append(keys, core.FacetUnknownYear)
+}
+
+var sourceKeys = []string{core.SourceTitle, core.SourceAlt, core.SourceTranscript}
+
+func searchReply(result core.SearchResult) *searchpb.SearchReply {
 	res := &searchpb.SearchReply{
-		Comics: make([]*searchpb.ComicReply, 0, len(comics)),
-		Total:  total,
+		Comics:  make([]*searchpb.ComicReply, 0, len(result.Hits)),
+		Total:   result.Total,
+		Tokens:  result.Tokens,
+		Years:   facetCounts(result.Facets.Years, yearKeys(result.Facets.Years)),
+		Sources: facetCounts(result.Facets.Sources, sourceKeys),
 	}
 
-	for _, c := range comics {
+	for _, h := range result.Hits {
 		res.Comics = append(res.Comics, &searchpb.ComicReply{
-			Id:  uint32(c.ID),
-			Url: c.URL,
+			Id:      uint32(h.ID),
+			Url:     h.URL,
+			Explain: explanationReply(h.Explain),
 		})
 	}
 
-	return res, nil
+	return res
+}
+
+func explanationReply(e *core.Explanation) *searchpb.Explanation {
+	if e == nil {
+		return nil
+	}
+
+	out := &searchpb.Explanation{
+		Function:      e.Function,
+		Profile:       e.Profile,
+		Score:         e.Score,
+		Components:    make([]*searchpb.ScoreComponent, 0, len(e.Components)),
+		Terms:         make([]*searchpb.TermExplain, 0, len(e.Terms)),
+		MatchedFields: e.MatchedFields,
+	}
+	for _, c := range e.Components {
+		out.Components = append(out.Components, &searchpb.ScoreComponent{
+			Name:         c.Name,
+			Value:        c.Value,
+			Weight:       c.Weight,
+			Contribution: c.Contribution,
+		})
+	}
+	for _, t := range e.Terms {
+		out.Terms = append(out.Terms, &searchpb.TermExplain{
+			Token:   t.Token,
+			Fields:  t.Fields,
+			TitleTf: uint32(t.TitleTF),
+			AltTf:   uint32(t.AltTF),
+			WordsTf: uint32(t.WordsTF),
+		})
+	}
+	return out
 }
 
 func (s *Server) GetIDComic(ctx context.Context, in *searchpb.ComicByIDRequest) (*searchpb.ComicReply, error) {
@@ -139,8 +251,192 @@ func (s *Server) GetAllComics(ctx context.Context, in *searchpb.ComicsPageReques
 	return res, nil
 }
 
-func (s *Server) GetRandomComic(ctx context.Context, _ *emptypb.Empty) (*searchpb.ComicReply, error) {
-	comic, err := s.service.RandomComic(ctx)
+func (s *Server) GetRandomComic(ctx context.Context, in *searchpb.RandomComicRequest) (*searchpb.ComicReply, error) {
+	exclude := make([]int, 0, len(in.GetExclude()))
+	for _, id := range in.GetExclude() {
+		exclude = append(exclude, int(id))
+	}
+
+	comic, err := s.service.RandomComic(ctx, core.RandomQuery{Seed: in.Seed, Exclude: exclude})
+	if err != nil {
+		return nil, pickError(err)
+	}
+
+	return &searchpb.ComicReply{
+		Id:  uint32(comic.ID),
+		Url: comic.URL,
+	}, nil
+}
+
+func (s *Server) ComicOfTheDay(ctx context.Context, in *searchpb.ComicOfTheDayRequest) (*searchpb.ComicReply, error) {
+	q := core.DailyQuery{Seed: in.GetSeed()}
+	if in.GetDate() != "" {
+		date, err := time.Parse(time.DateOnly, in.GetDate())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("bad date: %v", err))
+		}
+		q.Date = date
+	}
+	if len(in.GetPopularity()) > 0 {
+		q.Popularity = make(map[int]int, len(in.GetPopularity()))
+		for _, p := range in.GetPopularity() {
+			q.Popularity[int(p.GetComicId())] += int(p.GetCount())
+		}
+	}
+
+	comic, err := s.service.ComicOfTheDay(ctx, q)
+	if err != nil {
+		return nil, pickError(err)
+	}
+
+	return &searchpb.ComicReply{
+		Id:  uint32(comic.ID),
+		Url: comic.URL,
+	}, nil
+}
+
+// pickError - пустой индекс (еще не собран или все исключено) - NotFound
+func pickError(err error) error {
+	switch {
+	case errors.Is(err, core.ErrToLargeLimit), errors.Is(err, core.ErrBadArguments):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, core.ErrComicNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, core.ErrUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// StreamSearch - Send блокируется, пока клиент не вычитает окно flow control,
+// так что сервис не собирает следующую пачку раньше времени
+func (s *Server) StreamSearch(in *searchpb.StreamSearchRequest, stream grpc.ServerStreamingServer[searchpb.ComicsChunk]) error {
+	q, err := searchQuery(in.GetQuery())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = s.service.StreamSearch(stream.Context(), q, in.GetIndexed(), in.GetChunkSize(), func(hits []core.Hit) error {
+		chunk := &searchpb.ComicsChunk{Comics: make([]*searchpb.ComicReply, 0, len(hits))}
+		for _, h := range hits {
+			chunk.Comics = append(chunk.Comics, &searchpb.ComicReply{
+				Id:      uint32(h.ID),
+				Url:     h.URL,
+				Explain: explanationReply(h.Explain),
+			})
+		}
+		return stream.Send(chunk)
+	})
+	if err != nil {
+		return streamError(err)
+	}
+	return nil
+}
+
+func (s *Server) StreamComics(in *searchpb.StreamComicsRequest, stream grpc.ServerStreamingServer[searchpb.ComicsChunk]) error {
+	err := s.service.StreamComics(stream.Context(), in.GetChunkSize(), func(comics []core.Comics) error {
+		chunk := &searchpb.ComicsChunk{Comics: make([]*searchpb.ComicReply, 0, len(comics))}
+		for _, c := range comics {
+			chunk.Comics = append(chunk.Comics, &searchpb.ComicReply{
+				Id:  uint32(c.ID),
+				Url: c.URL,
+			})
+		}
+		return stream.Send(chunk)
+	})
+	if err != nil {
+		return streamError(err)
+	}
+	return nil
+}
+
+// streamError - ошибки Send уже содержат gRPC-статус и возвращаются как есть
+func streamError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, core.ErrEmptyPhrase),
+		errors.Is(err, core.ErrNonePhrase),
+		errors.Is(err, core.ErrBadArguments),
+		errors.Is(err, core.ErrUnknownProfile),
+		errors.Is(err, core.ErrToLargeLimit):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, core.ErrUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func (s *Server) IndexStats(ctx context.Context, in *searchpb.IndexStatsRequest) (*searchpb.IndexStatsReply, error) {
+	st, err := s.service.IndexStats(ctx, in.GetTop())
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrToLargeLimit):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	res := &searchpb.IndexStatsReply{
+		Generation:      st.Generation,
+		Terms:           uint32(st.Terms),
+		Docs:            uint32(st.Docs),
+		Postings:        uint64(st.Postings),
+		MaxPosting:      uint32(st.MaxPosting),
+		AvgPosting:      st.AvgPosting,
+		MemoryBytes:     st.MemoryBytes,
+		BuildDurationMs: st.BuildDuration.Milliseconds(),
+		Trigger:         string(st.Trigger),
+		TopTerms:        make([]*searchpb.TermStat, 0, len(st.TopTerms)),
+		Searches:        st.Searches,
+		IndexedSearches: st.IndexedSearches,
+		ResultCache:     cacheStatsReply(st.ResultCache),
+		NormCache:       cacheStatsReply(st.NormCache),
+
+		QueryAnalyzerVersion: st.QueryAnalyzerVersion,
+	}
+	// до первой сборки время не заполнено - отдаем 0, а не отрицательный unix
+	if !st.BuiltAt.IsZero() {
+		res.BuiltAtUnix = st.BuiltAt.Unix()
+	}
+	if st.LastRebuildError != "" {
+		res.LastRebuildError = st.LastRebuildError
+		res.LastRebuildErrorAtUnix = st.LastRebuildErrorAt.Unix()
+	}
+
+	for _, t := range st.TopTerms {
+		res.TopTerms = append(res.TopTerms, &searchpb.TermStat{
+			Term: t.Term,
+			Docs: uint32(t.Docs),
+		})
+	}
+	for _, v := range st.AnalyzerVersions {
+		res.AnalyzerVersions = append(res.AnalyzerVersions, &searchpb.AnalyzerVersionStat{
+			Version: v.Version,
+			Docs:    uint32(v.Docs),
+		})
+	}
+
+	return res, nil
+}
+
+func cacheStatsReply(c core.CacheStats) *searchpb.CacheStats {
+	return &searchpb.CacheStats{
+		Hits:     c.Hits,
+		Misses:   c.Misses,
+		Entries:  uint32(c.Entries),
+		Capacity: uint32(c.Capacity),
+	}
+}
+
+func (s *Server) RebuildIndex(ctx context.Context, _ *emptypb.Empty) (*searchpb.RebuildIndexReply, error) {
+	rb, err := s.service.Rebuild(ctx, core.TriggerManual)
 	if err != nil {
 		switch {
 		case errors.Is(err, core.ErrUnavailable):
@@ -150,8 +446,108 @@ func (s *Server) GetRandomComic(ctx context.Context, _ *emptypb.Empty) (*searchp
 		}
 	}
 
-	return &searchpb.ComicReply{
-		Id:  uint32(comic.ID),
-		Url: comic.URL,
+	return &searchpb.RebuildIndexReply{
+		Generation: rb.Generation,
+		Docs:       uint32(rb.Docs),
+		DurationMs: rb.Duration.Milliseconds(),
+	}, nil
+}
+
+func (s *Server) VerifyIndex(ctx context.Context, _ *emptypb.Empty) (*searchpb.VerifyIndexReply, error) {
+	drift, err := s.service.VerifyIndex(ctx)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrUnavailable):
+			return nil, status.Error(codes.Unavailable, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return &searchpb.VerifyIndexReply{
+		Generation:      drift.Generation,
+		DbDocs:          uint32(drift.DBDocs),
+		IndexDocs:       uint32(drift.IndexDocs),
+		InSync:          drift.InSync(),
+		Missing:         toUint32s(drift.Missing),
+		Stale:           toUint32s(drift.Stale),
+		Mismatched:      toUint32s(drift.Mismatched),
+		MissingCount:    uint32(drift.MissingCount),
+		StaleCount:      uint32(drift.StaleCount),
+		MismatchedCount: uint32(drift.MismatchedCount),
 	}, nil
 }
+
+func (s *Server) TopQueries(ctx context.Context, in *searchpb.AnalyticsRequest) (*searchpb.QueryStatsReply, error) {
+	stats, err := s.service.TopQueries(ctx, analyticsWindow(in), in.GetLimit())
+	if err != nil {
+		return nil, analyticsError(err)
+	}
+	return queryStatsReply(stats), nil
+}
+
+func (s *Server) ZeroResultQueries(ctx context.Context, in *searchpb.AnalyticsRequest) (*searchpb.QueryStatsReply, error) {
+	stats, err := s.service.ZeroResultQueries(ctx, analyticsWindow(in), in.GetLimit())
+	if err != nil {
+		return nil, analyticsError(err)
+	}
+	return queryStatsReply(stats), nil
+}
+
+func (s *Server) LatencyPercentiles(ctx context.Context, in *searchpb.AnalyticsRequest) (*searchpb.LatencyReply, error) {
+	stats, err := s.service.LatencyPercentiles(ctx, analyticsWindow(in))
+	if err != nil {
+		return nil, analyticsError(err)
+	}
+
+	res := &searchpb.LatencyReply{Endpoints: make([]*searchpb.LatencyStat, 0, len(stats))}
+	for _, st := range stats {
+		res.Endpoints = append(res.Endpoints, &searchpb.LatencyStat{
+			Endpoint: st.Endpoint,
+			Count:    uint32(st.Count),
+			P50Ms:    durationMs(st.P50),
+			P90Ms:    durationMs(st.P90),
+			P99Ms:    durationMs(st.P99),
+		})
+	}
+	return res, nil
+}
+
+func analyticsWindow(in *searchpb.AnalyticsRequest) time.Duration {
+	return time.Duration(in.GetWindowSeconds()) * time.Second
+}
+
+func analyticsError(err error) error {
+	switch {
+	case errors.Is(err, core.ErrBadArguments), errors.Is(err, core.ErrToLargeLimit):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func queryStatsReply(stats []core.QueryStat) *searchpb.QueryStatsReply {
+	res := &searchpb.QueryStatsReply{Queries: make([]*searchpb.QueryStat, 0, len(stats))}
+	for _, st := range stats {
+		res.Queries = append(res.Queries, &searchpb.QueryStat{
+			Query:        st.Query,
+			Count:        uint32(st.Count),
+			AvgResults:   st.AvgResults,
+			LastSeenUnix: st.LastSeen.Unix(),
+		})
+	}
+	return res
+}
+
+// durationMs - дробные миллисекунды, перцентили поиска по индексу меньше 1ms
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func toUint32s(ids []int) []uint32 {
+	out := make([]uint32, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, uint32(id))
+	}
+	return out
+}
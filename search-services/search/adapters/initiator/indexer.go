@@ -4,10 +4,12 @@ import (
 	"context"
 	"log/slog"
 	"time"
+
+	"yadro.com/course/search/core"
 )
 
 type IndexUpdater interface {
-	RebuildIndex(ctx context.Context) error
+	RebuildIndex(ctx context.Context, trigger core.IndexTrigger) error
 }
 
 type IndexInitiator struct {
@@ -29,7 +31,7 @@ func (i *IndexInitiator) Start(ctx context.Context) {
 }
 
 func (i *IndexInitiator) loop(ctx context.Context) {
-	if err := i.service.RebuildIndex(ctx); err != nil {
+	if err := i.service.RebuildIndex(ctx, core.TriggerStartup); err != nil {
 		i.log.Error("initial index build failed", "error", err)
 	}
 
@@ -42,7 +44,7 @@ func (i *IndexInitiator) loop(ctx context.Context) {
 			i.log.Info("index initiator stopped")
 			return
 		case <-ticker.C:
-			if err := i.service.RebuildIndex(ctx); err != nil {
+			if err := i.service.RebuildIndex(ctx, core.TriggerTTL); err != nil {
 				i.log.Error("periodic index rebuild failed", "error", err)
 			}
 		}
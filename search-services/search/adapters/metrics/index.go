@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"yadro.com/course/search/core"
+)
+
+// scrapeTimeout - IndexStats читает индекс под блокировкой, но в БД не ходит
+const scrapeTimeout = 2 * time.Second
+
+type IndexStater interface {
+	IndexStats(ctx context.Context, top uint32) (core.IndexStats, error)
+}
+
+var (
+	docsDesc       = prometheus.NewDesc("search_index_docs", "Comics in the inverted index.", nil, nil)
+	termsDesc      = prometheus.NewDesc("search_index_terms", "Unique terms in the inverted index.", nil, nil)
+	postingsDesc   = prometheus.NewDesc("search_index_postings", "Postings in the inverted index.", nil, nil)
+	memoryDesc     = prometheus.NewDesc("search_index_memory_bytes", "Estimated memory used by the inverted index.", nil, nil)
+	generationDesc = prometheus.NewDesc("search_index_generation", "Generation of the current index, grows with each rebuild.", nil, nil)
+	durationDesc   = prometheus.NewDesc("search_index_rebuild_duration_seconds", "Duration of the last successful index rebuild.", nil, nil)
+	builtDesc      = prometheus.NewDesc("search_index_built_timestamp_seconds", "Unix time of the last successful index rebuild.", nil, nil)
+	failedDesc     = prometheus.NewDesc("search_index_rebuild_failed", "1 if the last index rebuild failed and the previous index is still served.", nil, nil)
+)
+
+// IndexCollector - размер индекса и последняя пересборка; значения берутся из IndexStats
+// на каждый scrape, поэтому core ничего не знает о метриках
+type IndexCollector struct {
+	log     *slog.Logger
+	service IndexStater
+}
+
+func NewIndexCollector(log *slog.Logger, service IndexStater) *IndexCollector {
+	return &IndexCollector{log: log, service: service}
+}
+
+func (c *IndexCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{docsDesc, termsDesc, postingsDesc, memoryDesc, generationDesc, durationDesc, builtDesc, failedDesc} {
+		ch <- d
+	}
+}
+
+func (c *IndexCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	st, err := c.service.IndexStats(ctx, 1)
+	if err != nil {
+		c.log.Warn("index stats for metrics failed", "error", err)
+		return
+	}
+
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	gauge(docsDesc, float64(st.Docs))
+	gauge(termsDesc, float64(st.Terms))
+	gauge(postingsDesc, float64(st.Postings))
+	gauge(memoryDesc, float64(st.MemoryBytes))
+	gauge(generationDesc, float64(st.Generation))
+	gauge(durationDesc, st.BuildDuration.Seconds())
+	if !st.BuiltAt.IsZero() {
+		gauge(builtDesc, float64(st.BuiltAt.Unix()))
+	}
+	failed := 0.0
+	if st.LastRebuildError != "" {
+		failed = 1
+	}
+	gauge(failedDesc, failed)
+}
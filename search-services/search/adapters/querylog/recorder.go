@@ -0,0 +1,148 @@
+package querylog
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"yadro.com/course/search/core"
+)
+
+const (
+	// flushTimeout - на запись пачки при остановке, когда ctx сервиса уже отменен
+	flushTimeout = 5 * time.Second
+	// DefaultInterval - если interval не задан или не положительный
+	DefaultInterval = 2 * time.Second
+	// cleanupInterval - как часто удалять записи старше core.QueryLogRetention
+	cleanupInterval = time.Hour
+)
+
+// Recorder - буферизованная запись журнала запросов пачками.
+// Поиск не ждет БД: при переполненном буфере запись отбрасывается
+type Recorder struct {
+	log   *slog.Logger
+	store core.QueryLog
+
+	entries  chan core.QueryLogEntry
+	batch    int
+	interval time.Duration
+	// retention - записи старше удаляются при старте и раз в cleanupInterval
+	retention time.Duration
+
+	dropped atomic.Uint64
+
+	// done закрывается, когда loop дописал буфер после отмены ctx
+	done chan struct{}
+}
+
+// New - batch <= 0 пишет каждую запись сразу, buffer < 0 - без буфера,
+// interval <= 0 заменяется на DefaultInterval
+func New(log *slog.Logger, store core.QueryLog, buffer, batch int, interval time.Duration) *Recorder {
+	if batch <= 0 {
+		batch = 1
+	}
+	if buffer < 0 {
+		buffer = 0
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Recorder{
+		log:       log,
+		store:     store,
+		entries:   make(chan core.QueryLogEntry, buffer),
+		batch:     batch,
+		interval:  interval,
+		retention: core.QueryLogRetention,
+		done:      make(chan struct{}),
+	}
+}
+
+// Record - неблокирующая постановка записи в буфер
+func (r *Recorder) Record(e core.QueryLogEntry) {
+	select {
+	case r.entries <- e:
+	default:
+		if n := r.dropped.Add(1); n == 1 || n%1000 == 0 {
+			r.log.Warn("query log buffer is full, entries dropped", "dropped", n)
+		}
+	}
+}
+
+// Start - пишет пачку при наборе batch записей или раз в interval;
+// при отмене ctx дописывает то, что осталось в буфере. Старые записи удаляются
+// сразу и раз в cleanupInterval, иначе таблица растет без предела
+func (r *Recorder) Start(ctx context.Context) {
+	go r.loop(ctx)
+}
+
+// Wait - ждет остановки после отмены ctx, переданного в Start, вместе с последней записью
+func (r *Recorder) Wait() {
+	<-r.done
+}
+
+func (r *Recorder) loop(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+	r.cleanup(ctx)
+
+	pending := make([]core.QueryLogEntry, 0, r.batch)
+	for {
+		select {
+		case <-ctx.Done():
+			pending = r.drain(pending)
+			flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			r.flush(flushCtx, pending)
+			cancel()
+			r.log.Info("query log recorder stopped")
+			return
+		case e := <-r.entries:
+			pending = append(pending, e)
+			if len(pending) >= r.batch {
+				pending = r.flush(ctx, pending)
+			}
+		case <-ticker.C:
+			pending = r.flush(ctx, pending)
+		case <-cleanup.C:
+			r.cleanup(ctx)
+		}
+	}
+}
+
+func (r *Recorder) drain(pending []core.QueryLogEntry) []core.QueryLogEntry {
+	for {
+		select {
+		case e := <-r.entries:
+			pending = append(pending, e)
+		default:
+			return pending
+		}
+	}
+}
+
+// flush - при ошибке записи пачка теряется, журнал не должен копиться в памяти
+func (r *Recorder) flush(ctx context.Context, pending []core.QueryLogEntry) []core.QueryLogEntry {
+	if len(pending) == 0 {
+		return pending
+	}
+	if err := r.store.InsertQueries(ctx, pending); err != nil {
+		r.log.Error("query log flush failed", "entries", len(pending), "error", err)
+	}
+	return pending[:0]
+}
+
+// cleanup - ошибка только в лог, следующая попытка через cleanupInterval
+func (r *Recorder) cleanup(ctx context.Context) {
+	n, err := r.store.DeleteQueriesBefore(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		r.log.Error("query log cleanup failed", "error", err)
+		return
+	}
+	if n > 0 {
+		r.log.Info("query log cleaned up", "deleted", n, "retention", r.retention)
+	}
+}
@@ -0,0 +1,92 @@
+package querylog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"yadro.com/course/search/core"
+)
+
+// memLog - журнал в памяти, InsertQueries видит ctx, с которым писалась пачка
+type memLog struct {
+	core.QueryLog
+
+	mu      sync.Mutex
+	entries []core.QueryLogEntry
+	ctxErr  error
+	cutoffs []time.Time
+}
+
+func (m *memLog) DeleteQueriesBefore(_ context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cutoffs = append(m.cutoffs, before)
+	return 0, nil
+}
+
+func (m *memLog) InsertQueries(ctx context.Context, entries []core.QueryLogEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entries...)
+	m.ctxErr = ctx.Err()
+	return nil
+}
+
+func TestRecorder_FlushOnStop(t *testing.T) {
+	store := &memLog{}
+	// ни batch, ни interval не наступят - записи уйдут только при остановке
+	r := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, 10, 100, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Start(ctx)
+
+	for _, q := range []string{"linux", "cat", "xkcd"} {
+		r.Record(core.QueryLogEntry{Query: q})
+	}
+	cancel()
+	r.Wait()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.entries) != 3 {
+		t.Fatalf("flushed %d entries, want 3", len(store.entries))
+	}
+	if store.ctxErr != nil {
+		t.Fatalf("final flush ran with canceled ctx: %v", store.ctxErr)
+	}
+}
+
+func TestRecorder_BadConfig(t *testing.T) {
+	store := &memLog{}
+	// QUERY_LOG_FLUSH=0 и отрицательный буфер не роняют сервис
+	r := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, -1, 0, 0)
+	if r.interval != DefaultInterval {
+		t.Fatalf("interval = %s, want %s", r.interval, DefaultInterval)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Start(ctx)
+	cancel()
+	r.Wait()
+}
+
+func TestRecorder_Retention(t *testing.T) {
+	store := &memLog{}
+	r := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, 10, 100, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Start(ctx)
+	cancel()
+	r.Wait()
+
+	// записи старше окна аналитики удаляются уже при старте
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.cutoffs) != 1 {
+		t.Fatalf("cleanups = %d, want one on start", len(store.cutoffs))
+	}
+	if age := time.Since(store.cutoffs[0]); age < core.QueryLogRetention || age > core.QueryLogRetention+time.Minute {
+		t.Fatalf("cutoff age = %s, want %s", age, core.QueryLogRetention)
+	}
+}
@@ -0,0 +1,180 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"yadro.com/course/search/core"
+)
+
+// fileFormat - формат файла профилей:
+//
+//	default: default
+//	profiles:
+//	  default: {coverage: 100, title: 5, alt: 3, words: 1}
+type fileFormat struct {
+	Default  string                 `yaml:"default"`
+	Profiles map[string]profileYAML `yaml:"profiles"`
+}
+
+type profileYAML struct {
+	Coverage float64 `yaml:"coverage"`
+	Title    float64 `yaml:"title"`
+	Alt      float64 `yaml:"alt"`
+	Words    float64 `yaml:"words"`
+}
+
+type profileSet struct {
+	def      string
+	profiles map[string]core.RankingProfile
+}
+
+// Store - профили ранжирования из YAML файла.
+// Файл перечитывается при изменении mtime, невалидный файл не применяется -
+// продолжают работать предыдущие профили
+type Store struct {
+	log     *slog.Logger
+	path    string
+	modTime time.Time
+	current atomic.Pointer[profileSet]
+}
+
+// New - читает файл профилей, ошибка валидации на старте фатальна.
+// Пустой path - только встроенный core.DefaultProfile
+func New(log *slog.Logger, path string) (*Store, error) {
+	s := &Store{log: log, path: path}
+
+	if path == "" {
+		s.current.Store(&profileSet{
+			def:      core.DefaultProfile.Name,
+			profiles: map[string]core.RankingProfile{core.DefaultProfile.Name: core.DefaultProfile},
+		})
+		return s, nil
+	}
+
+	set, modTime, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	s.current.Store(set)
+	s.modTime = modTime
+	log.Info("ranking profiles loaded", "path", path, "default", set.def, "profiles", len(set.profiles))
+	return s, nil
+}
+
+func (s *Store) Profile(name string) (core.RankingProfile, error) {
+	set := s.current.Load()
+	if name == "" {
+		name = set.def
+	}
+	p, ok := set.profiles[name]
+	if !ok {
+		return core.RankingProfile{}, fmt.Errorf("%w: %q", core.ErrUnknownProfile, name)
+	}
+	return p, nil
+}
+
+// Start - раз в interval проверяет mtime файла и перечитывает его
+func (s *Store) Start(ctx context.Context, interval time.Duration) {
+	if s.path == "" || interval <= 0 {
+		return
+	}
+	go s.loop(ctx, interval)
+}
+
+func (s *Store) loop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("ranking profiles watcher stopped")
+			return
+		case <-ticker.C:
+			s.reload()
+		}
+	}
+}
+
+func (s *Store) reload() {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		s.log.Error("stat ranking profiles failed", "path", s.path, "error", err)
+		return
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return
+	}
+
+	set, modTime, err := load(s.path)
+	if err != nil {
+		s.log.Error("ranking profiles reload rejected, keeping previous", "path", s.path, "error", err)
+		// запоминаем mtime, чтобы не спамить ошибкой до следующего изменения файла
+		s.modTime = info.ModTime()
+		return
+	}
+	s.current.Store(set)
+	s.modTime = modTime
+	s.log.Info("ranking profiles reloaded", "path", s.path, "default", set.def, "profiles", len(set.profiles))
+}
+
+func load(path string) (*profileSet, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("stat ranking profiles: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("read ranking profiles: %w", err)
+	}
+
+	var f fileFormat
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, time.Time{}, fmt.Errorf("parse ranking profiles %q: %w", path, err)
+	}
+
+	set, err := validate(f)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid ranking profiles %q: %w", path, err)
+	}
+	return set, info.ModTime(), nil
+}
+
+func validate(f fileFormat) (*profileSet, error) {
+	if len(f.Profiles) == 0 {
+		return nil, fmt.Errorf("no profiles defined")
+	}
+
+	set := &profileSet{
+		def:      f.Default,
+		profiles: make(map[string]core.RankingProfile, len(f.Profiles)),
+	}
+	for name, p := range f.Profiles {
+		profile := core.RankingProfile{
+			Name:     name,
+			Coverage: p.Coverage,
+			Title:    p.Title,
+			Alt:      p.Alt,
+			Words:    p.Words,
+		}
+		if err := profile.Validate(); err != nil {
+			return nil, err
+		}
+		set.profiles[name] = profile
+	}
+
+	if set.def == "" {
+		return nil, fmt.Errorf("default profile is not set")
+	}
+	if _, ok := set.profiles[set.def]; !ok {
+		return nil, fmt.Errorf("default profile %q is not defined", set.def)
+	}
+	return set, nil
+}
@@ -0,0 +1,125 @@
+package ranking
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"yadro.com/course/search/core"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestLoad_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		err  string // подстрока ошибки, пусто - файл валиден
+	}{
+		{
+			name: "valid",
+			file: "default: a\nprofiles:\n  a: {coverage: 100, title: 5, alt: 3, words: 1}\n  b: {title: 1}\n",
+		},
+		{name: "not yaml", file: "default: [a\n", err: "parse ranking profiles"},
+		{name: "no profiles", file: "default: a\n", err: "no profiles defined"},
+		{name: "no default", file: "profiles:\n  a: {title: 1}\n", err: "default profile is not set"},
+		{name: "unknown default", file: "default: b\nprofiles:\n  a: {title: 1}\n", err: `default profile "b" is not defined`},
+		{name: "negative weight", file: "default: a\nprofiles:\n  a: {title: 1, alt: -1}\n", err: "alt weight must be a finite non-negative number"},
+		{name: "infinite weight", file: "default: a\nprofiles:\n  a: {title: .inf}\n", err: "title weight must be a finite non-negative number"},
+		{name: "zero field weights", file: "default: a\nprofiles:\n  a: {coverage: 100}\n", err: "all field weights are zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ranking.yaml")
+			writeFile(t, path, tt.file, time.Now())
+
+			_, err := New(discard, path)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// ranking.yaml из репозитория монтируется в контейнер search и должен проходить проверку
+func TestLoad_RepositoryFile(t *testing.T) {
+	s, err := New(discard, "../../ranking.yaml")
+	if err != nil {
+		t.Fatalf("load ranking.yaml: %v", err)
+	}
+	for _, name := range []string{"", "default", "title_boost", "fields_only"} {
+		if _, err := s.Profile(name); err != nil {
+			t.Errorf("profile %q: %v", name, err)
+		}
+	}
+}
+
+func TestStore_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranking.yaml")
+	mtime := time.Now().Add(-time.Hour)
+	writeFile(t, path, "default: a\nprofiles:\n  a: {title: 1}\n", mtime)
+
+	s, err := New(discard, path)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	wantTitle := func(name string, title float64) {
+		t.Helper()
+		p, err := s.Profile(name)
+		if err != nil {
+			t.Fatalf("profile %q: %v", name, err)
+		}
+		if p.Title != title {
+			t.Fatalf("profile %q title = %g, want %g", name, p.Title, title)
+		}
+	}
+	wantTitle("", 1)
+
+	// содержимое сменилось, а mtime нет - файл не перечитывается
+	writeFile(t, path, "default: a\nprofiles:\n  a: {title: 2}\n", mtime)
+	s.reload()
+	wantTitle("", 1)
+
+	mtime = mtime.Add(time.Minute)
+	writeFile(t, path, "default: b\nprofiles:\n  a: {title: 2}\n  b: {title: 3}\n", mtime)
+	s.reload()
+	wantTitle("", 3)
+	wantTitle("a", 2)
+
+	// невалидный файл отклоняется, работают прежние профили
+	mtime = mtime.Add(time.Minute)
+	writeFile(t, path, "default: a\nprofiles:\n  a: {title: -1}\n", mtime)
+	s.reload()
+	wantTitle("", 3)
+	wantTitle("a", 2)
+	if _, err := s.Profile("c"); !errors.Is(err, core.ErrUnknownProfile) {
+		t.Fatalf("unknown profile: err = %v, want ErrUnknownProfile", err)
+	}
+
+	// исправленный файл снова применяется
+	mtime = mtime.Add(time.Minute)
+	writeFile(t, path, "default: a\nprofiles:\n  a: {title: 4}\n", mtime)
+	s.reload()
+	wantTitle("", 4)
+	if _, err := s.Profile("b"); !errors.Is(err, core.ErrUnknownProfile) {
+		t.Fatalf("removed profile: err = %v, want ErrUnknownProfile", err)
+	}
+}
+
+func writeFile(t *testing.T, path, data string, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("chtimes %s: %v", path, err)
+	}
+}
@@ -2,47 +2,127 @@ package words
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"hash/fnv"
 	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"yadro.com/course/pkg/admintoken"
+	"yadro.com/course/pkg/grpcclient"
 	wordspb "yadro.com/course/proto/words"
 	"yadro.com/course/search/core"
 )
 
+// Горячие фразы после сброса кэша раскрываются заново одним NormBatch в пределах его лимитов в words
+const (
+	maxRefillPhrases = 1000
+	maxRefillLen     = 1 << 20
+	refillTimeout    = 5 * time.Second
+)
+
+// defaultAnalyzer - цепочка words, если в конфиге search она не задана
+const defaultAnalyzer = "default"
+
+// maxSeenVersions - сколько версий помнить, чтобы возврат к ним не досылал кэш
+const maxSeenVersions = 16
+
 type Client struct {
 	log    *slog.Logger
 	client wordspb.WordsClient
 	conn   *grpc.ClientConn
+
+	// cache - результаты Expand по версиям цепочки и синонимов и исходной фразе, nil - без кэша
+	cache *core.LRU[[]core.WeightedToken]
+
+	// analyzer - цепочка words для запросов, пустая - default;
+	// versions - версии цепочки и словаря синонимов из последнего ответа words;
+	// seen - версии, которые уже встречались у реплик words
+	analyzer string
+	versions atomic.Pointer[versions]
+	seenMu   sync.Mutex
+	seen     map[versions]struct{}
+
+	// language - язык запросов, должен совпадать с языком индексации update; пустой - автоопределение
+	language string
+
+	// refills - фоновые пакеты refill, Close их дожидается
+	refills sync.WaitGroup
+
+	// address - адрес words из конфига, за ним может быть несколько реплик;
+	// resolve - их адреса, nil - без раскрытия, через основное соединение
+	address string
+	resolve func(ctx context.Context, host string) ([]string, error)
+	// dictionary - последний словарь для Correct, его досылает SyncDictionary
+	dictionary atomic.Pointer[wordspb.DictionaryRequest]
+	// adminToken - admin_token words для служебных вызовов
+	adminToken string
 }
 
-func NewClient(address string, log *slog.Logger) (*Client, error) {
+// NewClient - cacheSize <= 0 выключает кэш Expand; adminToken - admin_token words
+func NewClient(address, adminToken, analyzer, language string, log *slog.Logger, cacheSize int, cacheTTL time.Duration) (*Client, error) {
 	// ClientConnection - создаем подключение для локальной сети/compose
-	conn, err := grpc.NewClient(
-		address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	conn, err := grpcclient.New(address, grpcclient.Options{
+		Idempotent: []string{
+			wordspb.Words_Ping_FullMethodName,
+			wordspb.Words_Norm_FullMethodName,
+			wordspb.Words_NormBatch_FullMethodName,
+			wordspb.Words_Expand_FullMethodName,
+			wordspb.Words_SetDictionary_FullMethodName,
+			wordspb.Words_Correct_FullMethodName,
+			wordspb.Words_Vocabulary_FullMethodName,
+		},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("new grpc client for  %s: %w", address, err)
 	}
 
 	return &Client{
-		client: wordspb.NewWordsClient(conn),
-		conn:   conn,
-		log:    log,
+		client:   wordspb.NewWordsClient(conn),
+		conn:     conn,
+		log:      log,
+		cache:    core.NewLRU[[]core.WeightedToken](cacheSize, cacheTTL),
+		analyzer: analyzer,
+		language: language,
+		address:  address,
+		resolve:  net.DefaultResolver.LookupHost,
+
+		adminToken: adminToken,
 	}, nil
 
 }
 
 // Close grpc connection
-func (c *Client) Close() error { return c.conn.Close() }
+func (c *Client) Close() error {
+	c.refills.Wait()
+	return c.conn.Close()
+}
+
+// Expand реализация порта Words
+// Делает grpc вызов Expand: слова запроса с весом 1 и синонимы, маппит ошибки в доменные
+func (c *Client) Expand(ctx context.Context, phrase string) ([]core.WeightedToken, error) {
+	if terms, ok := c.cache.Get(c.cacheKey(phrase)); ok {
+		return terms, nil
+	}
 
-// Norm реализация порта normalizer
-// Делает grpc вызов Norm и маппит ошибки в доменные
-func (c *Client) Norm(ctx context.Context, phrase string) ([]string, error) {
-	resp, err := c.client.Norm(ctx, &wordspb.WordsRequest{Phrase: phrase})
+	req := &wordspb.WordsRequest{Phrase: phrase}
+	if c.analyzer != "" {
+		req.Analyzer = &c.analyzer
+	}
+	// короткий запрос автоопределение легко отнесет к другому языку, и стемы разойдутся с индексом
+	if c.language != "" {
+		req.Language = &c.language
+	}
+	resp, err := c.client.Expand(ctx, req)
 	if err != nil {
 		switch status.Code(err) {
 		case codes.ResourceExhausted:
@@ -53,5 +133,299 @@ func (c *Client) Norm(ctx context.Context, phrase string) ([]string, error) {
 			return nil, err
 		}
 	}
-	return resp.GetWords(), nil
+	c.setVersion(resp.GetAnalyzerVersion(), resp.GetSynonymsVersion())
+
+	terms := make([]core.WeightedToken, 0, len(resp.GetWords()))
+	for _, w := range resp.GetWords() {
+		terms = append(terms, core.WeightedToken{Token: w.GetWord(), Weight: w.GetWeight()})
+	}
+	v := versions{analyzer: resp.GetAnalyzerVersion(), synonyms: resp.GetSynonymsVersion()}
+	c.cache.Put(v.key(phrase), terms)
+	return terms, nil
+}
+
+// refill - горячие фразы старой версии раскрываются одним NormBatch вместо промаха
+// на каждый следующий запрос. Ответ кэшируется под версией, которой посчитан
+func (c *Client) refill(keys []string) {
+	var phrases []string
+	total := 0
+	for _, key := range keys {
+		phrase := strings.SplitN(key, "\x00", 3)[2] // ключ из cacheKey: версии и фраза
+		if len(phrases) == maxRefillPhrases || total+len(phrase) > maxRefillLen {
+			break
+		}
+		phrases = append(phrases, phrase)
+		total += len(phrase)
+	}
+	if len(phrases) == 0 {
+		return
+	}
+
+	req := &wordspb.NormBatchRequest{Expand: true, Items: make([]*wordspb.NormItem, 0, len(phrases))}
+	if c.analyzer != "" {
+		req.Analyzer = &c.analyzer
+	}
+	if c.language != "" {
+		req.Language = &c.language
+	}
+	for i, p := range phrases {
+		req.Items = append(req.Items, &wordspb.NormItem{Id: strconv.Itoa(i), Phrase: p})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), refillTimeout)
+	defer cancel()
+	resp, err := c.client.NormBatch(ctx, req)
+	if err != nil {
+		c.log.Warn("expand cache refill failed", "phrases", len(phrases), "error", err)
+		return
+	}
+
+	refilled := 0
+	for i, r := range resp.GetResults() {
+		if i >= len(phrases) || codes.Code(r.GetCode()) != codes.OK || len(r.GetWeights()) != len(r.GetWords()) {
+			continue
+		}
+		terms := make([]core.WeightedToken, 0, len(r.GetWords()))
+		for j, w := range r.GetWords() {
+			terms = append(terms, core.WeightedToken{Token: w, Weight: r.GetWeights()[j]})
+		}
+		v := versions{analyzer: r.GetAnalyzerVersion(), synonyms: r.GetSynonymsVersion()}
+		c.cache.Put(v.key(phrases[i]), terms)
+		refilled++
+	}
+	c.log.Debug("expand cache refilled", "phrases", len(phrases), "refilled", refilled)
+}
+
+// cacheKey - версии цепочки и синонимов входят в ключ: токены разных версий несравнимы,
+// а перезагрузка синонимов меняет раскрытие при той же цепочке. Ищем под последней версией
+func (c *Client) cacheKey(phrase string) string {
+	return c.current().key(phrase)
+}
+
+// versions - версии цепочки и синонимов меняются вместе, одним указателем
+type versions struct {
+	analyzer, synonyms string
+}
+
+func (v versions) key(phrase string) string {
+	return v.analyzer + "\x00" + v.synonyms + "\x00" + phrase
+}
+
+func (c *Client) current() versions {
+	if v := c.versions.Load(); v != nil {
+		return *v
+	}
+	return versions{}
+}
+
+// setVersion - words сменил цепочку, ее словари (рестарт с новым конфигом) или перечитал синонимы.
+// Кэш не сбрасывается: записи лежат под версией, которой посчитаны, и во время rolling deploy,
+// когда round_robin приносит ответы реплик с разными версиями, записи обеих живут рядом,
+// пока их не вытеснит LRU. Горячие фразы прежней версии досылаются одним пакетом только
+// при первой встрече новой версии и только тем, кто первым ее установил.
+// Версии приходят в ответах Expand и из RefreshVersions
+func (c *Client) setVersion(analyzer, synonyms string) {
+	next := versions{analyzer: analyzer, synonyms: synonyms}
+	old := c.versions.Load()
+	if old != nil && *old == next {
+		return
+	}
+	if old == nil && next == (versions{}) {
+		return
+	}
+	if !c.versions.CompareAndSwap(old, &next) {
+		return
+	}
+	if !c.markSeen(next) || old == nil {
+		return
+	}
+
+	prefix := old.key("")
+	var hot []string
+	for _, key := range c.cache.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			hot = append(hot, key)
+		}
+	}
+	c.log.Info("query analyzer version changed, expand cache refilled", "version", analyzer, "synonyms", synonyms, "refill", len(hot))
+	if len(hot) > 0 {
+		c.refills.Go(func() { c.refill(hot) })
+	}
+}
+
+// markSeen - false, если версия уже встречалась
+func (c *Client) markSeen(v versions) bool {
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+	if _, ok := c.seen[v]; ok {
+		return false
+	}
+	if c.seen == nil || len(c.seen) >= maxSeenVersions {
+		c.seen = make(map[versions]struct{})
+	}
+	c.seen[v] = struct{}{}
+	return true
+}
+
+// RefreshVersions - версии цепочки запросов и синонимов из Vocabulary: смена видна сразу,
+// а не на первом промахе, и горячие фразы не отдают старое раскрытие до конца TTL
+func (c *Client) RefreshVersions(ctx context.Context) error {
+	vocab, err := c.client.Vocabulary(admintoken.Outgoing(ctx, c.adminToken), &emptypb.Empty{})
+	if err != nil {
+		return fmt.Errorf("words vocabulary: %w", err)
+	}
+	name := c.analyzer
+	if name == "" {
+		name = defaultAnalyzer
+	}
+	for _, an := range vocab.GetAnalyzers() {
+		if an.GetName() == name {
+			c.setVersion(an.GetVersion(), vocab.GetSynonymsVersion())
+			return nil
+		}
+	}
+	return fmt.Errorf("words has no analyzer %q", name)
+}
+
+// RunVersionCheck - RefreshVersions раз в interval до отмены ctx
+func (c *Client) RunVersionCheck(ctx context.Context, interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			if err := c.RefreshVersions(ctx); err != nil {
+				c.log.Warn("words version check failed", "error", err)
+			}
+		}
+	}
+}
+
+// SetDictionary - словарь корпуса для Correct в words, одним сообщением каждой реплике:
+// словарь живет в памяти words, и через round_robin его получила бы только одна из них
+func (c *Client) SetDictionary(ctx context.Context, counts map[string]int, surfaces map[string]string) error {
+	req := &wordspb.DictionaryRequest{Terms: make([]*wordspb.DictionaryTerm, 0, len(counts)), Analyzer: c.analyzer}
+	for term, n := range counts {
+		req.Terms = append(req.Terms, &wordspb.DictionaryTerm{Term: term, Count: uint32(n), Surface: surfaces[term]})
+	}
+	req.Version = dictionaryVersion(req.GetTerms())
+	c.dictionary.Store(req)
+
+	ctx = admintoken.Outgoing(ctx, c.adminToken)
+	return c.eachReplica(ctx, func(client wordspb.WordsClient) error {
+		_, err := client.SetDictionary(ctx, req)
+		return err
+	})
+}
+
+// SyncDictionary - досылает последний словарь репликам words с другой версией:
+// перезапущенная или новая реплика стартует без словаря, и Correct на ней недоступен
+func (c *Client) SyncDictionary(ctx context.Context) error {
+	req := c.dictionary.Load()
+	if req == nil {
+		return nil
+	}
+	ctx = admintoken.Outgoing(ctx, c.adminToken)
+	return c.eachReplica(ctx, func(client wordspb.WordsClient) error {
+		vocab, err := client.Vocabulary(ctx, &emptypb.Empty{})
+		if err != nil {
+			return err
+		}
+		if vocab.GetDictionaryVersion() == req.GetVersion() {
+			return nil
+		}
+		c.log.Info("words replica has stale spelling dictionary, pushing", "have", vocab.GetDictionaryVersion(), "version", req.GetVersion())
+		_, err = client.SetDictionary(ctx, req)
+		return err
+	})
+}
+
+// RunDictionarySync - SyncDictionary раз в interval до отмены ctx
+func (c *Client) RunDictionarySync(ctx context.Context, interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			if err := c.SyncDictionary(ctx); err != nil {
+				c.log.Warn("spelling dictionary sync failed", "error", err)
+			}
+		}
+	}
+}
+
+// eachReplica - fn для каждой реплики words за именем из адреса: имя раскрывается в адреса,
+// к каждому открывается свое соединение. Адрес со схемой (dns:///) или без resolve
+// идет одним вызовом через основное соединение. Ошибки реплик собираются вместе
+func (c *Client) eachReplica(ctx context.Context, fn func(wordspb.WordsClient) error) error {
+	host, port, err := net.SplitHostPort(c.address)
+	if err != nil || c.resolve == nil {
+		return dictionaryError(fn(c.client))
+	}
+	addrs, err := c.resolve(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve words replicas %s: %w", host, err)
+	}
+
+	var errs []error
+	for _, a := range addrs {
+		addr := net.JoinHostPort(a, port)
+		conn, err := grpcclient.New(addr, grpcclient.Options{Idempotent: []string{
+			wordspb.Words_SetDictionary_FullMethodName,
+			wordspb.Words_Vocabulary_FullMethodName,
+		}})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("words replica %s: %w", addr, err))
+			continue
+		}
+		if err := dictionaryError(fn(wordspb.NewWordsClient(conn))); err != nil {
+			errs = append(errs, fmt.Errorf("words replica %s: %w", addr, err))
+		}
+		_ = conn.Close()
+	}
+	return errors.Join(errs...)
+}
+
+var _ core.Speller = (*Client)(nil)
+
+func dictionaryError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch status.Code(err) {
+	case codes.ResourceExhausted, codes.InvalidArgument:
+		return core.ErrBadArguments
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return core.ErrUnavailable
+	default:
+		return err
+	}
+}
+
+// dictionaryVersion - хэш словаря, не зависящий от порядка термов:
+// одинаковый словарь от разных реплик search не рассылается заново
+func dictionaryVersion(terms []*wordspb.DictionaryTerm) string {
+	sorted := slices.Clone(terms)
+	slices.SortFunc(sorted, func(a, b *wordspb.DictionaryTerm) int {
+		return strings.Compare(a.GetTerm(), b.GetTerm())
+	})
+	h := fnv.New64a()
+	for _, t := range sorted {
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00%s\n", t.GetTerm(), t.GetCount(), t.GetSurface())
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// AnalyzerVersion - версия цепочки запросов для IndexStats, пустая до первого ответа words
+func (c *Client) AnalyzerVersion() string {
+	return c.current().analyzer
+}
+
+// CacheStats - счетчики кэша Expand для IndexStats
+func (c *Client) CacheStats() core.CacheStats {
+	return c.cache.Stats()
 }
@@ -0,0 +1,307 @@
+package words
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"yadro.com/course/pkg/admintoken"
+	wordspb "yadro.com/course/proto/words"
+	"yadro.com/course/search/core"
+)
+
+// fakeWords - words, у которого тест меняет версии цепочки и синонимов и ответ,
+// как при рестарте с новым конфигом или перезагрузке словаря синонимов
+type fakeWords struct {
+	wordspb.WordsClient
+	version  string
+	synonyms string
+	words    []string
+	calls    int
+	batches  [][]string
+	last     *wordspb.WordsRequest
+}
+
+func (f *fakeWords) Expand(_ context.Context, in *wordspb.WordsRequest, _ ...grpc.CallOption) (*wordspb.ExpandReply, error) {
+	f.calls++
+	f.last = in
+	reply := &wordspb.ExpandReply{AnalyzerVersion: f.version, SynonymsVersion: f.synonyms}
+	for _, w := range f.words {
+		reply.Words = append(reply.Words, &wordspb.WeightedWord{Word: w, Weight: 1})
+	}
+	return reply, nil
+}
+
+// NormBatch - пакет refill: каждая фраза раскрывается в words с весом 1
+func (f *fakeWords) NormBatch(_ context.Context, in *wordspb.NormBatchRequest, _ ...grpc.CallOption) (*wordspb.NormBatchReply, error) {
+	var phrases []string
+	reply := &wordspb.NormBatchReply{}
+	for _, item := range in.GetItems() {
+		phrases = append(phrases, item.GetPhrase())
+		r := &wordspb.NormResult{Id: item.GetId(), Words: f.words, AnalyzerVersion: f.version, SynonymsVersion: f.synonyms}
+		if !in.GetExpand() {
+			r.Code, r.Error = uint32(codes.InvalidArgument), "refill without expand"
+		}
+		for range f.words {
+			r.Weights = append(r.Weights, 1)
+		}
+		reply.Results = append(reply.Results, r)
+	}
+	f.batches = append(f.batches, phrases)
+	return reply, nil
+}
+
+func TestExpandCache_Versions(t *testing.T) {
+	fake := &fakeWords{version: "v1", synonyms: "s1", words: []string{"run"}}
+	c := &Client{
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client: fake,
+		cache:  core.NewLRU[[]core.WeightedToken](10, time.Hour),
+	}
+	ctx := context.Background()
+
+	norm := func(phrase string, want ...string) {
+		t.Helper()
+		got, err := c.Expand(ctx, phrase)
+		if err != nil {
+			t.Fatalf("expand %q: %v", phrase, err)
+		}
+		if !slices.Equal(core.Tokens(got), want) {
+			t.Fatalf("expand %q = %v, want %v", phrase, got, want)
+		}
+	}
+
+	norm("running", "run")
+	norm("running", "run")
+	if fake.calls != 1 {
+		t.Fatalf("words calls = %d, want 1 (second from cache)", fake.calls)
+	}
+
+	// words сменил цепочку: первый же промах приносит новую версию,
+	// а фразы старой версии раскрываются заново одним пакетом
+	fake.version, fake.words = "v2", []string{"running"}
+	norm("jumps", "running")
+	if c.AnalyzerVersion() != "v2" {
+		t.Fatalf("version = %q, want v2", c.AnalyzerVersion())
+	}
+	c.refills.Wait()
+	norm("running", "running")
+	if fake.calls != 2 || len(fake.batches) != 1 || !slices.Equal(fake.batches[0], []string{"running"}) {
+		t.Fatalf("calls = %d, batches = %v, want v1 entry refilled with v2 in one batch", fake.calls, fake.batches)
+	}
+
+	// перезагрузка синонимов при той же цепочке - тоже новая версия
+	fake.synonyms, fake.words = "s2", []string{"running", "sprint"}
+	norm("walks", "running", "sprint")
+	c.refills.Wait()
+	norm("running", "running", "sprint")
+	norm("jumps", "running", "sprint")
+	if fake.calls != 3 || len(fake.batches) != 2 || len(fake.batches[1]) != 2 {
+		t.Fatalf("calls = %d, batches = %v, want s1 entries refilled with s2", fake.calls, fake.batches)
+	}
+}
+
+func TestExpandCache_MixedVersions(t *testing.T) {
+	fake := &fakeWords{version: "v1", synonyms: "s1", words: []string{"run"}}
+	c := &Client{
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client: fake,
+		cache:  core.NewLRU[[]core.WeightedToken](10, time.Hour),
+	}
+	ctx := context.Background()
+	expand := func(phrase string) {
+		t.Helper()
+		if _, err := c.Expand(ctx, phrase); err != nil {
+			t.Fatalf("expand %q: %v", phrase, err)
+		}
+	}
+
+	// rolling deploy: round_robin чередует реплики v1 и v2. Новая v2 один раз досылает кэш,
+	// а возврат к уже встреченной v1 ничего не сбрасывает - ее записи снова отвечают из кэша
+	expand("running")
+	fake.version = "v2"
+	expand("jumps")
+	c.refills.Wait()
+	fake.version = "v1"
+	expand("walks")
+	c.refills.Wait()
+	calls := fake.calls
+	expand("running")
+	if fake.calls != calls {
+		t.Fatal("v1 entry was purged by the v2 replica")
+	}
+	fake.version = "v2"
+	expand("swims")
+	c.refills.Wait()
+	expand("jumps")
+	if len(fake.batches) != 1 || fake.calls != calls+1 {
+		t.Fatalf("calls = %d, batches = %v, want one refill for the first v2 answer", fake.calls-calls, fake.batches)
+	}
+}
+
+// Vocabulary - версия default и синонимов, как у Expand
+func (f *fakeWords) Vocabulary(context.Context, *emptypb.Empty, ...grpc.CallOption) (*wordspb.VocabularyReply, error) {
+	return &wordspb.VocabularyReply{
+		Analyzers:       []*wordspb.AnalyzerInfo{{Name: "default", Version: f.version}},
+		SynonymsVersion: f.synonyms,
+	}, nil
+}
+
+func TestRefreshVersions(t *testing.T) {
+	fake := &fakeWords{version: "v1", synonyms: "s1", words: []string{"run"}}
+	c := &Client{
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client: fake,
+		cache:  core.NewLRU[[]core.WeightedToken](10, time.Hour),
+	}
+	ctx := context.Background()
+	if _, err := c.Expand(ctx, "running"); err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+
+	// смена версии видна без промаха: горячая фраза сразу получает новое раскрытие
+	fake.version, fake.words = "v2", []string{"running"}
+	if err := c.RefreshVersions(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	c.refills.Wait()
+	got, err := c.Expand(ctx, "running")
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	if !slices.Equal(core.Tokens(got), []string{"running"}) || fake.calls != 1 || len(fake.batches) != 1 {
+		t.Fatalf("expand = %v, calls = %d, batches = %d, want v2 from refill", got, fake.calls, len(fake.batches))
+	}
+
+	// одновременные вызовы с одной новой версией: досылает кэш только один
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() { c.setVersion("v3", "s1") })
+	}
+	wg.Wait()
+	c.refills.Wait()
+	if len(fake.batches) != 2 {
+		t.Fatalf("batches = %d, want a single refill for v3", len(fake.batches))
+	}
+}
+
+func TestExpand_Language(t *testing.T) {
+	fake := &fakeWords{words: []string{"run"}}
+	c := &Client{
+		log:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		client:   fake,
+		analyzer: "default",
+		language: "english",
+	}
+	if _, err := c.Expand(context.Background(), "running"); err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	// язык запроса тот же, что у update при индексации, а не автоопределение
+	if fake.last.GetLanguage() != "english" || fake.last.GetAnalyzer() != "default" {
+		t.Fatalf("request = %+v, want english and default analyzer", fake.last)
+	}
+
+	c.language = ""
+	if _, err := c.Expand(context.Background(), "running"); err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	if fake.last.Language != nil {
+		t.Fatalf("empty language sent as %q, want autodetect", fake.last.GetLanguage())
+	}
+}
+
+// replicaWords - реплика words со словарем Correct в памяти: рестарт его теряет
+type replicaWords struct {
+	wordspb.UnimplementedWordsServer
+	mu      sync.Mutex
+	version string
+	surface string
+	pushes  int
+}
+
+func (r *replicaWords) SetDictionary(ctx context.Context, in *wordspb.DictionaryRequest) (*wordspb.DictionaryReply, error) {
+	// словарь может заменить только search с admin_token words
+	if md, _ := metadata.FromIncomingContext(ctx); !slices.Equal(md.Get(admintoken.MetadataKey), []string{"secret"}) {
+		return nil, status.Error(codes.PermissionDenied, "admin token required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.version, r.pushes = in.GetVersion(), r.pushes+1
+	r.surface = in.GetTerms()[0].GetSurface()
+	return &wordspb.DictionaryReply{Terms: uint32(len(in.GetTerms()))}, nil
+}
+
+func (r *replicaWords) Vocabulary(context.Context, *emptypb.Empty) (*wordspb.VocabularyReply, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &wordspb.VocabularyReply{DictionaryVersion: r.version}, nil
+}
+
+func (r *replicaWords) state() (string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.version, r.pushes
+}
+
+// serveReplica - реплика на ip:port, как вторая запись dns за тем же именем
+func serveReplica(t *testing.T, addr string) (*replicaWords, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("listen %s: %v", addr, err)
+	}
+	r := &replicaWords{}
+	srv := grpc.NewServer()
+	wordspb.RegisterWordsServer(srv, r)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	return r, port
+}
+
+func TestSetDictionary_AllReplicas(t *testing.T) {
+	first, port := serveReplica(t, "127.0.0.1:0")
+	second, _ := serveReplica(t, "127.0.0.2:"+port)
+	c := &Client{
+		log:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		address:    "words:" + port,
+		adminToken: "secret",
+		resolve: func(context.Context, string) ([]string, error) {
+			return []string{"127.0.0.1", "127.0.0.2"}, nil
+		},
+	}
+	ctx := context.Background()
+
+	if err := c.SetDictionary(ctx, map[string]int{"machin": 3}, map[string]string{"machin": "machine"}); err != nil {
+		t.Fatalf("set dictionary: %v", err)
+	}
+	v1, n1 := first.state()
+	v2, n2 := second.state()
+	if n1 != 1 || n2 != 1 || v1 == "" || v1 != v2 || first.surface != "machine" {
+		t.Fatalf("replicas = (%q, %d), (%q, %d), want the same dictionary on both", v1, n1, v2, n2)
+	}
+
+	// вторая реплика перезапустилась без словаря: сверка досылает его только ей
+	second.mu.Lock()
+	second.version = ""
+	second.mu.Unlock()
+	if err := c.SyncDictionary(ctx); err != nil {
+		t.Fatalf("sync dictionary: %v", err)
+	}
+	_, n1 = first.state()
+	v2, n2 = second.state()
+	if n1 != 1 || n2 != 2 || v2 != v1 {
+		t.Fatalf("pushes = %d, %d and version %q, want only the restarted replica updated to %q", n1, n2, v2, v1)
+	}
+}
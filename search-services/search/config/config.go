@@ -2,8 +2,10 @@ package config
 
 import (
 	"github.com/ilyakaznacheev/cleanenv"
+
 	"log"
 	"time"
+	"yadro.com/course/pkg/tracing"
 )
 
 type Broker struct {
@@ -11,12 +13,55 @@ type Broker struct {
 }
 
 type Config struct {
-	LogLevel     string        `yaml:"log_level" env:"LOG_LEVEL" env-default:"DEBUG"`
-	Address      string        `yaml:"search_address" env:"SEARCH_ADDRESS" env-default:"localhost:83"`
-	DBAddress    string        `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
-	WordsAddress string        `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:81"`
-	IndexTTL     time.Duration `yaml:"index_ttl" env:"INDEX_TTL" env-default:"24h"`
-	Broker       Broker        `yaml:"broker"`
+	LogLevel     string `yaml:"log_level" env:"LOG_LEVEL" env-default:"DEBUG"`
+	Address      string `yaml:"search_address" env:"SEARCH_ADDRESS" env-default:"localhost:83"`
+	DBAddress    string `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
+	WordsAddress string `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:81"`
+	// WordsAnalyzer - цепочка words для запросов, должна давать те же термы, что и цепочка update
+	WordsAnalyzer string `yaml:"words_analyzer" env:"WORDS_ANALYZER" env-default:"default"`
+	// WordsLanguage - язык запросов, тот же, что у update при индексации; пусто - автоопределение words
+	WordsLanguage string        `yaml:"words_language" env:"WORDS_LANGUAGE" env-default:"english"`
+	IndexTTL      time.Duration `yaml:"index_ttl" env:"INDEX_TTL" env-default:"24h"`
+	Broker        Broker        `yaml:"broker"`
+
+	// WordsVersionCheck - как часто спрашивать у words версии цепочки и синонимов, чтобы сбросить
+	// кэш Expand сразу после их смены; 0 - только по ответам Expand
+	WordsVersionCheck time.Duration `yaml:"words_version_check" env:"WORDS_VERSION_CHECK" env-default:"10s"`
+
+	// WordsDictionarySync - как часто сверять словарь Correct на репликах words и досылать его
+	// перезапущенным; 0 - только после пересборки индекса
+	WordsDictionarySync time.Duration `yaml:"words_dictionary_sync" env:"WORDS_DICTIONARY_SYNC" env-default:"1m"`
+
+	// AdminToken - токен, с которым api вызывает методы суперпользователя (индекс, аналитика,
+	// выгрузка базы); пустой - вызовы закрыты
+	AdminToken string `yaml:"admin_token" env:"SEARCH_ADMIN_TOKEN"`
+	// WordsAdminToken - admin_token words для Vocabulary и SetDictionary: сверка версий и словаря Correct
+	WordsAdminToken string `yaml:"words_admin_token" env:"WORDS_ADMIN_TOKEN"`
+
+	// SearchBackend - array (пересечение массивов + ранжирование в Go) или fts (Postgres full-text)
+	SearchBackend string `yaml:"search_backend" env:"SEARCH_BACKEND" env-default:"array"`
+
+	// RankingFile - YAML с профилями ранжирования, пусто - только встроенный профиль default
+	RankingFile   string        `yaml:"ranking_file" env:"RANKING_FILE" env-default:""`
+	RankingReload time.Duration `yaml:"ranking_reload" env:"RANKING_RELOAD" env-default:"5s"`
+
+	// размеры кэшей в записях, 0 - кэш выключен
+	CacheSize     int           `yaml:"cache_size" env:"CACHE_SIZE" env-default:"1000"`
+	CacheTTL      time.Duration `yaml:"cache_ttl" env:"CACHE_TTL" env-default:"5m"`
+	NormCacheSize int           `yaml:"norm_cache_size" env:"NORM_CACHE_SIZE" env-default:"5000"`
+	NormCacheTTL  time.Duration `yaml:"norm_cache_ttl" env:"NORM_CACHE_TTL" env-default:"1h"`
+
+	// журнал запросов пишется в БД пачками по query_log_batch или раз в query_log_flush,
+	// при заполненном буфере новые записи отбрасываются
+	QueryLogBuffer int           `yaml:"query_log_buffer" env:"QUERY_LOG_BUFFER" env-default:"1024"`
+	QueryLogBatch  int           `yaml:"query_log_batch" env:"QUERY_LOG_BATCH" env-default:"100"`
+	QueryLogFlush  time.Duration `yaml:"query_log_flush" env:"QUERY_LOG_FLUSH" env-default:"2s"`
+
+	// MetricsAddress - /metrics для Prometheus на отдельном порту, пусто - выключено
+	MetricsAddress string `yaml:"metrics_address" env:"METRICS_ADDRESS" env-default:":9090"`
+
+	// Tracing - экспорт OTLP трейсов, по умолчанию выключен
+	Tracing tracing.Config `yaml:"tracing"`
 }
 
 func MustLoad(configPath string) Config {
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU_Eviction(t *testing.T) {
+	c := NewLRU[int](2, 0)
+	c.Put("a", 1)
+	c.Put("b", 2)
+
+	// a становится свежей, вытесняется b
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("get a = %d, %v", v, ok)
+	}
+	c.Put("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("least recently used entry b was not evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if v, ok := c.Get(key); !ok || v != want {
+			t.Fatalf("get %s = %d, %v, want %d", key, v, ok, want)
+		}
+	}
+
+	// перезапись не увеличивает число записей
+	c.Put("a", 10)
+	if v, _ := c.Get("a"); v != 10 {
+		t.Fatalf("overwritten a = %d, want 10", v)
+	}
+	st := c.Stats()
+	if st.Entries != 2 || st.Capacity != 2 || st.Hits != 4 || st.Misses != 1 {
+		t.Fatalf("stats = %+v, want 2 entries, 4 hits, 1 miss", st)
+	}
+
+	c.Purge()
+	if _, ok := c.Get("a"); ok || c.Stats().Entries != 0 {
+		t.Fatal("entries left after purge")
+	}
+}
+
+func TestLRU_TTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU[string](10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Put("a", "x")
+	now = now.Add(30 * time.Second)
+	c.Put("b", "y")
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a expired before ttl")
+	}
+
+	// чтение не продлевает запись, перезапись продлевает
+	now = now.Add(31 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("a served after ttl")
+	}
+	if c.Stats().Entries != 1 {
+		t.Fatalf("expired entry was not removed, entries = %d", c.Stats().Entries)
+	}
+	c.Put("b", "z")
+	now = now.Add(59 * time.Second)
+	if v, ok := c.Get("b"); !ok || v != "z" {
+		t.Fatalf("get b = %q, %v, want refreshed z", v, ok)
+	}
+}
+
+func TestLRU_Disabled(t *testing.T) {
+	c := NewLRU[int](0, time.Minute)
+	if c != nil {
+		t.Fatal("zero capacity must disable cache")
+	}
+	c.Put("a", 1)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("disabled cache returned a value")
+	}
+	c.Purge()
+	if st := c.Stats(); st != (CacheStats{}) {
+		t.Fatalf("disabled cache stats = %+v", st)
+	}
+}
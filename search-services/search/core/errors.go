@@ -3,10 +3,12 @@ package core
 import "errors"
 
 var (
-	ErrEmptyPhrase   = errors.New("empty phrase")
-	ErrToLargeLimit  = errors.New("too large limit")
-	ErrUnavailable   = errors.New("dependency unavailable")
-	ErrBadArguments  = errors.New("arguments are not acceptable")
-	ErrNonePhrase    = errors.New("this is too philosophical, try something less abstract))")
-	ErrComicNotFound = errors.New("comic not found")
+	ErrEmptyPhrase    = errors.New("empty phrase")
+	ErrToLargeLimit   = errors.New("too large limit")
+	ErrUnavailable    = errors.New("dependency unavailable")
+	ErrBadArguments   = errors.New("arguments are not acceptable")
+	ErrNonePhrase     = errors.New("this is too philosophical, try something less abstract))")
+	ErrComicNotFound  = errors.New("comic not found")
+	ErrUnknownProfile = errors.New("unknown ranking profile")
+	ErrIndexNotReady  = errors.New("index is not built yet")
 )
@@ -1,93 +1,501 @@
 package core
 
 import (
+	"cmp"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
+const (
+	maxShards = 16
+	// parallelQueryDocs - на меньших индексах шарды обходятся по очереди:
+	// запуск горутин дороже самого поиска по 3k комиксов
+	parallelQueryDocs = 1 << 14
+)
+
+// InvertedIndex - индекс токен -> комиксы, разбитый на шарды по хэшу id.
+// Читатели работают с неизменяемым снимком, новая сборка строится в стороне (шарды параллельно)
+// и подменяет снимок атомарно, поэтому поиск не блокируется на время пересборки
 type InvertedIndex struct {
-	mu      sync.RWMutex
-	byToken map[string][]int
-	docs    map[int]Comics
+	mu        sync.Mutex // сериализует сборки
+	shardBits int
+	current   atomic.Pointer[indexSnapshot]
+}
+
+type indexSnapshot struct {
+	shards []indexShard
+	docs   int
+	// pickable - комиксы с картинкой по возрастанию id, для случайного выбора и комикса дня
+	pickable []docRef
+	// versions - число комиксов по версии цепочки words
+	versions map[string]int
+
+	// сведения о сборке, отдаются в IndexStats
+	generation    uint64
+	builtAt       time.Time
+	buildDuration time.Duration
+	trigger       IndexTrigger
+}
+
+// docRef - комикс в снимке: шард и номер в его docs
+type docRef struct {
+	shard uint32
+	ord   uint32
+}
+
+// indexShard - posting lists хранят не id, а номера комиксов в docs.
+// docs отсортирован по id, поэтому списки номеров тоже упорядочены по id
+type indexShard struct {
+	docs     []Comics
+	postings map[string][]uint32
 }
 
+// NewInvertedIndex - по шарду на процессор, но не больше maxShards
 func NewInvertedIndex() *InvertedIndex {
-	return &InvertedIndex{
-		byToken: make(map[string][]int),
-		docs:    make(map[int]Comics),
+	return newInvertedIndex(min(runtime.GOMAXPROCS(0), maxShards))
+}
+
+// newInvertedIndex - число шардов округляется вверх до степени двойки
+func newInvertedIndex(shards int) *InvertedIndex {
+	idx := &InvertedIndex{}
+	for 1<<idx.shardBits < shards {
+		idx.shardBits++
+	}
+	idx.current.Store(&indexSnapshot{shards: make([]indexShard, 1<<idx.shardBits)})
+	return idx
+}
+
+// shardOf - фибоначчиево хэширование, соседние id расходятся по разным шардам
+func (idx *InvertedIndex) shardOf(id int) int {
+	if idx.shardBits == 0 {
+		return 0
 	}
+	return int(uint32(id) * 2654435769 >> (32 - idx.shardBits))
 }
 
-func (idx *InvertedIndex) Build(comics []Comics) {
-	byToken := make(map[string][]int, len(comics)*4)
-	docs := make(map[int]Comics, len(comics))
+// Build - собирает новый снимок и подменяет им текущий, generation увеличивается на каждую сборку
+func (idx *InvertedIndex) Build(comics []Comics, trigger IndexTrigger) uint64 {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
 
+	start := time.Now()
+	parts := make([][]Comics, 1<<idx.shardBits)
 	for _, c := range comics {
-		docs[c.ID] = c
+		i := idx.shardOf(c.ID)
+		parts[i] = append(parts[i], c)
+	}
 
-		seen := make(map[string]struct{}, len(c.Title)+len(c.Alt)+len(c.Words))
-		add := func(tok string) {
-			if tok == "" {
-				return
-			}
-			if _, ok := seen[tok]; ok {
-				return
+	shards := make([]indexShard, len(parts))
+	var wg sync.WaitGroup
+	for i := range parts {
+		wg.Go(func() { shards[i] = buildShard(parts[i]) })
+	}
+	wg.Wait()
+
+	snap := &indexSnapshot{
+		shards:     shards,
+		docs:       len(comics),
+		pickable:   pickable(shards),
+		versions:   analyzerVersions(comics),
+		generation: idx.current.Load().generation + 1,
+		builtAt:    time.Now(),
+		trigger:    trigger,
+	}
+	snap.buildDuration = snap.builtAt.Sub(start)
+	idx.current.Store(snap)
+
+	return snap.generation
+}
+
+func buildShard(docs []Comics) indexShard {
+	slices.SortFunc(docs, func(a, b Comics) int { return cmp.Compare(a.ID, b.ID) })
+
+	postings := make(map[string][]uint32, len(docs)*4)
+	for ord, c := range docs {
+		for _, tok := range comicTokens(c) {
+			postings[tok] = append(postings[tok], uint32(ord))
+		}
+	}
+	return indexShard{docs: docs, postings: postings}
+}
+
+// pickable - заглушки пропущенных выпусков (без картинки) не выдаются
+func pickable(shards []indexShard) []docRef {
+	var refs []docRef
+	for s, sh := range shards {
+		for ord, c := range sh.docs {
+			if c.URL != "" {
+				refs = append(refs, docRef{shard: uint32(s), ord: uint32(ord)})
 			}
-			seen[tok] = struct{}{}
-			byToken[tok] = append(byToken[tok], c.ID)
 		}
+	}
+	slices.SortFunc(refs, func(a, b docRef) int {
+		return cmp.Compare(shards[a.shard].docs[a.ord].ID, shards[b.shard].docs[b.ord].ID)
+	})
+	return refs
+}
 
-		for _, t := range c.Title {
-			add(t)
+func (snap *indexSnapshot) doc(ref docRef) Comics {
+	return snap.shards[ref.shard].docs[ref.ord]
+}
+
+// Pick - детерминированный выбор комикса по seed из комиксов с картинкой, кроме exclude
+// (отсортирован по возрастанию). Результат зависит только от seed, exclude и набора комиксов в индексе
+func (idx *InvertedIndex) Pick(seed uint64, exclude []int) (Comics, bool) {
+	snap := idx.current.Load()
+	n := len(snap.pickable)
+	if n == 0 {
+		return Comics{}, false
+	}
+
+	// равномерный выбор, исключенные пропускаем, двигаясь дальше по id
+	rnd := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	start := int(rnd.Uint64N(uint64(n)))
+	for k := range n {
+		c := snap.doc(snap.pickable[(start+k)%n])
+		if _, ok := slices.BinarySearch(exclude, c.ID); !ok {
+			return c, true
 		}
-		for _, t := range c.Alt {
-			add(t)
+	}
+	return Comics{}, false
+}
+
+// PickRendezvous - выбор комикса с картинкой взвешенным rendezvous hashing: у каждого комикса
+// свой score от seed и id, побеждает лучший. Вес 1, у популярных 1 + popularity.
+// Новый комикс или избранное у другого комикса меняют выбор, только если сами обгоняют победителя,
+// поэтому комикс дня не прыгает от каждого изменения корпуса
+func (idx *InvertedIndex) PickRendezvous(seed uint64, popularity map[int]int) (Comics, bool) {
+	snap := idx.current.Load()
+	var best Comics
+	bestScore := math.Inf(1)
+	for _, ref := range snap.pickable {
+		c := snap.doc(ref)
+		weight := 1.0
+		if p := popularity[c.ID]; p > 0 {
+			weight += float64(p)
 		}
-		for _, t := range c.Words {
-			add(t)
+		// u равномерно в (0, 1), -ln(u)/weight - экспоненциальное распределение с интенсивностью weight
+		u := (float64(mix64(seed^mix64(uint64(c.ID)))>>11) + 0.5) / (1 << 53)
+		if score := -math.Log(u) / weight; score < bestScore {
+			best, bestScore = c, score
 		}
 	}
+	return best, !math.IsInf(bestScore, 1)
+}
 
-	idx.mu.Lock()
-	defer idx.mu.Unlock()
-	idx.byToken = byToken
-	idx.docs = docs
+// mix64 - финализатор splitmix64
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Generation - номер текущего снимка, меняется при каждой сборке
+func (idx *InvertedIndex) Generation() uint64 {
+	return idx.current.Load().generation
+}
+
+// comicTokens - уникальные непустые токены комикса из title, alt и words
+func comicTokens(c Comics) []string {
+	seen := make(map[string]struct{}, len(c.Title)+len(c.Alt)+len(c.Words))
+	out := make([]string, 0, len(c.Title)+len(c.Alt)+len(c.Words))
+	for _, field := range [][]string{c.Title, c.Alt, c.Words} {
+		for _, tok := range field {
+			if tok == "" {
+				continue
+			}
+			if _, ok := seen[tok]; ok {
+				continue
+			}
+			seen[tok] = struct{}{}
+			out = append(out, tok)
+		}
+	}
+	return out
 }
 
-func (idx *InvertedIndex) DocsForTokens(tokens []string) []int {
+// Candidates - комиксы, в которых встречается хотя бы один токен и которые проходят фильтры,
+// отсортированные по ID. Работает с одним снимком, так что подмена индекса посреди запроса ему не мешает
+func (idx *InvertedIndex) Candidates(tokens []string, filters SearchFilters) []Comics {
 	if len(tokens) == 0 {
 		return nil
 	}
-	idx.mu.RLock()
-	defer idx.mu.RUnlock()
+	snap := idx.current.Load()
 
-	set := make(map[int]struct{}, len(tokens)*4)
+	parts := make([][]Comics, len(snap.shards))
+	if snap.docs < parallelQueryDocs || len(snap.shards) == 1 {
+		for i := range snap.shards {
+			parts[i] = snap.shards[i].candidates(tokens, filters)
+		}
+	} else {
+		var wg sync.WaitGroup
+		for i := range snap.shards {
+			wg.Go(func() { parts[i] = snap.shards[i].candidates(tokens, filters) })
+		}
+		wg.Wait()
+	}
+	return mergeByID(parts)
+}
+
+// candidates - объединение posting lists токенов, затем диапазон id бинарным поиском
+// и пересечение с IDs; остальные фильтры проверяются по каждому комиксу
+func (sh *indexShard) candidates(tokens []string, filters SearchFilters) []Comics {
+	lists := make([][]uint32, 0, len(tokens))
 	for _, tok := range tokens {
-		ids := idx.byToken[tok]
-		for _, id := range ids {
-			set[id] = struct{}{}
+		if p := sh.postings[tok]; len(p) > 0 {
+			lists = append(lists, p)
 		}
 	}
-	if len(set) == 0 {
+	if len(lists) == 0 {
 		return nil
 	}
-	res := make([]int, 0, len(set))
-	for id := range set {
-		res = append(res, id)
+
+	ords := unionPostings(lists)
+	if filters.IDFrom > 0 || filters.IDTo > 0 {
+		lo, hi := sh.ordRange(filters.IDFrom, filters.IDTo)
+		ords = trimPostings(ords, lo, hi)
+	}
+	if filters.OnlyIDs {
+		ords = intersectPostings(ords, sh.ordinals(filters.IDs))
+	}
+
+	// id уже учтены выше
+	rest := filters
+	rest.IDFrom, rest.IDTo = 0, 0
+	rest.OnlyIDs, rest.IDs = false, nil
+
+	out := make([]Comics, 0, len(ords))
+	for _, ord := range ords {
+		if c := sh.docs[ord]; rest.Match(c) {
+			out = append(out, c)
+		}
 	}
-	sort.Ints(res)
-	return res
+	return out
 }
 
-func (idx *InvertedIndex) DocsByIDs(ids []int) []Comics {
-	idx.mu.RLock()
-	defer idx.mu.RUnlock()
+// ordRange - номера docs с id в [from, to], 0 - без границы
+func (sh *indexShard) ordRange(from, to int) (uint32, uint32) {
+	lo, hi := 0, len(sh.docs)
+	if from > 0 {
+		lo = sort.Search(len(sh.docs), func(i int) bool { return sh.docs[i].ID >= from })
+	}
+	if to > 0 {
+		hi = sort.Search(len(sh.docs), func(i int) bool { return sh.docs[i].ID > to })
+	}
+	return uint32(lo), uint32(hi)
+}
 
-	out := make([]Comics, 0, len(ids))
+// ordinals - номера docs для отсортированных id, отсутствующие в шарде пропускаются
+func (sh *indexShard) ordinals(ids []int) []uint32 {
+	out := make([]uint32, 0, len(ids))
 	for _, id := range ids {
-		if c, ok := idx.docs[id]; ok {
-			out = append(out, c)
+		if i, ok := slices.BinarySearchFunc(sh.docs, id, func(c Comics, id int) int { return cmp.Compare(c.ID, id) }); ok {
+			out = append(out, uint32(i))
 		}
 	}
 	return out
 }
+
+// mergeByID - слияние отсортированных по id результатов шардов,
+// шардов не больше maxShards, поэтому голова выбирается простым перебором
+func mergeByID(parts [][]Comics) []Comics {
+	total := 0
+	for _, p := range parts {
+		total += len(p)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	out := make([]Comics, 0, total)
+	heads := make([]int, len(parts))
+	for len(out) < total {
+		best := -1
+		for i, p := range parts {
+			if heads[i] < len(p) && (best < 0 || p[heads[i]].ID < parts[best][heads[best]].ID) {
+				best = i
+			}
+		}
+		out = append(out, parts[best][heads[best]])
+		heads[best]++
+	}
+	return out
+}
+
+// brokenPosting - метка документа из неупорядоченного posting list, в токенах из БД ее не бывает
+const brokenPosting = "\x00broken posting"
+
+// Fingerprints - generation и хэши токенов каждого документа текущего снимка.
+// Токены собираются обходом posting lists, а не из копий документов: поиск идет по спискам,
+// и битый или устаревший список должен давать расхождение с БД.
+// Номер вне docs пропускается (у документа пропадет токен), документы неупорядоченного
+// списка получают brokenPosting - слияние списков на таком выдает неверных кандидатов
+func (idx *InvertedIndex) Fingerprints() (uint64, map[int]uint64) {
+	snap := idx.current.Load()
+
+	out := make(map[int]uint64, snap.docs)
+	for _, sh := range snap.shards {
+		tokens := make([][]string, len(sh.docs))
+		for tok, ords := range sh.postings {
+			sorted := increasing(ords)
+			for _, ord := range ords {
+				if int(ord) >= len(sh.docs) {
+					continue
+				}
+				tokens[ord] = append(tokens[ord], tok)
+				if !sorted {
+					tokens[ord] = append(tokens[ord], brokenPosting)
+				}
+			}
+		}
+		for ord, c := range sh.docs {
+			out[c.ID] = tokensHash(tokens[ord])
+		}
+	}
+	return snap.generation, out
+}
+
+// increasing - строго по возрастанию, повтор тоже нарушает порядок posting list
+func increasing(ords []uint32) bool {
+	for i := 1; i < len(ords); i++ {
+		if ords[i] <= ords[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// tokensHash - хэш множества токенов, не зависит от порядка и повторов
+func tokensHash(tokens []string) uint64 {
+	sorted := slices.Clone(tokens)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	h := fnv.New64a()
+	for _, t := range sorted {
+		_, _ = h.Write([]byte(t))
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// Stats - снимок размеров индекса и top самых частых термов (по длине posting list)
+func (idx *InvertedIndex) Stats(top int) IndexStats {
+	snap := idx.current.Load()
+	termDocs := snap.termDocs()
+
+	st := IndexStats{
+		Generation:    snap.generation,
+		Terms:         len(termDocs),
+		Docs:          snap.docs,
+		BuiltAt:       snap.builtAt,
+		BuildDuration: snap.buildDuration,
+		Trigger:       snap.trigger,
+		MemoryBytes:   snap.memoryFootprint(),
+	}
+
+	terms := make([]TermStat, 0, len(termDocs))
+	for tok, docs := range termDocs {
+		st.Postings += docs
+		if docs > st.MaxPosting {
+			st.MaxPosting = docs
+		}
+		terms = append(terms, TermStat{Term: tok, Docs: docs})
+	}
+	if st.Terms > 0 {
+		st.AvgPosting = float64(st.Postings) / float64(st.Terms)
+	}
+
+	// самые частые сверху, при равенстве - по алфавиту, чтобы выдача была стабильной
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Docs == terms[j].Docs {
+			return terms[i].Term < terms[j].Term
+		}
+		return terms[i].Docs > terms[j].Docs
+	})
+	if len(terms) > top {
+		terms = terms[:top]
+	}
+	st.TopTerms = terms
+
+	st.AnalyzerVersions = make([]VersionStat, 0, len(snap.versions))
+	for v, docs := range snap.versions {
+		st.AnalyzerVersions = append(st.AnalyzerVersions, VersionStat{Version: v, Docs: docs})
+	}
+	sort.Slice(st.AnalyzerVersions, func(i, j int) bool {
+		a, b := st.AnalyzerVersions[i], st.AnalyzerVersions[j]
+		if a.Docs == b.Docs {
+			return a.Version < b.Version
+		}
+		return a.Docs > b.Docs
+	})
+
+	return st
+}
+
+func analyzerVersions(comics []Comics) map[string]int {
+	out := make(map[string]int)
+	for _, c := range comics {
+		out[c.AnalyzerVersion]++
+	}
+	return out
+}
+
+// TermCounts - число комиксов по каждому терму текущего снимка
+func (idx *InvertedIndex) TermCounts() map[string]int {
+	return idx.current.Load().termDocs()
+}
+
+// termDocs - терм может встречаться в нескольких шардах, его документы суммируются
+func (snap *indexSnapshot) termDocs() map[string]int {
+	out := make(map[string]int)
+	for _, sh := range snap.shards {
+		for tok, ords := range sh.postings {
+			out[tok] += len(ords)
+		}
+	}
+	return out
+}
+
+// memoryFootprint - приблизительная оценка занимаемой памяти:
+// заголовки строк/слайсов, данные и грубая оценка накладных расходов map на запись
+func (snap *indexSnapshot) memoryFootprint() uint64 {
+	const (
+		mapEntryOverhead = 16
+		stringHeader     = uint64(unsafe.Sizeof(""))
+		sliceHeader      = uint64(unsafe.Sizeof([]uint32(nil)))
+		ordSize          = uint64(unsafe.Sizeof(uint32(0)))
+		comicsSize       = uint64(unsafe.Sizeof(Comics{}))
+	)
+
+	strs := func(arr []string) uint64 {
+		n := uint64(cap(arr)) * stringHeader
+		for _, s := range arr {
+			n += uint64(len(s))
+		}
+		return n
+	}
+
+	total := uint64(cap(snap.pickable)) * uint64(unsafe.Sizeof(docRef{}))
+	for _, sh := range snap.shards {
+		for tok, ords := range sh.postings {
+			total += stringHeader + uint64(len(tok)) + sliceHeader + uint64(cap(ords))*ordSize + mapEntryOverhead
+		}
+		total += uint64(cap(sh.docs)) * comicsSize
+		for _, c := range sh.docs {
+			total += uint64(len(c.URL)) + strs(c.Title) + strs(c.Alt) + strs(c.Words)
+		}
+	}
+	return total
+}
@@ -0,0 +1,478 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+// mapIndex - прежняя реализация индекса (map токен -> id и множество на запрос),
+// оставлена для проверки совпадения выдачи и для сравнения в бенчмарках
+type mapIndex struct {
+	byToken map[string][]int
+	docs    map[int]Comics
+}
+
+func newMapIndex(comics []Comics) *mapIndex {
+	idx := &mapIndex{
+		byToken: make(map[string][]int, len(comics)*4),
+		docs:    make(map[int]Comics, len(comics)),
+	}
+	for _, c := range comics {
+		idx.docs[c.ID] = c
+		for _, tok := range comicTokens(c) {
+			idx.byToken[tok] = append(idx.byToken[tok], c.ID)
+		}
+	}
+	return idx
+}
+
+func (idx *mapIndex) Candidates(tokens []string, filters SearchFilters) []Comics {
+	set := make(map[int]struct{}, len(tokens)*4)
+	for _, tok := range tokens {
+		for _, id := range idx.byToken[tok] {
+			set[id] = struct{}{}
+		}
+	}
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	out := make([]Comics, 0, len(ids))
+	for _, id := range ids {
+		if c := idx.docs[id]; filters.Match(c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// synthCorpus - комиксы со словами из словаря vocab по закону Ципфа:
+// как и в реальных текстах, немногие слова встречаются почти везде
+func synthCorpus(docs, vocab, title, alt, words int) []Comics {
+	rnd := rand.New(rand.NewSource(42))
+	zipf := rand.NewZipf(rnd, 1.1, 1, uint64(vocab-1))
+	dict := make([]string, vocab)
+	for i := range dict {
+		dict[i] = fmt.Sprintf("w%d", i)
+	}
+	pick := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = dict[zipf.Uint64()]
+		}
+		return out
+	}
+
+	start := time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)
+	comics := make([]Comics, docs)
+	for i := range comics {
+		comics[i] = Comics{
+			ID:            i + 1,
+			Title:         pick(title),
+			Alt:           pick(alt),
+			Words:         pick(words),
+			Published:     start.AddDate(0, 0, i%7000),
+			HasTranscript: i%3 != 0,
+		}
+	}
+	return comics
+}
+
+func TestPostings(t *testing.T) {
+	tests := []struct {
+		name      string
+		lists     [][]uint32
+		union     []uint32
+		intersect []uint32 // первых двух списков
+	}{
+		{name: "empty", lists: nil, union: nil},
+		{name: "single", lists: [][]uint32{{1, 5, 9}}, union: []uint32{1, 5, 9}},
+		{name: "disjoint", lists: [][]uint32{{1, 3}, {2, 4}}, union: []uint32{1, 2, 3, 4}, intersect: []uint32{}},
+		{name: "overlap", lists: [][]uint32{{1, 2, 7}, {2, 7, 8}}, union: []uint32{1, 2, 7, 8}, intersect: []uint32{2, 7}},
+		{name: "odd count", lists: [][]uint32{{5}, {1, 5}, {3}}, union: []uint32{1, 3, 5}, intersect: []uint32{5}},
+		{name: "with empty", lists: [][]uint32{{}, {4, 6}}, union: []uint32{4, 6}, intersect: []uint32{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unionPostings(tt.lists); !slices.Equal(got, tt.union) {
+				t.Fatalf("union = %v, want %v", got, tt.union)
+			}
+			if len(tt.lists) >= 2 {
+				if got := intersectPostings(tt.lists[0], tt.lists[1]); !slices.Equal(got, tt.intersect) {
+					t.Fatalf("intersect = %v, want %v", got, tt.intersect)
+				}
+			}
+		})
+	}
+
+	p := []uint32{2, 4, 6, 8}
+	if got := trimPostings(p, 4, 8); !slices.Equal(got, []uint32{4, 6}) {
+		t.Fatalf("trim = %v, want [4 6]", got)
+	}
+	if got := trimPostings(p, 7, 3); len(got) != 0 {
+		t.Fatalf("trim of empty range = %v", got)
+	}
+}
+
+// Выдача шардированного индекса должна совпадать с прежней при любом числе шардов,
+// в том числе на корпусе больше parallelQueryDocs, где шарды обходятся параллельно
+func TestInvertedIndex_MatchesMapIndex(t *testing.T) {
+	transcript := true
+	filters := map[string]SearchFilters{
+		"none":       {},
+		"id range":   {IDFrom: 100, IDTo: 900},
+		"id from":    {IDFrom: 19000},
+		"published":  {PublishedFrom: time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), PublishedTo: time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"transcript": {HasTranscript: &transcript},
+		"only ids":   {OnlyIDs: true, IDs: []int{3, 17, 256, 1024, 19999, 50000}},
+		"no ids":     {OnlyIDs: true},
+		"combined":   {IDFrom: 10, IDTo: 5000, HasTranscript: &transcript, OnlyIDs: true, IDs: []int{11, 12, 13, 4000, 4999}},
+	}
+	queries := [][]string{
+		{"w0"},
+		{"w1", "w7", "w7", "w300"},
+		{"w999", "absent"},
+		{"absent"},
+	}
+
+	for _, docs := range []int{50, parallelQueryDocs + 4000} {
+		comics := synthCorpus(docs, 1000, 2, 4, 6)
+		want := newMapIndex(comics)
+
+		for _, shards := range []int{1, 3, 8} {
+			idx := newInvertedIndex(shards)
+			idx.Build(comics, TriggerManual)
+
+			for name, f := range filters {
+				for _, q := range queries {
+					got := idx.Candidates(q, f)
+					exp := want.Candidates(q, f)
+					if !slices.EqualFunc(got, exp, func(a, b Comics) bool { return a.ID == b.ID }) {
+						t.Fatalf("docs=%d shards=%d filter=%q query=%v: got %d comics, want %d",
+							docs, shards, name, q, len(got), len(exp))
+					}
+				}
+			}
+
+			st := idx.Stats(1)
+			if st.Docs != docs || st.Terms != len(want.byToken) {
+				t.Fatalf("docs=%d shards=%d: stats docs=%d terms=%d, want terms=%d",
+					docs, shards, st.Docs, st.Terms, len(want.byToken))
+			}
+		}
+	}
+}
+
+// Сравнение с прежней реализацией:
+// go test ./search/core/ -run '^$' -bench Index -benchmem
+// корпус на 1M комиксов строится несколько секунд и пропускается с -short
+func benchCorpora() []struct {
+	name   string
+	comics []Comics
+} {
+	corpora := []struct {
+		name   string
+		comics []Comics
+	}{
+		// примерно как xkcd: 3k комиксов, длинные транскрипты
+		{name: "3k", comics: synthCorpus(3000, 20000, 3, 10, 40)},
+	}
+	if !testing.Short() {
+		corpora = append(corpora, struct {
+			name   string
+			comics []Comics
+		}{name: "1M", comics: synthCorpus(1_000_000, 200000, 2, 3, 5)})
+	}
+	return corpora
+}
+
+var benchQueries = [][]string{
+	{"w3"},                          // частый терм
+	{"w12", "w150", "w4000"},        // смешанный запрос
+	{"w10001", "w15000", "w199999"}, // редкие термы
+}
+
+func BenchmarkIndexCandidates(b *testing.B) {
+	for _, corpus := range benchCorpora() {
+		old := newMapIndex(corpus.comics)
+		sharded := NewInvertedIndex()
+		sharded.Build(corpus.comics, TriggerManual)
+
+		for qi, q := range benchQueries {
+			b.Run(fmt.Sprintf("docs=%s/query=%d/map", corpus.name, qi), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					_ = old.Candidates(q, SearchFilters{})
+				}
+			})
+			b.Run(fmt.Sprintf("docs=%s/query=%d/sharded", corpus.name, qi), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					_ = sharded.Candidates(q, SearchFilters{})
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkIndexCandidatesParallel - запросы из многих горутин, как под нагрузкой gRPC
+func BenchmarkIndexCandidatesParallel(b *testing.B) {
+	for _, corpus := range benchCorpora() {
+		old := newMapIndex(corpus.comics)
+		sharded := NewInvertedIndex()
+		sharded.Build(corpus.comics, TriggerManual)
+
+		q := benchQueries[1]
+		b.Run("docs="+corpus.name+"/map", func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = old.Candidates(q, SearchFilters{})
+				}
+			})
+		})
+		b.Run("docs="+corpus.name+"/sharded", func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = sharded.Candidates(q, SearchFilters{})
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkIndexBuild(b *testing.B) {
+	for _, corpus := range benchCorpora() {
+		b.Run("docs="+corpus.name+"/map", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				_ = newMapIndex(corpus.comics)
+			}
+		})
+		b.Run("docs="+corpus.name+"/sharded", func(b *testing.B) {
+			idx := NewInvertedIndex()
+			b.ReportAllocs()
+			for b.Loop() {
+				idx.Build(corpus.comics, TriggerManual)
+			}
+		})
+	}
+}
+
+func TestInvertedIndex_Pick(t *testing.T) {
+	comics := synthCorpus(20, 50, 1, 1, 1)
+	for i := range comics {
+		if i%4 != 0 {
+			comics[i].URL = fmt.Sprintf("https://imgs.xkcd.com/comics/%d.png", comics[i].ID)
+		}
+	}
+	idx := newInvertedIndex(3)
+	if _, ok := idx.Pick(1, nil); ok {
+		t.Fatal("pick from empty index")
+	}
+	idx.Build(comics, TriggerManual)
+
+	first, ok := idx.Pick(7, nil)
+	if !ok || first.URL == "" {
+		t.Fatalf("pick = %+v, %v, want comic with image", first, ok)
+	}
+	if again, _ := idx.Pick(7, nil); again.ID != first.ID {
+		t.Fatalf("same seed picked %d and %d", first.ID, again.ID)
+	}
+
+	// исключены все комиксы с картинкой, кроме одного
+	var exclude []int
+	for _, c := range comics {
+		if c.URL != "" && c.ID != 10 {
+			exclude = append(exclude, c.ID)
+		}
+	}
+	for seed := range uint64(20) {
+		if c, ok := idx.Pick(seed, exclude); !ok || c.ID != 10 {
+			t.Fatalf("seed %d: pick = %d, %v, want 10", seed, c.ID, ok)
+		}
+	}
+	all := append(slices.Clone(exclude), 10)
+	slices.Sort(all)
+	if _, ok := idx.Pick(1, all); ok {
+		t.Fatal("pick with everything excluded")
+	}
+
+}
+
+func TestInvertedIndex_PickRendezvous(t *testing.T) {
+	comics := synthCorpus(20, 50, 1, 1, 1)
+	for i := range comics {
+		if i%4 != 0 {
+			comics[i].URL = fmt.Sprintf("https://imgs.xkcd.com/comics/%d.png", comics[i].ID)
+		}
+	}
+	idx := newInvertedIndex(3)
+	if _, ok := idx.PickRendezvous(1, nil); ok {
+		t.Fatal("pick from empty index")
+	}
+	idx.Build(comics, TriggerManual)
+
+	// популярность перевешивает равномерный выбор
+	hits := 0
+	for seed := range uint64(200) {
+		c, ok := idx.PickRendezvous(seed, map[int]int{2: 1000})
+		if !ok || c.URL == "" {
+			t.Fatalf("seed %d: pick = %+v, %v, want comic with image", seed, c, ok)
+		}
+		if c.ID == 2 {
+			hits++
+		}
+	}
+	if hits < 150 {
+		t.Fatalf("popular comic picked %d of 200 times", hits)
+	}
+
+	// новый комикс сдвигает выбор только там, где выигрывает сам
+	before := make(map[uint64]int)
+	for seed := range uint64(200) {
+		c, _ := idx.PickRendezvous(seed, nil)
+		before[seed] = c.ID
+	}
+	idx.Build(append(slices.Clone(comics), Comics{ID: 100, URL: "https://imgs.xkcd.com/comics/100.png"}), TriggerManual)
+	moved := 0
+	for seed := range uint64(200) {
+		c, _ := idx.PickRendezvous(seed, nil)
+		if c.ID != before[seed] {
+			if c.ID != 100 {
+				t.Fatalf("seed %d: pick moved from %d to %d, not to the new comic", seed, before[seed], c.ID)
+			}
+			moved++
+		}
+	}
+	if moved == 0 || moved > 40 {
+		t.Fatalf("new comic won %d of 200 seeds, want about 1/16", moved)
+	}
+}
+
+func TestInvertedIndex_AnalyzerVersions(t *testing.T) {
+	comics := synthCorpus(10, 50, 1, 1, 1)
+	for i := range comics {
+		switch {
+		case i < 6:
+			comics[i].AnalyzerVersion = "b"
+		case i < 8:
+			comics[i].AnalyzerVersion = "a"
+		}
+	}
+	idx := newInvertedIndex(3)
+	idx.Build(comics, TriggerManual)
+
+	// по убыванию числа комиксов, при равенстве - по версии; пустая - без версии
+	want := []VersionStat{{Version: "b", Docs: 6}, {Version: "", Docs: 2}, {Version: "a", Docs: 2}}
+	if got := idx.Stats(1).AnalyzerVersions; !slices.Equal(got, want) {
+		t.Fatalf("analyzer versions = %+v, want %+v", got, want)
+	}
+}
+
+func TestInvertedIndex_Stats(t *testing.T) {
+	comics := []Comics{
+		{ID: 1, Title: []string{"linux", "kernel"}, Words: []string{"linux"}},
+		{ID: 2, Title: []string{"linux"}, Alt: []string{"tree"}},
+		{ID: 3, Words: []string{"tree", "linux"}},
+	}
+	idx := newInvertedIndex(2)
+	idx.Build(comics, TriggerBroker)
+
+	st := idx.Stats(2)
+	if st.Generation != 1 || st.Docs != 3 || st.Trigger != TriggerBroker {
+		t.Fatalf("stats = %+v, want generation 1, 3 docs, broker trigger", st)
+	}
+	// повтор токена в одном комиксе считается одним документом
+	if st.Terms != 3 || st.Postings != 6 || st.MaxPosting != 3 || st.AvgPosting != 2 {
+		t.Fatalf("terms=%d postings=%d max=%d avg=%g, want 3, 6, 3, 2", st.Terms, st.Postings, st.MaxPosting, st.AvgPosting)
+	}
+	want := []TermStat{{Term: "linux", Docs: 3}, {Term: "tree", Docs: 2}}
+	if !slices.Equal(st.TopTerms, want) {
+		t.Fatalf("top terms = %+v, want %+v", st.TopTerms, want)
+	}
+	if st.MemoryBytes == 0 {
+		t.Fatal("memory footprint is zero")
+	}
+}
+
+// verifyDB - БД для VerifyIndex, остальные методы порта не вызываются
+type verifyDB struct {
+	DB
+	comics []Comics
+}
+
+func (db verifyDB) All(context.Context) ([]Comics, error) {
+	return db.comics, nil
+}
+
+func TestService_VerifyIndex(t *testing.T) {
+	comics := synthCorpus(50, 30, 2, 2, 3)
+	db := &verifyDB{comics: comics}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewService(log, db, nil, BackendArray, nil, nil, nil)
+	s.index = newInvertedIndex(4)
+	ctx := context.Background()
+
+	if _, err := s.Rebuild(ctx, TriggerManual); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	drift, err := s.VerifyIndex(ctx)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !drift.InSync() || drift.DBDocs != 50 || drift.IndexDocs != 50 {
+		t.Fatalf("fresh index drift = %+v, want in sync", drift)
+	}
+
+	// БД ушла вперед: новый комикс, удаленный и измененный
+	db.comics = append(slices.Clone(comics[1:]), Comics{ID: 100, Title: []string{"new"}})
+	db.comics[0] = Comics{ID: comics[1].ID, Title: []string{"edited"}}
+	drift, _ = s.VerifyIndex(ctx)
+	if !slices.Equal(drift.Missing, []int{100}) || !slices.Equal(drift.Stale, []int{1}) ||
+		!slices.Equal(drift.Mismatched, []int{comics[1].ID}) {
+		t.Fatalf("drift = %+v, want missing [100], stale [1], mismatched [%d]", drift, comics[1].ID)
+	}
+
+	// документы в снимке целы, испорчен только posting list - сверка должна это заметить
+	db.comics = comics
+	snap := s.index.current.Load()
+	sh := &snap.shards[s.index.shardOf(7)]
+	ord := slices.IndexFunc(sh.docs, func(c Comics) bool { return c.ID == 7 })
+	tok := comicTokens(sh.docs[ord])[0]
+	sh.postings[tok] = slices.DeleteFunc(slices.Clone(sh.postings[tok]), func(o uint32) bool { return o == uint32(ord) })
+
+	drift, _ = s.VerifyIndex(ctx)
+	if drift.MissingCount != 0 || drift.StaleCount != 0 || !slices.Contains(drift.Mismatched, 7) {
+		t.Fatalf("drift after dropped posting = %+v, want comic 7 mismatched", drift)
+	}
+
+	// неупорядоченный список портит все свои документы
+	if _, err := s.Rebuild(ctx, TriggerManual); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	snap = s.index.current.Load()
+	for _, sh := range snap.shards {
+		for tok, ords := range sh.postings {
+			if len(ords) > 1 {
+				reversed := slices.Clone(ords)
+				slices.Reverse(reversed)
+				sh.postings[tok] = reversed
+				drift, _ = s.VerifyIndex(ctx)
+				if drift.MismatchedCount < len(ords) {
+					t.Fatalf("unsorted posting of %d docs: mismatched %d", len(ords), drift.MismatchedCount)
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("no posting list with several docs")
+}
@@ -1,9 +1,421 @@
 package core
 
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
 type Comics struct {
 	ID    int
 	URL   string
 	Title []string
 	Alt   []string
 	Words []string
+
+	Published     time.Time // нулевое значение - дата неизвестна
+	HasTranscript bool
+
+	// AnalyzerVersion - версия цепочки words, которой update нормализовал комикс;
+	// пустая - комикс сохранен до появления версий
+	AnalyzerVersion string
+}
+
+// RankedComics - комикс из полнотекстового поиска вместе с ts_rank_cd.
+// TitleRank/AltRank/WordsRank - ранг по одному полю с единичным весом, нужны для explain
+type RankedComics struct {
+	Comics
+	Rank      float64
+	TitleRank float64
+	AltRank   float64
+	WordsRank float64
+}
+
+type SearchQuery struct {
+	Phrase  string
+	Limit   uint32
+	Explain bool
+	Profile string // профиль ранжирования, пусто - профиль по умолчанию
+	Filters SearchFilters
+	UserID  uint32 // только для журнала запросов, 0 - анонимный
+}
+
+// SearchFilters - ограничения на кандидатов, нулевые значения - без ограничения.
+// Применяются до ранжирования и limit, поэтому Total считается уже по отфильтрованным
+type SearchFilters struct {
+	PublishedFrom time.Time
+	PublishedTo   time.Time // включительно
+	IDFrom        int
+	IDTo          int // включительно
+	HasTranscript *bool
+
+	// OnlyIDs - искать только среди IDs (избранное пользователя), пустой IDs - пустая выдача.
+	// IDs отсортированы по возрастанию (Service.prepare)
+	OnlyIDs bool
+	IDs     []int
+}
+
+func (f SearchFilters) Validate() error {
+	if !f.PublishedFrom.IsZero() && !f.PublishedTo.IsZero() && f.PublishedFrom.After(f.PublishedTo) {
+		return fmt.Errorf("%w: published_from after published_to", ErrBadArguments)
+	}
+	if f.IDFrom < 0 || f.IDTo < 0 || (f.IDTo > 0 && f.IDFrom > f.IDTo) {
+		return fmt.Errorf("%w: bad id range", ErrBadArguments)
+	}
+	return nil
+}
+
+// cacheKey - часть ключа кэша результатов, список IDs сворачивается в хэш
+func (f SearchFilters) cacheKey() string {
+	transcript := "any"
+	if f.HasTranscript != nil {
+		transcript = strconv.FormatBool(*f.HasTranscript)
+	}
+	key := fmt.Sprintf("%s..%s|%d..%d|%s",
+		f.PublishedFrom.Format(time.DateOnly), f.PublishedTo.Format(time.DateOnly),
+		f.IDFrom, f.IDTo, transcript,
+	)
+	if f.OnlyIDs {
+		h := fnv.New64a()
+		for _, id := range f.IDs {
+			_, _ = h.Write(strconv.AppendInt(nil, int64(id), 10))
+			_, _ = h.Write([]byte{','})
+		}
+		key += fmt.Sprintf("|ids:%d:%x", len(f.IDs), h.Sum64())
+	}
+	return key
+}
+
+// Match - проходит ли комикс фильтры. Комикс без даты не проходит фильтр по дате
+func (f SearchFilters) Match(c Comics) bool {
+	if !f.PublishedFrom.IsZero() && (c.Published.IsZero() || c.Published.Before(f.PublishedFrom)) {
+		return false
+	}
+	if !f.PublishedTo.IsZero() && (c.Published.IsZero() || c.Published.After(f.PublishedTo)) {
+		return false
+	}
+	if f.IDFrom > 0 && c.ID < f.IDFrom {
+		return false
+	}
+	if f.IDTo > 0 && c.ID > f.IDTo {
+		return false
+	}
+	if f.HasTranscript != nil && c.HasTranscript != *f.HasTranscript {
+		return false
+	}
+	if f.OnlyIDs {
+		if _, ok := slices.BinarySearch(f.IDs, c.ID); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// WeightedToken - нормализованный токен запроса и его вес в ранжировании:
+// 1 у слов самой фразы, меньше - у синонимов, найденных words
+type WeightedToken struct {
+	Token  string
+	Weight float64
+}
+
+// Tokens - токены без весов, по ним выбираются кандидаты
+func Tokens(terms []WeightedToken) []string {
+	out := make([]string, 0, len(terms))
+	for _, t := range terms {
+		out = append(out, t.Token)
+	}
+	return out
+}
+
+// Facets - распределение отфильтрованных кандидатов до limit.
+// Sources - в каком поле совпал хотя бы один токен: title, alt, transcript (words).
+// Years - год публикации, FacetUnknownYear для комиксов без даты
+type Facets struct {
+	Years   map[string]int
+	Sources map[string]int
+}
+
+const FacetUnknownYear = "unknown"
+
+const (
+	SourceTitle      = "title"
+	SourceAlt        = "alt"
+	SourceTranscript = "transcript"
+)
+
+func newFacets() Facets {
+	return Facets{Years: map[string]int{}, Sources: map[string]int{}}
+}
+
+// add - учитывает комикс в фасетах, поля совпадений посчитаны scoreComic
+func (f Facets) add(c Comics, p scoreParts) {
+	year := FacetUnknownYear
+	if !c.Published.IsZero() {
+		year = strconv.Itoa(c.Published.Year())
+	}
+	f.Years[year]++
+
+	if p.titleMatches > 0 {
+		f.Sources[SourceTitle]++
+	}
+	if p.altMatches > 0 {
+		f.Sources[SourceAlt]++
+	}
+	if p.wordsMatches > 0 {
+		f.Sources[SourceTranscript]++
+	}
+}
+
+// RankingProfile - веса ранжирования.
+// score = Coverage*покрытые_токены + Title*совпадения_в_title + Alt*... + Words*...
+type RankingProfile struct {
+	Name     string
+	Coverage float64
+	Title    float64
+	Alt      float64
+	Words    float64
+}
+
+// DefaultProfile - веса, которые были зашиты в коде до появления профилей
+var DefaultProfile = RankingProfile{
+	Name:     "default",
+	Coverage: 100,
+	Title:    5,
+	Alt:      3,
+	Words:    1,
+}
+
+func (p RankingProfile) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("ranking profile without name")
+	}
+	for name, w := range map[string]float64{
+		"coverage": p.Coverage,
+		"title":    p.Title,
+		"alt":      p.Alt,
+		"words":    p.Words,
+	} {
+		if math.IsNaN(w) || math.IsInf(w, 0) || w < 0 {
+			return fmt.Errorf("ranking profile %q: %s weight must be a finite non-negative number", p.Name, name)
+		}
+	}
+	if p.Title == 0 && p.Alt == 0 && p.Words == 0 {
+		return fmt.Errorf("ranking profile %q: all field weights are zero", p.Name)
+	}
+	return nil
+}
+
+// FTSWeights - веса для ts_rank_cd в порядке {D, C, B, A}.
+// Postgres принимает веса в [0, 1], поэтому поля нормируются по максимальному,
+// D (в tsv не используется) остается 0.1
+func (p RankingProfile) FTSWeights() [4]float64 {
+	top := max(p.Title, p.Alt, p.Words)
+	return [4]float64{0.1, p.Words / top, p.Alt / top, p.Title / top}
+}
+
+type Hit struct {
+	Comics
+	Score   float64
+	Explain *Explanation // заполняется только при SearchQuery.Explain
+}
+
+type SearchResult struct {
+	Hits   []Hit
+	Total  uint32
+	Tokens []string // нормализованные токены запроса
+	Facets Facets
+}
+
+// Функции ранжирования, которые попадают в Explanation.Function
+const (
+	RankingFields = "fields"     // scoreComic: покрытие токенов + веса полей
+	RankingFTS    = "ts_rank_cd" // Postgres, бэкенд fts
+)
+
+// Explanation - из чего сложился score комикса
+type Explanation struct {
+	Function      string
+	Profile       string
+	Score         float64
+	Components    []ScoreComponent
+	Terms         []TermExplain
+	MatchedFields []string
+}
+
+// ScoreComponent - слагаемое score: Contribution = Value * Weight
+type ScoreComponent struct {
+	Name         string
+	Value        float64
+	Weight       float64
+	Contribution float64
+}
+
+// TermExplain - частота токена запроса в каждом поле комикса
+type TermExplain struct {
+	Token   string
+	Fields  []string
+	TitleTF int
+	AltTF   int
+	WordsTF int
+}
+
+// Backend - способ поиска кандидатов и ранжирования в Find
+type Backend string
+
+const (
+	// BackendArray - пересечение массивов в БД, ранжирование в Go (scoreComic)
+	BackendArray Backend = "array"
+	// BackendFTS - полнотекстовый поиск Postgres, ранжирование и limit внутри БД (ts_rank_cd)
+	BackendFTS Backend = "fts"
+)
+
+func ParseBackend(s string) (Backend, error) {
+	switch Backend(s) {
+	case BackendArray, BackendFTS:
+		return Backend(s), nil
+	default:
+		return "", fmt.Errorf("unknown search backend %q", s)
+	}
+}
+
+// IndexTrigger - что вызвало пересборку индекса
+type IndexTrigger string
+
+const (
+	TriggerStartup IndexTrigger = "startup"
+	TriggerTTL     IndexTrigger = "ttl"
+	TriggerBroker  IndexTrigger = "broker"
+	TriggerManual  IndexTrigger = "manual"
+)
+
+// IndexRebuild - итог пересборки индекса
+type IndexRebuild struct {
+	Generation uint64
+	Docs       int
+	Duration   time.Duration
+	Trigger    IndexTrigger
+}
+
+// IndexDrift - расхождение индекса с БД.
+// Missing - есть в БД, нет в индексе; Stale - есть в индексе, нет в БД;
+// Mismatched - токены в индексе отличаются от БД. Списки ID обрезаны, счетчики полные
+type IndexDrift struct {
+	Generation      uint64
+	DBDocs          int
+	IndexDocs       int
+	Missing         []int
+	Stale           []int
+	Mismatched      []int
+	MissingCount    int
+	StaleCount      int
+	MismatchedCount int
+}
+
+func (d IndexDrift) InSync() bool {
+	return d.MissingCount == 0 && d.StaleCount == 0 && d.MismatchedCount == 0
+}
+
+type TermStat struct {
+	Term string
+	Docs int
+}
+
+// VersionStat - сколько комиксов в индексе нормализовано версией цепочки words
+type VersionStat struct {
+	Version string
+	Docs    int
+}
+
+type IndexStats struct {
+	Generation    uint64
+	Terms         int
+	Docs          int
+	Postings      int
+	MaxPosting    int
+	AvgPosting    float64
+	MemoryBytes   uint64
+	BuiltAt       time.Time
+	BuildDuration time.Duration
+	Trigger       IndexTrigger
+	TopTerms      []TermStat
+
+	// последняя неудачная пересборка, пусто если ошибок не было
+	LastRebuildError   string
+	LastRebuildErrorAt time.Time
+
+	// счетчики запросов с момента старта сервиса
+	Searches        uint64
+	IndexedSearches uint64
+
+	ResultCache CacheStats
+	NormCache   CacheStats
+
+	// AnalyzerVersions - версии цепочек в индексе, больше одной - нужна переиндексация.
+	// QueryAnalyzerVersion - версия, которой words разобрал последний запрос
+	AnalyzerVersions     []VersionStat
+	QueryAnalyzerVersion string
+}
+
+// CacheStats - счетчики кэша, нулевые если кэш выключен
+type CacheStats struct {
+	Hits     uint64
+	Misses   uint64
+	Entries  int
+	Capacity int
+}
+
+// эндпоинты поиска в журнале запросов
+const (
+	EndpointSearch  = "search"
+	EndpointISearch = "isearch"
+)
+
+// QueryLogEntry - один поисковый запрос для аналитики
+type QueryLogEntry struct {
+	At       time.Time
+	Endpoint string
+	Phrase   string
+	// Query - нормализованные токены без повторов, отсортированные и через пробел,
+	// по нему группируются одинаковые запросы
+	Query   string
+	Tokens  []string
+	Results int
+	Latency time.Duration
+	UserID  uint32 // 0 - анонимный запрос
+	Cached  bool
+}
+
+// QueryStat - агрегат по нормализованному запросу за окно
+type QueryStat struct {
+	Query      string
+	Count      int
+	AvgResults float64
+	LastSeen   time.Time
+}
+
+// LatencyStats - перцентили времени ответа по эндпоинту за окно
+type LatencyStats struct {
+	Endpoint string
+	Count    int
+	P50      time.Duration
+	P90      time.Duration
+	P99      time.Duration
+}
+
+// RandomQuery - Seed nil - случайный выбор, иначе выбор воспроизводится
+// при том же наборе комиксов; Exclude - id, которые не выдавать (уже показанные)
+type RandomQuery struct {
+	Seed    *uint64
+	Exclude []int
+}
+
+// DailyQuery - комикс дня: дата (день по UTC) и seed однозначно задают выбор.
+// Popularity - id -> сколько раз комикс добавлен в избранное, смещает выбор к популярным
+type DailyQuery struct {
+	Date       time.Time
+	Seed       uint64
+	Popularity map[int]int
 }
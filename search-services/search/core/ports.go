@@ -2,28 +2,89 @@ package core
 
 import (
 	"context"
+	"time"
 )
 
 type Search interface {
-	Find(ctx context.Context, phrase string, limit uint32) ([]Comics, error)
-	IndexedSearch(ctx context.Context, phrase string, limit uint32) ([]Comics, uint32, error)
+	Find(ctx context.Context, q SearchQuery) (SearchResult, error)
+	IndexedSearch(ctx context.Context, q SearchQuery) (SearchResult, error)
+	IndexStats(ctx context.Context, top uint32) (IndexStats, error)
+	Rebuild(ctx context.Context, trigger IndexTrigger) (IndexRebuild, error)
+	VerifyIndex(ctx context.Context) (IndexDrift, error)
 	Ping(ctx context.Context) error
 
+	TopQueries(ctx context.Context, window time.Duration, limit uint32) ([]QueryStat, error)
+	ZeroResultQueries(ctx context.Context, window time.Duration, limit uint32) ([]QueryStat, error)
+	LatencyPercentiles(ctx context.Context, window time.Duration) ([]LatencyStats, error)
+
 	GetComicByID(ctx context.Context, id int) (Comics, error)
-	RandomComic(ctx context.Context) (Comics, error)
+	RandomComic(ctx context.Context, q RandomQuery) (Comics, error)
+	ComicOfTheDay(ctx context.Context, q DailyQuery) (Comics, error)
 	GetAllComics(ctx context.Context, page, limit uint32) ([]Comics, uint32, error)
+
+	StreamSearch(ctx context.Context, q SearchQuery, indexed bool, chunk uint32, send func([]Hit) error) error
+	StreamComics(ctx context.Context, chunk uint32, send func([]Comics) error) error
 }
 
 type DB interface {
-	Find(ctx context.Context, tokens []string) ([]Comics, error)
+	QueryLog
+
+	Find(ctx context.Context, tokens []string, filters SearchFilters) ([]Comics, error)
+	FindRanked(ctx context.Context, terms []WeightedToken, limit uint32, weights [4]float64, filters SearchFilters) ([]RankedComics, error)
+	// FindRankedEach - FindRanked для стриминга: строки идут в fn по мере чтения из БД
+	FindRankedEach(ctx context.Context, terms []WeightedToken, limit uint32, weights [4]float64, filters SearchFilters, fn func(RankedComics) error) error
+	Facets(ctx context.Context, tokens []string, filters SearchFilters) (Facets, error)
 	All(ctx context.Context) ([]Comics, error)
+	// TermSurfaces - термин -> самое частое написание в комиксах до стемминга
+	TermSurfaces(ctx context.Context) (map[string]string, error)
+	// DailyPopularity - снимок популярности для комикса дня на day (YYYY-MM-DD), один на все
+	// реплики и рестарты. Если снимка нет, сохраняет popularity; при пустой popularity - nil без записи
+	DailyPopularity(ctx context.Context, day string, popularity map[int]int) (map[int]int, error)
 	Ping(ctx context.Context) error
 
 	GetByID(ctx context.Context, id int) (Comics, error)
 	GetAll(ctx context.Context, offset, limit int) ([]Comics, error)
+	GetAfter(ctx context.Context, afterID, limit int) ([]Comics, error)
 	Count(ctx context.Context) (int, error)
 }
 
+// QueryLog - журнал поисковых запросов, since - начало окна аналитики
+type QueryLog interface {
+	InsertQueries(ctx context.Context, entries []QueryLogEntry) error
+	// DeleteQueriesBefore - удаляет записи старше before, возвращает их число
+	DeleteQueriesBefore(ctx context.Context, before time.Time) (int64, error)
+	TopQueries(ctx context.Context, since time.Time, limit int) ([]QueryStat, error)
+	ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]QueryStat, error)
+	LatencyPercentiles(ctx context.Context, since time.Time) ([]LatencyStats, error)
+}
+
+// QueryRecorder - асинхронная запись в журнал, Record не должен блокировать поиск
+type QueryRecorder interface {
+	Record(e QueryLogEntry)
+}
+
+// Words - нормализация запроса: слова самой фразы с весом 1 и синонимы из словаря words с меньшим
 type Words interface {
-	Norm(ctx context.Context, phrase string) ([]string, error)
+	Expand(ctx context.Context, phrase string) ([]WeightedToken, error)
+}
+
+// CacheStatter - реализуют адаптеры со своим кэшем (words), счетчики попадают в IndexStats
+type CacheStatter interface {
+	CacheStats() CacheStats
+}
+
+// Speller - реализуют адаптеры words с исправлением опечаток: после пересборки индекса
+// им передается частотный словарь корпуса (терм -> число комиксов) и написание термов в комиксах
+type Speller interface {
+	SetDictionary(ctx context.Context, counts map[string]int, surfaces map[string]string) error
+}
+
+// AnalyzerVersioner - реализуют адаптеры words, которые знают версию цепочки запросов
+type AnalyzerVersioner interface {
+	AnalyzerVersion() string
+}
+
+// Profiles - источник профилей ранжирования, пустое имя - профиль по умолчанию
+type Profiles interface {
+	Profile(name string) (RankingProfile, error)
 }
@@ -0,0 +1,83 @@
+package core
+
+import "sort"
+
+// Posting list - отсортированный по возрастанию []uint32 без повторов.
+// Объединение и пересечение делаются слиянием, без промежуточных map
+
+// unionPostings - объединение попарным слиянием, O(n log k) для k списков.
+// Один список возвращается как есть: списки снимка индекса неизменяемы, менять результат нельзя
+func unionPostings(lists [][]uint32) []uint32 {
+	switch len(lists) {
+	case 0:
+		return nil
+	case 1:
+		return lists[0]
+	}
+
+	work := make([][]uint32, len(lists))
+	copy(work, lists)
+	for len(work) > 1 {
+		// next пишет в начало work: индекс записи i/2 не обгоняет чтение i
+		next := work[:0]
+		for i := 0; i < len(work); i += 2 {
+			if i+1 == len(work) {
+				next = append(next, work[i])
+				continue
+			}
+			next = append(next, mergeUnion(work[i], work[i+1]))
+		}
+		work = next
+	}
+	return work[0]
+}
+
+func mergeUnion(a, b []uint32) []uint32 {
+	out := make([]uint32, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
+
+// intersectPostings - пересечение слиянием, повторы в b допускаются
+func intersectPostings(a, b []uint32) []uint32 {
+	out := make([]uint32, 0, min(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// trimPostings - значения из [lo, hi), без копирования
+func trimPostings(p []uint32, lo, hi uint32) []uint32 {
+	if lo >= hi {
+		return nil
+	}
+	i := sort.Search(len(p), func(k int) bool { return p[k] >= lo })
+	j := sort.Search(len(p), func(k int) bool { return p[k] >= hi })
+	return p[i:j]
+}
@@ -0,0 +1,221 @@
+package core
+
+import "sort"
+
+// scoreParts - слагаемые score в scoreComic
+// Каждое совпадение считается с весом токена, поэтому синоним дает меньше самого слова запроса
+type scoreParts struct {
+	covered      float64
+	titleMatches float64
+	altMatches   float64
+	wordsMatches float64
+}
+
+func (p scoreParts) score(w RankingProfile) float64 {
+	return p.covered*w.Coverage +
+		p.titleMatches*w.Title +
+		p.altMatches*w.Alt +
+		p.wordsMatches*w.Words
+}
+
+// rangComics - общая функция для ранжирования для Find и IndexedSearch.
+// Фасеты считаются по всем кандидатам до limit
+func rangComics(comics []Comics, terms []WeightedToken, limit uint32, profile RankingProfile, explain bool) ([]Hit, uint32, Facets) {
+	ranked, facets := rankComics(comics, terms, profile)
+	total := uint32(len(ranked))
+
+	// применяем limit
+	if uint32(len(ranked)) > limit {
+		ranked = ranked[:limit]
+	}
+	return rankedHits(comics, ranked, terms, profile, explain), total, facets
+}
+
+// rankedComic - место комикса в выдаче: индекс в срезе кандидатов вместо копии комикса,
+// чтобы стриминг держал в памяти только порядок, а Hit собирал по пачкам
+type rankedComic struct {
+	idx   int
+	parts scoreParts
+	score float64
+}
+
+// rankComics - score всех кандидатов с хотя бы одним совпадением и фасеты по ним
+func rankComics(comics []Comics, terms []WeightedToken, profile RankingProfile) ([]rankedComic, Facets) {
+	facets := newFacets()
+	ranked := make([]rankedComic, 0, len(comics))
+	for i, c := range comics {
+		parts := scoreComic(c, terms)
+		if parts.covered > 0 {
+			facets.add(c, parts)
+			ranked = append(ranked, rankedComic{
+				idx:   i,
+				parts: parts,
+				score: parts.score(profile),
+			})
+		}
+	}
+
+	// сортировка - по score убывает, при равенстве по ID возрастает
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score == ranked[j].score {
+			return comics[ranked[i].idx].ID < comics[ranked[j].idx].ID
+		}
+		return ranked[i].score > ranked[j].score
+	})
+	return ranked, facets
+}
+
+// rankedHits - Hit для отрезка выдачи rankComics
+func rankedHits(comics []Comics, ranked []rankedComic, terms []WeightedToken, profile RankingProfile, explain bool) []Hit {
+	out := make([]Hit, 0, len(ranked))
+	for _, r := range ranked {
+		c := comics[r.idx]
+		hit := Hit{Comics: c, Score: r.score}
+		if explain {
+			hit.Explain = explainFields(c, terms, r.parts, profile)
+		}
+		out = append(out, hit)
+	}
+	return out
+}
+
+// scoreComic - функция для подсчета весов
+func scoreComic(c Comics, terms []WeightedToken) scoreParts {
+	titleSet := makeSet(c.Title)
+	altSet := makeSet(c.Alt)
+	wordsSet := makeSet(c.Words)
+
+	var parts scoreParts
+
+	// coveered - сет, для уникальных токенов, которые встречаются в любом поле, с их весом
+	covered := make(map[string]float64, len(terms))
+
+	for _, t := range terms {
+		matched := false
+		if titleSet[t.Token] {
+			parts.titleMatches += t.Weight
+			matched = true
+		}
+		if altSet[t.Token] {
+			parts.altMatches += t.Weight
+			matched = true
+		}
+		if wordsSet[t.Token] {
+			parts.wordsMatches += t.Weight
+			matched = true
+		}
+
+		if matched {
+			covered[t.Token] = max(covered[t.Token], t.Weight)
+		}
+	}
+
+	// coveredTokens нужен чтобы комикс, который покрывает много токенов стоял выше остальных
+	for _, w := range covered {
+		parts.covered += w
+	}
+	return parts
+}
+
+// Сет для перевода слайса в мапу для более быстрой проверки (O(1) вместо O(n))
+func makeSet(arr []string) map[string]bool {
+	m := make(map[string]bool, len(arr))
+	for _, v := range arr {
+		if v == "" {
+			continue
+		}
+		m[v] = true
+	}
+	return m
+}
+
+// explainFields - разбор score из scoreComic
+func explainFields(c Comics, query []WeightedToken, p scoreParts, profile RankingProfile) *Explanation {
+	terms, fields := termFrequencies(c, Tokens(query))
+	return &Explanation{
+		Function: RankingFields,
+		Profile:  profile.Name,
+		Score:    p.score(profile),
+		Components: []ScoreComponent{
+			component("coverage", p.covered, profile.Coverage),
+			component("title", p.titleMatches, profile.Title),
+			component("alt", p.altMatches, profile.Alt),
+			component("words", p.wordsMatches, profile.Words),
+		},
+		Terms:         terms,
+		MatchedFields: fields,
+	}
+}
+
+// explainFTS - разбор ts_rank_cd по полям. Ранг по полю считается БД отдельно
+// с единичным весом, поэтому сумма вкладов совпадает с Rank приблизительно
+func explainFTS(rc RankedComics, tokens []string, profile RankingProfile) *Explanation {
+	terms, fields := termFrequencies(rc.Comics, tokens)
+	weights := profile.FTSWeights()
+	return &Explanation{
+		Function: RankingFTS,
+		Profile:  profile.Name,
+		Score:    rc.Rank,
+		Components: []ScoreComponent{
+			component("title", rc.TitleRank, weights[3]),
+			component("alt", rc.AltRank, weights[2]),
+			component("words", rc.WordsRank, weights[1]),
+		},
+		Terms:         terms,
+		MatchedFields: fields,
+	}
+}
+
+func component(name string, value, weight float64) ScoreComponent {
+	return ScoreComponent{Name: name, Value: value, Weight: weight, Contribution: value * weight}
+}
+
+// termFrequencies - сколько раз каждый токен запроса встречается в полях комикса
+// и список полей, где совпал хотя бы один токен
+func termFrequencies(c Comics, tokens []string) ([]TermExplain, []string) {
+	count := func(arr []string, tok string) int {
+		n := 0
+		for _, v := range arr {
+			if v == tok {
+				n++
+			}
+		}
+		return n
+	}
+
+	var inTitle, inAlt, inWords bool
+	terms := make([]TermExplain, 0, len(tokens))
+	for _, tok := range tokens {
+		te := TermExplain{
+			Token:   tok,
+			TitleTF: count(c.Title, tok),
+			AltTF:   count(c.Alt, tok),
+			WordsTF: count(c.Words, tok),
+		}
+		if te.TitleTF > 0 {
+			te.Fields = append(te.Fields, "title")
+			inTitle = true
+		}
+		if te.AltTF > 0 {
+			te.Fields = append(te.Fields, "alt")
+			inAlt = true
+		}
+		if te.WordsTF > 0 {
+			te.Fields = append(te.Fields, "words")
+			inWords = true
+		}
+		terms = append(terms, te)
+	}
+
+	var fields []string
+	if inTitle {
+		fields = append(fields, "title")
+	}
+	if inAlt {
+		fields = append(fields, "alt")
+	}
+	if inWords {
+		fields = append(fields, "words")
+	}
+	return terms, fields
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"testing"
+)
+
+// titleHeavy - профиль не по умолчанию, чтобы explain брал веса из запрошенного профиля
+var titleHeavy = RankingProfile{Name: "title_heavy", Coverage: 10, Title: 50, Alt: 2, Words: 0.5}
+
+// namedProfiles - профиль по имени, пустое имя - по умолчанию
+type namedProfiles map[string]RankingProfile
+
+func (p namedProfiles) Profile(name string) (RankingProfile, error) {
+	if name == "" {
+		return DefaultProfile, nil
+	}
+	if profile, ok := p[name]; ok {
+		return profile, nil
+	}
+	return RankingProfile{}, ErrUnknownProfile
+}
+
+// explainCorpus - linux дважды в title первого комикса, в alt второго и в words третьего
+func explainCorpus() []Comics {
+	return []Comics{
+		{ID: 1, Title: []string{"linux", "linux", "kernel"}, Alt: []string{"cat"}},
+		{ID: 2, Title: []string{"cat"}, Alt: []string{"linux"}, Words: []string{"kernel"}},
+		{ID: 3, Words: []string{"linux", "linux", "linux"}},
+		{ID: 4, Title: []string{"dog"}},
+	}
+}
+
+func TestService_ExplainFields(t *testing.T) {
+	comics := explainCorpus()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	profiles := namedProfiles{titleHeavy.Name: titleHeavy}
+
+	for _, indexed := range []bool{false, true} {
+		t.Run(fmt.Sprint("indexed=", indexed), func(t *testing.T) {
+			s := NewService(log, findDB{comics: comics}, staticWords{}, BackendArray, profiles, nil, nil)
+			s.index.Build(comics, TriggerManual)
+
+			find := s.Find
+			if indexed {
+				find = s.IndexedSearch
+			}
+			res, err := find(context.Background(), SearchQuery{Phrase: "linux kernel", Explain: true, Profile: titleHeavy.Name})
+			if err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			if len(res.Hits) != 3 {
+				t.Fatalf("hits = %d, want 3", len(res.Hits))
+			}
+
+			want := map[int]struct {
+				fields []string
+				tf     map[string][3]int // title, alt, words
+			}{
+				1: {[]string{"title"}, map[string][3]int{"linux": {2, 0, 0}, "kernel": {1, 0, 0}}},
+				2: {[]string{"alt", "words"}, map[string][3]int{"linux": {0, 1, 0}, "kernel": {0, 0, 1}}},
+				3: {[]string{"words"}, map[string][3]int{"linux": {0, 0, 3}, "kernel": {0, 0, 0}}},
+			}
+			for _, h := range res.Hits {
+				e := h.Explain
+				if e == nil {
+					t.Fatalf("comic %d: no explanation", h.ID)
+				}
+				if e.Function != RankingFields || e.Profile != titleHeavy.Name {
+					t.Fatalf("comic %d: function %q profile %q, want %q %q", h.ID, e.Function, e.Profile, RankingFields, titleHeavy.Name)
+				}
+				sum := 0.0
+				for _, c := range e.Components {
+					if c.Contribution != c.Value*c.Weight {
+						t.Fatalf("comic %d: component %+v, contribution is not value*weight", h.ID, c)
+					}
+					sum += c.Contribution
+				}
+				if math.Abs(sum-h.Score) > 1e-9 || e.Score != h.Score {
+					t.Fatalf("comic %d: components sum %v, explain score %v, want hit score %v", h.ID, sum, e.Score, h.Score)
+				}
+				if e.Components[1].Name != "title" || e.Components[1].Weight != titleHeavy.Title {
+					t.Fatalf("comic %d: title component %+v, want weight of %s", h.ID, e.Components[1], titleHeavy.Name)
+				}
+
+				w := want[h.ID]
+				if !slices.Equal(e.MatchedFields, w.fields) {
+					t.Fatalf("comic %d: matched fields %v, want %v", h.ID, e.MatchedFields, w.fields)
+				}
+				for _, term := range e.Terms {
+					if got := [3]int{term.TitleTF, term.AltTF, term.WordsTF}; got != w.tf[term.Token] {
+						t.Fatalf("comic %d: %s tf = %v, want %v", h.ID, term.Token, got, w.tf[term.Token])
+					}
+				}
+			}
+			// title весит больше всего, комикс с linux и kernel в title первый
+			if res.Hits[0].ID != 1 {
+				t.Fatalf("first hit = %d, want 1", res.Hits[0].ID)
+			}
+		})
+	}
+}
+
+// ftsRanks - FindRanked как ts_rank_cd: ранг по каждому полю с единичным весом
+// и итоговый ранг с весами профиля
+type ftsRanks struct {
+	findDB
+}
+
+func (db ftsRanks) FindRanked(_ context.Context, terms []WeightedToken, limit uint32, weights [4]float64, _ SearchFilters) ([]RankedComics, error) {
+	var out []RankedComics
+	for _, c := range db.comics {
+		p := scoreComic(c, terms)
+		if p.covered == 0 || uint32(len(out)) == limit {
+			continue
+		}
+		rc := RankedComics{Comics: c, TitleRank: p.titleMatches, AltRank: p.altMatches, WordsRank: p.wordsMatches}
+		rc.Rank = rc.TitleRank*weights[3] + rc.AltRank*weights[2] + rc.WordsRank*weights[1]
+		out = append(out, rc)
+	}
+	return out, nil
+}
+
+func TestService_ExplainFTS(t *testing.T) {
+	comics := explainCorpus()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewService(log, ftsRanks{findDB{comics: comics}}, staticWords{}, BackendFTS, namedProfiles{titleHeavy.Name: titleHeavy}, nil, nil)
+
+	res, err := s.Find(context.Background(), SearchQuery{Phrase: "linux", Explain: true, Profile: titleHeavy.Name})
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(res.Hits) != 3 {
+		t.Fatalf("hits = %d, want 3", len(res.Hits))
+	}
+	weights := titleHeavy.FTSWeights()
+	for _, h := range res.Hits {
+		e := h.Explain
+		if e == nil || e.Function != RankingFTS || e.Profile != titleHeavy.Name || e.Score != h.Score {
+			t.Fatalf("comic %d: explanation %+v, want ts_rank_cd of %s with hit score", h.ID, e, titleHeavy.Name)
+		}
+		byName := make(map[string]ScoreComponent, len(e.Components))
+		sum := 0.0
+		for _, c := range e.Components {
+			byName[c.Name] = c
+			sum += c.Contribution
+		}
+		if math.Abs(sum-h.Score) > 1e-9 {
+			t.Fatalf("comic %d: components sum %v, want rank %v", h.ID, sum, h.Score)
+		}
+		if byName["title"].Weight != weights[3] || byName["alt"].Weight != weights[2] || byName["words"].Weight != weights[1] {
+			t.Fatalf("comic %d: components %+v, want weights %v", h.ID, e.Components, weights)
+		}
+		if len(e.Terms) != 1 || e.Terms[0].Token != "linux" || len(e.MatchedFields) != 1 {
+			t.Fatalf("comic %d: terms %+v fields %v, want linux in one field", h.ID, e.Terms, e.MatchedFields)
+		}
+	}
+	if got := res.Hits[0].Explain.Terms[0].TitleTF; got != 2 {
+		t.Fatalf("comic 1 title tf = %d, want 2", got)
+	}
+}
+
+func TestService_NoExplain(t *testing.T) {
+	comics := explainCorpus()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, backend := range []Backend{BackendArray, BackendFTS} {
+		for _, indexed := range []bool{false, true} {
+			t.Run(fmt.Sprint(backend, " indexed=", indexed), func(t *testing.T) {
+				s := NewService(log, ftsRanks{findDB{comics: comics}}, staticWords{}, backend, staticProfiles{}, nil, nil)
+				s.index.Build(comics, TriggerManual)
+
+				find := s.Find
+				if indexed {
+					find = s.IndexedSearch
+				}
+				res, err := find(context.Background(), SearchQuery{Phrase: "linux"})
+				if err != nil {
+					t.Fatalf("find: %v", err)
+				}
+				if len(res.Hits) == 0 {
+					t.Fatal("no hits")
+				}
+				for _, h := range res.Hits {
+					if h.Explain != nil {
+						t.Fatalf("comic %d: explanation %+v without explain", h.ID, h.Explain)
+					}
+				}
+			})
+		}
+	}
+}
@@ -2,208 +2,648 @@ package core
 
 import (
 	"context"
-	"math/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	defaultLimit = 10
-
-	weightTitle = 5
-	weightAlt   = 3
-	weightWords = 1
+	defaultLimit    = 10
+	maxLimit        = 100
+	defaultTopTerms = 10
+	maxDriftIDs     = 100
+	maxExclude      = 1000
+
+	// стриминг: limit поиска и размер пачки
+	maxStreamLimit = 100000
+	defaultChunk   = 100
+	maxChunk       = 1000
+
+	defaultAnalyticsWindow = 24 * time.Hour
+	maxAnalyticsWindow     = QueryLogRetention
 )
 
+// QueryLogRetention - журнал запросов хранится столько, сколько может запросить аналитика
+const QueryLogRetention = 90 * 24 * time.Hour
+
 type Service struct {
-	db    DB
-	words Words
+	log      *slog.Logger
+	db       DB
+	words    Words
+	backend  Backend
+	profiles Profiles
 
 	index *InvertedIndex
+
+	// cache - готовые результаты поиска, сбрасывается при смене generation индекса
+	cache *LRU[cachedResult]
+
+	// queries - журнал запросов для аналитики, nil - не пишем
+	queries QueryRecorder
+
+	// rebuildMu - пересборки (ttl, nats, ручная) идут по одной,
+	// чтобы более старое чтение из БД не перезаписало свежий индекс
+	rebuildMu sync.Mutex
+
+	// errMu отдельно от rebuildMu, чтобы IndexStats не ждал идущую пересборку
+	errMu          sync.Mutex
+	lastRebuildErr string
+	lastRebuildAt  time.Time
+
+	searches        atomic.Uint64
+	indexedSearches atomic.Uint64
+}
+
+// cachedResult - результат вместе с generation индекса, на котором он посчитан
+type cachedResult struct {
+	generation uint64
+	result     SearchResult
+}
+
+// ResultCache - кэш результатов поиска для NewService
+type ResultCache = LRU[cachedResult]
+
+// NewResultCache - size <= 0 выключает кэш
+func NewResultCache(size int, ttl time.Duration) *ResultCache {
+	return NewLRU[cachedResult](size, ttl)
 }
 
-func NewService(db DB, words Words) *Service {
+// NewService - cache может быть nil, тогда результаты не кэшируются;
+// queries nil - запросы не попадают в журнал
+func NewService(log *slog.Logger, db DB, words Words, backend Backend, profiles Profiles, cache *ResultCache, queries QueryRecorder) *Service {
 	return &Service{
-		db:    db,
-		words: words,
+		log:      log,
+		db:       db,
+		words:    words,
+		backend:  backend,
+		profiles: profiles,
 
-		index: NewInvertedIndex(),
+		index:   NewInvertedIndex(),
+		cache:   cache,
+		queries: queries,
 	}
 }
 
-// RebuildIndex - вызывается инициатором, полностью пересобирает индекс из БД.
-func (s *Service) RebuildIndex(ctx context.Context) error {
+// RebuildIndex - вызывается инициатором и подписчиком, полностью пересобирает индекс из БД.
+func (s *Service) RebuildIndex(ctx context.Context, trigger IndexTrigger) error {
+	_, err := s.Rebuild(ctx, trigger)
+	return err
+}
+
+// Rebuild - пересборка с отчетом. Новый индекс строится в стороне,
+// при ошибке чтения из БД продолжает работать старый
+func (s *Service) Rebuild(ctx context.Context, trigger IndexTrigger) (IndexRebuild, error) {
+	s.rebuildMu.Lock()
+	defer s.rebuildMu.Unlock()
+
+	start := time.Now()
 	comics, err := s.db.All(ctx)
 	if err != nil {
-		return err
+		s.setRebuildError(err)
+		return IndexRebuild{}, err
 	}
 
-	s.index.Build(comics)
-	return nil
+	generation := s.index.Build(comics, trigger)
+	s.cache.Purge()
+	s.setRebuildError(nil)
+	s.pushDictionary(ctx)
+
+	return IndexRebuild{
+		Generation: generation,
+		Docs:       len(comics),
+		Duration:   time.Since(start),
+		Trigger:    trigger,
+	}, nil
 }
 
-func (s *Service) Ping(ctx context.Context) error {
-	return s.db.Ping(ctx)
+// pushDictionary - словарь для исправления опечаток в words; ошибка не ломает пересборку,
+// Correct просто останется со старым словарем до следующей
+func (s *Service) pushDictionary(ctx context.Context) {
+	sp, ok := s.words.(Speller)
+	if !ok {
+		return
+	}
+	counts := s.index.TermCounts()
+	// без написаний words вернет во фразе сами термы - хуже, но не повод не отдавать словарь
+	surfaces, err := s.db.TermSurfaces(ctx)
+	if err != nil {
+		s.log.WarnContext(ctx, "load term surfaces failed", "error", err)
+	}
+	if err := sp.SetDictionary(ctx, counts, surfaces); err != nil {
+		s.log.WarnContext(ctx, "push spelling dictionary failed", "terms", len(counts), "error", err)
+		return
+	}
+	s.log.DebugContext(ctx, "spelling dictionary pushed", "terms", len(counts))
 }
 
-func (s *Service) Find(ctx context.Context, phrase string, limit uint32) ([]Comics, error) {
-	phrase = strings.TrimSpace(phrase)
-	if phrase == "" {
-		return nil, ErrEmptyPhrase
+func (s *Service) setRebuildError(err error) {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+
+	if err == nil {
+		s.lastRebuildErr = ""
+		s.lastRebuildAt = time.Time{}
+		return
 	}
-	if limit == 0 {
-		limit = defaultLimit
+	s.lastRebuildErr = err.Error()
+	s.lastRebuildAt = time.Now()
+}
+
+// VerifyIndex - сверяет набор документов и хэши их токенов в индексе с БД.
+// Токены документа в индексе восстанавливаются по posting lists (InvertedIndex.Fingerprints)
+func (s *Service) VerifyIndex(ctx context.Context) (IndexDrift, error) {
+	comics, err := s.db.All(ctx)
+	if err != nil {
+		return IndexDrift{}, err
 	}
-	if limit > 100 {
-		return nil, ErrToLargeLimit
+	generation, indexed := s.index.Fingerprints()
+
+	drift := IndexDrift{
+		Generation: generation,
+		DBDocs:     len(comics),
+		IndexDocs:  len(indexed),
+	}
+
+	inDB := make(map[int]struct{}, len(comics))
+	for _, c := range comics {
+		inDB[c.ID] = struct{}{}
+		hash, ok := indexed[c.ID]
+		switch {
+		case !ok:
+			drift.MissingCount++
+			drift.Missing = appendCapped(drift.Missing, c.ID)
+		case hash != tokensHash(comicTokens(c)):
+			drift.MismatchedCount++
+			drift.Mismatched = appendCapped(drift.Mismatched, c.ID)
+		}
+	}
+	for id := range indexed {
+		if _, ok := inDB[id]; !ok {
+			drift.StaleCount++
+			drift.Stale = appendCapped(drift.Stale, id)
+		}
+	}
+
+	sort.Ints(drift.Missing)
+	sort.Ints(drift.Stale)
+	sort.Ints(drift.Mismatched)
+	return drift, nil
+}
+
+func appendCapped(ids []int, id int) []int {
+	if len(ids) >= maxDriftIDs {
+		return ids
+	}
+	return append(ids, id)
+}
+
+// IndexStats - статистика индекса и счетчики поисковых запросов
+func (s *Service) IndexStats(_ context.Context, top uint32) (IndexStats, error) {
+	if top == 0 {
+		top = defaultTopTerms
+	}
+	if top > 100 {
+		return IndexStats{}, ErrToLargeLimit
+	}
+
+	st := s.index.Stats(int(top))
+
+	s.errMu.Lock()
+	st.LastRebuildError = s.lastRebuildErr
+	st.LastRebuildErrorAt = s.lastRebuildAt
+	s.errMu.Unlock()
+
+	st.Searches = s.searches.Load()
+	st.IndexedSearches = s.indexedSearches.Load()
+	st.ResultCache = s.cache.Stats()
+	if w, ok := s.words.(CacheStatter); ok {
+		st.NormCache = w.CacheStats()
 	}
+	if w, ok := s.words.(AnalyzerVersioner); ok {
+		st.QueryAnalyzerVersion = w.AnalyzerVersion()
+	}
+	return st, nil
+}
+
+func (s *Service) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
 
-	// нормализуем фразу
-	tokens, err := s.words.Norm(ctx, phrase)
+// IndexReady - индекс собран хотя бы раз; до этого isearch и комикс дня отвечают пусто
+func (s *Service) IndexReady(_ context.Context) error {
+	if s.index.Generation() == 0 {
+		return ErrIndexNotReady
+	}
+	return nil
+}
+
+func (s *Service) Find(ctx context.Context, q SearchQuery) (SearchResult, error) {
+	s.searches.Add(1)
+
+	plan, err := s.prepare(ctx, EndpointSearch, q, defaultLimit, maxLimit)
+	if errors.Is(err, ErrNonePhrase) {
+		// фраза из одних стоп-слов - тоже запрос без результатов, он нужен в аналитике
+		s.logSearch(ctx, plan, SearchResult{}, false)
+	}
 	if err != nil {
-		return nil, err
+		return SearchResult{}, err
 	}
-	if len(tokens) == 0 {
-		return nil, ErrNonePhrase
+
+	return s.cached(ctx, plan, func() (SearchResult, error) {
+		return s.find(ctx, plan)
+	})
+}
+
+// find - поиск через БД выбранным бэкендом
+func (s *Service) find(ctx context.Context, plan searchPlan) (SearchResult, error) {
+	if s.backend == BackendFTS {
+		// полнотекстовый режим - БД сама ранжирует и применяет limit
+		ranked, err := s.db.FindRanked(ctx, plan.terms, plan.limit, plan.profile.FTSWeights(), plan.filters)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		// limit применен в БД, фасеты по всем кандидатам считаются отдельным запросом,
+		// из них же Total: у каждого кандидата ровно один год
+		facets, err := s.db.Facets(ctx, plan.tokens, plan.filters)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		total := 0
+		for _, n := range facets.Years {
+			total += n
+		}
+		hits := make([]Hit, 0, len(ranked))
+		for _, rc := range ranked {
+			hit := Hit{Comics: rc.Comics, Score: rc.Rank}
+			if plan.explain {
+				hit.Explain = explainFTS(rc, plan.tokens, plan.profile)
+			}
+			hits = append(hits, hit)
+		}
+		return SearchResult{Hits: hits, Total: uint32(total), Tokens: plan.tokens, Facets: facets}, nil
 	}
 
 	// получаем кандидатов из бд
-	comics, err := s.db.Find(ctx, tokens)
+	comics, err := s.db.Find(ctx, plan.tokens, plan.filters)
 	if err != nil {
-		return nil, err
+		return SearchResult{}, err
 	}
-
-	result, _ := rangComics(comics, tokens, limit)
-	return result, nil
+	hits, total, facets := rangComics(comics, plan.terms, plan.limit, plan.profile, plan.explain)
+	return SearchResult{Hits: hits, Total: total, Tokens: plan.tokens, Facets: facets}, nil
 }
 
 // IndexedSearch - метод поиска по индексу
-func (s *Service) IndexedSearch(ctx context.Context, phrase string, limit uint32) ([]Comics, uint32, error) {
-	phrase = strings.TrimSpace(phrase)
-	if phrase == "" {
-		return nil, 0, ErrEmptyPhrase
+func (s *Service) IndexedSearch(ctx context.Context, q SearchQuery) (SearchResult, error) {
+	s.indexedSearches.Add(1)
+
+	plan, err := s.prepare(ctx, EndpointISearch, q, defaultLimit, maxLimit)
+	if errors.Is(err, ErrNonePhrase) {
+		// фраза из одних стоп-слов - тоже запрос без результатов, он нужен в аналитике
+		s.logSearch(ctx, plan, SearchResult{}, false)
 	}
-	if limit == 0 {
-		limit = defaultLimit
+	if err != nil {
+		return SearchResult{}, err
 	}
-	if limit > 100 {
-		return nil, 0, ErrToLargeLimit
+
+	return s.cached(ctx, plan, func() (SearchResult, error) {
+		return s.indexedFind(plan), nil
+	})
+}
+
+// indexedFind - кандидаты из индекса в памяти, ранжирование по тому же алгоритму
+func (s *Service) indexedFind(plan searchPlan) SearchResult {
+	candidates := s.index.Candidates(plan.tokens, plan.filters)
+	hits, total, facets := rangComics(candidates, plan.terms, plan.limit, plan.profile, plan.explain)
+	return SearchResult{Hits: hits, Total: total, Tokens: plan.tokens, Facets: facets}
+}
+
+// StreamSearch - поиск для выгрузок: limit до maxStreamLimit (0 - все результаты),
+// результаты отдаются в send пачками по chunk. Следующая пачка не собирается,
+// пока send не вернется, поэтому медленный получатель тормозит отправку.
+// Hit собираются по пачкам: в fts строки читаются из БД по мере отправки,
+// в остальных режимах в памяти кроме кандидатов только порядок выдачи.
+// Мимо кэша и журнала запросов - это не пользовательский поиск
+func (s *Service) StreamSearch(ctx context.Context, q SearchQuery, indexed bool, chunk uint32, send func([]Hit) error) error {
+	size, err := chunkSize(chunk)
+	if err != nil {
+		return err
 	}
 
-	// нормализуем фразу
-	tokens, err := s.words.Norm(ctx, phrase)
+	endpoint := EndpointSearch
+	if indexed {
+		endpoint = EndpointISearch
+	}
+	plan, err := s.prepare(ctx, endpoint, q, maxStreamLimit, maxStreamLimit)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
-	if len(tokens) == 0 {
-		return nil, 0, ErrNonePhrase
+
+	if !indexed && s.backend == BackendFTS {
+		return s.streamRanked(ctx, plan, size, send)
 	}
 
-	// получаем ID комиксов из индекса
-	ids := s.index.DocsForTokens(tokens)
-	if len(ids) == 0 {
-		return nil, 0, nil
+	var candidates []Comics
+	if indexed {
+		candidates = s.index.Candidates(plan.tokens, plan.filters)
+	} else if candidates, err = s.db.Find(ctx, plan.tokens, plan.filters); err != nil {
+		return err
+	}
+	ranked, _ := rankComics(candidates, plan.terms, plan.profile)
+	if uint32(len(ranked)) > plan.limit {
+		ranked = ranked[:plan.limit]
 	}
 
-	// берём сами комиксы из памяти
-	candidates := s.index.DocsByIDs(ids)
+	for part := range slices.Chunk(ranked, size) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := send(rankedHits(candidates, part, plan.terms, plan.profile, plan.explain)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// ранжируем по тому же алгоритму
-	result, total := rangComics(candidates, tokens, limit)
-	return result, total, nil
+// streamRanked - fts-выгрузка: пачка отправляется, как только из БД прочитано chunk строк
+func (s *Service) streamRanked(ctx context.Context, plan searchPlan, size int, send func([]Hit) error) error {
+	hits := make([]Hit, 0, size)
+	err := s.db.FindRankedEach(ctx, plan.terms, plan.limit, plan.profile.FTSWeights(), plan.filters, func(rc RankedComics) error {
+		hit := Hit{Comics: rc.Comics, Score: rc.Rank}
+		if plan.explain {
+			hit.Explain = explainFTS(rc, plan.tokens, plan.profile)
+		}
+		hits = append(hits, hit)
+		if len(hits) < size {
+			return nil
+		}
+		if err := send(hits); err != nil {
+			return err
+		}
+		hits = make([]Hit, 0, size)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(hits) > 0 {
+		return send(hits)
+	}
+	return nil
 }
 
-// rangComics - общая функция для ранжирования для Find и IndexedSearch
-func rangComics(comics []Comics, tokens []string, limit uint32) ([]Comics, uint32) {
-	type scored struct {
-		comic Comics
-		score int
+// StreamComics - все комиксы по возрастанию id пачками по chunk,
+// каждая пачка читается из БД только после отправки предыдущей
+func (s *Service) StreamComics(ctx context.Context, chunk uint32, send func([]Comics) error) error {
+	size, err := chunkSize(chunk)
+	if err != nil {
+		return err
 	}
 
-	scoredList := make([]scored, 0, len(comics))
-	for _, c := range comics {
-		score := scoreComic(c, tokens)
-		if score > 0 {
-			scoredList = append(scoredList, scored{
-				comic: c,
-				score: score,
-			})
+	afterID := 0
+	for {
+		comics, err := s.db.GetAfter(ctx, afterID, size)
+		if err != nil {
+			return err
+		}
+		if len(comics) == 0 {
+			return nil
 		}
+		if err := send(comics); err != nil {
+			return err
+		}
+		if len(comics) < size {
+			return nil
+		}
+		afterID = comics[len(comics)-1].ID
 	}
+}
 
-	total := uint32(len(scoredList))
+func chunkSize(chunk uint32) (int, error) {
+	if chunk == 0 {
+		return defaultChunk, nil
+	}
+	if chunk > maxChunk {
+		return 0, ErrToLargeLimit
+	}
+	return int(chunk), nil
+}
 
-	// сортировка - по score убывает, при равенстве по ID возрастает
-	sort.Slice(scoredList, func(i, j int) bool {
-		if scoredList[i].score == scoredList[j].score {
-			return scoredList[i].comic.ID < scoredList[j].comic.ID
-		}
-		return scoredList[i].score > scoredList[j].score
-	})
+// searchPlan - провалидированный запрос после нормализации, из него же строится ключ кэша
+type searchPlan struct {
+	endpoint string
+	terms    []WeightedToken
+	tokens   []string // токены terms без весов, для выборки кандидатов
+	limit    uint32
+	profile  RankingProfile
+	explain  bool
+	filters  SearchFilters
+
+	// для журнала запросов, в ключ кэша не входят
+	phrase string
+	userID uint32
+	start  time.Time
+}
 
-	// применяем limit
-	if uint32(len(scoredList)) > limit {
-		scoredList = scoredList[:limit]
-	}
+// cacheKey - веса профиля входят в ключ, так как профиль с тем же именем может смениться при перечитывании файла
+func (p searchPlan) cacheKey(backend Backend) string {
+	return fmt.Sprintf("%s|%s|%s:%g,%g,%g,%g|%d|%t|%s|%s",
+		p.endpoint, backend,
+		p.profile.Name, p.profile.Coverage, p.profile.Title, p.profile.Alt, p.profile.Words,
+		p.limit, p.explain, p.filters.cacheKey(), termsKey(p.terms),
+	)
+}
 
-	out := make([]Comics, 0, len(scoredList))
-	for _, sc := range scoredList {
-		out = append(out, sc.comic)
+// termsKey - веса входят в ключ: смена словаря синонимов меняет их при тех же токенах
+func termsKey(terms []WeightedToken) string {
+	var b strings.Builder
+	for _, t := range terms {
+		fmt.Fprintf(&b, "%s:%g\x00", t.Token, t.Weight)
 	}
-	return out, total
+	return b.String()
 }
 
-// scoreComic - функция для подсчета весов
-func scoreComic(c Comics, tokens []string) int {
-	titleSet := makeSet(c.Title)
-	altSet := makeSet(c.Alt)
-	wordsSet := makeSet(c.Words)
+// cached - отдает результат из кэша, если он посчитан на текущем generation индекса,
+// иначе считает через compute и кладет в кэш
+func (s *Service) cached(ctx context.Context, plan searchPlan, compute func() (SearchResult, error)) (SearchResult, error) {
+	key := plan.cacheKey(s.backend)
+	generation := s.index.Generation()
 
-	var titleMatches, altMatches, wordsMatches int
+	if c, ok := s.cache.Get(key); ok && c.generation == generation {
+		s.logSearch(ctx, plan, c.result, true)
+		return c.result, nil
+	}
 
-	// coveered - сет, для уникальных токенов, которые встречаются в любом поле
-	covered := make(map[string]struct{}, len(tokens))
+	result, err := compute()
+	if err != nil {
+		return SearchResult{}, err
+	}
+	s.cache.Put(key, cachedResult{generation: generation, result: result})
 
-	for _, t := range tokens {
-		matched := false
-		if titleSet[t] {
-			titleMatches++
-			matched = true
-		}
-		if altSet[t] {
-			altMatches++
-			matched = true
-		}
-		if wordsSet[t] {
-			wordsMatches++
-			matched = true
-		}
+	s.logSearch(ctx, plan, result, false)
+	return result, nil
+}
 
-		if matched {
-			covered[t] = struct{}{}
+// logSearch - профиль пишется в лог с каждым запросом, чтобы потом сравнивать выдачу офлайн;
+// сюда же попадает запись в журнал запросов для аналитики
+func (s *Service) logSearch(ctx context.Context, plan searchPlan, result SearchResult, cached bool) {
+	if s.queries != nil {
+		// группируем по словам самой фразы без синонимов: их набор меняется с перезагрузкой словаря;
+		// без токенов - по самой фразе, иначе все такие запросы сольются в пустую строку
+		query := normalizedQuery(queryTokens(plan.terms))
+		if query == "" {
+			query = strings.ToLower(strings.Join(strings.Fields(plan.phrase), " "))
 		}
+		s.queries.Record(QueryLogEntry{
+			At:       plan.start,
+			Endpoint: plan.endpoint,
+			Phrase:   plan.phrase,
+			Query:    query,
+			Tokens:   plan.tokens,
+			Results:  int(result.Total),
+			Latency:  time.Since(plan.start),
+			UserID:   plan.userID,
+			Cached:   cached,
+		})
+	}
+
+	ids := make([]int, 0, len(result.Hits))
+	for _, h := range result.Hits {
+		ids = append(ids, h.ID)
+	}
+	s.log.InfoContext(ctx, "search",
+		"endpoint", plan.endpoint,
+		"backend", s.backend,
+		"profile", plan.profile.Name,
+		"tokens", plan.tokens,
+		"limit", plan.limit,
+		"total", result.Total,
+		"ids", ids,
+		"cached", cached,
+	)
+}
+
+// prepare - проверка запроса, выбор профиля и нормализация фразы, общая для всех видов поиска;
+// нулевой limit заменяется на defLimit
+func (s *Service) prepare(ctx context.Context, endpoint string, q SearchQuery, defLimit, maxLimit uint32) (searchPlan, error) {
+	start := time.Now()
+	phrase := strings.TrimSpace(q.Phrase)
+	if phrase == "" {
+		return searchPlan{}, ErrEmptyPhrase
+	}
+	limit := q.Limit
+	if limit == 0 {
+		limit = defLimit
+	}
+	if limit > maxLimit {
+		return searchPlan{}, ErrToLargeLimit
 	}
 
-	if len(covered) == 0 {
-		return 0
+	profile, err := s.profiles.Profile(q.Profile)
+	if err != nil {
+		return searchPlan{}, err
 	}
 
-	// coveredTokens нужен чтобы комикс, который покрывает много токенов стоял выше остальных
-	coveredTokens := len(covered)
+	filters := q.Filters
+	if err := filters.Validate(); err != nil {
+		return searchPlan{}, err
+	}
+	filters.IDs = slices.Clone(filters.IDs)
+	slices.Sort(filters.IDs)
 
-	return coveredTokens*100 + titleMatches*weightTitle + altMatches*weightAlt + wordsMatches*weightWords
+	// нормализуем фразу и раскрываем синонимы
+	terms, err := s.words.Expand(ctx, phrase)
+	if err != nil {
+		return searchPlan{}, err
+	}
+	plan := searchPlan{
+		endpoint: endpoint,
+		terms:    terms,
+		tokens:   Tokens(terms),
+		limit:    limit,
+		profile:  profile,
+		explain:  q.Explain,
+		filters:  filters,
+
+		phrase: phrase,
+		userID: q.UserID,
+		start:  start,
+	}
+	if len(terms) == 0 {
+		// plan отдается и с ошибкой: Find и IndexedSearch пишут такой запрос в журнал
+		return plan, ErrNonePhrase
+	}
+	return plan, nil
 }
 
-// Сет для перевода слайса в мапу для более быстрой проверки (O(1) вместо O(n))
-func makeSet(arr []string) map[string]bool {
-	m := make(map[string]bool, len(arr))
-	for _, v := range arr {
-		if v == "" {
-			continue
+// queryTokens - токены самой фразы, синонимы с весом меньше 1 отбрасываются
+func queryTokens(terms []WeightedToken) []string {
+	out := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t.Weight >= 1 {
+			out = append(out, t.Token)
 		}
-		m[v] = true
 	}
-	return m
+	return out
+}
+
+// normalizedQuery - одинаковые по смыслу фразы ("cats dog", "dog cat") дают один ключ
+func normalizedQuery(tokens []string) string {
+	uniq := slices.Clone(tokens)
+	slices.Sort(uniq)
+	return strings.Join(slices.Compact(uniq), " ")
+}
+
+// TopQueries - самые частые нормализованные запросы за окно
+func (s *Service) TopQueries(ctx context.Context, window time.Duration, limit uint32) ([]QueryStat, error) {
+	since, n, err := analyticsWindow(window, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.TopQueries(ctx, since, n)
+}
+
+// ZeroResultQueries - частые запросы без результатов за окно
+func (s *Service) ZeroResultQueries(ctx context.Context, window time.Duration, limit uint32) ([]QueryStat, error) {
+	since, n, err := analyticsWindow(window, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.ZeroResultQueries(ctx, since, n)
+}
+
+// LatencyPercentiles - p50/p90/p99 времени ответа по эндпоинтам за окно
+func (s *Service) LatencyPercentiles(ctx context.Context, window time.Duration) ([]LatencyStats, error) {
+	since, _, err := analyticsWindow(window, defaultLimit)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.LatencyPercentiles(ctx, since)
+}
+
+// analyticsWindow - нулевые окно и limit заменяются значениями по умолчанию
+func analyticsWindow(window time.Duration, limit uint32) (time.Time, int, error) {
+	if window == 0 {
+		window = defaultAnalyticsWindow
+	}
+	if window < 0 || window > maxAnalyticsWindow {
+		return time.Time{}, 0, ErrBadArguments
+	}
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if limit > 100 {
+		return time.Time{}, 0, ErrToLargeLimit
+	}
+	return time.Now().Add(-window), int(limit), nil
 }
 
 // GetComicByID - получение комикса по id
@@ -245,26 +685,69 @@ func (s *Service) GetAllComics(ctx context.Context, page, limit uint32) ([]Comic
 	return comics, uint32(total), nil
 }
 
-// RandomComic - получаем рандомный комикс
-func (s *Service) RandomComic(ctx context.Context) (Comics, error) {
-	total, err := s.db.Count(ctx)
-	if err != nil {
-		return Comics{}, err
+// RandomComic - случайный комикс из индекса в памяти, без OFFSET по таблице
+func (s *Service) RandomComic(_ context.Context, q RandomQuery) (Comics, error) {
+	if len(q.Exclude) > maxExclude {
+		return Comics{}, ErrToLargeLimit
 	}
-	if total == 0 {
-		return Comics{}, ErrNonePhrase
+	seed := rand.Uint64()
+	if q.Seed != nil {
+		seed = *q.Seed
 	}
 
-	// случайный offset [0, total)
-	n := rand.Intn(total)
+	exclude := slices.Clone(q.Exclude)
+	slices.Sort(exclude)
 
-	comics, err := s.db.GetAll(ctx, n, 1)
+	comic, ok := s.index.Pick(seed, exclude)
+	if !ok {
+		return Comics{}, ErrComicNotFound
+	}
+	return comic, nil
+}
+
+// ComicOfTheDay - один и тот же комикс на дату и seed. Популярность фиксируется в БД первым запросом
+// за сегодняшнюю дату, а выбор через PickRendezvous сдвигает только добавленный комикс, который обгоняет победителя
+func (s *Service) ComicOfTheDay(ctx context.Context, q DailyQuery) (Comics, error) {
+	today := time.Now().UTC().Format(time.DateOnly)
+	day := today
+	if !q.Date.IsZero() {
+		day = q.Date.UTC().Format(time.DateOnly)
+	}
+	if day > today {
+		return Comics{}, fmt.Errorf("%w: date %s is in the future", ErrBadArguments, day)
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(day))
+	_, _ = h.Write(binary.LittleEndian.AppendUint64(nil, q.Seed))
+
+	popularity, err := s.dailyPopularity(ctx, day, day == today, q.Popularity)
 	if err != nil {
 		return Comics{}, err
 	}
-	if len(comics) == 0 {
-		return Comics{}, ErrNonePhrase
+	comic, ok := s.index.PickRendezvous(h.Sum64(), popularity)
+	if !ok {
+		return Comics{}, ErrComicNotFound
 	}
+	return comic, nil
+}
 
-	return comics[0], nil
+// dailyPopularity - снимок популярности на день: первый запрос с популярностью за сегодня фиксирует его,
+// новые избранные в течение дня на выбор не влияют. Снимок хранится в БД, поэтому рестарт
+// и другие реплики search выбирают тот же комикс. Прошлые дни только читаются: сегодняшняя
+// популярность для них не подходит, и без снимка выбор равномерный. Так и таблица не растет
+// от произвольных дат в запросах
+func (s *Service) dailyPopularity(ctx context.Context, day string, today bool, popularity map[int]int) (map[int]int, error) {
+	if len(popularity) == 0 {
+		// равномерный выбор без учета популярности
+		return nil, nil
+	}
+	if !today {
+		popularity = nil
+	}
+	snapshot, err := s.db.DailyPopularity(ctx, day, popularity)
+	if err != nil {
+		return nil, fmt.Errorf("daily popularity: %w", err)
+	}
+	return snapshot, nil
 }
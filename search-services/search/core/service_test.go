@@ -0,0 +1,324 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// staticWords - нормализация без words: фраза режется по пробелам, стоп-слова выбрасываются,
+// слова из synonyms раскрываются с весом 0.5
+type staticWords struct {
+	synonyms map[string]string
+}
+
+func (w staticWords) Expand(_ context.Context, phrase string) ([]WeightedToken, error) {
+	var terms []WeightedToken
+	for _, f := range strings.Fields(strings.ToLower(phrase)) {
+		if f == "the" || f == "of" {
+			continue
+		}
+		terms = append(terms, WeightedToken{Token: f, Weight: 1})
+		if syn, ok := w.synonyms[f]; ok {
+			terms = append(terms, WeightedToken{Token: syn, Weight: 0.5})
+		}
+	}
+	return terms, nil
+}
+
+// memRecorder - журнал запросов в памяти
+type memRecorder struct {
+	entries []QueryLogEntry
+}
+
+func (r *memRecorder) Record(e QueryLogEntry) {
+	r.entries = append(r.entries, e)
+}
+
+// staticProfiles - всегда профиль по умолчанию
+type staticProfiles struct{}
+
+func (staticProfiles) Profile(string) (RankingProfile, error) {
+	return DefaultProfile, nil
+}
+
+// findDB - Find отдает корпус как есть, FindRanked и Facets ведут себя как Postgres:
+// limit применяется к выдаче, фасеты считаются по всем совпадениям
+type findDB struct {
+	DB
+	comics []Comics
+	read   *int // сколько строк FindRankedEach успел прочитать
+}
+
+func (db findDB) Find(context.Context, []string, SearchFilters) ([]Comics, error) {
+	return db.comics, nil
+}
+
+func (db findDB) FindRanked(ctx context.Context, terms []WeightedToken, limit uint32, weights [4]float64, filters SearchFilters) ([]RankedComics, error) {
+	var out []RankedComics
+	err := db.FindRankedEach(ctx, terms, limit, weights, filters, func(rc RankedComics) error {
+		out = append(out, rc)
+		return nil
+	})
+	return out, err
+}
+
+func (db findDB) FindRankedEach(_ context.Context, terms []WeightedToken, limit uint32, _ [4]float64, _ SearchFilters, fn func(RankedComics) error) error {
+	n := uint32(0)
+	for _, c := range db.comics {
+		p := scoreComic(c, terms)
+		if p.covered == 0 || n == limit {
+			continue
+		}
+		n++
+		if db.read != nil {
+			*db.read = int(n)
+		}
+		if err := fn(RankedComics{Comics: c, Rank: p.covered}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db findDB) Facets(_ context.Context, tokens []string, _ SearchFilters) (Facets, error) {
+	terms := make([]WeightedToken, 0, len(tokens))
+	for _, t := range tokens {
+		terms = append(terms, WeightedToken{Token: t, Weight: 1})
+	}
+	facets := newFacets()
+	for _, c := range db.comics {
+		if p := scoreComic(c, terms); p.covered > 0 {
+			facets.add(c, p)
+		}
+	}
+	return facets, nil
+}
+
+// linuxCorpus - 7 комиксов про linux и 3 посторонних
+func linuxCorpus() []Comics {
+	var comics []Comics
+	for id := 1; id <= 10; id++ {
+		title := "linux"
+		if id > 7 {
+			title = "cat"
+		}
+		comics = append(comics, Comics{ID: id, Title: []string{title}})
+	}
+	return comics
+}
+
+func TestService_FindTotal(t *testing.T) {
+	comics := linuxCorpus()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, backend := range []Backend{BackendArray, BackendFTS} {
+		t.Run(string(backend), func(t *testing.T) {
+			s := NewService(log, findDB{comics: comics}, staticWords{}, backend, staticProfiles{}, nil, nil)
+			res, err := s.Find(context.Background(), SearchQuery{Phrase: "linux", Limit: 3})
+			if err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			if len(res.Hits) != 3 {
+				t.Fatalf("hits = %d, want limit 3", len(res.Hits))
+			}
+			if res.Total != 7 {
+				t.Fatalf("total = %d, want all 7 matches", res.Total)
+			}
+			if res.Facets.Years[FacetUnknownYear] != 7 {
+				t.Fatalf("facets = %+v, want 7 matches", res.Facets)
+			}
+		})
+	}
+}
+
+func TestService_FindSynonym(t *testing.T) {
+	comics := []Comics{
+		{ID: 1, Title: []string{"cat"}},
+		{ID: 2, Title: []string{"javascript"}},
+		{ID: 3, Title: []string{"js"}},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	words := staticWords{synonyms: map[string]string{"js": "javascript"}}
+
+	for _, indexed := range []bool{false, true} {
+		t.Run(fmt.Sprint("indexed=", indexed), func(t *testing.T) {
+			s := NewService(log, findDB{comics: comics}, words, BackendArray, staticProfiles{}, nil, nil)
+			s.index.Build(comics, TriggerManual)
+
+			find := s.Find
+			if indexed {
+				find = s.IndexedSearch
+			}
+			res, err := find(context.Background(), SearchQuery{Phrase: "js"})
+			if err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			var ids []int
+			for _, h := range res.Hits {
+				ids = append(ids, h.ID)
+			}
+			// комикс только с синонимом найден, но ниже комикса со словом запроса
+			if fmt.Sprint(ids) != "[3 2]" {
+				t.Fatalf("hits = %v, want [3 2]", ids)
+			}
+		})
+	}
+}
+
+func TestService_LogsEmptyQuery(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	queries := &memRecorder{}
+	s := NewService(log, findDB{}, staticWords{}, BackendArray, staticProfiles{}, nil, queries)
+
+	_, err := s.Find(context.Background(), SearchQuery{Phrase: "  The  of "})
+	if !errors.Is(err, ErrNonePhrase) {
+		t.Fatalf("err = %v, want ErrNonePhrase", err)
+	}
+	if len(queries.entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(queries.entries))
+	}
+	e := queries.entries[0]
+	if e.Results != 0 || e.Query != "the of" || e.Endpoint != EndpointSearch {
+		t.Fatalf("entry = %+v, want zero-result entry grouped by phrase", e)
+	}
+}
+
+func TestService_StreamSearch(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tests := []struct {
+		name    string
+		backend Backend
+		indexed bool
+	}{
+		{name: "array", backend: BackendArray},
+		{name: "fts", backend: BackendFTS},
+		{name: "indexed", backend: BackendArray, indexed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read := 0
+			db := findDB{comics: linuxCorpus(), read: &read}
+			s := NewService(log, db, staticWords{}, tt.backend, staticProfiles{}, nil, nil)
+			s.index.Build(db.comics, TriggerManual)
+
+			var chunks [][]int
+			var readAtSend []int
+			err := s.StreamSearch(context.Background(), SearchQuery{Phrase: "linux", Limit: 5}, tt.indexed, 2, func(hits []Hit) error {
+				var ids []int
+				for _, h := range hits {
+					ids = append(ids, h.ID)
+				}
+				chunks = append(chunks, ids)
+				readAtSend = append(readAtSend, read)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+			if fmt.Sprint(chunks) != "[[1 2] [3 4] [5]]" {
+				t.Fatalf("chunks = %v, want [[1 2] [3 4] [5]]", chunks)
+			}
+			// fts не дочитывает выдачу до отправки первой пачки
+			if tt.backend == BackendFTS && !tt.indexed && readAtSend[0] != 2 {
+				t.Fatalf("rows read before first chunk = %d, want 2", readAtSend[0])
+			}
+		})
+	}
+}
+
+// dailyDB - снимки популярности в общей для реплик БД: первый на дату выигрывает
+type dailyDB struct {
+	DB
+	mu        *sync.Mutex
+	snapshots map[string]map[int]int
+}
+
+func (db dailyDB) DailyPopularity(_ context.Context, day string, popularity map[int]int) (map[int]int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.snapshots[day]; !ok && len(popularity) > 0 {
+		db.snapshots[day] = maps.Clone(popularity)
+	}
+	return db.snapshots[day], nil
+}
+
+func TestService_ComicOfTheDayStable(t *testing.T) {
+	var comics []Comics
+	for id := 1; id <= 30; id++ {
+		comics = append(comics, Comics{ID: id, URL: fmt.Sprintf("https://imgs.xkcd.com/comics/%d.png", id)})
+	}
+	db := dailyDB{mu: &sync.Mutex{}, snapshots: make(map[string]map[int]int)}
+	replica := func() *Service {
+		s := NewService(slog.New(slog.NewTextHandler(io.Discard, nil)), db, nil, BackendArray, nil, nil, nil)
+		s.index.Build(comics, TriggerManual)
+		return s
+	}
+	s := replica()
+	ctx := context.Background()
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+
+	first, err := s.ComicOfTheDay(ctx, DailyQuery{Date: date, Seed: 42, Popularity: map[int]int{5: 2, 7: 1}})
+	if err != nil {
+		t.Fatalf("comic of the day: %v", err)
+	}
+	// за день кто-то добавил комикс в избранное - выбор на эту дату не меняется
+	// ни на этой реплике, ни на другой или после рестарта: снимок берется из БД
+	other := replica()
+	for id := 1; id <= 30; id++ {
+		popularity := map[int]int{5: 2, 7: 1}
+		popularity[id]++
+		for _, r := range []*Service{s, other} {
+			got, err := r.ComicOfTheDay(ctx, DailyQuery{Date: date.Add(time.Hour), Seed: 42, Popularity: popularity})
+			if err != nil {
+				t.Fatalf("comic of the day: %v", err)
+			}
+			if got.ID != first.ID {
+				t.Fatalf("favorite on %d moved comic of the day from %d to %d", id, first.ID, got.ID)
+			}
+		}
+	}
+	if len(db.snapshots) != 1 {
+		t.Fatalf("snapshots = %v, want one for %s", db.snapshots, date.Format(time.DateOnly))
+	}
+}
+
+func TestService_ComicOfTheDayPastAndFuture(t *testing.T) {
+	var comics []Comics
+	for id := 1; id <= 30; id++ {
+		comics = append(comics, Comics{ID: id, URL: fmt.Sprintf("https://imgs.xkcd.com/comics/%d.png", id)})
+	}
+	db := dailyDB{mu: &sync.Mutex{}, snapshots: make(map[string]map[int]int)}
+	s := NewService(slog.New(slog.NewTextHandler(io.Discard, nil)), db, nil, BackendArray, nil, nil, nil)
+	s.index.Build(comics, TriggerManual)
+	ctx := context.Background()
+
+	// прошлый день без снимка: сегодняшняя популярность не сохраняется и на выбор не влияет
+	past := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	uniform, err := s.ComicOfTheDay(ctx, DailyQuery{Date: past, Seed: 42})
+	if err != nil {
+		t.Fatalf("comic of the day: %v", err)
+	}
+	got, err := s.ComicOfTheDay(ctx, DailyQuery{Date: past, Seed: 42, Popularity: map[int]int{5: 100, 7: 50}})
+	if err != nil {
+		t.Fatalf("comic of the day: %v", err)
+	}
+	if got.ID != uniform.ID {
+		t.Fatalf("past day = %d with popularity, want unbiased %d", got.ID, uniform.ID)
+	}
+	if len(db.snapshots) != 0 {
+		t.Fatalf("snapshots = %v, want none for a past day", db.snapshots)
+	}
+
+	_, err = s.ComicOfTheDay(ctx, DailyQuery{Date: time.Now().UTC().AddDate(0, 0, 1), Seed: 42})
+	if !errors.Is(err, ErrBadArguments) {
+		t.Fatalf("future day: err = %v, want ErrBadArguments", err)
+	}
+}
@@ -9,12 +9,22 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 	"yadro.com/course/search/adapters/broker"
 	"yadro.com/course/search/adapters/initiator"
+	searchmetrics "yadro.com/course/search/adapters/metrics"
+	"yadro.com/course/search/adapters/querylog"
+	"yadro.com/course/search/adapters/ranking"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"yadro.com/course/pkg/admintoken"
+	"yadro.com/course/pkg/health"
+	"yadro.com/course/pkg/metrics"
+	"yadro.com/course/pkg/requestid"
+	"yadro.com/course/pkg/tracing"
 	searchpb "yadro.com/course/proto/search"
 	"yadro.com/course/search/adapters/db"
 	searchgrpc "yadro.com/course/search/adapters/grpc"
@@ -43,6 +53,19 @@ func run(cfg config.Config, log *slog.Logger) error {
 	log.Info("starting server")
 	log.Debug("debug messages are enabled")
 
+	// tracing: до создания gRPC клиентов и серверов, они берут провайдер при создании
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "search")
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("failed to flush traces", "error", err)
+		}
+	}()
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
@@ -51,15 +74,41 @@ func run(cfg config.Config, log *slog.Logger) error {
 	if err != nil {
 		return fmt.Errorf("failed to connect to db: %v", err)
 	}
+	if err := storage.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate db: %v", err)
+	}
 
 	// words adapter
-	words, err := words.NewClient(cfg.WordsAddress, log)
+	words, err := words.NewClient(cfg.WordsAddress, cfg.WordsAdminToken, cfg.WordsAnalyzer, cfg.WordsLanguage, log, cfg.NormCacheSize, cfg.NormCacheTTL)
 	if err != nil {
 		return fmt.Errorf("failed create Words client: %v", err)
 	}
 
 	// service
-	search := core.NewService(storage, words)
+	backend, err := core.ParseBackend(cfg.SearchBackend)
+	if err != nil {
+		return err
+	}
+	log.Info("search backend selected", "backend", backend)
+
+	// ranking profiles
+	profiles, err := ranking.New(log, cfg.RankingFile)
+	if err != nil {
+		return err
+	}
+	profiles.Start(ctx, cfg.RankingReload)
+
+	cache := core.NewResultCache(cfg.CacheSize, cfg.CacheTTL)
+	// query log
+	queries := querylog.New(log, storage, cfg.QueryLogBuffer, cfg.QueryLogBatch, cfg.QueryLogFlush)
+	queries.Start(ctx)
+	// последняя пачка журнала пишется после отмены ctx, run дожидается ее
+	defer func() {
+		stop()
+		queries.Wait()
+	}()
+
+	search := core.NewService(log, storage, words, backend, profiles, cache, queries)
 
 	// initiator index
 	init := initiator.New(log, search, cfg.IndexTTL)
@@ -71,7 +120,27 @@ func run(cfg config.Config, log *slog.Logger) error {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			requestid.UnaryServerInterceptor(),
+			metrics.UnaryServerInterceptor(),
+			// вызовы суперпользователя - индекс и аналитику - может сделать только api
+			admintoken.UnaryServerInterceptor(cfg.AdminToken,
+				searchpb.Search_RebuildIndex_FullMethodName,
+				searchpb.Search_VerifyIndex_FullMethodName,
+				searchpb.Search_TopQueries_FullMethodName,
+				searchpb.Search_ZeroResultQueries_FullMethodName,
+				searchpb.Search_LatencyPercentiles_FullMethodName,
+			),
+		),
+		grpc.ChainStreamInterceptor(
+			requestid.StreamServerInterceptor(),
+			metrics.StreamServerInterceptor(),
+			// выгрузка всей базы - тоже только суперпользователю
+			admintoken.StreamServerInterceptor(cfg.AdminToken, searchpb.Search_StreamComics_FullMethodName),
+		),
+		tracing.ServerOption(),
+	)
 	searchpb.RegisterSearchServer(s, searchgrpc.NewServer(search))
 	reflection.Register(s)
 
@@ -82,10 +151,29 @@ func run(cfg config.Config, log *slog.Logger) error {
 	}
 	defer sub.Close()
 
+	// grpc.health.v1: готовность по Postgres, NATS и собранному индексу
+	checker := health.Register(s, log, searchpb.Search_ServiceDesc.ServiceName, map[string]health.Check{
+		"postgres": storage.Ping,
+		"nats":     sub.Ping,
+		"index":    search.IndexReady,
+	})
+
 	if err := sub.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start subscriber loop: %v", err)
 	}
 
+	// metrics: пул БД и /metrics на отдельном порту
+	metrics.RegisterDB(storage.Pool(), "search")
+	prometheus.MustRegister(searchmetrics.NewIndexCollector(log, search))
+	metrics.Serve(ctx, log, cfg.MetricsAddress)
+	checker.Start(ctx, health.Interval)
+	if cfg.WordsVersionCheck > 0 {
+		go words.RunVersionCheck(ctx, cfg.WordsVersionCheck)
+	}
+	if cfg.WordsDictionarySync > 0 {
+		go words.RunDictionarySync(ctx, cfg.WordsDictionarySync)
+	}
+
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down server")
@@ -111,5 +199,6 @@ func mustMakeLogger(levelStr string) *slog.Logger {
 		panic("unknown log level: " + levelStr)
 	}
 	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
-	return slog.New(handler)
+	// request_id из контекста вызова добавляется к записям *Context
+	return slog.New(requestid.NewLogHandler(handler))
 }
@@ -0,0 +1,11 @@
+package broker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var published = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "nats_published_total",
+	Help: "NATS messages published, by subject and result: ok or error.",
+}, []string{"subject", "result"})
@@ -4,6 +4,12 @@ import (
 	"context"
 	"github.com/nats-io/nats.go"
 	"log/slog"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"yadro.com/course/pkg/requestid"
+	"yadro.com/course/pkg/tracing"
 )
 
 type Publisher struct {
@@ -30,13 +36,39 @@ func (p *Publisher) Close() {
 	p.nc.Close()
 }
 
+// Ping - соединение с NATS живо: сервер отвечает на PING
+func (p *Publisher) Ping(ctx context.Context) error {
+	return p.nc.FlushWithContext(ctx)
+}
+
 func (p *Publisher) NotifyDBUpdated(ctx context.Context) {
-	if err := p.nc.Publish(p.subject, []byte("XKCD DB has been updated")); err != nil {
-		p.log.Error("Failed to publish updated data", "error", err)
+	ctx, span := tracing.Tracer().Start(ctx, "publish "+p.subject,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.MessagingSystemKey.String("nats"), semconv.MessagingDestinationName(p.subject)),
+	)
+	defer span.End()
+
+	msg := nats.NewMsg(p.subject)
+	msg.Data = []byte("XKCD DB has been updated")
+	// id запроса, запустившего обновление, чтобы пересборку индекса в search можно было связать с ним
+	if id := requestid.FromContext(ctx); id != "" {
+		msg.Header.Set(requestid.Header, id)
+	}
+	// traceparent: пересборка индекса в search продолжает трейс обновления
+	tracing.Inject(ctx, msg.Header)
+
+	if err := p.nc.PublishMsg(msg); err != nil {
+		published.WithLabelValues(p.subject, "error").Inc()
+		span.SetStatus(codes.Error, err.Error())
+		p.log.ErrorContext(ctx, "Failed to publish updated data", "error", err)
 		return
 	}
 	if err := p.nc.Flush(); err != nil {
-		p.log.Error("could not publish message", "error", err)
+		published.WithLabelValues(p.subject, "error").Inc()
+		span.SetStatus(codes.Error, err.Error())
+		p.log.ErrorContext(ctx, "could not publish message", "error", err)
+		return
 	}
-	p.log.Info("db updated event published")
+	published.WithLabelValues(p.subject, "ok").Inc()
+	p.log.InfoContext(ctx, "db updated event published")
 }
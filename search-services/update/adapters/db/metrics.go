@@ -0,0 +1,12 @@
+package db
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// comicsSaved - по одному на задачу воркера update (включая заглушки для 404)
+var comicsSaved = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "update_comics_saved_total",
+	Help: "Comics upserted by update workers, by result: ok or error.",
+}, []string{"result"})
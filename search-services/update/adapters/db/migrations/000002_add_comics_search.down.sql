@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_comics_words;
+DROP INDEX IF EXISTS idx_comics_alt;
+DROP INDEX IF EXISTS idx_comics_title;
+DROP INDEX IF EXISTS idx_comics_tsv;
+ALTER TABLE comics DROP COLUMN IF EXISTS tsv;
@@ -0,0 +1,16 @@
+-- Полнотекстовый поиск по комиксам: взвешенный tsvector + GIN индексы
+-- title -> A, alt -> B, words -> C; токены уже нормализованы words-сервисом,
+-- поэтому собираем вектор через array_to_tsvector без повторного стемминга
+ALTER TABLE comics ADD COLUMN IF NOT EXISTS tsv tsvector
+    GENERATED ALWAYS AS (
+        setweight(array_to_tsvector(array_remove(title, '')), 'A') ||
+        setweight(array_to_tsvector(array_remove(alt, '')), 'B') ||
+        setweight(array_to_tsvector(array_remove(words, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_comics_tsv ON comics USING GIN (tsv);
+
+-- индексы под пересечение массивов (&&) в поиске без FTS
+CREATE INDEX IF NOT EXISTS idx_comics_title ON comics USING GIN (title);
+CREATE INDEX IF NOT EXISTS idx_comics_alt ON comics USING GIN (alt);
+CREATE INDEX IF NOT EXISTS idx_comics_words ON comics USING GIN (words);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_comics_published;
+ALTER TABLE comics DROP COLUMN IF EXISTS has_transcript;
+ALTER TABLE comics DROP COLUMN IF EXISTS published;
@@ -0,0 +1,7 @@
+-- Метаданные комикса для фильтров поиска: дата публикации и наличие транскрипта.
+-- published NULL - комикс загружен до этой миграции (перекачивается при следующем update)
+-- или отсутствует на xkcd (заглушка с пустым img_url)
+ALTER TABLE comics ADD COLUMN IF NOT EXISTS published DATE;
+ALTER TABLE comics ADD COLUMN IF NOT EXISTS has_transcript BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_comics_published ON comics (published);
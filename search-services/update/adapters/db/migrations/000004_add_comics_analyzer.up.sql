@@ -0,0 +1,3 @@
+-- Версия цепочки words, которой нормализованы title, alt и words.
+-- NULL - комикс сохранен до этой миграции или не нормализован
+ALTER TABLE comics ADD COLUMN IF NOT EXISTS analyzer_version TEXT;
@@ -0,0 +1,3 @@
+-- Исходное написание терминов комикса до стемминга: термин -> первое написание в title, alt или words.
+-- По нему search отдает в words частотный словарь с читаемыми формами для исправления опечаток
+ALTER TABLE comics ADD COLUMN IF NOT EXISTS surfaces JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -2,11 +2,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"yadro.com/course/pkg/tracing"
 	"yadro.com/course/update/core"
 )
 
@@ -17,7 +20,7 @@ type DB struct {
 
 func New(log *slog.Logger, address string) (*DB, error) {
 
-	db, err := sqlx.Connect("pgx", address)
+	db, err := tracing.ConnectDB(address)
 	if err != nil {
 		log.Error("connection problem", "address", address, "error", err)
 		return nil, err
@@ -29,6 +32,15 @@ func New(log *slog.Logger, address string) (*DB, error) {
 	}, nil
 }
 
+func (db *DB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
+}
+
+// Pool - пул соединений, его статистика уходит в метрики
+func (db *DB) Pool() *sql.DB {
+	return db.conn.DB
+}
+
 // Add - идемпотентный upsert по id
 // comics.Words - передаем напрямую, sqlx сам конвертирует []string в text[]
 func (db *DB) Add(ctx context.Context, comics core.Comics) error {
@@ -48,19 +60,37 @@ func (db *DB) Add(ctx context.Context, comics core.Comics) error {
 		words = []string{}
 	}
 
-	_, err := db.conn.ExecContext(ctx, `
-		INSERT INTO comics (id, img_url, title, alt, words)
-		VALUES ($1, $2, $3, $4, $5)
+	// нулевая дата (заглушка 404 или xkcd без даты) пишется как NULL
+	published := sql.NullTime{Time: comics.Published, Valid: !comics.Published.IsZero()}
+
+	surfaces := comics.Surfaces
+	if surfaces == nil {
+		surfaces = map[string]string{}
+	}
+	surfacesJSON, err := json.Marshal(surfaces)
+	if err != nil {
+		return fmt.Errorf("marshal surfaces: %w", err)
+	}
+
+	_, err = db.conn.ExecContext(ctx, `
+		INSERT INTO comics (id, img_url, title, alt, words, published, has_transcript, analyzer_version, surfaces)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
 		ON CONFLICT (id) DO UPDATE SET
 			img_url   = EXCLUDED.img_url,
 		    title     = EXCLUDED.title,
 		    alt       = EXCLUDED.alt,
 			words     = EXCLUDED.words,
+			published = EXCLUDED.published,
+			has_transcript = EXCLUDED.has_transcript,
+			analyzer_version = EXCLUDED.analyzer_version,
+			surfaces  = EXCLUDED.surfaces,
 			fetched_at= NOW()
-	`, comics.ID, comics.URL, title, alt, words)
+	`, comics.ID, comics.URL, title, alt, words, published, comics.HasTranscript, comics.AnalyzerVersion, string(surfacesJSON))
 	if err != nil {
+		comicsSaved.WithLabelValues("error").Inc()
 		return fmt.Errorf("upsert comics: %w", err)
 	}
+	comicsSaved.WithLabelValues("ok").Inc()
 	return nil
 }
 
@@ -101,10 +131,15 @@ func (db *DB) Stats(ctx context.Context) (core.DBStats, error) {
 	return st, nil
 }
 
-// IDs - слайс уже загруженных id комиксов для идемпотентности
+// IDs - слайс уже загруженных id комиксов для идемпотентности.
+// Строки без даты публикации, сохраненные до миграции 000003, не считаются загруженными -
+// update перекачает их и заполнит published/has_transcript. Заглушки 404 (пустой img_url) не трогаем
 func (db *DB) IDs(ctx context.Context) ([]int, error) {
 	var out []int
-	if err := db.conn.SelectContext(ctx, &out, `SELECT id FROM comics`); err != nil {
+	if err := db.conn.SelectContext(ctx, &out, `
+		SELECT id FROM comics
+		WHERE published IS NOT NULL OR img_url = ''
+	`); err != nil {
 		return nil, fmt.Errorf("get ids: %w", err)
 	}
 	return out, nil
@@ -0,0 +1,18 @@
+package grpc
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	updateRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "update_runs_total",
+		Help: "Update jobs by result: ok, failed or already_running.",
+	}, []string{"result"})
+	updateDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "update_run_duration_seconds",
+		Help:    "Duration of update jobs that were started.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200},
+	})
+)
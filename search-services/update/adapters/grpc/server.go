@@ -6,6 +6,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"time"
 	updatepb "yadro.com/course/proto/update"
 	"yadro.com/course/update/core"
 )
@@ -48,12 +49,18 @@ func (s *Server) Status(ctx context.Context, _ *emptypb.Empty) (*updatepb.Status
 }
 
 func (s *Server) Update(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	start := time.Now()
 	if err := s.service.Update(ctx); err != nil {
 		if errors.Is(err, core.ErrAlreadyExists) {
+			updateRuns.WithLabelValues("already_running").Inc()
 			return nil, status.Error(codes.AlreadyExists, "update already running")
 		}
+		updateRuns.WithLabelValues("failed").Inc()
+		updateDuration.Observe(time.Since(start).Seconds())
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	updateRuns.WithLabelValues("ok").Inc()
+	updateDuration.Observe(time.Since(start).Seconds())
 
 	// Уведомляем брокер, что база обновилась
 	if s.notifier != nil {
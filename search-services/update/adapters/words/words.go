@@ -2,37 +2,55 @@ package words
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log/slog"
+	"strconv"
 	"yadro.com/course/update/core"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"yadro.com/course/pkg/grpcclient"
 	wordspb "yadro.com/course/proto/words"
 )
 
+// Лимиты NormBatch в words: пакет больше уходит потоком NormStream
+const (
+	maxBatchLen   = 1 << 20
+	maxBatchItems = 1000
+)
+
 type Client struct {
 	log    *slog.Logger
 	client wordspb.WordsClient
 	conn   *grpc.ClientConn
+	// analyzer - цепочка words для комиксов, пустая - default
+	analyzer string
+	// language - язык комиксов: короткий заголовок автоопределение может отнести к другому языку,
+	// и стемы разойдутся с индексом; пустой - автоопределение words
+	language string
 }
 
-func NewClient(address string, log *slog.Logger) (*Client, error) {
+func NewClient(address, analyzer, language string, log *slog.Logger) (*Client, error) {
 	// ClientConnection - создаем подключение для локальной сети/compose
-	conn, err := grpc.NewClient(
-		address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	conn, err := grpcclient.New(address, grpcclient.Options{
+		Idempotent: []string{
+			wordspb.Words_Ping_FullMethodName,
+			wordspb.Words_Norm_FullMethodName,
+			wordspb.Words_NormBatch_FullMethodName,
+		},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("new grpc client for  %s: %w", address, err)
 	}
 
 	return &Client{
-		client: wordspb.NewWordsClient(conn),
-		conn:   conn,
-		log:    log,
+		client:   wordspb.NewWordsClient(conn),
+		conn:     conn,
+		log:      log,
+		analyzer: analyzer,
+		language: language,
 	}, nil
 
 }
@@ -40,19 +58,100 @@ func NewClient(address string, log *slog.Logger) (*Client, error) {
 // Close grpc connection
 func (c *Client) Close() error { return c.conn.Close() }
 
-// Norm реализация порта normalizer
-// Делает grpc вызов Norm и маппит ошибки в доменные
-func (c *Client) Norm(ctx context.Context, phrase string) ([]string, error) {
-	resp, err := c.client.Norm(ctx, &wordspb.WordsRequest{Phrase: phrase})
+// NormBatch реализация порта normalizer
+// Делает один grpc вызов NormBatch на все фразы и маппит ошибки в доменные.
+// Пакет сверх лимитов NormBatch (длинные транскрипты) нормализуется через NormStream
+func (c *Client) NormBatch(ctx context.Context, phrases []string) ([]core.NormResult, error) {
+	total := 0
+	for _, p := range phrases {
+		total += len(p)
+	}
+	if total > maxBatchLen || len(phrases) > maxBatchItems {
+		return c.normStream(ctx, phrases)
+	}
+
+	req := &wordspb.NormBatchRequest{Items: make([]*wordspb.NormItem, 0, len(phrases))}
+	if c.analyzer != "" {
+		req.Analyzer = &c.analyzer
+	}
+	if c.language != "" {
+		req.Language = &c.language
+	}
+	for i, p := range phrases {
+		req.Items = append(req.Items, &wordspb.NormItem{Id: strconv.Itoa(i), Phrase: p})
+	}
+
+	resp, err := c.client.NormBatch(ctx, req)
+	if err != nil {
+		return nil, normError(status.Code(err), err)
+	}
+	if len(resp.GetResults()) != len(phrases) {
+		return nil, fmt.Errorf("norm batch: got %d results for %d phrases", len(resp.GetResults()), len(phrases))
+	}
+
+	out := make([]core.NormResult, 0, len(phrases))
+	for _, r := range resp.GetResults() {
+		out = append(out, normResult(r))
+	}
+	return out, nil
+}
+
+// normStream - фразы отправляются в фоне, ответы читаются по мере готовности:
+// words отвечает на каждую фразу, и без чтения ответов поток встанет на flow control
+func (c *Client) normStream(ctx context.Context, phrases []string) ([]core.NormResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.NormStream(ctx)
 	if err != nil {
-		switch status.Code(err) {
-		case codes.ResourceExhausted:
-			return nil, core.ErrBadArguments
-		case codes.Unavailable, codes.DeadlineExceeded:
-			return nil, core.ErrUnavailable
-		default:
-			return nil, err
+		return nil, normError(status.Code(err), err)
+	}
+
+	go func() {
+		for i, p := range phrases {
+			item := &wordspb.NormItem{Id: strconv.Itoa(i), Phrase: p}
+			if c.analyzer != "" {
+				item.Analyzer = &c.analyzer
+			}
+			if c.language != "" {
+				item.Language = &c.language
+			}
+			// ошибку отправки вернет Recv
+			if stream.Send(item) != nil {
+				return
+			}
+		}
+		_ = stream.CloseSend()
+	}()
+
+	out := make([]core.NormResult, 0, len(phrases))
+	for i := range phrases {
+		r, err := stream.Recv()
+		if err != nil {
+			return nil, normError(status.Code(err), err)
 		}
+		if r.GetId() != strconv.Itoa(i) {
+			return nil, fmt.Errorf("norm stream: got result %q for phrase %d", r.GetId(), i)
+		}
+		out = append(out, normResult(r))
+	}
+	return out, nil
+}
+
+func normResult(r *wordspb.NormResult) core.NormResult {
+	if code := codes.Code(r.GetCode()); code != codes.OK {
+		return core.NormResult{Err: normError(code, errors.New(r.GetError()))}
+	}
+	return core.NormResult{Words: r.GetWords(), Surfaces: r.GetSurfaces(), AnalyzerVersion: r.GetAnalyzerVersion()}
+}
+
+func normError(code codes.Code, err error) error {
+	switch code {
+	case codes.ResourceExhausted, codes.InvalidArgument:
+		return core.ErrBadArguments
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return core.ErrUnavailable
+	default:
+		return err
 	}
-	return resp.GetWords(), nil
 }
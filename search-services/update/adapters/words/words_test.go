@@ -0,0 +1,119 @@
+package words
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	wordspb "yadro.com/course/proto/words"
+	"yadro.com/course/update/core"
+)
+
+// fakeWords - words, который возвращает фразу словом и считает вызовы каждого RPC
+type fakeWords struct {
+	wordspb.UnimplementedWordsServer
+	batches, streams int
+	language         string
+}
+
+func (f *fakeWords) NormBatch(_ context.Context, in *wordspb.NormBatchRequest) (*wordspb.NormBatchReply, error) {
+	f.batches++
+	f.language = in.GetLanguage()
+	reply := &wordspb.NormBatchReply{}
+	for _, item := range in.GetItems() {
+		reply.Results = append(reply.Results, f.result(item))
+	}
+	return reply, nil
+}
+
+func (f *fakeWords) NormStream(stream grpc.BidiStreamingServer[wordspb.NormItem, wordspb.NormResult]) error {
+	f.streams++
+	for {
+		item, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f.language = item.GetLanguage()
+		if err := stream.Send(f.result(item)); err != nil {
+			return err
+		}
+	}
+}
+
+func (f *fakeWords) result(item *wordspb.NormItem) *wordspb.NormResult {
+	if item.GetPhrase() == "bad" {
+		return &wordspb.NormResult{Id: item.GetId(), Code: uint32(codes.InvalidArgument), Error: "bad phrase"}
+	}
+	return &wordspb.NormResult{Id: item.GetId(), Words: []string{item.GetPhrase()[:1]}, AnalyzerVersion: "v1"}
+}
+
+func newTestClient(t *testing.T, fake *fakeWords) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	wordspb.RegisterWordsServer(srv, fake)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &Client{client: wordspb.NewWordsClient(conn), conn: conn, language: "english"}
+}
+
+func TestNormBatch_StreamsLargeBatch(t *testing.T) {
+	tests := []struct {
+		name             string
+		phrases          []string
+		batches, streams int
+	}{
+		{name: "small", phrases: []string{"title", "bad", "alt"}, batches: 1},
+		{name: "too large", phrases: []string{"title", "bad", strings.Repeat("a", maxBatchLen)}, streams: 1},
+		{name: "too many", phrases: append([]string{"title", "bad"}, strings.Split(strings.Repeat("x", maxBatchItems), "")...), streams: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeWords{}
+			c := newTestClient(t, fake)
+
+			got, err := c.NormBatch(context.Background(), tt.phrases)
+			if err != nil {
+				t.Fatalf("norm batch: %v", err)
+			}
+			if fake.batches != tt.batches || fake.streams != tt.streams {
+				t.Fatalf("batches=%d streams=%d, want %d and %d", fake.batches, fake.streams, tt.batches, tt.streams)
+			}
+			if fake.language != "english" {
+				t.Fatalf("language = %q, want english", fake.language)
+			}
+			if len(got) != len(tt.phrases) {
+				t.Fatalf("got %d results for %d phrases", len(got), len(tt.phrases))
+			}
+			// результаты выровнены по фразам, ошибка фразы - в ее результате
+			if got[0].Words[0] != "t" || got[0].AnalyzerVersion != "v1" {
+				t.Fatalf("first result = %+v", got[0])
+			}
+			if !errors.Is(got[1].Err, core.ErrBadArguments) {
+				t.Fatalf("bad phrase err = %v, want ErrBadArguments", got[1].Err)
+			}
+			if last := got[len(got)-1]; len(last.Words) != 1 || last.Err != nil {
+				t.Fatalf("last result = %+v", last)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package xkcd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"yadro.com/course/update/core"
+)
+
+var fetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "update_xkcd_fetch_duration_seconds",
+	Help:    "Latency of xkcd API requests by operation and result.",
+	Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10},
+}, []string{"op", "result"})
+
+// observeFetch - result: ok, not_found (у xkcd нет некоторых номеров) или error
+func observeFetch(op string, start time.Time, err error) {
+	result := "ok"
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		result = "not_found"
+	case err != nil:
+		result = "error"
+	}
+	fetchDuration.WithLabelValues(op, result).Observe(time.Since(start).Seconds())
+}
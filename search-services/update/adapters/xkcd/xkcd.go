@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"yadro.com/course/pkg/tracing"
 	"yadro.com/course/update/core"
 )
 
@@ -26,7 +28,7 @@ func NewClient(url string, timeout time.Duration, log *slog.Logger) (*Client, er
 		url = "https://" + url
 	}
 	return &Client{
-		client: http.Client{Timeout: timeout},
+		client: http.Client{Timeout: timeout, Transport: tracing.Transport(nil)},
 		log:    log,
 		url:    strings.TrimRight(url, "/"),
 	}, nil
@@ -38,9 +40,30 @@ type res struct {
 	Title      string `json:"title"`
 	Alt        string `json:"alt"`
 	Transcript string `json:"transcript"`
+	Year       string `json:"year"`
+	Month      string `json:"month"`
+	Day        string `json:"day"`
+}
+
+// published - xkcd отдает дату строками без ведущих нулей, при ошибке - нулевое время
+func (x res) published() time.Time {
+	year, errY := strconv.Atoi(x.Year)
+	month, errM := strconv.Atoi(x.Month)
+	day, errD := strconv.Atoi(x.Day)
+	if errY != nil || errM != nil || errD != nil {
+		return time.Time{}
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
 }
 
 func (c Client) Get(ctx context.Context, id int) (core.XKCDInfo, error) {
+	start := time.Now()
+	info, err := c.get(ctx, id)
+	observeFetch("get", start, err)
+	return info, err
+}
+
+func (c Client) get(ctx context.Context, id int) (core.XKCDInfo, error) {
 	u := fmt.Sprintf("%s/%d/info.0.json", c.url, id)
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 
@@ -68,6 +91,7 @@ func (c Client) Get(ctx context.Context, id int) (core.XKCDInfo, error) {
 			Title:       x.Title,
 			Alt:         x.Alt,
 			Description: desc,
+			Published:   x.published(),
 		}, nil
 	case http.StatusNotFound:
 		return core.XKCDInfo{}, core.ErrNotFound
@@ -77,6 +101,13 @@ func (c Client) Get(ctx context.Context, id int) (core.XKCDInfo, error) {
 }
 
 func (c Client) LastID(ctx context.Context) (int, error) {
+	start := time.Now()
+	id, err := c.lastID(ctx)
+	observeFetch("last_id", start, err)
+	return id, err
+}
+
+func (c Client) lastID(ctx context.Context) (int, error) {
 	u := fmt.Sprintf("%s/info.0.json", c.url)
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 
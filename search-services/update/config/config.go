@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+
+	"yadro.com/course/pkg/tracing"
 )
 
 type Broker struct {
@@ -24,7 +26,17 @@ type Config struct {
 	XKCD         XKCD   `yaml:"xkcd"`
 	DBAddress    string `yaml:"db_address" env:"DB_ADDRESS" env-default:"localhost:82"`
 	WordsAddress string `yaml:"words_address" env:"WORDS_ADDRESS" env-default:"localhost:81"`
-	Broker       Broker `yaml:"broker"`
+	// WordsAnalyzer - цепочка words для индексации; search должен разбирать запросы совместимой
+	WordsAnalyzer string `yaml:"words_analyzer" env:"WORDS_ANALYZER" env-default:"default"`
+	// WordsLanguage - язык комиксов; search разбирает запросы с тем же языком
+	WordsLanguage string `yaml:"words_language" env:"WORDS_LANGUAGE" env-default:"english"`
+	Broker        Broker `yaml:"broker"`
+
+	// MetricsAddress - /metrics для Prometheus на отдельном порту, пусто - выключено
+	MetricsAddress string `yaml:"metrics_address" env:"METRICS_ADDRESS" env-default:":9090"`
+
+	// Tracing - экспорт OTLP трейсов, по умолчанию выключен
+	Tracing tracing.Config `yaml:"tracing"`
 }
 
 func MustLoad(configPath string) Config {
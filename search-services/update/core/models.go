@@ -1,5 +1,7 @@
 package core
 
+import "time"
+
 type ServiceStatus string
 
 const (
@@ -24,6 +26,23 @@ type Comics struct {
 	Title []string
 	Alt   []string
 	Words []string
+
+	// AnalyzerVersion - версия цепочки words, которой получены Title, Alt и Words;
+	// пустая - комикс не нормализован или сохранен до появления версий
+	AnalyzerVersion string
+	// Surfaces - термин -> его написание в комиксе до стемминга, первое по title, alt, words
+	Surfaces map[string]string
+
+	Published     time.Time // нулевое значение - дата неизвестна
+	HasTranscript bool
+}
+
+// NormResult - нормализация одной фразы пакета; Surfaces - написание Words до стемминга
+type NormResult struct {
+	Words           []string
+	Surfaces        []string
+	AnalyzerVersion string
+	Err             error
 }
 
 type XKCDInfo struct {
@@ -32,4 +51,5 @@ type XKCDInfo struct {
 	Title       string
 	Alt         string
 	Description string
+	Published   time.Time
 }
@@ -24,5 +24,6 @@ type XKCD interface {
 }
 
 type Words interface {
-	Norm(ctx context.Context, phrase string) ([]string, error)
+	// NormBatch - результаты в порядке phrases; ошибка одной фразы - в ее NormResult
+	NormBatch(ctx context.Context, phrases []string) ([]NormResult, error)
 }
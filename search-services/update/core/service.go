@@ -7,6 +7,20 @@ import (
 	"log/slog"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"yadro.com/course/pkg/tracing"
+)
+
+const (
+	// normBatchPhrases - фраз в одном NormBatch, как лимит пакета в words; у комикса их три
+	normBatchPhrases = 1000
+	normBatchComics  = normBatchPhrases / 3
+	// normBatchLinger - неполный пакет уходит не позже, чтобы комиксы попадали в базу по ходу загрузки
+	normBatchLinger = 500 * time.Millisecond
 )
 
 // Service
@@ -65,14 +79,21 @@ func (s *Service) Update(ctx context.Context) (err error) {
 	if workers > 64 {
 		workers = 64
 	}
-	s.log.Debug("starting update workers", "workers", workers, "latest", latest)
+	s.log.DebugContext(ctx, "starting update workers", "workers", workers, "latest", latest)
 
 	// Создаем буфферизированный канал, емкостью в 2 воркера - для отправки немного задач вперед, пока воркеры отдыхают
 	// 2 воркера - отличное значение, не слишком большое (иначе съест память) и не слишком маленькое (иначе будет блокироваться main)
 	type job struct{ id int }
 	jobs := make(chan job, workers*2)
+	// fetched - загруженные комиксы ждут нормализации пакетом на много комиксов сразу
+	fetched := make(chan XKCDInfo, normBatchComics)
 
-	var wg sync.WaitGroup
+	var wg, saver sync.WaitGroup
+	saver.Go(func() { s.saveBatches(ctx, fetched) })
+	defer func() {
+		close(fetched)
+		saver.Wait()
+	}()
 
 	worker := func() {
 		for {
@@ -84,53 +105,14 @@ func (s *Service) Update(ctx context.Context) (err error) {
 					return // канал закрыт - работа закончена
 				}
 
-				// Загружаем коммиксы с xkcd
-				info, err := s.xkcd.Get(ctx, j.id)
-				if err != nil {
-					// если ошибка - спокойно пропускаем, так как не все номера существуют (404),
-					// но добавляем в базу номер комикса и пустые значения
-					if errors.Is(err, ErrNotFound) {
-						_ = s.db.Add(ctx, Comics{
-							ID:    j.id,
-							URL:   "",
-							Title: []string{},
-							Alt:   []string{},
-							Words: []string{},
-						})
-						continue
-					}
-					s.log.Warn("xkcd get failed", "id", j.id, "err", err)
+				info, ok := s.fetch(ctx, j.id)
+				if !ok {
 					continue
 				}
-
-				// Нормализация
-				title, errTitle := s.words.Norm(ctx, info.Title)
-				if errTitle != nil {
-					s.log.Warn("normalize title failed, storing empty", "id", j.id, "err", errTitle)
-					title = []string{}
-				}
-
-				alt, errAlt := s.words.Norm(ctx, info.Alt)
-				if errAlt != nil {
-					s.log.Warn("normalize alt failed, storing empty", "id", j.id, "err", errAlt)
-					alt = []string{}
-				}
-
-				words, errDesc := s.words.Norm(ctx, info.Description)
-				if errDesc != nil {
-					s.log.Warn("normalize description failed, storing empty words", "id", j.id, "err", errDesc)
-					words = []string{}
-				}
-
-				if err := s.db.Add(ctx, Comics{
-					ID:    info.ID,
-					URL:   info.URL,
-					Title: title,
-					Alt:   alt,
-					Words: words,
-				}); err != nil {
-					s.log.Warn("db add failed", "id", j.id, "err", err)
-					continue
+				select {
+				case fetched <- info:
+				case <-ctx.Done():
+					return
 				}
 			}
 		}
@@ -159,6 +141,128 @@ func (s *Service) Update(ctx context.Context) (err error) {
 	return nil
 }
 
+// fetch - один комикс с xkcd; спан на каждый комикс. ok - комикс нужно нормализовать и сохранить,
+// отсутствующий номер сохраняется сразу пустым
+func (s *Service) fetch(ctx context.Context, id int) (XKCDInfo, bool) {
+	ctx, span := tracing.Tracer().Start(ctx, "update.comic", trace.WithAttributes(attribute.Int("comic.id", id)))
+	defer span.End()
+
+	// Загружаем коммиксы с xkcd
+	info, err := s.xkcd.Get(ctx, id)
+	if err != nil {
+		// если ошибка - спокойно пропускаем, так как не все номера существуют (404),
+		// но добавляем в базу номер комикса и пустые значения
+		if errors.Is(err, ErrNotFound) {
+			span.SetAttributes(attribute.Bool("comic.missing", true))
+			_ = s.db.Add(ctx, Comics{
+				ID:    id,
+				URL:   "",
+				Title: []string{},
+				Alt:   []string{},
+				Words: []string{},
+			})
+			return XKCDInfo{}, false
+		}
+		span.SetStatus(codes.Error, err.Error())
+		s.log.WarnContext(ctx, "xkcd get failed", "id", id, "err", err)
+		return XKCDInfo{}, false
+	}
+	return info, true
+}
+
+// saveBatches - копит загруженные комиксы в пакет до normBatchComics или normBatchLinger
+// и сохраняет его; закрытие fetched сохраняет остаток
+func (s *Service) saveBatches(ctx context.Context, fetched <-chan XKCDInfo) {
+	batch := make([]XKCDInfo, 0, normBatchComics)
+	tick := time.NewTicker(normBatchLinger)
+	defer tick.Stop()
+
+	for {
+		select {
+		case info, ok := <-fetched:
+			if !ok {
+				s.save(ctx, batch)
+				return
+			}
+			batch = append(batch, info)
+			if len(batch) < normBatchComics {
+				continue
+			}
+		case <-tick.C:
+		}
+		s.save(ctx, batch)
+		batch = batch[:0]
+	}
+}
+
+// save - нормализация пакета одним вызовом и запись комиксов в базу.
+// Если words не ответил на весь пакет, комиксы не сохраняются: следующий update загрузит их снова
+func (s *Service) save(ctx context.Context, batch []XKCDInfo) {
+	if len(batch) == 0 || ctx.Err() != nil {
+		return
+	}
+	ctx, span := tracing.Tracer().Start(ctx, "update.batch", trace.WithAttributes(attribute.Int("batch.comics", len(batch))))
+	defer span.End()
+
+	comics, err := s.normalize(ctx, batch)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		s.log.WarnContext(ctx, "normalize failed, batch skipped until next update",
+			"comics", len(batch), "from", batch[0].ID, "to", batch[len(batch)-1].ID, "err", err)
+		return
+	}
+	for i, c := range comics {
+		info := batch[i]
+		c.ID, c.URL = info.ID, info.URL
+		c.Published = info.Published
+		c.HasTranscript = info.Description != ""
+		if err := s.db.Add(ctx, c); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			s.log.WarnContext(ctx, "db add failed", "id", info.ID, "err", err)
+		}
+	}
+}
+
+// normalize - title, alt и description всех комиксов пакета одним вызовом NormBatch.
+// Ошибка всего вызова возвращается; при ошибке одной фразы поле сохраняется пустым, как и раньше.
+// AnalyzerVersion - версия цепочки words, пустая, если ни одно поле комикса не нормализовано
+func (s *Service) normalize(ctx context.Context, batch []XKCDInfo) ([]Comics, error) {
+	fields := []string{"title", "alt", "description"}
+	phrases := make([]string, 0, len(batch)*len(fields))
+	out := make([]Comics, len(batch))
+	for i, info := range batch {
+		phrases = append(phrases, info.Title, info.Alt, info.Description)
+		out[i] = Comics{Title: []string{}, Alt: []string{}, Words: []string{}, Surfaces: map[string]string{}}
+	}
+
+	results, err := s.words.NormBatch(ctx, phrases)
+	if err != nil {
+		return nil, fmt.Errorf("norm batch: %w", err)
+	}
+	for i, r := range results {
+		info, c := batch[i/len(fields)], &out[i/len(fields)]
+		if r.Err != nil {
+			s.log.WarnContext(ctx, "normalize "+fields[i%len(fields)]+" failed, storing empty", "id", info.ID, "err", r.Err)
+			continue
+		}
+		switch i % len(fields) {
+		case 0:
+			c.Title = r.Words
+		case 1:
+			c.Alt = r.Words
+		case 2:
+			c.Words = r.Words
+		}
+		c.AnalyzerVersion = r.AnalyzerVersion
+		for j, w := range r.Words {
+			if _, ok := c.Surfaces[w]; !ok && j < len(r.Surfaces) {
+				c.Surfaces[w] = r.Surfaces[j]
+			}
+		}
+	}
+	return out, nil
+}
+
 func (s *Service) Stats(ctx context.Context) (ServiceStats, error) {
 	dbst, err := s.db.Stats(ctx)
 	if err != nil {
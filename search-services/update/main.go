@@ -8,10 +8,15 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"time"
 	"yadro.com/course/update/adapters/broker"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+	"yadro.com/course/pkg/health"
+	"yadro.com/course/pkg/metrics"
+	"yadro.com/course/pkg/requestid"
+	"yadro.com/course/pkg/tracing"
 	updatepb "yadro.com/course/proto/update"
 	"yadro.com/course/update/adapters/db"
 	updategrpc "yadro.com/course/update/adapters/grpc"
@@ -42,6 +47,19 @@ func run(cfg config.Config, log *slog.Logger) error {
 	log.Info("starting server")
 	log.Debug("debug messages are enabled")
 
+	// tracing: до создания gRPC клиентов и серверов, они берут провайдер при создании
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "update")
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("failed to flush traces", "error", err)
+		}
+	}()
+
 	// database adapter
 	storage, err := db.New(log, cfg.DBAddress)
 	if err != nil {
@@ -58,7 +76,7 @@ func run(cfg config.Config, log *slog.Logger) error {
 	}
 
 	// words adapter
-	words, err := words.NewClient(cfg.WordsAddress, log)
+	words, err := words.NewClient(cfg.WordsAddress, cfg.WordsAnalyzer, cfg.WordsLanguage, log)
 	if err != nil {
 		return fmt.Errorf("failed create Words client: %v", err)
 	}
@@ -82,14 +100,28 @@ func run(cfg config.Config, log *slog.Logger) error {
 	}
 	defer publisher.Close()
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestid.UnaryServerInterceptor(), metrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(requestid.StreamServerInterceptor(), metrics.StreamServerInterceptor()),
+		tracing.ServerOption(),
+	)
 	updatepb.RegisterUpdateServer(s, updategrpc.NewServer(updater, publisher))
+	// grpc.health.v1: готовность по Postgres и NATS
+	checker := health.Register(s, log, updatepb.Update_ServiceDesc.ServiceName, map[string]health.Check{
+		"postgres": storage.Ping,
+		"nats":     publisher.Ping,
+	})
 	reflection.Register(s)
 
 	// context for Ctrl-C
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
+	// metrics: пул БД и /metrics на отдельном порту
+	metrics.RegisterDB(storage.Pool(), "update")
+	metrics.Serve(ctx, log, cfg.MetricsAddress)
+	checker.Start(ctx, health.Interval)
+
 	go func() {
 		<-ctx.Done()
 		log.Debug("shutting down server")
@@ -115,5 +147,6 @@ func mustMakeLogger(logLevel string) *slog.Logger {
 		panic("unknown log level: " + logLevel)
 	}
 	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
-	return slog.New(handler)
+	// request_id из контекста вызова добавляется к записям *Context
+	return slog.New(requestid.NewLogHandler(handler))
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
@@ -9,26 +10,70 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"io"
 	"log"
+	"log/slog"
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 	"unicode/utf8"
 	"yadro.com/course/words/words"
 
 	"google.golang.org/protobuf/types/known/emptypb"
+	"yadro.com/course/pkg/admintoken"
+	"yadro.com/course/pkg/health"
+	"yadro.com/course/pkg/metrics"
+	"yadro.com/course/pkg/requestid"
+	"yadro.com/course/pkg/tracing"
 	wordspb "yadro.com/course/proto/words"
 )
 
 const (
 	maxPhraseLen    = 4096
+	maxBatchLen     = 1 << 20 // суммарный размер фраз NormBatch
+	maxBatchItems   = 1000
+	maxStreamLen    = 16 << 20 // суммарный размер фраз одного NormStream
 	maxShutdownTime = 5 * time.Second
+
+	// словарь корпуса приходит одним сообщением: у xkcd десятки тысяч терминов
+	maxDictionaryTerms = 1 << 20
+	maxDictionaryMsg   = 64 << 20
 )
 
 type Config struct {
 	Port string `yaml:"port" env:"WORDS_ADDRESS" env-default:":80"`
+
+	// AdminToken - токен служебных вызовов (Analyze, Vocabulary, SetDictionary) от api и search;
+	// пустой - вызовы закрыты
+	AdminToken string `yaml:"admin_token" env:"WORDS_ADMIN_TOKEN"`
+
+	// MetricsAddress - /metrics для Prometheus на отдельном порту, пусто - выключено
+	MetricsAddress string `yaml:"metrics_address" env:"METRICS_ADDRESS" env-default:":9090"`
+
+	// Tracing - экспорт OTLP трейсов, по умолчанию выключен
+	Tracing tracing.Config `yaml:"tracing"`
+
+	// SynonymsFile - словарь синонимов для Expand, пустой - без синонимов.
+	// Перечитывается по SIGHUP и, если SynonymsReload > 0, при изменении файла
+	SynonymsFile   string        `yaml:"synonyms_file" env:"WORDS_SYNONYMS_FILE"`
+	SynonymsReload time.Duration `yaml:"synonyms_reload" env:"WORDS_SYNONYMS_RELOAD" env-default:"0s"`
+	SynonymWeight  float64       `yaml:"synonym_weight" env:"WORDS_SYNONYM_WEIGHT" env-default:"0.5"`
+
+	// StopWordsAdd и StopWordsRemove - поправки к стоп-словам snowball,
+	// ProtectedTerms - термины без стемминга и проверки на стоп-слово; в env через запятую
+	StopWordsAdd    []string `yaml:"stop_words_add" env:"WORDS_STOP_WORDS_ADD"`
+	StopWordsRemove []string `yaml:"stop_words_remove" env:"WORDS_STOP_WORDS_REMOVE"`
+	ProtectedTerms  []string `yaml:"protected_terms" env:"WORDS_PROTECTED_TERMS"`
+
+	// Analyzers - цепочки фильтров по именам, default есть всегда. Фильтры:
+	// lowercase, asciifold, stopwords, stem, lemma, edge_ngram, split
+	Analyzers  map[string][]string `yaml:"analyzers"`
+	LemmasFile string              `yaml:"lemmas_file" env:"WORDS_LEMMAS_FILE"`
+	NgramMin   int                 `yaml:"edge_ngram_min" env:"WORDS_EDGE_NGRAM_MIN" env-default:"2"`
+	NgramMax   int                 `yaml:"edge_ngram_max" env:"WORDS_EDGE_NGRAM_MAX" env-default:"10"`
 }
 
 func loadConfig() (Config, error) {
@@ -59,39 +104,387 @@ func (s *server) Ping(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, erro
 	return &emptypb.Empty{}, nil
 }
 
-func (s *server) Norm(_ context.Context, in *wordspb.WordsRequest) (*wordspb.WordsReply, error) {
+func (s *server) Norm(ctx context.Context, in *wordspb.WordsRequest) (*wordspb.WordsReply, error) {
 	phrase := in.GetPhrase()
-	log.Printf("Norm start: len_runes=%d", utf8.RuneCountInString(phrase))
 
 	// длина входной строки не больше 4kib
 	if len(phrase) > maxPhraseLen {
-		log.Printf("Norm too_large: len_runes=%d", utf8.RuneCountInString(phrase))
+		log.Printf("Norm too_large: len_runes=%d request_id=%s", utf8.RuneCountInString(phrase), requestid.FromContext(ctx))
+		return nil, status.Error(codes.ResourceExhausted, "phrase too large (>4KiB)")
+	}
+
+	opt := requestOptions(in)
+	out, err := s.service.Norm(phrase, opt)
+	if err != nil {
+		log.Printf("Norm failed: %v request_id=%s", err, requestid.FromContext(ctx))
+		return nil, normError(err, opt)
+	}
+	return &wordspb.WordsReply{
+		Words:           out.Words,
+		Language:        out.Language,
+		Analyzer:        out.Analyzer,
+		AnalyzerVersion: out.AnalyzerVersion,
+	}, nil
+}
+
+func (s *server) Expand(ctx context.Context, in *wordspb.WordsRequest) (*wordspb.ExpandReply, error) {
+	phrase := in.GetPhrase()
+
+	if len(phrase) > maxPhraseLen {
+		log.Printf("Expand too_large: len_runes=%d request_id=%s", utf8.RuneCountInString(phrase), requestid.FromContext(ctx))
+		return nil, status.Error(codes.ResourceExhausted, "phrase too large (>4KiB)")
+	}
+
+	opt := requestOptions(in)
+	norm, weighted, err := s.service.Expand(phrase, opt)
+	if err != nil {
+		log.Printf("Expand failed: %v request_id=%s", err, requestid.FromContext(ctx))
+		return nil, normError(err, opt)
+	}
+
+	reply := &wordspb.ExpandReply{
+		Words:           make([]*wordspb.WeightedWord, 0, len(weighted)),
+		Language:        norm.Language,
+		Analyzer:        norm.Analyzer,
+		AnalyzerVersion: norm.AnalyzerVersion,
+		SynonymsVersion: norm.SynonymsVersion,
+	}
+	for _, w := range weighted {
+		reply.Words = append(reply.Words, &wordspb.WeightedWord{Word: w.Word, Weight: w.Weight})
+	}
+	return reply, nil
+}
+
+// NormBatch - Norm для многих фраз за один вызов. maxPhraseLen действует на каждую фразу:
+// слишком длинная получает ошибку в своем результате, остальные нормализуются.
+// Превышение maxBatchLen или maxBatchItems отклоняет весь пакет
+func (s *server) NormBatch(ctx context.Context, in *wordspb.NormBatchRequest) (*wordspb.NormBatchReply, error) {
+	items := in.GetItems()
+	total := 0
+	for _, item := range items {
+		total += len(item.GetPhrase())
+	}
+	if len(items) > maxBatchItems || total > maxBatchLen {
+		log.Printf("NormBatch too_large: items=%d bytes=%d request_id=%s", len(items), total, requestid.FromContext(ctx))
+	}
+	if len(items) > maxBatchItems {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many phrases (>%d)", maxBatchItems)
+	}
+	if total > maxBatchLen {
+		return nil, status.Error(codes.ResourceExhausted, "batch too large (>1MiB)")
+	}
+
+	opt := words.Options{Language: in.GetLanguage(), Analyzer: in.GetAnalyzer()}
+	reply := &wordspb.NormBatchReply{Results: make([]*wordspb.NormResult, 0, len(items))}
+	for _, item := range items {
+		reply.Results = append(reply.Results, s.normItem(item, opt, in.GetExpand()))
+	}
+	return reply, nil
+}
+
+// NormStream - результат на каждую фразу в порядке поступления. maxPhraseLen действует на фразу,
+// как в NormBatch; превышение maxStreamLen суммарно за поток завершает его с ResourceExhausted
+func (s *server) NormStream(stream grpc.BidiStreamingServer[wordspb.NormItem, wordspb.NormResult]) error {
+	n, total := 0, 0
+	for {
+		item, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		total += len(item.GetPhrase())
+		if total > maxStreamLen {
+			log.Printf("NormStream too_large: items=%d bytes=%d request_id=%s", n, total, requestid.FromContext(stream.Context()))
+			return status.Error(codes.ResourceExhausted, "stream too large (>16MiB)")
+		}
+		if err := stream.Send(s.normItem(item, words.Options{}, false)); err != nil {
+			return err
+		}
+		n++
+	}
+}
+
+// normItem - язык и цепочка фразы переопределяют заданные для пакета.
+// expand - слова с весами и синонимы, как в Expand
+func (s *server) normItem(item *wordspb.NormItem, opt words.Options, expand bool) *wordspb.NormResult {
+	res := &wordspb.NormResult{Id: item.GetId()}
+	if item.Language != nil {
+		opt.Language = item.GetLanguage()
+	}
+	if item.Analyzer != nil {
+		opt.Analyzer = item.GetAnalyzer()
+	}
+	if len(item.GetPhrase()) > maxPhraseLen {
+		res.Code = uint32(codes.ResourceExhausted)
+		res.Error = "phrase too large (>4KiB)"
+		return res
+	}
+
+	var out words.Normalized
+	var err error
+	if expand {
+		var weighted []words.WeightedWord
+		out, weighted, err = s.service.Expand(item.GetPhrase(), opt)
+		for _, w := range weighted {
+			res.Words = append(res.Words, w.Word)
+			res.Weights = append(res.Weights, w.Weight)
+		}
+	} else {
+		out, err = s.service.Norm(item.GetPhrase(), opt)
+		res.Words = out.Words
+		res.Surfaces = out.Surfaces
+	}
+	if err != nil {
+		st := status.Convert(normError(err, opt))
+		res.Code = uint32(st.Code())
+		res.Error = st.Message()
+		return res
+	}
+	res.Language = out.Language
+	res.Analyzer = out.Analyzer
+	res.AnalyzerVersion = out.AnalyzerVersion
+	res.SynonymsVersion = out.SynonymsVersion
+	return res
+}
+
+func (s *server) Analyze(ctx context.Context, in *wordspb.WordsRequest) (*wordspb.AnalyzeReply, error) {
+	phrase := in.GetPhrase()
+	if len(phrase) > maxPhraseLen {
+		return nil, status.Error(codes.ResourceExhausted, "phrase too large (>4KiB)")
+	}
+
+	opt := requestOptions(in)
+	a, err := s.service.Analyze(phrase, opt)
+	if err != nil {
+		log.Printf("Analyze failed: %v request_id=%s", err, requestid.FromContext(ctx))
+		return nil, normError(err, opt)
+	}
+
+	reply := &wordspb.AnalyzeReply{
+		Language:        a.Language,
+		Stages:          make([]*wordspb.AnalyzeStage, 0, len(a.Stages)),
+		Words:           a.Words,
+		Analyzer:        a.Analyzer,
+		AnalyzerVersion: a.AnalyzerVersion,
+	}
+	for _, st := range a.Stages {
+		stage := &wordspb.AnalyzeStage{Name: st.Name, Tokens: make([]*wordspb.AnalyzeToken, 0, len(st.Tokens))}
+		for _, t := range st.Tokens {
+			stage.Tokens = append(stage.Tokens, &wordspb.AnalyzeToken{
+				Text:    t.Text,
+				Start:   uint32(t.Start),
+				End:     uint32(t.End),
+				Dropped: t.Dropped,
+				Note:    t.Note,
+			})
+		}
+		reply.Stages = append(reply.Stages, stage)
+	}
+	return reply, nil
+}
+
+func (s *server) Vocabulary(_ context.Context, _ *emptypb.Empty) (*wordspb.VocabularyReply, error) {
+	added, removed, protected := s.service.Vocabulary().Lists()
+	reply := &wordspb.VocabularyReply{
+		StopWordsAdded:   added,
+		StopWordsRemoved: removed,
+		ProtectedTerms:   protected,
+		Languages:        words.Languages(),
+
+		DictionaryVersion: s.service.DictionaryVersion(),
+		SynonymsVersion:   s.service.SynonymsVersion(),
+	}
+	for _, an := range s.service.Analyzers() {
+		reply.Analyzers = append(reply.Analyzers, &wordspb.AnalyzerInfo{Name: an.Name, Filters: an.Filters, Version: an.Version})
+	}
+	return reply, nil
+}
+
+// SetDictionary - частотный словарь для Correct, присылает search после пересборки индекса
+func (s *server) SetDictionary(ctx context.Context, in *wordspb.DictionaryRequest) (*wordspb.DictionaryReply, error) {
+	terms := in.GetTerms()
+	if len(terms) > maxDictionaryTerms {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many terms (>%d)", maxDictionaryTerms)
+	}
+	counts := make(map[string]int, len(terms))
+	surfaces := make(map[string]string, len(terms))
+	for _, t := range terms {
+		counts[t.GetTerm()] += int(t.GetCount())
+		if t.GetSurface() != "" {
+			surfaces[t.GetTerm()] = t.GetSurface()
+		}
+	}
+
+	n, err := s.service.SetDictionary(counts, surfaces, in.GetAnalyzer(), in.GetVersion())
+	if err != nil {
+		return nil, normError(err, words.Options{Analyzer: in.GetAnalyzer()})
+	}
+	log.Printf("SetDictionary done: analyzer=%q terms=%d version=%s request_id=%s", in.GetAnalyzer(), n, in.GetVersion(), requestid.FromContext(ctx))
+	return &wordspb.DictionaryReply{Terms: uint32(n)}, nil
+}
+
+func (s *server) Correct(_ context.Context, in *wordspb.WordsRequest) (*wordspb.CorrectReply, error) {
+	phrase := in.GetPhrase()
+	if len(phrase) > maxPhraseLen {
 		return nil, status.Error(codes.ResourceExhausted, "phrase too large (>4KiB)")
 	}
 
-	out, err := s.service.Norm(phrase)
+	opt := requestOptions(in)
+	c, err := s.service.Correct(phrase, opt)
 	if err != nil {
-		log.Printf("Normalize failde: %v", err)
-		return nil, status.Error(codes.Internal, err.Error())
+		if errors.Is(err, words.ErrNoDictionary) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, normError(err, opt)
+	}
+
+	reply := &wordspb.CorrectReply{
+		Tokens:          make([]*wordspb.TokenCorrection, 0, len(c.Tokens)),
+		Phrase:          c.Phrase,
+		Language:        c.Language,
+		Analyzer:        c.Analyzer,
+		AnalyzerVersion: c.AnalyzerVersion,
+	}
+	for _, t := range c.Tokens {
+		tc := &wordspb.TokenCorrection{Token: t.Token, Term: t.Term, Known: t.Known}
+		for _, sg := range t.Suggestions {
+			tc.Suggestions = append(tc.Suggestions, &wordspb.Suggestion{
+				Term:     sg.Term,
+				Distance: uint32(sg.Distance),
+				Count:    uint32(sg.Count),
+			})
+		}
+		reply.Tokens = append(reply.Tokens, tc)
+	}
+	return reply, nil
+}
+
+func requestOptions(in *wordspb.WordsRequest) words.Options {
+	return words.Options{Language: in.GetLanguage(), Analyzer: in.GetAnalyzer()}
+}
+
+func normError(err error, opt words.Options) error {
+	switch {
+	case errors.Is(err, words.ErrUnknownLanguage):
+		return status.Errorf(codes.InvalidArgument, "unknown language %q, supported: %s",
+			opt.Language, strings.Join(words.Languages(), ", "))
+	case errors.Is(err, words.ErrUnknownAnalyzer):
+		return status.Errorf(codes.InvalidArgument, "unknown analyzer %q", opt.Analyzer)
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// watchSynonyms - перезагрузка словаря по SIGHUP и по изменению файла.
+// Ошибка разбора не роняет сервис: остается прежний словарь
+func watchSynonyms(ctx context.Context, synonyms *words.Synonyms, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	reload := func(reason string) {
+		n, err := synonyms.Reload()
+		if err != nil {
+			log.Printf("synonyms reload (%s) failed, keeping previous: %v", reason, err)
+			return
+		}
+		log.Printf("synonyms reloaded (%s): phrases=%d", reason, n)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload("sighup")
+		case <-tick:
+			if synonyms.Changed() {
+				reload("file changed")
+			}
+		}
 	}
-	return &wordspb.WordsReply{Words: out}, nil
 }
 
 func run(cfg Config) error {
+	// tracing: до создания gRPC сервера, он берет провайдер при создании
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "words")
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), maxShutdownTime)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("failed to flush traces: %v", err)
+		}
+	}()
+
+	vocab := words.NewVocabulary(cfg.StopWordsAdd, cfg.StopWordsRemove, cfg.ProtectedTerms)
+	lemmas, err := words.LoadLemmas(cfg.LemmasFile)
+	if err != nil {
+		return fmt.Errorf("failed to load lemmas: %w", err)
+	}
+	analyzers, err := words.NewAnalyzers(vocab, words.AnalyzerConfig{
+		Chains:   cfg.Analyzers,
+		Lemmas:   lemmas,
+		NgramMin: cfg.NgramMin,
+		NgramMax: cfg.NgramMax,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build analyzers: %w", err)
+	}
+	for _, an := range analyzers.List() {
+		log.Printf("analyzer %s: %s version=%s", an.Name, strings.Join(an.Filters, ","), an.Version)
+	}
+	synonyms, err := words.LoadSynonyms(cfg.SynonymsFile, vocab)
+	if err != nil {
+		return fmt.Errorf("failed to load synonyms: %w", err)
+	}
+
 	listener, err := net.Listen("tcp", cfg.Port)
 	if err != nil {
 		return fmt.Errorf("failed to listen port %s: %w", cfg.Port, err)
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.MaxRecvMsgSize(maxDictionaryMsg),
+		grpc.ChainUnaryInterceptor(
+			requestid.UnaryServerInterceptor(),
+			metrics.UnaryServerInterceptor(),
+			// отладка цепочки, настройки развертывания и замена словаря Correct - только для api и search
+			admintoken.UnaryServerInterceptor(cfg.AdminToken,
+				wordspb.Words_Analyze_FullMethodName,
+				wordspb.Words_Vocabulary_FullMethodName,
+				wordspb.Words_SetDictionary_FullMethodName,
+			),
+		),
+		grpc.ChainStreamInterceptor(requestid.StreamServerInterceptor(), metrics.StreamServerInterceptor()),
+		tracing.ServerOption(),
+	)
 	wordspb.RegisterWordsServer(grpcServer, &server{
-		service: words.NewService(),
+		service: words.NewService(analyzers, synonyms, cfg.SynonymWeight),
 	})
+	// grpc.health.v1: у words нет внешних зависимостей, SERVING сразу после старта
+	checker := health.Register(grpcServer, slog.Default(), wordspb.Words_ServiceDesc.ServiceName, nil)
 	reflection.Register(grpcServer)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	go watchSynonyms(ctx, synonyms, cfg.SynonymsReload)
+
+	// slog.Default пишет через стандартный log, как и остальной words
+	metrics.Serve(ctx, slog.Default(), cfg.MetricsAddress)
+	checker.Start(ctx, health.Interval)
+
 	go func() {
 		log.Printf("words gRPC starting %s", cfg.Port)
 		if err := grpcServer.Serve(listener); err != nil {
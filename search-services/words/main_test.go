@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	wordspb "yadro.com/course/proto/words"
+	"yadro.com/course/words/words"
+)
+
+func newTestClient(t *testing.T) wordspb.WordsClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	wordspb.RegisterWordsServer(srv, &server{service: words.NewService(nil, nil, 0)})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return wordspb.NewWordsClient(conn)
+}
+
+func TestNormBatch(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	english, klingon := "english", "klingon"
+
+	resp, err := client.NormBatch(ctx, &wordspb.NormBatchRequest{
+		Language: &english,
+		Items: []*wordspb.NormItem{
+			{Id: "title", Phrase: "Running dogs"},
+			{Id: "big", Phrase: strings.Repeat("a", maxPhraseLen+1)},
+			{Id: "ru", Phrase: "бегущие собаки", Language: new(string)},
+			{Id: "bad", Phrase: "qapla", Language: &klingon},
+		},
+	})
+	if err != nil {
+		t.Fatalf("norm batch: %v", err)
+	}
+
+	want := []struct {
+		id   string
+		code codes.Code
+		lang string
+	}{
+		{"title", codes.OK, "english"},
+		{"big", codes.ResourceExhausted, ""},
+		{"ru", codes.OK, "russian"}, // пустой язык фразы - автоопределение
+		{"bad", codes.InvalidArgument, ""},
+	}
+	if len(resp.GetResults()) != len(want) {
+		t.Fatalf("got %d results, want %d", len(resp.GetResults()), len(want))
+	}
+	for i, r := range resp.GetResults() {
+		if r.GetId() != want[i].id || codes.Code(r.GetCode()) != want[i].code || r.GetLanguage() != want[i].lang {
+			t.Fatalf("result %d = %v, want %+v", i, r, want[i])
+		}
+	}
+	if got := resp.GetResults()[0].GetWords(); len(got) != 2 || got[0] != "run" {
+		t.Fatalf("title words = %q", got)
+	}
+
+	// expand - слова фразы с весом 1, веса выровнены по words
+	resp, err = client.NormBatch(ctx, &wordspb.NormBatchRequest{
+		Language: &english,
+		Expand:   true,
+		Items:    []*wordspb.NormItem{{Id: "q", Phrase: "Running dogs"}},
+	})
+	if err != nil {
+		t.Fatalf("expand batch: %v", err)
+	}
+	if r := resp.GetResults()[0]; len(r.GetWeights()) != len(r.GetWords()) || r.GetWeights()[0] != 1 {
+		t.Fatalf("expand result = %v, want weight per word", r)
+	}
+
+	items := make([]*wordspb.NormItem, maxBatchLen/maxPhraseLen+1)
+	for i := range items {
+		items[i] = &wordspb.NormItem{Phrase: strings.Repeat("a", maxPhraseLen)}
+	}
+	_, err = client.NormBatch(ctx, &wordspb.NormBatchRequest{Items: items})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("oversized batch error = %v, want ResourceExhausted", err)
+	}
+
+	_, err = client.NormBatch(ctx, &wordspb.NormBatchRequest{Items: make([]*wordspb.NormItem, maxBatchItems+1)})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("too many items error = %v, want ResourceExhausted", err)
+	}
+}
+
+func TestNormStream(t *testing.T) {
+	client := newTestClient(t)
+	stream, err := client.NormStream(context.Background())
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+
+	phrases := []string{"cats", strings.Repeat("b", maxPhraseLen+1), "кошки"}
+	for i, p := range phrases {
+		if err := stream.Send(&wordspb.NormItem{Id: string(rune('a' + i)), Phrase: p}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("close send: %v", err)
+	}
+
+	var got []*wordspb.NormResult
+	for {
+		r, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		got = append(got, r)
+	}
+	if len(got) != 3 || got[0].GetId() != "a" || got[2].GetId() != "c" {
+		t.Fatalf("results = %v", got)
+	}
+	if codes.Code(got[1].GetCode()) != codes.ResourceExhausted || got[2].GetLanguage() != "russian" {
+		t.Fatalf("results = %v", got)
+	}
+}
+
+func TestNormStream_TotalLimit(t *testing.T) {
+	client := newTestClient(t)
+	stream, err := client.NormStream(context.Background())
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+
+	// отправка в фоне: сервер отвечает на каждую фразу, и без чтения ответов поток встанет
+	go func() {
+		phrase := strings.Repeat("a", maxPhraseLen)
+		for range maxStreamLen/maxPhraseLen + 1 {
+			if stream.Send(&wordspb.NormItem{Phrase: phrase}) != nil {
+				return
+			}
+		}
+		_ = stream.CloseSend()
+	}()
+
+	n := 0
+	for {
+		_, err := stream.Recv()
+		if err != nil {
+			if status.Code(err) != codes.ResourceExhausted {
+				t.Fatalf("stream error = %v, want ResourceExhausted", err)
+			}
+			break
+		}
+		n++
+	}
+	if n != maxStreamLen/maxPhraseLen {
+		t.Fatalf("got %d results before the limit, want %d", n, maxStreamLen/maxPhraseLen)
+	}
+}
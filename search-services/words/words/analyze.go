@@ -0,0 +1,86 @@
+package words
+
+// Стадии Analyze вокруг фильтров цепочки: cleanup и tokenize до них, dedup после
+const (
+	StageCleanup  = "cleanup"
+	StageTokenize = "tokenize"
+	StageDedup    = "dedup"
+)
+
+// Причины, по которым токен не прошел стадию или прошел ее без изменений
+const (
+	DropStopWord  = "stopword"
+	DropDuplicate = "duplicate"
+	KeepProtected = "protected"
+	KeepDigits    = "digits"
+)
+
+// AnalyzedToken - токен на выходе стадии. Start и End - позиция в исходной фразе
+// в символах, [Start, End). Dropped - почему токен отброшен на этой стадии;
+// Note - почему он прошел стадию как есть (защищенный термин, число)
+// или каким фильтром порожден (ngram, compound)
+type AnalyzedToken struct {
+	Text       string
+	Start, End int
+	Dropped    string
+	Note       string
+}
+
+type AnalyzeStage struct {
+	Name   string
+	Tokens []AnalyzedToken
+}
+
+// Analysis - разбор Norm по стадиям; Words совпадает с результатом Norm
+type Analysis struct {
+	Normalized
+	Stages []AnalyzeStage
+}
+
+// Analyze - те же шаги, что и Norm, но с промежуточными результатами.
+// Стадия содержит все токены, пришедшие на вход, отброшенные помечены Dropped
+// и на следующую стадию не попадают
+func (s *service) Analyze(phrase string, opt Options) (Analysis, error) {
+	req, err := s.prepare(phrase, opt)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	// cleanup - все, что не вошло в токены, заменяется пробелами
+	text := []rune(phrase)
+	cleaned := make([]rune, len(text))
+	for i := range cleaned {
+		cleaned[i] = ' '
+	}
+	for _, t := range req.tokens {
+		copy(cleaned[t.start:t.end], text[t.start:t.end])
+	}
+
+	a := Analysis{Stages: []AnalyzeStage{
+		{Name: StageCleanup, Tokens: []AnalyzedToken{{Text: string(cleaned), Start: 0, End: len(cleaned)}}},
+		analyzeStage(StageTokenize, req.tokens),
+	}}
+	out := req.analyzer.run(req.tokens, req.language, func(name string, tokens []token) {
+		a.Stages = append(a.Stages, analyzeStage(name, tokens))
+	})
+	a.Normalized = req.normalized(out)
+	return a, nil
+}
+
+func analyzeStage(name string, tokens []token) AnalyzeStage {
+	st := AnalyzeStage{Name: name, Tokens: make([]AnalyzedToken, 0, len(tokens))}
+	for _, t := range tokens {
+		note := t.note
+		if note == "" && name != StageDedup {
+			note = t.keep
+		}
+		st.Tokens = append(st.Tokens, AnalyzedToken{
+			Text:    t.text,
+			Start:   t.start,
+			End:     t.end,
+			Dropped: t.dropped,
+			Note:    note,
+		})
+	}
+	return st
+}
@@ -0,0 +1,75 @@
+package words
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	s := NewService(testAnalyzers(t, NewVocabulary(nil, nil, []string{"c++"}), nil), nil, 0)
+
+	a, err := s.Analyze("The Dogs, the dog & C++ 42", Options{})
+	if err != nil {
+		t.Fatalf("analyze: %v", err)
+	}
+	if a.Language != "english" {
+		t.Fatalf("language = %s", a.Language)
+	}
+
+	names := make([]string, 0, len(a.Stages))
+	for _, st := range a.Stages {
+		names = append(names, st.Name)
+	}
+	if want := []string{StageCleanup, StageTokenize, FilterLowercase, FilterStopWords, FilterStem, StageDedup}; !slices.Equal(names, want) {
+		t.Fatalf("stages = %v, want %v", names, want)
+	}
+
+	if got := a.Stages[0].Tokens[0].Text; got != "The Dogs  the dog   C++ 42" {
+		t.Fatalf("cleanup = %q", got)
+	}
+
+	type tok struct {
+		text       string
+		start, end int
+		dropped    string
+		note       string
+	}
+	stageTokens := func(i int) []tok {
+		var out []tok
+		for _, t := range a.Stages[i].Tokens {
+			out = append(out, tok{t.Text, t.Start, t.End, t.Dropped, t.Note})
+		}
+		return out
+	}
+
+	wantStop := []tok{
+		{"the", 0, 3, DropStopWord, ""},
+		{"dogs", 4, 8, "", ""},
+		{"the", 10, 13, DropStopWord, ""},
+		{"dog", 14, 17, "", ""},
+		{"c++", 20, 23, "", KeepProtected},
+		{"42", 24, 26, "", KeepDigits},
+	}
+	if got := stageTokens(3); !slices.Equal(got, wantStop) {
+		t.Fatalf("stopwords = %v, want %v", got, wantStop)
+	}
+
+	wantDedup := []tok{
+		{"dog", 4, 8, "", ""},
+		{"dog", 14, 17, DropDuplicate, ""},
+		{"c++", 20, 23, "", ""},
+		{"42", 24, 26, "", ""},
+	}
+	if got := stageTokens(5); !slices.Equal(got, wantDedup) {
+		t.Fatalf("dedup = %v, want %v", got, wantDedup)
+	}
+
+	// итог Analyze совпадает с Norm
+	for _, phrase := range []string{"The Dogs, the dog & C++ 42", "Кошки и собаки", "", "İstanbul ÅNGSTRÖM"} {
+		a, _ := s.Analyze(phrase, Options{})
+		norm, _ := s.Norm(phrase, Options{})
+		if !slices.Equal(a.Words, norm.Words) {
+			t.Fatalf("Analyze(%q).Words = %q, Norm = %q", phrase, a.Words, norm.Words)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package words
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrUnknownAnalyzer - в конфиге нет цепочки с таким именем
+var ErrUnknownAnalyzer = errors.New("unknown analyzer")
+
+// DefaultAnalyzer - цепочка по умолчанию, совпадает с прежним Norm
+const DefaultAnalyzer = "default"
+
+// Фильтры цепочек; порядок в цепочке важен: split нужен исходный регистр,
+// stopwords и lemma сравнивают слова в нижнем регистре
+const (
+	FilterLowercase = "lowercase"
+	FilterASCIIFold = "asciifold"
+	FilterStopWords = "stopwords"
+	FilterStem      = "stem"
+	FilterLemma     = "lemma"
+	FilterEdgeNgram = "edge_ngram"
+	FilterSplit     = "split"
+)
+
+// analyzerRevision - менять при изменении поведения фильтров, чтобы сменилась версия всех цепочек
+const analyzerRevision = 1
+
+var defaultChain = []string{FilterLowercase, FilterStopWords, FilterStem}
+
+// token - токен в цепочке. Start и End - позиция в исходной фразе в символах.
+// keep - защищенный термин или число: фильтры, кроме dedup, его не меняют.
+// note - чем фильтр породил токен (ngram, compound), dropped - почему отбросил
+type token struct {
+	text       string
+	start, end int
+	joined     bool // через дефис или апостроф от предыдущего токена
+	keep       string
+	note       string
+	dropped    string
+}
+
+// Пометки токенов, порожденных фильтрами
+const (
+	NoteNgram    = "ngram"
+	NoteCompound = "compound"
+)
+
+type filter struct {
+	name  string
+	apply func(l language, in []token) []token
+}
+
+// Analyzer - именованная цепочка фильтров.
+// Version - хэш цепочки и всех словарей, от которых зависит результат
+type Analyzer struct {
+	Name    string
+	Filters []string
+	Version string
+
+	filters []filter
+}
+
+// AnalyzerConfig - цепочки по именам; цепочка default добавляется, если ее нет.
+// Lemmas - словарь форма -> лемма для lemma, NgramMin/NgramMax - длины префиксов edge_ngram
+type AnalyzerConfig struct {
+	Chains   map[string][]string
+	Lemmas   Lemmas
+	NgramMin int
+	NgramMax int
+}
+
+// Analyzers - все цепочки развертывания
+type Analyzers struct {
+	vocab  *Vocabulary
+	byName map[string]*Analyzer
+}
+
+func NewAnalyzers(vocab *Vocabulary, cfg AnalyzerConfig) (*Analyzers, error) {
+	if cfg.NgramMin <= 0 || cfg.NgramMax < cfg.NgramMin {
+		return nil, fmt.Errorf("bad edge_ngram range [%d, %d]", cfg.NgramMin, cfg.NgramMax)
+	}
+
+	chains := make(map[string][]string, len(cfg.Chains)+1)
+	chains[DefaultAnalyzer] = defaultChain
+	for name, chain := range cfg.Chains {
+		chains[strings.ToLower(strings.TrimSpace(name))] = chain
+	}
+
+	a := &Analyzers{vocab: vocab, byName: make(map[string]*Analyzer, len(chains))}
+	for name, chain := range chains {
+		an, err := newAnalyzer(name, chain, vocab, cfg)
+		if err != nil {
+			return nil, err
+		}
+		a.byName[name] = an
+	}
+	return a, nil
+}
+
+// DefaultAnalyzers - только default без поправок словаря
+func DefaultAnalyzers() *Analyzers {
+	a, _ := NewAnalyzers(nil, AnalyzerConfig{NgramMin: 2, NgramMax: 10})
+	return a
+}
+
+func newAnalyzer(name string, chain []string, vocab *Vocabulary, cfg AnalyzerConfig) (*Analyzer, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("analyzer %q: empty chain", name)
+	}
+	an := &Analyzer{Name: name}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "rev=%d\n", analyzerRevision)
+	stopAdd, stopRemove, protected := vocab.Lists()
+	fmt.Fprintf(h, "stop+=%q\nstop-=%q\nprotected=%q\n", stopAdd, stopRemove, protected)
+
+	for _, raw := range chain {
+		name := strings.ToLower(strings.TrimSpace(raw))
+		var f func(language, []token) []token
+		switch name {
+		case FilterLowercase:
+			f = mapTokens(lower)
+		case FilterASCIIFold:
+			f = mapTokens(asciiFold)
+		case FilterStopWords:
+			f = func(l language, in []token) []token {
+				return dropTokens(in, func(t token) bool { return vocab.isStop(l, t.text) })
+			}
+		case FilterStem:
+			f = func(l language, in []token) []token {
+				return mapTokens(func(w string) string { return l.stem(w, false) })(l, in)
+			}
+		case FilterLemma:
+			f = mapTokens(cfg.Lemmas.lemma)
+			fmt.Fprintf(h, "lemmas=%s\n", cfg.Lemmas.digest())
+		case FilterEdgeNgram:
+			f = edgeNgrams(cfg.NgramMin, cfg.NgramMax)
+			fmt.Fprintf(h, "ngram=%d..%d\n", cfg.NgramMin, cfg.NgramMax)
+		case FilterSplit:
+			f = splitCompounds
+		default:
+			return nil, fmt.Errorf("analyzer %q: unknown filter %q", an.Name, raw)
+		}
+		an.Filters = append(an.Filters, name)
+		an.filters = append(an.filters, filter{name: name, apply: f})
+		fmt.Fprintf(h, "filter=%s\n", name)
+	}
+
+	an.Version = hex.EncodeToString(h.Sum(nil))[:12]
+	return an, nil
+}
+
+// Get - цепочка по имени, пустое имя - default
+func (a *Analyzers) Get(name string) (*Analyzer, error) {
+	if name == "" {
+		name = DefaultAnalyzer
+	}
+	an, ok := a.byName[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, ErrUnknownAnalyzer
+	}
+	return an, nil
+}
+
+// List - цепочки по имени
+func (a *Analyzers) List() []*Analyzer {
+	out := make([]*Analyzer, 0, len(a.byName))
+	for _, an := range a.byName {
+		out = append(out, an)
+	}
+	slices.SortFunc(out, func(x, y *Analyzer) int { return strings.Compare(x.Name, y.Name) })
+	return out
+}
+
+// run - токены после каждого фильтра и dedup; stage вызывается для трассировки Analyze.
+// Фильтр получает только не отброшенные токены
+func (an *Analyzer) run(tokens []token, l language, stage func(name string, out []token)) []token {
+	for _, f := range an.filters {
+		out := f.apply(l, tokens)
+		if stage != nil {
+			stage(f.name, out)
+		}
+		tokens = kept(out)
+	}
+
+	seen := newSet(len(tokens))
+	out := make([]token, 0, len(tokens))
+	for _, t := range tokens {
+		if !seen.Add(t.text) {
+			t.dropped = DropDuplicate
+		}
+		out = append(out, t)
+	}
+	if stage != nil {
+		stage(StageDedup, out)
+	}
+	return kept(out)
+}
+
+func kept(tokens []token) []token {
+	out := make([]token, 0, len(tokens))
+	for _, t := range tokens {
+		if t.dropped == "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// mapTokens - замена текста токена, кроме keep; пустой результат оставляет токен как был
+func mapTokens(fn func(string) string) func(language, []token) []token {
+	return func(_ language, in []token) []token {
+		out := make([]token, 0, len(in))
+		for _, t := range in {
+			if t.keep == "" {
+				if w := fn(t.text); w != "" {
+					t.text = w
+				}
+			}
+			out = append(out, t)
+		}
+		return out
+	}
+}
+
+func dropTokens(in []token, drop func(token) bool) []token {
+	out := make([]token, 0, len(in))
+	for _, t := range in {
+		if t.keep == "" && drop(t) {
+			t.dropped = DropStopWord
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// asciiFold - латиница с диакритикой к ASCII: crème -> creme, straße -> strasse.
+// Остальные алфавиты не трогаем: й в кириллице - отдельная буква, а не и с надстрочным знаком
+func asciiFold(w string) string {
+	var b strings.Builder
+	for _, r := range w {
+		if r < utf8.RuneSelf || !unicode.Is(unicode.Latin, r) {
+			b.WriteRune(r)
+			continue
+		}
+		if s, ok := foldSpecial[r]; ok {
+			b.WriteString(s)
+			continue
+		}
+		if d := norm.NFD.String(string(r)); d[0] < utf8.RuneSelf {
+			b.WriteByte(d[0])
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// foldSpecial - латинские буквы без разложения в NFD
+var foldSpecial = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D", 'þ': "th", 'Þ': "TH",
+}
+
+// edgeNgrams - после каждого слова его префиксы длиной от min до max символов,
+// короче самого слова: search -> se, sea, sear, searc. Нужны при индексации для поиска по префиксу
+func edgeNgrams(minLen, maxLen int) func(language, []token) []token {
+	return func(_ language, in []token) []token {
+		out := make([]token, 0, len(in)*2)
+		for _, t := range in {
+			out = append(out, t)
+			if t.keep != "" || t.note == NoteNgram {
+				continue
+			}
+			runes := []rune(t.text)
+			for n := minLen; n <= maxLen && n < len(runes); n++ {
+				out = append(out, token{text: string(runes[:n]), start: t.start, end: t.start + n, note: NoteNgram})
+			}
+		}
+		return out
+	}
+}
+
+// splitCompounds - к слову в CamelCase добавляет его части (JavaScript -> java, script),
+// к словам через дефис - их слитное написание (e-mail -> email). Исходные токены остаются
+func splitCompounds(_ language, in []token) []token {
+	out := make([]token, 0, len(in)*2)
+	for i, t := range in {
+		out = append(out, t)
+		if t.keep == "" {
+			out = append(out, camelParts(t)...)
+		}
+
+		// конец группы через дефис: склеиваем ее целиком
+		if i+1 < len(in) && joinedTo(in[i], in[i+1]) {
+			continue
+		}
+		start := i
+		for start > 0 && joinedTo(in[start-1], in[start]) {
+			start--
+		}
+		if start < i {
+			var b strings.Builder
+			for _, g := range in[start : i+1] {
+				b.WriteString(g.text)
+			}
+			out = append(out, token{text: b.String(), start: in[start].start, end: t.end, note: NoteCompound})
+		}
+	}
+	return out
+}
+
+func joinedTo(prev, next token) bool {
+	return next.joined && prev.end+1 == next.start && prev.keep == "" && next.keep == ""
+}
+
+// camelParts - части по смене регистра: JavaScript -> Java, Script; XMLHttp -> XML, Http.
+// Одна часть - слово не составное
+func camelParts(t token) []token {
+	r := []rune(t.text)
+	var parts []token
+	start := 0
+	for i := 1; i < len(r); i++ {
+		lowerUpper := unicode.IsLower(r[i-1]) && unicode.IsUpper(r[i])
+		acronymEnd := unicode.IsUpper(r[i-1]) && unicode.IsUpper(r[i]) && i+1 < len(r) && unicode.IsLower(r[i+1])
+		if lowerUpper || acronymEnd {
+			parts = append(parts, token{text: string(r[start:i]), start: t.start + start, end: t.start + i, note: NoteCompound})
+			start = i
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return append(parts, token{text: string(r[start:]), start: t.start + start, end: t.end, note: NoteCompound})
+}
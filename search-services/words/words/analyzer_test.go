@@ -0,0 +1,115 @@
+package words
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func testAnalyzers(t *testing.T, vocab *Vocabulary, chains map[string][]string) *Analyzers {
+	t.Helper()
+	a, err := NewAnalyzers(vocab, AnalyzerConfig{Chains: chains, Lemmas: Lemmas{"went": "go", "mice": "mouse"}, NgramMin: 2, NgramMax: 4})
+	if err != nil {
+		t.Fatalf("analyzers: %v", err)
+	}
+	return a
+}
+
+func TestAnalyzerChains(t *testing.T) {
+	s := NewService(testAnalyzers(t, NewVocabulary(nil, nil, []string{"c++"}), map[string][]string{
+		"fold":   {"lowercase", "asciifold"},
+		"lemma":  {"lowercase", "stopwords", "lemma"},
+		"prefix": {"lowercase", "stopwords", "edge_ngram"},
+		"split":  {"split", "lowercase"},
+	}), nil, 0)
+
+	tests := []struct {
+		analyzer string
+		phrase   string
+		want     []string
+	}{
+		{analyzer: "", phrase: "Running Dogs", want: []string{"run", "dog"}},
+		{analyzer: "fold", phrase: "Crème Brûlée Straße Ёлка", want: []string{"creme", "brulee", "strasse", "ёлка"}},
+		{analyzer: "lemma", phrase: "The mice went home", want: []string{"mouse", "go", "home"}},
+		{analyzer: "prefix", phrase: "Search in C++ 2024", want: []string{"search", "se", "sea", "sear", "c++", "2024"}},
+		{analyzer: "split", phrase: "JavaScript XMLHttpRequest", want: []string{"javascript", "java", "script", "xmlhttprequest", "xml", "http", "request"}},
+		{analyzer: "split", phrase: "e-mail rock'n'roll", want: []string{"e", "mail", "email", "rock", "n", "roll", "rocknroll"}},
+		{analyzer: "split", phrase: "well - known", want: []string{"well", "known"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.analyzer+"/"+tt.phrase, func(t *testing.T) {
+			res, err := s.Norm(tt.phrase, Options{Language: "english", Analyzer: tt.analyzer})
+			if err != nil {
+				t.Fatalf("norm: %v", err)
+			}
+			if !slices.Equal(res.Words, tt.want) {
+				t.Fatalf("Norm(%q) = %q, want %q", tt.phrase, res.Words, tt.want)
+			}
+			if res.AnalyzerVersion == "" {
+				t.Fatal("empty analyzer version")
+			}
+		})
+	}
+
+	if _, err := s.Norm("x", Options{Analyzer: "absent"}); err != ErrUnknownAnalyzer {
+		t.Fatalf("unknown analyzer error = %v", err)
+	}
+}
+
+func TestAnalyzerConfig(t *testing.T) {
+	if _, err := NewAnalyzers(nil, AnalyzerConfig{Chains: map[string][]string{"x": {"lowercase", "soundex"}}, NgramMin: 2, NgramMax: 4}); err == nil {
+		t.Fatal("unknown filter accepted")
+	}
+	if _, err := NewAnalyzers(nil, AnalyzerConfig{Chains: map[string][]string{"x": {}}, NgramMin: 2, NgramMax: 4}); err == nil {
+		t.Fatal("empty chain accepted")
+	}
+	if _, err := NewAnalyzers(nil, AnalyzerConfig{NgramMin: 3, NgramMax: 2}); err == nil {
+		t.Fatal("bad ngram range accepted")
+	}
+
+	// версия зависит от цепочки и словарей, но не от имени
+	version := func(vocab *Vocabulary, chain []string, lemmas Lemmas) string {
+		a, err := NewAnalyzers(vocab, AnalyzerConfig{Chains: map[string][]string{"x": chain}, Lemmas: lemmas, NgramMin: 2, NgramMax: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		an, _ := a.Get("x")
+		return an.Version
+	}
+	base := version(nil, defaultChain, nil)
+	if def, _ := DefaultAnalyzers().Get(""); def.Version != base {
+		t.Fatalf("default version %s != same chain %s", def.Version, base)
+	}
+	if version(nil, []string{"lowercase", "stem"}, nil) == base {
+		t.Fatal("version ignores chain")
+	}
+	if version(NewVocabulary(nil, []string{"will"}, nil), defaultChain, nil) == base {
+		t.Fatal("version ignores stop words")
+	}
+	lemmaChain := []string{"lowercase", "lemma"}
+	if version(nil, lemmaChain, Lemmas{"went": "go"}) == version(nil, lemmaChain, Lemmas{"went": "walk"}) {
+		t.Fatal("version ignores lemmas")
+	}
+}
+
+func TestLoadLemmas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lemmas.txt")
+	if err := os.WriteFile(path, []byte("# comment\nGo: went, gone\nmouse: Mice\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := LoadLemmas(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if l["went"] != "go" || l["gone"] != "go" || l["mice"] != "mouse" || len(l) != 3 {
+		t.Fatalf("lemmas = %v", l)
+	}
+
+	if err := os.WriteFile(path, []byte("went go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLemmas(path); err == nil {
+		t.Fatal("bad line accepted")
+	}
+}
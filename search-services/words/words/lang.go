@@ -0,0 +1,139 @@
+package words
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/blevesearch/snowballstem/arabic"
+	"github.com/blevesearch/snowballstem/danish"
+	"github.com/blevesearch/snowballstem/dutch"
+	"github.com/blevesearch/snowballstem/finnish"
+	"github.com/blevesearch/snowballstem/german"
+	"github.com/blevesearch/snowballstem/irish"
+	"github.com/blevesearch/snowballstem/italian"
+	"github.com/blevesearch/snowballstem/portuguese"
+	"github.com/blevesearch/snowballstem/romanian"
+	"github.com/blevesearch/snowballstem/tamil"
+	"github.com/blevesearch/snowballstem/turkish"
+	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/french"
+	"github.com/kljensen/snowball/hungarian"
+	"github.com/kljensen/snowball/norwegian"
+	"github.com/kljensen/snowball/russian"
+	"github.com/kljensen/snowball/spanish"
+	"github.com/kljensen/snowball/swedish"
+)
+
+// ErrUnknownLanguage - язык не поддерживается snowball
+var ErrUnknownLanguage = errors.New("unknown language")
+
+// DefaultLanguage - если определить язык не удалось (в т.ч. запросы из одних цифр)
+const DefaultLanguage = "english"
+
+// language - стеммер и стоп-слова одного языка.
+// script - алфавит, кроме латиницы, который однозначно дает этот язык;
+// letters - буквы, которые встречаются только в этом языке из поддерживаемых:
+// у шведского, датского, финского и других с общими буквами их нет, там решают стоп-слова
+type language struct {
+	name    string
+	code    string
+	stem    func(word string, stemStopWords bool) string
+	isStop  func(word string) bool
+	script  *unicode.RangeTable
+	letters string
+}
+
+// languages - все языки snowball: kljensen/snowball и остальные из blevesearch/snowballstem
+// (snowballstem.go); порядок задает приоритет при равных очках
+var languages = []language{
+	{name: "english", code: "en", stem: english.Stem, isStop: english.IsStopWord},
+	{name: "russian", code: "ru", stem: russian.Stem, isStop: russian.IsStopWord, script: unicode.Cyrillic},
+	{name: "french", code: "fr", stem: french.Stem, isStop: french.IsStopWord, letters: "àâçèêëîïôœùûÿ"},
+	{name: "spanish", code: "es", stem: spanish.Stem, isStop: spanish.IsStopWord, letters: "ñ¿¡"},
+	{name: "swedish", code: "sv", stem: swedish.Stem, isStop: swedish.IsStopWord},
+	{name: "german", code: "de", stem: snowballstemStem(german.Stem, germanIsStopWord), isStop: germanIsStopWord, letters: "äöüß"},
+	{name: "norwegian", code: "no", stem: norwegian.Stem, isStop: norwegian.IsStopWord, letters: "æø"},
+	{name: "hungarian", code: "hu", stem: hungarian.Stem, isStop: hungarian.IsStopWord, letters: "őű"},
+	{name: "danish", code: "da", stem: snowballstemStem(danish.Stem, danishIsStopWord), isStop: danishIsStopWord},
+	{name: "dutch", code: "nl", stem: snowballstemStem(dutch.Stem, dutchIsStopWord), isStop: dutchIsStopWord},
+	{name: "finnish", code: "fi", stem: snowballstemStem(finnish.Stem, finnishIsStopWord), isStop: finnishIsStopWord},
+	{name: "italian", code: "it", stem: snowballstemStem(italian.Stem, italianIsStopWord), isStop: italianIsStopWord, letters: "ìò"},
+	{name: "portuguese", code: "pt", stem: snowballstemStem(portuguese.Stem, portugueseIsStopWord), isStop: portugueseIsStopWord, letters: "ãõ"},
+	{name: "romanian", code: "ro", stem: snowballstemStem(romanian.Stem, romanianIsStopWord), isStop: romanianIsStopWord, letters: "ășț"},
+	{name: "turkish", code: "tr", stem: snowballstemStem(turkish.Stem, turkishIsStopWord), isStop: turkishIsStopWord, letters: "ğış"},
+	{name: "irish", code: "ga", stem: snowballstemStem(irish.Stem, irishIsStopWord), isStop: irishIsStopWord},
+	{name: "arabic", code: "ar", stem: snowballstemStem(arabic.Stem, arabicIsStopWord), isStop: arabicIsStopWord, script: unicode.Arabic},
+	{name: "tamil", code: "ta", stem: snowballstemStem(tamil.Stem, tamilIsStopWord), isStop: tamilIsStopWord, script: unicode.Tamil},
+}
+
+// lookupLanguage - по имени (russian) или коду ISO 639-1 (ru), без учета регистра
+func lookupLanguage(name string) (language, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, l := range languages {
+		if name == l.name || name == l.code {
+			return l, nil
+		}
+	}
+	return language{}, ErrUnknownLanguage
+}
+
+// Languages - имена поддерживаемых языков
+func Languages() []string {
+	out := make([]string, 0, len(languages))
+	for _, l := range languages {
+		out = append(out, l.name)
+	}
+	return out
+}
+
+// detectLanguage - буквы кириллицы, арабского письма или тамильского, если их больше,
+// чем латиницы, однозначно дают язык; для латиницы считаем стоп-слова и характерные
+// буквы каждого языка, при равенстве побеждает язык выше в списке, т.е. английский
+func detectLanguage(tokens []string) language {
+	var latin int
+	scripts := make([]int, len(languages))
+	for _, w := range tokens {
+		for _, r := range w {
+			if unicode.Is(unicode.Latin, r) {
+				latin++
+				continue
+			}
+			for i, l := range languages {
+				if l.script != nil && unicode.Is(l.script, r) {
+					scripts[i]++
+					break
+				}
+			}
+		}
+	}
+	byScript := -1
+	for i, n := range scripts {
+		if n > latin && (byScript < 0 || n > scripts[byScript]) {
+			byScript = i
+		}
+	}
+	if byScript >= 0 {
+		return languages[byScript]
+	}
+
+	best, bestScore := languages[0], 0
+	for _, l := range languages {
+		if l.script != nil {
+			continue
+		}
+		score := 0
+		for _, w := range tokens {
+			if l.isStop(w) {
+				score++
+			}
+			if l.letters != "" && strings.ContainsAny(w, l.letters) {
+				score += 2
+			}
+		}
+		if score > bestScore {
+			best, bestScore = l, score
+		}
+	}
+	return best
+}
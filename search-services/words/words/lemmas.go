@@ -0,0 +1,74 @@
+package words
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Lemmas - словарь форма -> лемма для фильтра lemma. Формат файла, # - комментарий:
+//
+//	go: went, gone, goes, going
+//	mouse: mice
+//
+// В отличие от стемминга дает настоящее слово, но только для форм из словаря
+type Lemmas map[string]string
+
+// LoadLemmas - пустой path дает пустой словарь
+func LoadLemmas(path string) (Lemmas, error) {
+	if path == "" {
+		return Lemmas{}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open lemmas: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	lemmas := make(Lemmas)
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		text, _, _ := strings.Cut(sc.Text(), "#")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		lemma, forms, ok := strings.Cut(text, ":")
+		lemma = lower(strings.TrimSpace(lemma))
+		if !ok || lemma == "" {
+			return nil, fmt.Errorf("lemmas line %d: want \"lemma: form, form\"", line)
+		}
+		for form := range strings.SplitSeq(forms, ",") {
+			if form = lower(strings.TrimSpace(form)); form != "" {
+				lemmas[form] = lemma
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read lemmas: %w", err)
+	}
+	return lemmas, nil
+}
+
+// lemma - пустая строка, если формы нет в словаре
+func (l Lemmas) lemma(w string) string {
+	return l[w]
+}
+
+// digest - для версии цепочки: смена словаря меняет токены
+func (l Lemmas) digest() string {
+	forms := make([]string, 0, len(l))
+	for form := range l {
+		forms = append(forms, form)
+	}
+	slices.Sort(forms)
+
+	h := sha256.New()
+	for _, form := range forms {
+		fmt.Fprintf(h, "%s:%s\n", form, l[form])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
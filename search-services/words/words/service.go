@@ -1,11 +1,80 @@
 package words
 
 type Service interface {
-	Norm(phrase string) ([]string, error)
+	// Norm - нормализованные слова фразы, язык и цепочка, по правилам которых она разобрана
+	Norm(phrase string, opt Options) (Normalized, error)
+	// Expand - Norm плюс синонимы из словаря: слова запроса с весом 1,
+	// синонимы с весом synonymWeight
+	Expand(phrase string, opt Options) (Normalized, []WeightedWord, error)
+	// Analyze - Norm по стадиям с позициями токенов и причинами отсева, для отладки поиска
+	Analyze(phrase string, opt Options) (Analysis, error)
+	// Vocabulary - активные настройки стоп-слов и защищенных терминов
+	Vocabulary() *Vocabulary
+	// Analyzers - цепочки фильтров развертывания
+	Analyzers() []*Analyzer
+	// SetDictionary - заменяет частотный словарь корпуса (термин -> число комиксов) для Correct;
+	// surfaces - исходное написание терминов для исправленной фразы, version - метка словаря от search
+	SetDictionary(counts map[string]int, surfaces map[string]string, analyzer, version string) (int, error)
+	// DictionaryVersion - version последнего SetDictionary, пустая - словаря нет
+	DictionaryVersion() string
+	// SynonymsVersion - версия загруженного словаря синонимов, пустая - словаря нет
+	SynonymsVersion() string
+	// Correct - варианты исправления для слов запроса, которых нет в словаре
+	Correct(phrase string, opt Options) (Correction, error)
 }
 
-type service struct{}
+// Options - пустой Language - определить язык по фразе, пустой Analyzer - цепочка default
+type Options struct {
+	Language string
+	Analyzer string
+}
+
+// Normalized - результат Norm. AnalyzerVersion меняется вместе с цепочкой и ее словарями:
+// токены разных версий сравнивать нельзя. SynonymsVersion заполняет только Expand:
+// перезагрузка синонимов меняет раскрытие, не меняя цепочку.
+// Surfaces - исходное написание каждого из Words в нижнем регистре, до стемминга
+type Normalized struct {
+	Words           []string
+	Surfaces        []string
+	Language        string
+	Analyzer        string
+	AnalyzerVersion string
+	SynonymsVersion string
+}
+
+// WeightedWord - нормализованное слово и его вес для ранжирования
+type WeightedWord struct {
+	Word   string
+	Weight float64
+}
+
+type service struct {
+	analyzers     *Analyzers
+	synonyms      *Synonyms
+	synonymWeight float64
+	speller       speller
+}
+
+// NewService - analyzers nil - только default без поправок словаря,
+// synonyms nil - Expand без синонимов
+func NewService(analyzers *Analyzers, synonyms *Synonyms, synonymWeight float64) Service {
+	if analyzers == nil {
+		analyzers = DefaultAnalyzers()
+	}
+	return &service{analyzers: analyzers, synonyms: synonyms, synonymWeight: synonymWeight}
+}
+
+func (s *service) Vocabulary() *Vocabulary {
+	return s.analyzers.vocab
+}
+
+func (s *service) SynonymsVersion() string {
+	if t := s.synonyms.table(); t != nil {
+		return t.version
+	}
+	return ""
+}
 
-func NewService() Service {
-	return &service{}
+func (s *service) Analyzers() []*Analyzer {
+	return s.analyzers.List()
 }
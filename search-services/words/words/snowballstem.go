@@ -0,0 +1,37 @@
+package words
+
+import (
+	"strings"
+
+	"github.com/blevesearch/snowballstem"
+)
+
+// В kljensen/snowball нет части языков snowball, их стеммеры берутся из порта
+// blevesearch/snowballstem, а стоп-слова - из stopwords.go
+
+// snowballstemStem - та же сигнатура, что у стеммеров kljensen/snowball:
+// стоп-слово без stemStopWords возвращается как есть
+func snowballstemStem(stem func(*snowballstem.Env) bool, isStop func(string) bool) func(string, bool) string {
+	return func(word string, stemStopWords bool) string {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if !stemStopWords && isStop(word) {
+			return word
+		}
+		env := snowballstem.NewEnv(word)
+		stem(env)
+		return env.Current()
+	}
+}
+
+// stopWords - isStop по списку слов через пробел
+func stopWords(list string) func(string) bool {
+	words := strings.Fields(list)
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return func(word string) bool {
+		_, ok := set[word]
+		return ok
+	}
+}
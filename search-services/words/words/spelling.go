@@ -0,0 +1,266 @@
+package words
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrNoDictionary - словарь для Correct еще не загружен
+var ErrNoDictionary = errors.New("spelling dictionary is not loaded")
+
+// Параметры symmetric delete (SymSpell): исправления на расстоянии до maxEditDistance.
+// Удаления считаются только от первых spellPrefixLen символов, чтобы индекс удалений
+// не рос на длинных словах; расстояние до кандидата все равно считается по всему слову
+const (
+	maxEditDistance  = 2
+	spellPrefixLen   = 7
+	maxSuggestions   = 3
+	minSpellTokenLen = 3 // короткие слова слишком неоднозначны, их не исправляем
+)
+
+// dictionary - неизменяемый снимок частотного словаря, заменяется целиком
+type dictionary struct {
+	// analyzer - цепочка, которой получены термины; ею же разбирается запрос
+	analyzer string
+	counts   map[string]int
+	// surfaces - термин -> самое частое исходное написание в корпусе, им пишется исправленная фраза:
+	// термины - стемы ("machin"), показывать их пользователю нельзя
+	surfaces map[string]string
+	// version - метка словаря от search, по ней он находит реплики со старым словарем
+	version string
+	// deletes - строка после удаления до maxEditDistance символов из префикса -> термины
+	deletes map[string][]string
+}
+
+func newDictionary(counts map[string]int, surfaces map[string]string, analyzer, version string) *dictionary {
+	d := &dictionary{
+		analyzer: analyzer,
+		counts:   make(map[string]int, len(counts)),
+		surfaces: make(map[string]string, len(surfaces)),
+		version:  version,
+		deletes:  make(map[string][]string, len(counts)*4),
+	}
+	for term, n := range counts {
+		if term == "" || n <= 0 {
+			continue
+		}
+		d.counts[term] = n
+		if sf := surfaces[term]; sf != "" {
+			d.surfaces[term] = sf
+		}
+		for del := range deletes(prefix(term)) {
+			d.deletes[del] = append(d.deletes[del], term)
+		}
+	}
+	return d
+}
+
+func prefix(w string) string {
+	r := []rune(w)
+	if len(r) > spellPrefixLen {
+		r = r[:spellPrefixLen]
+	}
+	return string(r)
+}
+
+// deletes - сама строка и все строки, полученные удалением до maxEditDistance символов
+func deletes(w string) set {
+	out := newSet(16)
+	out.Add(w)
+	level := []string{w}
+	for range maxEditDistance {
+		var next []string
+		for _, s := range level {
+			r := []rune(s)
+			if len(r) <= 1 {
+				continue
+			}
+			for i := range r {
+				d := string(r[:i]) + string(r[i+1:])
+				if out.Add(d) {
+					next = append(next, d)
+				}
+			}
+		}
+		level = next
+	}
+	return out
+}
+
+// Suggestion - термин словаря рядом с исходным: Distance - расстояние Дамерау-Левенштейна,
+// Count - в скольких комиксах встречается термин
+type Suggestion struct {
+	Term     string
+	Distance int
+	Count    int
+}
+
+// surface - написание термина для фразы: самое частое в корпусе, без него сам термин
+func (d *dictionary) surface(term string) string {
+	if sf, ok := d.surfaces[term]; ok {
+		return sf
+	}
+	return term
+}
+
+// lookup - термины на расстоянии до maxEditDistance: по возрастанию расстояния,
+// затем по убыванию частоты
+func (d *dictionary) lookup(term string) []Suggestion {
+	r := []rune(term)
+	seen := newSet(32)
+	var out []Suggestion
+	for del := range deletes(prefix(term)) {
+		for _, cand := range d.deletes[del] {
+			if !seen.Add(cand) {
+				continue
+			}
+			c := []rune(cand)
+			if abs(len(c)-len(r)) > maxEditDistance {
+				continue
+			}
+			if dist := editDistance(r, c); dist <= maxEditDistance {
+				out = append(out, Suggestion{Term: cand, Distance: dist, Count: d.counts[cand]})
+			}
+		}
+	}
+	slices.SortFunc(out, func(a, b Suggestion) int {
+		if a.Distance != b.Distance {
+			return a.Distance - b.Distance
+		}
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Term, b.Term)
+	})
+	if len(out) > maxSuggestions {
+		out = out[:maxSuggestions]
+	}
+	return out
+}
+
+// editDistance - расстояние Дамерау-Левенштейна в варианте optimal string alignment:
+// перестановка соседних символов стоит 1, как вставка, удаление и замена
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// speller - частотный словарь корпуса для Correct. Словарь присылает search
+// после пересборки индекса, до этого Correct возвращает ErrNoDictionary
+type speller struct {
+	current atomic.Pointer[dictionary]
+}
+
+func (sp *speller) dictionary() (*dictionary, error) {
+	d := sp.current.Load()
+	if d == nil {
+		return nil, ErrNoDictionary
+	}
+	return d, nil
+}
+
+// TokenCorrection - слово запроса: Token - в нижнем регистре, Term - после цепочки.
+// Known - термин есть в словаре, тогда Suggestions пустой
+type TokenCorrection struct {
+	Token       string
+	Term        string
+	Known       bool
+	Suggestions []Suggestion
+}
+
+// Correction - результат Correct. Phrase - запрос, в котором незнакомые слова заменены
+// исходным написанием лучших вариантов; пустой, если заменять нечего
+type Correction struct {
+	Normalized
+	Tokens []TokenCorrection
+	Phrase string
+}
+
+// SetDictionary - analyzer - цепочка, которой получены термины, пустая - default
+func (s *service) SetDictionary(counts map[string]int, surfaces map[string]string, analyzer, version string) (int, error) {
+	an, err := s.analyzers.Get(analyzer)
+	if err != nil {
+		return 0, err
+	}
+	d := newDictionary(counts, surfaces, an.Name, version)
+	s.speller.current.Store(d)
+	return len(d.counts), nil
+}
+
+func (s *service) DictionaryVersion() string {
+	if d := s.speller.current.Load(); d != nil {
+		return d.version
+	}
+	return ""
+}
+
+// Correct - каждое слово запроса проходит цепочку словаря (или opt.Analyzer) отдельно:
+// стоп-слова, числа и защищенные термины не исправляются
+func (s *service) Correct(phrase string, opt Options) (Correction, error) {
+	d, err := s.speller.dictionary()
+	if err != nil {
+		return Correction{}, err
+	}
+	if opt.Analyzer == "" {
+		opt.Analyzer = d.analyzer
+	}
+	req, err := s.prepare(phrase, opt)
+	if err != nil {
+		return Correction{}, err
+	}
+
+	out := Correction{Normalized: req.normalized(req.analyzer.run(req.tokens, req.language, nil))}
+	words := make([]string, 0, len(req.tokens))
+	corrected := false
+	for _, t := range req.tokens {
+		tc := TokenCorrection{Token: lower(t.text)}
+		words = append(words, tc.Token)
+
+		norm := req.analyzer.run([]token{t}, req.language, nil)
+		if len(norm) == 0 || t.keep != "" {
+			continue
+		}
+		tc.Term = norm[0].text
+		_, tc.Known = d.counts[tc.Term]
+		if !tc.Known && len([]rune(tc.Term)) >= minSpellTokenLen {
+			tc.Suggestions = d.lookup(tc.Term)
+		}
+		if len(tc.Suggestions) > 0 {
+			words[len(words)-1] = d.surface(tc.Suggestions[0].Term)
+			corrected = true
+		}
+		out.Tokens = append(out.Tokens, tc)
+	}
+	if corrected {
+		out.Phrase = strings.Join(words, " ")
+	}
+	return out, nil
+}
@@ -0,0 +1,108 @@
+package words
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"robot", "robot", 0},
+		{"robot", "robt", 1},
+		{"robot", "roboto", 1},
+		{"robot", "rabot", 1},
+		{"robot", "rboot", 1}, // перестановка соседних
+		{"kitten", "sitting", 3},
+		{"кошка", "кшока", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDictionaryLookup(t *testing.T) {
+	d := newDictionary(map[string]int{
+		"robot": 40, "rocket": 15, "root": 3, "boat": 10, "physic": 25, "physicist": 12, "": 5, "zero": 0,
+	}, nil, DefaultAnalyzer, "")
+	if _, ok := d.counts["zero"]; ok {
+		t.Fatal("term with zero count kept")
+	}
+
+	tests := []struct {
+		term string
+		want []string
+	}{
+		// сначала ближние, при равном расстоянии - более частые
+		{term: "robt", want: []string{"robot", "root", "boat"}},
+		{term: "rokcet", want: []string{"rocket"}},
+		// различие за пределами префикса все равно учитывается в расстоянии
+		{term: "physicixt", want: []string{"physicist"}},
+		{term: "qwerty", want: nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, s := range d.lookup(tt.term) {
+			got = append(got, s.Term)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("lookup(%q) = %v, want %v", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestCorrect(t *testing.T) {
+	s := NewService(nil, nil, 0)
+	if _, err := s.Correct("robots", Options{}); !errors.Is(err, ErrNoDictionary) {
+		t.Fatalf("correct without dictionary: err = %v, want ErrNoDictionary", err)
+	}
+	if _, err := s.SetDictionary(map[string]int{"robot": 1}, nil, "nope", "v1"); !errors.Is(err, ErrUnknownAnalyzer) {
+		t.Fatalf("set dictionary with unknown analyzer: err = %v", err)
+	}
+
+	// термины словаря - уже нормализованные слова индекса, surfaces - их написание в комиксах
+	n, err := s.SetDictionary(map[string]int{"robot": 40, "rocket": 15, "launch": 8, "physic": 25},
+		map[string]string{"physic": "physics"}, "", "v1")
+	if err != nil || n != 4 {
+		t.Fatalf("set dictionary = %d, %v", n, err)
+	}
+	if v := s.DictionaryVersion(); v != "v1" {
+		t.Fatalf("dictionary version = %q, want v1", v)
+	}
+
+	c, err := s.Correct("The robts launched 2 rockts", Options{})
+	if err != nil {
+		t.Fatalf("correct: %v", err)
+	}
+	if want := "the robot launched 2 rocket"; c.Phrase != want {
+		t.Fatalf("phrase = %q, want %q", c.Phrase, want)
+	}
+	// стоп-слово и число не исправляются и в Tokens не попадают
+	var tokens []string
+	for _, tc := range c.Tokens {
+		tokens = append(tokens, tc.Token)
+	}
+	if want := []string{"robts", "launched", "rockts"}; !slices.Equal(tokens, want) {
+		t.Fatalf("tokens = %v, want %v", tokens, want)
+	}
+	if !c.Tokens[1].Known || len(c.Tokens[1].Suggestions) != 0 {
+		t.Fatalf("known word corrected: %+v", c.Tokens[1])
+	}
+
+	// во фразу идет написание из корпуса, а не стем
+	c, err = s.Correct("phisics", Options{})
+	if err != nil || c.Phrase != "physics" {
+		t.Fatalf("correct stemmed term = %q, %v, want physics", c.Phrase, err)
+	}
+
+	c, err = s.Correct("robot launch", Options{})
+	if err != nil || c.Phrase != "" {
+		t.Fatalf("correct known phrase = %q, %v, want no correction", c.Phrase, err)
+	}
+}
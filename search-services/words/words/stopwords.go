@@ -0,0 +1,100 @@
+package words
+
+// Стоп-слова языков из blevesearch/snowballstem. Для датского, голландского, итальянского,
+// немецкого, португальского и финского - списки snowball (<язык>/stop.txt, у финского
+// без редких падежных форм местоимений). У румынского, турецкого, ирландского, арабского
+// и тамильского в snowball списков нет, там самые частые служебные слова
+
+var germanIsStopWord = stopWords(`
+	aber alle allem allen aller alles als also am an ander andere anderem anderen anderer
+	anderes anderm andern anderr anders auch auf aus bei bin bis bist da damit dann der den
+	des dem die das daß dass derselbe derselben denselben desselben demselben dieselbe
+	dieselben dasselbe dazu dein deine deinem deinen deiner deines denn derer dessen dich dir
+	du dies diese diesem diesen dieser dieses doch dort durch ein eine einem einen einer eines
+	einig einige einigem einigen einiger einiges einmal er ihn ihm es etwas euer eure eurem
+	euren eurer eures für gegen gewesen hab habe haben hat hatte hatten hier hin hinter ich
+	mich mir ihr ihre ihrem ihren ihrer ihres euch im in indem ins ist jede jedem jeden jeder
+	jedes jene jenem jenen jener jenes jetzt kann kein keine keinem keinen keiner keines
+	können könnte machen man manche manchem manchen mancher manches mein meine meinem meinen
+	meiner meines mit muss musste nach nicht nichts noch nun nur ob oder ohne sehr sein seine
+	seinem seinen seiner seines selbst sich sie ihnen sind so solche solchem solchen solcher
+	solches soll sollte sondern sonst über um und uns unsere unserem unseren unser unseres
+	unter viel vom von vor während war waren warst was weg weil weiter welche welchem welchen
+	welcher welches wenn werde werden wie wieder will wir wird wirst wo wollen wollte würde
+	würden zu zum zur zwar zwischen`)
+
+var danishIsStopWord = stopWords(`
+	og i jeg det at en den til er som på de med han af for ikke der var mig sig men et har
+	om vi min havde ham hun nu over da fra du ud sin dem os op man hans hvor eller hvad skal
+	selv her alle vil blev kunne ind når være dog noget ville jo deres efter ned skulle denne
+	end dette mit også under have dig anden hende mine alt meget sit sine vor mod disse hvis
+	din nogle hos blive mange ad bliver hendes været thi jer sådan`)
+
+var dutchIsStopWord = stopWords(`
+	de en van ik te dat die in een hij het niet zijn is was op aan met als voor had er maar
+	om hem dan zou of wat mijn men dit zo door over ze zich bij ook tot je mij uit der daar
+	haar naar heb hoe heeft hebben deze u want nog zal me zij nu ge geen omdat iets worden
+	toch al waren veel meer doen toen moet ben zonder kan hun dus alles onder ja eens hier
+	wie werd altijd doch wordt wezen kunnen ons zelf tegen na reeds wil kon niets uw iemand
+	geweest andere`)
+
+var finnishIsStopWord = stopWords(`
+	olla olen olet on olemme olette ovat ole oli olisi olisit olisin olisimme olisitte
+	olisivat olit olin olimme olitte olivat ollut olleet en et ei emme ette eivät minä minun
+	minut minua minussa minulle sinä sinun sinut sinua sinulle hän hänen hänet häntä hänelle
+	me meidän meidät meitä meille te teidän teidät teitä teille he heidän heidät heitä
+	heille tämä tämän tätä tässä tästä tähän tuo tuon tuota se sen sitä siinä siitä siihen
+	nämä näiden näitä nuo noiden noita ne niiden niitä mikä minkä mitä mitkä kuka kenen
+	kenet ketä ketkä joka jonka jota jotka joiden joita että ja jos koska kuin mutta niin
+	sekä sillä tai vaan vai vaikka kanssa mukaan noin poikki yli kun nyt itse`)
+
+var italianIsStopWord = stopWords(`
+	ad al allo ai agli all agl alla alle con col coi da dal dallo dai dagli dall dagl dalla
+	dalle di del dello dei degli dell degl della delle in nel nello nei negli nell negl nella
+	nelle su sul sullo sui sugli sull sugl sulla sulle per tra contro io tu lui lei noi voi
+	loro mio mia miei mie tuo tua tuoi tue suo sua suoi sue nostro nostra nostri nostre
+	vostro vostra vostri vostre mi ti ci vi lo la li le gli ne il un uno una ma ed se perché
+	anche come dov dove che chi cui non più quale quanto quanti quanta quante quello quelli
+	quella quelle questo questi questa queste si tutto tutti a c e i l o ho hai ha abbiamo
+	avete hanno abbia avevo aveva avevamo avevano sono sei è siamo siete era eri eravamo
+	erano fui fu furono sia stato stata stati state essere avere fare faccio fa fanno`)
+
+var portugueseIsStopWord = stopWords(`
+	de a o que e do da em um para com não uma os no se na por mais as dos como mas ao ele
+	das à seu sua ou quando muito nos já eu também só pelo pela até isso ela entre depois
+	sem mesmo aos seus quem nas me esse eles você essa num nem suas meu às minha numa pelos
+	elas qual nós lhe deles essas esses pelas este dele tu te vocês vos lhes meus minhas teu
+	tua teus tuas nosso nossa nossos nossas dela delas esta estes estas aquele aquela
+	aqueles aquelas isto aquilo estou está estamos estão estive esteve estivemos estiveram
+	estava estávamos estavam foi fomos foram era éramos eram sou somos são tenho tem temos
+	têm tinha tinham tive teve há hei houve`)
+
+var romanianIsStopWord = stopWords(`
+	a acea aceasta această aceea acei aceia acel acela acele acelea acest acesta aceste
+	acestea acestei acolo acum ai aici al ale alt alta altceva alte altele altfel am ar are
+	asta atunci au avea avem aveți avut azi bine ca care ce cel ceva chiar cine cu cum când
+	da dacă dar de deci deja din dintre doar după ea ei el ele era este eu fi fie fost iar
+	îi îl îmi în între își la le li lor lui mai mult ne nici nimic noi nu o pe pentru poate
+	prin să se și sub sunt tot toți un una unei unui unde voi vă`)
+
+var turkishIsStopWord = stopWords(`
+	acaba ama aslında az bazı belki ben biri birkaç birşey bir biz bu çok çünkü da daha de
+	defa diye eğer en gibi hem hep hepsi her hiç için ile ise kez ki kim mı mi mu mü nasıl
+	ne neden nerde nerede nereye niçin niye o onlar sanki sen şey siz şu tüm ve veya ya yani`)
+
+var irishIsStopWord = stopWords(`
+	a ach ag agus an aon ar arna as ba beirt bhúr caoga ceathair cúig chuig cé dá de do don
+	dtí faoi fiche go gur i í iad idir in ina inar is le leis mar mo na ná ní níor nó ó ón
+	os sa sé seacht sí siad sin sna tar thar thú trí tú um`)
+
+var arabicIsStopWord = stopWords(`
+	من إلى عن على في مع هذا هذه ذلك تلك التي الذي الذين ما لا لم لن إن أن كان كانت قد ثم
+	أو و هو هي هم نحن أنا أنت كل بعض غير بين عند حتى إذا كما لكن بل منذ أي`)
+
+var tamilIsStopWord = stopWords(`
+	ஒரு என்று மற்றும் இந்த இது என்ற கொண்டு என்பது பல ஆகும் அல்லது அவர் நான் உள்ள அந்த
+	இவர் என முதல் என்ன இருந்து சில என் போன்ற வேண்டும் வந்து இதன் அது அவன் தான் மேலும்
+	பின்னர் கொண்ட இருக்கும் தனது உள்ளது போது அதன் தன் பிறகு அவர்கள் வரை அவள் நீ ஆகிய
+	இருந்தது உள்ளன வந்த இருந்த மிகவும் இங்கு மீது ஓர் இவை பற்றி வேறு இதில் போல் இப்போது
+	மட்டும் மேல் பின் எனக்கு இன்னும் அன்று ஒரே மிக அங்கு அதை அதே ஏன் யார் எல்லாம் நாம்
+	எனவே எந்த`)
@@ -0,0 +1,183 @@
+package words
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Формат словаря синонимов, по правилу на строку, # - комментарий до конца строки:
+//
+//	js, javascript, ecmascript     - двустороннее: любой вариант раскрывается в остальные
+//	ml => machine learning         - одностороннее: только левая часть раскрывается в правую
+//	bobby tables => exploits of a mom
+//
+// Варианты могут быть фразами из нескольких слов. Совпадение ищется по токенам
+// запроса до стоп-слов и стемминга, раскрытие нормализуется как сам запрос
+
+// synonymTable - неизменяемый снимок словаря, заменяется целиком при перезагрузке
+type synonymTable struct {
+	// rules - фраза (токены через пробел) -> варианты раскрытия
+	rules  map[string][][]string
+	maxLen int
+	// version - хэш содержимого файла, по нему клиенты узнают о перезагрузке
+	version string
+}
+
+// expansions - варианты для всех фраз запроса, включая пересекающиеся
+func (t *synonymTable) expansions(tokens []string) [][]string {
+	if t == nil || len(t.rules) == 0 {
+		return nil
+	}
+	var out [][]string
+	for i := range tokens {
+		for n := 1; n <= t.maxLen && i+n <= len(tokens); n++ {
+			out = append(out, t.rules[strings.Join(tokens[i:i+n], " ")]...)
+		}
+	}
+	return out
+}
+
+func (t *synonymTable) add(from []string, to [][]string) {
+	key := strings.Join(from, " ")
+	for _, alt := range to {
+		if strings.Join(alt, " ") != key {
+			t.rules[key] = append(t.rules[key], alt)
+		}
+	}
+	t.maxLen = max(t.maxLen, len(from))
+}
+
+// parseSynonyms - ошибка указывает номер строки
+func parseSynonyms(r io.Reader, vocab *Vocabulary) (*synonymTable, error) {
+	t := &synonymTable{rules: make(map[string][][]string)}
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text, _, _ := strings.Cut(sc.Text(), "#")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		left, right, oneWay := strings.Cut(text, "=>")
+		from, err := synonymPhrases(left, vocab)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !oneWay {
+			if len(from) < 2 {
+				return nil, fmt.Errorf("line %d: two-way rule needs at least two phrases", line)
+			}
+			for _, f := range from {
+				t.add(f, from)
+			}
+			continue
+		}
+
+		to, err := synonymPhrases(right, vocab)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		for _, f := range from {
+			t.add(f, to)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read synonyms: %w", err)
+	}
+	return t, nil
+}
+
+func synonymPhrases(list string, vocab *Vocabulary) ([][]string, error) {
+	var out [][]string
+	for p := range strings.SplitSeq(list, ",") {
+		tokens := vocab.tokenize(p)
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("empty phrase in %q", strings.TrimSpace(list))
+		}
+		out = append(out, tokens)
+	}
+	return out, nil
+}
+
+// Synonyms - словарь синонимов из файла с перезагрузкой без рестарта.
+// Запросы читают текущий снимок без блокировок
+type Synonyms struct {
+	path    string
+	vocab   *Vocabulary
+	current atomic.Pointer[synonymTable]
+
+	mu      sync.Mutex
+	modTime time.Time
+}
+
+// LoadSynonyms - пустой path дает пустой словарь.
+// Фразы разбираются на токены с защищенными терминами vocab, как и запросы
+func LoadSynonyms(path string, vocab *Vocabulary) (*Synonyms, error) {
+	s := &Synonyms{path: path, vocab: vocab}
+	if path == "" {
+		return s, nil
+	}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload - перечитать файл; при ошибке остается прежний словарь.
+// Возвращает число фраз в новом словаре
+func (s *Synonyms) Reload() (int, error) {
+	if s.path == "" {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return 0, fmt.Errorf("open synonyms: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("stat synonyms: %w", err)
+	}
+	h := fnv.New64a()
+	t, err := parseSynonyms(io.TeeReader(f, h), s.vocab)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", s.path, err)
+	}
+	t.version = strconv.FormatUint(h.Sum64(), 16)
+	s.current.Store(t)
+	s.modTime = info.ModTime()
+	return len(t.rules), nil
+}
+
+// Changed - файл изменился с последней успешной загрузки
+func (s *Synonyms) Changed() bool {
+	if s.path == "" {
+		return false
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !info.ModTime().Equal(s.modTime)
+}
+
+func (s *Synonyms) table() *synonymTable {
+	if s == nil {
+		return nil
+	}
+	return s.current.Load()
+}
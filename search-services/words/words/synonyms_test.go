@@ -0,0 +1,109 @@
+package words
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSynonyms = `
+# комментарий
+js, javascript   # двустороннее
+ml => machine learning
+bobby tables => exploits of a mom
+`
+
+func TestParseSynonyms(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{name: "valid", text: testSynonyms},
+		{name: "empty", text: "\n# только комментарий\n"},
+		{name: "single two-way", text: "js", wantErr: "line 1: two-way rule"},
+		{name: "empty phrase", text: "\nml =>", wantErr: "line 2: empty phrase"},
+		{name: "empty alternative", text: "js, , javascript", wantErr: "line 1: empty phrase"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSynonyms(strings.NewReader(tt.text), nil)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.txt")
+	if err := os.WriteFile(path, []byte(testSynonyms), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	synonyms, err := LoadSynonyms(path, nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	s := NewService(nil, synonyms, 0.5)
+
+	tests := []struct {
+		phrase string
+		want   map[string]float64
+	}{
+		{phrase: "js", want: map[string]float64{"js": 1, "javascript": 0.5}},
+		{phrase: "JavaScript tricks", want: map[string]float64{"javascript": 1, "trick": 1, "js": 0.5}},
+		{phrase: "ml", want: map[string]float64{"ml": 1, "machin": 0.5, "learn": 0.5}},
+		// одностороннее правило не раскрывается обратно
+		{phrase: "machine learning", want: map[string]float64{"machin": 1, "learn": 1}},
+		{phrase: "little bobby tables", want: map[string]float64{"littl": 1, "bobbi": 1, "tabl": 1, "exploit": 0.5, "mom": 0.5}},
+		// синоним, совпавший со словом запроса, не понижает его вес
+		{phrase: "js javascript", want: map[string]float64{"js": 1, "javascript": 1}},
+		{phrase: "tables bobby", want: map[string]float64{"tabl": 1, "bobbi": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			_, got, err := s.Expand(tt.phrase, Options{Language: "english"})
+			if err != nil {
+				t.Fatalf("expand: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expand(%q) = %v, want %v", tt.phrase, got, tt.want)
+			}
+			for _, w := range got {
+				if weight, ok := tt.want[w.Word]; !ok || weight != w.Weight {
+					t.Fatalf("Expand(%q) = %v, want %v", tt.phrase, got, tt.want)
+				}
+			}
+		})
+	}
+
+	// битый файл не заменяет словарь, исправленный подхватывается
+	if err := os.WriteFile(path, []byte("broken"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := synonyms.Reload(); err == nil {
+		t.Fatal("reload of broken file succeeded")
+	}
+	if _, got, _ := s.Expand("js", Options{Language: "english"}); len(got) != 2 {
+		t.Fatalf("after failed reload Expand(js) = %v", got)
+	}
+	before, _, _ := s.Expand("js", Options{Language: "english"})
+	if err := os.WriteFile(path, []byte("js, typescript"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := synonyms.Reload(); err != nil || n != 2 {
+		t.Fatalf("reload = %d, %v", n, err)
+	}
+	after, got, _ := s.Expand("js", Options{Language: "english"})
+	if len(got) != 2 || got[1].Word != "typescript" {
+		t.Fatalf("after reload Expand(js) = %v", got)
+	}
+	// цепочка та же, раскрытие другое - клиенты узнают об этом по версии словаря
+	if after.AnalyzerVersion != before.AnalyzerVersion || after.SynonymsVersion == before.SynonymsVersion || after.SynonymsVersion == "" {
+		t.Fatalf("versions before %+v, after %+v: want same analyzer, new synonyms version", before, after)
+	}
+}
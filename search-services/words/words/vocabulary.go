@@ -0,0 +1,164 @@
+package words
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Vocabulary - настройки нормализации конкретного развертывания поверх списков snowball:
+// добавленные и убранные стоп-слова и защищенные термины.
+// Защищенный термин (c++, ios, xkcd) остается одним токеном как есть:
+// без стемминга и без проверки на стоп-слово. Списки общие для всех языков
+type Vocabulary struct {
+	stopAdd    set
+	stopRemove set
+	protected  set
+	// terms - защищенные термины по убыванию длины: из "ms-dos" и "ms" выигрывает длинный
+	terms [][]rune
+}
+
+// NewVocabulary - элементы приводятся к нижнему регистру, пустые пропускаются
+func NewVocabulary(stopAdd, stopRemove, protected []string) *Vocabulary {
+	v := &Vocabulary{
+		stopAdd:    listSet(stopAdd),
+		stopRemove: listSet(stopRemove),
+		protected:  listSet(protected),
+	}
+	for t := range v.protected {
+		v.terms = append(v.terms, []rune(t))
+	}
+	slices.SortFunc(v.terms, func(a, b []rune) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return slices.Compare(a, b)
+	})
+	return v
+}
+
+func listSet(list []string) set {
+	s := newSet(len(list))
+	for _, w := range list {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			s.Add(w)
+		}
+	}
+	return s
+}
+
+// Lists - активные настройки, каждый список отсортирован
+func (v *Vocabulary) Lists() (stopAdd, stopRemove, protected []string) {
+	if v == nil {
+		return nil, nil, nil
+	}
+	sorted := func(s set) []string {
+		out := make([]string, 0, len(s))
+		for w := range s {
+			out = append(out, w)
+		}
+		slices.Sort(out)
+		return out
+	}
+	return sorted(v.stopAdd), sorted(v.stopRemove), sorted(v.protected)
+}
+
+func (v *Vocabulary) isStop(l language, w string) bool {
+	if v != nil {
+		if v.stopRemove.Has(w) {
+			return false
+		}
+		if v.stopAdd.Has(w) {
+			return true
+		}
+	}
+	return l.isStop(w)
+}
+
+func (v *Vocabulary) isProtected(w string) bool {
+	return v != nil && v.protected.Has(w)
+}
+
+// tokenize - токены фразы в нижнем регистре
+func (v *Vocabulary) tokenize(phrase string) []string {
+	tokens := v.tokens([]rune(phrase))
+	if len(tokens) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, lower(t.text))
+	}
+	return out
+}
+
+// tokens - разбиение по всему, что не буква и не цифра в Unicode, регистр сохраняется
+// для CamelCase. Защищенный термин, начинающийся на границе слова, берется целиком
+// вместе с небуквенными символами и сразу в нижнем регистре
+func (v *Vocabulary) tokens(text []rune) []token {
+	var out []token
+	for i := 0; i < len(text); {
+		if i == 0 || !isWordRune(text[i-1]) {
+			if t := v.matchProtected(text[i:]); t != nil {
+				out = append(out, token{text: string(t), start: i, end: i + len(t), keep: KeepProtected})
+				i += len(t)
+				continue
+			}
+		}
+		if !isWordRune(text[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(text) && isWordRune(text[j]) {
+			j++
+		}
+		t := token{text: string(text[i:j]), start: i, end: j}
+		// e-mail, rock'n'roll: части слова через один дефис или апостроф
+		if n := len(out); n > 0 && out[n-1].end == i-1 && i >= 2 && joiner(text[i-1]) {
+			t.joined = true
+		}
+		if isDigits(t.text) {
+			t.keep = KeepDigits
+		}
+		out = append(out, t)
+		i = j
+	}
+	return out
+}
+
+func joiner(r rune) bool {
+	switch r {
+	case '-', '\'', '\u2010', '\u2011', '\u2019':
+		return true
+	}
+	return false
+}
+
+// lower - посимвольный lowercase: в отличие от strings.ToLower длина в символах
+// не меняется, и позиции токенов совпадают с исходной фразой
+func lower(s string) string {
+	return strings.Map(unicode.ToLower, s)
+}
+
+// matchProtected - термин в начале text без учета регистра; термин должен кончаться
+// на границе слова, если только сам не кончается небуквенным символом, как c++
+func (v *Vocabulary) matchProtected(text []rune) []rune {
+	if v == nil {
+		return nil
+	}
+	for _, t := range v.terms {
+		if len(t) > len(text) || !slices.EqualFunc(text[:len(t)], t, func(a, b rune) bool { return unicode.ToLower(a) == b }) {
+			continue
+		}
+		if len(t) == len(text) || !isWordRune(text[len(t)]) || !isWordRune(t[len(t)-1]) {
+			return t
+		}
+	}
+	return nil
+}
+
+// isWordRune - знаки (IsMark) тоже часть слова: гласные тамильского, огласовки арабского
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+}
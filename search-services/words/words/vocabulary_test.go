@@ -0,0 +1,63 @@
+package words
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVocabularyTokenize(t *testing.T) {
+	v := NewVocabulary(nil, nil, []string{"C++", "c#", ".net", "ms-dos", "ms", " "})
+	tests := []struct {
+		phrase string
+		want   []string
+	}{
+		{phrase: "I love C++!", want: []string{"i", "love", "c++"}},
+		{phrase: "c++11 and c#", want: []string{"c++", "11", "and", "c#"}},
+		{phrase: "asp.net vs .NET", want: []string{"asp", "net", "vs", ".net"}},
+		{phrase: "ms-dos 6.22", want: []string{"ms-dos", "6", "22"}},
+		{phrase: "ms office", want: []string{"ms", "office"}},
+		{phrase: "msdos", want: []string{"msdos"}},
+		{phrase: "abc++", want: []string{"abc"}},
+		{phrase: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			if got := v.tokenize(tt.phrase); !slices.Equal(got, tt.want) {
+				t.Fatalf("tokenize(%q) = %q, want %q", tt.phrase, got, tt.want)
+			}
+		})
+	}
+
+	_, _, protected := v.Lists()
+	if want := []string{".net", "c#", "c++", "ms", "ms-dos"}; !slices.Equal(protected, want) {
+		t.Fatalf("protected = %q, want %q", protected, want)
+	}
+}
+
+func TestNorm_Vocabulary(t *testing.T) {
+	v := NewVocabulary([]string{"comic"}, []string{"will", "It"}, []string{"ios", "c++", "xkcd"})
+	s := NewService(testAnalyzers(t, v, nil), nil, 0)
+
+	tests := []struct {
+		phrase string
+		want   []string
+	}{
+		// will и it больше не стоп-слова, comic - стоп-слово
+		{phrase: "It will work", want: []string{"it", "will", "work"}},
+		{phrase: "the xkcd comic", want: []string{"xkcd"}},
+		// без защиты ios стал бы io, а c++ - c
+		{phrase: "iOS apps in C++", want: []string{"ios", "app", "c++"}},
+		{phrase: "ios iOS", want: []string{"ios"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			res, err := s.Norm(tt.phrase, Options{Language: "english"})
+			if err != nil {
+				t.Fatalf("norm: %v", err)
+			}
+			if !slices.Equal(res.Words, tt.want) {
+				t.Fatalf("Norm(%q) = %q, want %q", tt.phrase, res.Words, tt.want)
+			}
+		})
+	}
+}
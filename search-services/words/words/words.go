@@ -1,61 +1,121 @@
 package words
 
 import (
-	"github.com/kljensen/snowball"
-	"github.com/kljensen/snowball/english"
-	"log"
-	"regexp"
-	"strings"
+	"unicode"
 )
 
-var nonAlphaNum = regexp.MustCompile(`[^a-z0-9]+`)
+// tokenize - lowercase и разбиение по всему, что не буква и не цифра в Unicode,
+// так что кириллица и буквы с диакритикой остаются в токенах
+func tokenize(phrase string) []string {
+	return (*Vocabulary)(nil).tokenize(phrase)
+}
 
-func (s *service) Norm(phrase string) ([]string, error) {
-	// lowercase + отчистка + пробелы между слов
-	lc := strings.ToLower(phrase)
-	clean := nonAlphaNum.ReplaceAllString(lc, " ")
-	tokens := strings.Fields(clean)
+func isDigits(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
 
-	out := make([]string, 0, len(tokens))
-	seen := newSet(len(tokens))
+// request - разобранная фраза: токены, язык и цепочка
+type request struct {
+	runes    []rune
+	tokens   []token
+	language language
+	analyzer *Analyzer
+}
 
-	for _, w := range tokens {
-		if w == "" {
-			continue
-		}
+func (s *service) prepare(phrase string, opt Options) (request, error) {
+	an, err := s.analyzers.Get(opt.Analyzer)
+	if err != nil {
+		return request{}, err
+	}
+	runes := []rune(phrase)
+	tokens := s.analyzers.vocab.tokens(runes)
 
-		// если токен — чисто цифры, оставляем как есть (без стоп-слов и стемминга)
-		isDigits := true
-		for i := 0; i < len(w); i++ {
-			if w[i] < '0' || w[i] > '9' {
-				isDigits = false
-				break
-			}
+	// язык из запроса, иначе определяем по самой фразе
+	var l language
+	if opt.Language != "" {
+		if l, err = lookupLanguage(opt.Language); err != nil {
+			return request{}, err
 		}
-		if isDigits {
-			if seen.Add(w) {
-				out = append(out, w)
-				log.Printf("Norm add_digits: %q", w)
-			}
-			continue
+	} else {
+		words := make([]string, 0, len(tokens))
+		for _, t := range tokens {
+			words = append(words, lower(t.text))
 		}
+		l = detectLanguage(words)
+	}
+	return request{runes: runes, tokens: tokens, language: l, analyzer: an}, nil
+}
 
-		// отсеиваем часто употребляемые слова типа of/a/the/, местоимения и глагольные частицы (will).
-		if english.IsStopWord(w) {
-			log.Printf("Norm drop_stopword: %q", w)
-			continue
-		}
-		// стемминг
-		stem, err := snowball.Stem(w, "english", false)
-		if err != nil || stem == "" {
-			stem = w
+func (r request) normalized(tokens []token) Normalized {
+	words := make([]string, 0, len(tokens))
+	surfaces := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		words = append(words, t.text)
+		surfaces = append(surfaces, lower(string(r.runes[t.start:t.end])))
+	}
+	return Normalized{
+		Words:           words,
+		Surfaces:        surfaces,
+		Language:        r.language.name,
+		Analyzer:        r.analyzer.Name,
+		AnalyzerVersion: r.analyzer.Version,
+	}
+}
+
+func (s *service) Norm(phrase string, opt Options) (Normalized, error) {
+	req, err := s.prepare(phrase, opt)
+	if err != nil {
+		return Normalized{}, err
+	}
+
+	return req.normalized(req.analyzer.run(req.tokens, req.language, nil)), nil
+}
+
+func (s *service) Expand(phrase string, opt Options) (Normalized, []WeightedWord, error) {
+	req, err := s.prepare(phrase, opt)
+	if err != nil {
+		return Normalized{}, nil, err
+	}
+
+	norm := req.normalized(req.analyzer.run(req.tokens, req.language, nil))
+	seen := newSet(len(norm.Words))
+	out := make([]WeightedWord, 0, len(norm.Words))
+	for _, w := range norm.Words {
+		seen.Add(w)
+		out = append(out, WeightedWord{Word: w, Weight: 1})
+	}
+
+	// синонимы ищутся по токенам запроса в нижнем регистре и проходят ту же цепочку;
+	// слова запроса уже в seen, синоним с тем же словом их не понизит
+	raw := make([]string, 0, len(req.tokens))
+	for _, t := range req.tokens {
+		raw = append(raw, lower(t.text))
+	}
+	table := s.synonyms.table()
+	if table != nil {
+		norm.SynonymsVersion = table.version
+	}
+	for _, alt := range table.expansions(raw) {
+		tokens := make([]token, 0, len(alt))
+		for _, w := range alt {
+			t := token{text: w}
+			if s.analyzers.vocab.isProtected(w) {
+				t.keep = KeepProtected
+			} else if isDigits(w) {
+				t.keep = KeepDigits
+			}
+			tokens = append(tokens, t)
 		}
-		// добавляем без дублей
-		if seen.Add(stem) {
-			out = append(out, stem)
-			log.Printf("Norm add_digits: %q", w)
+		for _, t := range req.analyzer.run(tokens, req.language, nil) {
+			if seen.Add(t.text) {
+				out = append(out, WeightedWord{Word: t.text, Weight: s.synonymWeight})
+			}
 		}
 	}
-	log.Printf("Norm done: in=%d out=%d", len(tokens), len(out))
-	return out, nil
+	return norm, out, nil
 }
@@ -0,0 +1,117 @@
+package words
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name   string
+		phrase string
+		want   []string
+	}{
+		{name: "ascii", phrase: "Hello, World!", want: []string{"hello", "world"}},
+		{name: "digits", phrase: "xkcd #1234 (2013)", want: []string{"xkcd", "1234", "2013"}},
+		{name: "cyrillic", phrase: "Привет, МИР!", want: []string{"привет", "мир"}},
+		{name: "yo", phrase: "Ёлка", want: []string{"ёлка"}},
+		{name: "diacritics", phrase: "Crème brûlée, niño", want: []string{"crème", "brûlée", "niño"}},
+		{name: "apostrophe splits", phrase: "don't", want: []string{"don", "t"}},
+		{name: "mixed scripts", phrase: "linux-ядро", want: []string{"linux", "ядро"}},
+		{name: "punctuation only", phrase: "—…!?", want: nil},
+		{name: "empty", phrase: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.phrase); !slices.Equal(got, tt.want) {
+				t.Fatalf("tokenize(%q) = %q, want %q", tt.phrase, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		phrase string
+		want   string
+	}{
+		{phrase: "the cat is on the table", want: "english"},
+		{phrase: "linux", want: "english"},
+		{phrase: "42", want: "english"},
+		{phrase: "кот сидит на столе", want: "russian"},
+		{phrase: "le chat est sur la table", want: "french"},
+		{phrase: "el niño juega en el parque", want: "spanish"},
+		{phrase: "katten är på bordet", want: "swedish"},
+		{phrase: "katten sitter på bordet med meg", want: "norwegian"},
+		{phrase: "der hund und die katze", want: "german"},
+		{phrase: "große straße", want: "german"},
+		{phrase: "Mädchen", want: "german"},
+		{phrase: "Käse", want: "german"},
+		{phrase: "bær", want: "norwegian"},
+		{phrase: "jeg har ikke været hjemme", want: "danish"},
+		{phrase: "ik heb een kat en een hond", want: "dutch"},
+		{phrase: "minä olen kotona ja sinä et ole", want: "finnish"},
+		{phrase: "il gatto dorme sulla sedia con il cane", want: "italian"},
+		{phrase: "o gato não está em casa", want: "portuguese"},
+		{phrase: "pisica este în casă", want: "romanian"},
+		{phrase: "kedi ve kuş ile", want: "turkish"},
+		{phrase: "tá an cat agus an madra sa teach", want: "irish"},
+		{phrase: "القطة على الطاولة", want: "arabic"},
+		{phrase: "பூனை மேசையில் உள்ளது", want: "tamil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			if got := detectLanguage(tokenize(tt.phrase)); got.name != tt.want {
+				t.Fatalf("detectLanguage(%q) = %s, want %s", tt.phrase, got.name, tt.want)
+			}
+		})
+	}
+}
+
+func TestNorm(t *testing.T) {
+	tests := []struct {
+		name     string
+		phrase   string
+		lang     string
+		want     []string
+		wantLang string
+	}{
+		{name: "english", phrase: "The running dogs ran", want: []string{"run", "dog", "ran"}, wantLang: "english"},
+		{name: "russian detected", phrase: "Коты и собаки", want: []string{"кот", "собак"}, wantLang: "russian"},
+		{name: "by code", phrase: "los gatos", lang: "es", want: []string{"gat"}, wantLang: "spanish"},
+		{name: "by name", phrase: "le chat", lang: "French", want: []string{"chat"}, wantLang: "french"},
+		{name: "german by code", phrase: "Die Katzen laufen schnell", lang: "de", want: []string{"katz", "lauf", "schnell"}, wantLang: "german"},
+		{name: "german detected", phrase: "Die Häuser und Straßen", want: []string{"haus", "strass"}, wantLang: "german"},
+		{name: "danish by code", phrase: "hundene løber", lang: "da", want: []string{"hund", "løb"}, wantLang: "danish"},
+		{name: "tamil detected", phrase: "பூனைகள் மேசையில்", want: []string{"பூனை", "மேசை"}, wantLang: "tamil"},
+		{name: "digits kept", phrase: "comic 404", want: []string{"comic", "404"}, wantLang: "english"},
+		{name: "duplicates", phrase: "cats cat", want: []string{"cat"}, wantLang: "english"},
+		{name: "empty", phrase: "", want: []string{}, wantLang: "english"},
+	}
+	s := NewService(nil, nil, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.Norm(tt.phrase, Options{Language: tt.lang})
+			if err != nil {
+				t.Fatalf("Norm(%q, %q): %v", tt.phrase, tt.lang, err)
+			}
+			if !slices.Equal(res.Words, tt.want) || res.Language != tt.wantLang {
+				t.Fatalf("Norm(%q, %q) = %q, %s; want %q, %s", tt.phrase, tt.lang, res.Words, res.Language, tt.want, tt.wantLang)
+			}
+		})
+	}
+
+	if _, err := s.Norm("hallo welt", Options{Language: "klingon"}); !errors.Is(err, ErrUnknownLanguage) {
+		t.Fatalf("unknown language error = %v", err)
+	}
+
+	// написание до стемминга выровнено по словам, у дубликата - первое
+	res, err := s.Norm("The Running dogs, running", Options{})
+	if err != nil {
+		t.Fatalf("norm: %v", err)
+	}
+	if want := []string{"running", "dogs"}; !slices.Equal(res.Surfaces, want) {
+		t.Fatalf("surfaces = %q, want %q", res.Surfaces, want)
+	}
+}
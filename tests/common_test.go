@@ -35,3 +35,33 @@ func TestPing(t *testing.T) {
 	require.Equal(t, "ok", reply.Replies["update"], "no db running")
 	require.Equal(t, "ok", reply.Replies["search"], "no search running")
 }
+
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func TestHealthz(t *testing.T) {
+	resp, err := client.Get(address + "/healthz")
+	require.NoError(t, err, "cannot get healthz")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "wrong status")
+
+	var reply HealthResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+	require.Equal(t, "ok", reply.Status)
+}
+
+func TestReadyz(t *testing.T) {
+	resp, err := client.Get(address + "/readyz")
+	require.NoError(t, err, "cannot get readyz")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "wrong status")
+
+	var reply HealthResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+	require.Equal(t, "ready", reply.Status)
+	for _, service := range []string{"words", "update", "search", "auth", "favorites"} {
+		require.Equal(t, "ok", reply.Checks[service], "%s is not ready", service)
+	}
+}
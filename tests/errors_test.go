@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Instance  string `json:"instance"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id"`
+	Errors    []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func getProblem(t *testing.T, req *http.Request, status int) Problem {
+	t.Helper()
+	resp, err := client.Do(req)
+	require.NoError(t, err, "request failed")
+	defer resp.Body.Close()
+	require.Equal(t, status, resp.StatusCode, "wrong status")
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+	var p Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&p), "decode failed")
+	require.Equal(t, status, p.Status)
+	require.Equal(t, resp.Header.Get("X-Request-ID"), p.RequestID, "request id differs from header")
+	return p
+}
+
+func TestProblemBadParameter(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, address+"/api/search?phrase=linux&limit=asdf", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Request-ID", "tests-bad-limit")
+
+	p := getProblem(t, req, http.StatusBadRequest)
+	require.Equal(t, "invalid_parameter", p.Code)
+	require.Equal(t, "tests-bad-limit", p.RequestID)
+	require.Equal(t, "/api/search", p.Instance)
+	require.Len(t, p.Errors, 1)
+	require.Equal(t, "limit", p.Errors[0].Field)
+}
+
+func TestProblemUnauthorized(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, address+"/api/db/update", nil)
+	require.NoError(t, err)
+
+	p := getProblem(t, req, http.StatusUnauthorized)
+	require.Equal(t, "unauthorized", p.Code)
+	require.NotEmpty(t, p.RequestID)
+}
+
+func TestProblemComicNotFound(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, address+"/api/comics/999999", nil)
+	require.NoError(t, err)
+
+	p := getProblem(t, req, http.StatusNotFound)
+	require.Equal(t, "not_found", p.Code)
+}
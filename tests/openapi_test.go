@@ -0,0 +1,39 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type OpenAPISpec struct {
+	OpenAPI string                                `json:"openapi"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+func getJSON(t *testing.T, url string, v any) {
+	t.Helper()
+	resp, err := client.Get(url)
+	require.NoError(t, err, "cannot get %s", url)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "wrong status for %s", url)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	var spec OpenAPISpec
+	getJSON(t, address+"/api/openapi.json", &spec)
+	require.True(t, strings.HasPrefix(spec.OpenAPI, "3."), "not an openapi 3 document")
+	require.NotEmpty(t, spec.Paths)
+}
+
+func TestSwaggerUI(t *testing.T) {
+	resp, err := client.Get(address + "/api/docs")
+	require.NoError(t, err, "cannot get docs")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "wrong status")
+	require.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+}